@@ -18,6 +18,8 @@ import (
 	"go.uber.org/zap"
 
 	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/service"
 	"code-kanban/service/terminal"
 	"code-kanban/utils"
 )
@@ -51,7 +53,7 @@ func Init(ctx context.Context, cfg *utils.AppConfig, assets embed.FS, info *AppI
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.CorsAllowOrigins,
 		AllowMethods:     "GET,POST",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, " + userIDHeader + ", " + agentHeader,
 		AllowCredentials: cfg.CorsAllowOrigins != "*",
 	}))
 	app.Use(recover.New(recover.Config{EnableStackTrace: true}))
@@ -60,9 +62,20 @@ func Init(ctx context.Context, cfg *utils.AppConfig, assets embed.FS, info *AppI
 
 	humaAPI, v1 := h.NewAPI(app, cfg)
 	humaAPI.UseMiddleware(h.HumaTraceMiddleware)
+	humaAPI.UseMiddleware(taskActorMiddleware)
 	h.HumaValidatePatch()
 	humaTypesRegister()
 
+	// 先构建可能失败的依赖，避免初始化失败后遗留已启动的后台任务
+	attachmentStore, err := service.NewAttachmentStorage(cfg.AttachmentConfig)
+	if err != nil {
+		return err
+	}
+	commitMessageDrafter, err := service.NewCommitMessageDrafter(cfg.CommitMessage)
+	if err != nil {
+		return err
+	}
+
 	terminalManager := terminal.NewManager(terminal.Config{
 		Shell:                 cfg.Terminal.Shell,
 		IdleTimeout:           cfg.Terminal.IdleDuration(),
@@ -72,16 +85,47 @@ func Init(ctx context.Context, cfg *utils.AppConfig, assets embed.FS, info *AppI
 		AIAssistantStatus:     cfg.Terminal.AIAssistantStatus,
 	}, theLogger)
 	terminalManager.StartBackground(ctx)
+	issueSyncRunner := service.NewIssueSyncRunner(cfg.IssueSync)
+	issueSyncRunner.StartBackground(ctx)
+	service.NewTaskScheduler().StartBackground(ctx)
+	remoteSyncScheduler := service.NewRemoteSyncScheduler(cfg.RemoteSync)
+	remoteSyncScheduler.StartBackground(ctx)
+	watchAssistantEvents(ctx, terminalManager, theLogger)
+	service.NewTimeTracker(terminalManager).StartBackground(ctx)
 
 	registerHealthRoutes(app, humaAPI)
 	registerProjectRoutes(v1)
-	registerWorktreeRoutes(v1)
+	registerWorktreeRoutes(v1, commitMessageDrafter)
+	registerWorktreeHookRoutes(v1)
+	registerWorktreeSourceRoutes(v1)
+	registerWorktreeDiffRoutes(v1)
+	registerWorktreeStageRoutes(v1)
 	registerBranchRoutes(v1)
+	registerCommitHistoryRoutes(v1)
+	registerMergePreviewRoutes(v1)
+	registerRemoteRoutes(v1, remoteSyncScheduler)
 	registerTaskRoutes(v1)
+	registerTaskBulkRoutes(v1)
+	registerTaskCommentRoutes(v1, terminalManager)
+	registerTaskColumnRoutes(v1)
+	registerTaskChecklistRoutes(v1)
+	registerTaskBoardRoutes(v1)
+	registerTaskEventRoutes(v1)
+	registerTaskExchangeRoutes(v1)
+	registerIssueSyncRoutes(v1, issueSyncRunner)
+	registerTaskRecurrenceRoutes(v1)
+	registerTaskTimeRoutes(v1)
+	registerTaskAutomationRoutes(v1)
 	registerNotePadRoutes(v1)
 	registerSystemRoutes(v1, cfg)
 	registerUploadRoutes(v1, cfg, theLogger)
+	registerAttachmentRoutes(v1, model.NewAttachmentService(attachmentStore, model.AttachmentOptions{
+		SizeLimit:      cfg.AttachmentSizeLimit * 1024,
+		CompressImages: cfg.ImageCompress,
+	}))
 	registerTerminalRoutes(app, v1, cfg, terminalManager, theLogger)
+	registerTaskAgentRoutes(v1, cfg, terminalManager)
+	registerWorktreeConflictRoutes(v1, cfg, terminalManager)
 	mountStatic(app, cfg, assets, theLogger)
 	exposeOpenAPI(app, humaAPI, cfg, theLogger)
 
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/model/tables"
+)
+
+const attachmentTag = "attachment-附件"
+
+type uploadAttachmentForm struct {
+	File      huma.FormFile `form:"file" required:"true" doc:"上传的文件"`
+	OwnerType string        `form:"ownerType" enum:"task,comment,notepad" required:"true" doc:"附件所属对象类型"`
+	OwnerID   string        `form:"ownerId" required:"true" doc:"附件所属对象ID"`
+}
+
+type attachmentDownloadOutput struct {
+	ContentType        string `header:"Content-Type"`
+	ContentDisposition string `header:"Content-Disposition"`
+	CacheControl       string `header:"Cache-Control"`
+	ContentTypeOptions string `header:"X-Content-Type-Options"`
+	Body               []byte
+}
+
+func registerAttachmentRoutes(group *huma.Group, attachmentService *model.AttachmentService) {
+	huma.Post(group, "/attachments/upload", func(ctx context.Context, input *struct {
+		RawBody huma.MultipartFormFiles[uploadAttachmentForm]
+	}) (*h.ItemResponse[tables.AttachmentTable], error) {
+		form := input.RawBody.Data()
+		defer form.File.Close()
+		data, err := io.ReadAll(form.File)
+		if err != nil {
+			return nil, huma.Error400BadRequest("failed to read uploaded file", err)
+		}
+
+		attachment, err := attachmentService.Upload(ctx, &model.UploadAttachmentRequest{
+			OwnerType:   form.OwnerType,
+			OwnerID:     form.OwnerID,
+			FileName:    form.File.Filename,
+			ContentType: form.File.ContentType,
+			Data:        data,
+		})
+		if err != nil {
+			return nil, mapAttachmentError(err)
+		}
+
+		resp := h.NewItemResponse(*attachment)
+		resp.Status = http.StatusCreated
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "attachment-upload"
+		op.Summary = "上传附件"
+		op.Description = "以 multipart/form-data 上传文件并关联到任务、评论或记事板。开启 imageCompress 时，较大的 JPEG/PNG 图片会在保存前压缩。"
+		op.Tags = []string{attachmentTag}
+	})
+
+	huma.Get(group, "/attachments", func(ctx context.Context, input *struct {
+		OwnerType string `query:"ownerType" enum:"task,comment,notepad" required:"true" doc:"附件所属对象类型"`
+		OwnerID   string `query:"ownerId" required:"true" doc:"附件所属对象ID"`
+	}) (*h.ItemsResponse[tables.AttachmentTable], error) {
+		attachments, err := attachmentService.ListAttachments(ctx, input.OwnerType, input.OwnerID)
+		if err != nil {
+			return nil, mapAttachmentError(err)
+		}
+
+		resp := h.NewItemsResponse(attachments)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "attachment-list"
+		op.Summary = "附件列表"
+		op.Tags = []string{attachmentTag}
+	})
+
+	huma.Get(group, "/attachments/{id}/download", func(ctx context.Context, input *struct {
+		ID       string `path:"id"`
+		Download bool   `query:"download" doc:"强制以下载方式返回，而不是在浏览器中直接预览"`
+	}) (*attachmentDownloadOutput, error) {
+		attachment, data, err := attachmentService.ReadAttachment(ctx, input.ID)
+		if err != nil {
+			return nil, mapAttachmentError(err)
+		}
+
+		return &attachmentDownloadOutput{
+			ContentType:        attachment.ContentType,
+			ContentDisposition: attachmentDisposition(attachment, input.Download),
+			CacheControl:       "private, max-age=86400",
+			ContentTypeOptions: "nosniff",
+			Body:               data,
+		}, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "attachment-download"
+		op.Summary = "下载附件"
+		op.Tags = []string{attachmentTag}
+	})
+
+	huma.Post(group, "/attachments/{id}/delete", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.MessageResponse, error) {
+		if err := attachmentService.DeleteAttachment(ctx, input.ID); err != nil {
+			return nil, mapAttachmentError(err)
+		}
+
+		resp := h.NewMessageResponse("deleted")
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "attachment-delete"
+		op.Summary = "删除附件"
+		op.Tags = []string{attachmentTag}
+	})
+}
+
+// attachmentDisposition 图片、PDF 和纯文本在浏览器内预览，其余类型（包括可执行脚本的 SVG）一律下载
+func attachmentDisposition(attachment *tables.AttachmentTable, forceDownload bool) string {
+	disposition := "attachment"
+	mediaType, _, _ := mime.ParseMediaType(attachment.ContentType)
+	inline := (strings.HasPrefix(mediaType, "image/") && mediaType != "image/svg+xml") ||
+		mediaType == "application/pdf" || mediaType == "text/plain"
+	if inline && !forceDownload {
+		disposition = "inline"
+	}
+	return mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName})
+}
+
+func mapAttachmentError(err error) error {
+	switch {
+	case errors.Is(err, model.ErrAttachmentNotFound):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, model.ErrNotePadNotFound):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, model.ErrInvalidAttachment):
+		return huma.Error400BadRequest(err.Error())
+	case errors.Is(err, model.ErrAttachmentTooLarge):
+		return huma.NewError(http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		return mapTaskError(err)
+	}
+}
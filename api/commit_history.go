@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/service"
+	"code-kanban/utils/git"
+)
+
+type worktreeCommitsInput struct {
+	ID       string   `path:"id"`
+	Ahead    bool     `query:"ahead" doc:"只列出领先于基准分支的提交，即该 worktree 新产生的提交"`
+	Base     string   `query:"base" doc:"ahead 的基准分支，默认项目的默认分支"`
+	Path     []string `query:"path" doc:"只列出修改了这些路径的提交，可重复"`
+	Author   string   `query:"author" doc:"按作者名或邮箱过滤（不区分大小写的正则）"`
+	Page     int      `query:"page" default:"1"`
+	PageSize int      `query:"pageSize" default:"50"`
+}
+
+type branchCommitsInput struct {
+	ProjectID  string   `path:"projectId"`
+	BranchName string   `path:"branchName"`
+	Ahead      bool     `query:"ahead" doc:"只列出领先于基准分支的提交"`
+	Base       string   `query:"base" doc:"ahead 的基准分支，默认项目的默认分支"`
+	Path       []string `query:"path" doc:"只列出修改了这些路径的提交，可重复"`
+	Author     string   `query:"author" doc:"按作者名或邮箱过滤（不区分大小写的正则）"`
+	Page       int      `query:"page" default:"1"`
+	PageSize   int      `query:"pageSize" default:"50"`
+}
+
+type worktreeCommitInput struct {
+	ID               string `path:"id"`
+	SHA              string `path:"sha" doc:"提交哈希，可以是缩写"`
+	Context          int    `query:"context" minimum:"0" maximum:"100" doc:"上下文行数，默认 3"`
+	IgnoreWhitespace bool   `query:"ignoreWhitespace" doc:"忽略空白变化"`
+	WordDiff         bool   `query:"wordDiff" doc:"返回行内（按词）差异"`
+	MaxFiles         int    `query:"maxFiles" minimum:"0" maximum:"2000" doc:"最多返回的文件数，默认 300"`
+	MaxFileLines     int    `query:"maxFileLines" minimum:"0" maximum:"50000" doc:"单个文件超过该行数时只返回统计，默认 3000"`
+}
+
+type projectCommitInput struct {
+	ProjectID        string `path:"projectId"`
+	SHA              string `path:"sha" doc:"提交哈希，可以是缩写"`
+	Context          int    `query:"context" minimum:"0" maximum:"100" doc:"上下文行数，默认 3"`
+	IgnoreWhitespace bool   `query:"ignoreWhitespace" doc:"忽略空白变化"`
+	WordDiff         bool   `query:"wordDiff" doc:"返回行内（按词）差异"`
+	MaxFiles         int    `query:"maxFiles" minimum:"0" maximum:"2000" doc:"最多返回的文件数，默认 300"`
+	MaxFileLines     int    `query:"maxFileLines" minimum:"0" maximum:"50000" doc:"单个文件超过该行数时只返回统计，默认 3000"`
+}
+
+func commitDiffOptions(contextLines int, ignoreWhitespace, wordDiff bool, maxFiles, maxFileLines int) git.DiffOptions {
+	return git.DiffOptions{
+		ContextLines:     contextLines,
+		IgnoreWhitespace: ignoreWhitespace,
+		WordDiff:         wordDiff,
+		Limits:           git.DiffLimits{MaxFiles: maxFiles, MaxFileLines: maxFileLines},
+	}
+}
+
+func registerCommitHistoryRoutes(group *huma.Group) {
+	worktreeSvc := service.NewWorktreeService()
+	branchSvc := service.NewBranchService()
+
+	huma.Get(group, "/worktrees/{id}/commits", func(ctx context.Context, input *worktreeCommitsInput) (*h.PaginatedResponse[git.Commit], error) {
+		query := &service.CommitQuery{
+			AheadOnly: input.Ahead,
+			Base:      input.Base,
+			Paths:     input.Path,
+			Author:    input.Author,
+			Page:      input.Page,
+			PageSize:  input.PageSize,
+		}
+		commits, total, err := worktreeSvc.ListWorktreeCommits(ctx, input.ID, query)
+		if err != nil {
+			return nil, mapWorktreeGitError(err)
+		}
+
+		resp := h.NewPaginatedResponse(commits, total, query.Page, query.PageSize)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-commit-list"
+		op.Summary = "Worktree 提交历史"
+		op.Description = "按时间倒序分页返回提交，包含作者、时间、提交信息和文件改动统计。"
+		op.Tags = []string{worktreeTag}
+	})
+
+	huma.Get(group, "/worktrees/{id}/commits/{sha}", func(ctx context.Context, input *worktreeCommitInput) (*h.ItemResponse[service.CommitDetail], error) {
+		opts := commitDiffOptions(input.Context, input.IgnoreWhitespace, input.WordDiff, input.MaxFiles, input.MaxFileLines)
+		detail, err := worktreeSvc.GetWorktreeCommit(ctx, input.ID, input.SHA, opts)
+		if err != nil {
+			return nil, mapWorktreeGitError(err)
+		}
+
+		resp := h.NewItemResponse(*detail)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-commit-get"
+		op.Summary = "Worktree 提交详情"
+		op.Description = "返回提交信息及其相对第一个父提交的差异。"
+		op.Tags = []string{worktreeTag}
+	})
+
+	huma.Get(group, "/projects/{projectId}/branches/{branchName}/commits", func(ctx context.Context, input *branchCommitsInput) (*h.PaginatedResponse[git.Commit], error) {
+		query := &service.CommitQuery{
+			AheadOnly: input.Ahead,
+			Base:      input.Base,
+			Paths:     input.Path,
+			Author:    input.Author,
+			Page:      input.Page,
+			PageSize:  input.PageSize,
+		}
+		// 路由参数不会自动解码，feature%2Fx 这样的分支名需要还原
+		branch, err := url.PathUnescape(input.BranchName)
+		if err != nil {
+			return nil, huma.Error400BadRequest("invalid branch name")
+		}
+		commits, total, err := branchSvc.ListBranchCommits(ctx, input.ProjectID, branch, query)
+		if err != nil {
+			return nil, mapCommitHistoryError(err)
+		}
+
+		resp := h.NewPaginatedResponse(commits, total, query.Page, query.PageSize)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "branch-commit-list"
+		op.Summary = "分支提交历史"
+		op.Description = "branchName 可以是本地分支，也可以是 origin/feature 这样的远程跟踪分支；包含斜杠时需要 URL 编码。"
+		op.Tags = []string{branchTag}
+	})
+
+	huma.Get(group, "/projects/{projectId}/commits/{sha}", func(ctx context.Context, input *projectCommitInput) (*h.ItemResponse[service.CommitDetail], error) {
+		opts := commitDiffOptions(input.Context, input.IgnoreWhitespace, input.WordDiff, input.MaxFiles, input.MaxFileLines)
+		detail, err := branchSvc.GetCommit(ctx, input.ProjectID, input.SHA, opts)
+		if err != nil {
+			return nil, mapCommitHistoryError(err)
+		}
+
+		resp := h.NewItemResponse(*detail)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "project-commit-get"
+		op.Summary = "提交详情"
+		op.Description = "返回项目仓库中任意提交的信息及其相对第一个父提交的差异。"
+		op.Tags = []string{branchTag}
+	})
+}
+
+func mapCommitHistoryError(err error) error {
+	switch {
+	case errors.Is(err, git.ErrCommitNotFound),
+		errors.Is(err, git.ErrBaseBranchNotFound),
+		errors.Is(err, model.ErrBranchNotFound):
+		return huma.Error404NotFound(err.Error())
+	default:
+		return mapBranchError(err)
+	}
+}
@@ -0,0 +1,54 @@
+package h
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humafiber"
+)
+
+// EventSender 向 SSE 流写入一条事件，连接断开时返回错误。
+type EventSender func(event string, data any) error
+
+// NewEventStream 构造 text/event-stream 流式响应。
+// Fiber 默认会缓冲整个响应体，huma 自带的 sse 包在这里无法逐条推送，
+// 因此改用 fasthttp 的 SetBodyStreamWriter，每条事件写完立即 flush。
+// run 在 handler 返回之后执行，不能再访问 huma.Context。
+func NewEventStream(run func(send EventSender)) *huma.StreamResponse {
+	return &huma.StreamResponse{
+		Body: func(ctx huma.Context) {
+			fiberCtx := humafiber.Unwrap(ctx)
+			fiberCtx.Set("Content-Type", "text/event-stream")
+			fiberCtx.Set("Cache-Control", "no-cache")
+			fiberCtx.Set("X-Accel-Buffering", "no")
+			fiberCtx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+				run(func(event string, data any) error {
+					payload, err := json.Marshal(data)
+					if err != nil {
+						return err
+					}
+					if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", strings.TrimSpace(event), payload); err != nil {
+						return err
+					}
+					return w.Flush()
+				})
+			})
+		},
+	}
+}
+
+// EventStreamResponses 为流式接口补充 OpenAPI 中的响应说明。
+func EventStreamResponses(op *huma.Operation, description string) {
+	if op.Responses == nil {
+		op.Responses = map[string]*huma.Response{}
+	}
+	op.Responses["200"] = &huma.Response{
+		Description: description,
+		Content: map[string]*huma.MediaType{
+			"text/event-stream": {Schema: &huma.Schema{Type: huma.TypeString}},
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/service"
+)
+
+const issueSyncTag = "issue-sync-议题同步"
+
+type updateIssueSyncInput struct {
+	ID   string `path:"id"`
+	Body struct {
+		Enabled   bool   `json:"enabled" doc:"是否启用后台定时同步"`
+		Direction string `json:"direction,omitempty" enum:"both,pull,push" default:"both" doc:"同步方向"`
+	}
+}
+
+type runIssueSyncInput struct {
+	ID   string `path:"id"`
+	Body struct {
+		Direction string `json:"direction,omitempty" enum:"both,pull,push" doc:"本次同步方向，默认使用项目配置"`
+	}
+}
+
+func registerIssueSyncRoutes(group *huma.Group, runner *service.IssueSyncRunner) {
+	huma.Get(group, "/projects/{id}/issue-sync", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.ItemResponse[service.IssueSyncStatus], error) {
+		status, err := runner.Status(ctx, input.ID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*status)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "issue-sync-status"
+		op.Summary = "议题同步状态"
+		op.Description = "返回项目的同步配置、上次同步结果以及当前是否有同步任务在运行。"
+		op.Tags = []string{issueSyncTag}
+	})
+
+	huma.Post(group, "/projects/{id}/issue-sync/update", func(ctx context.Context, input *updateIssueSyncInput) (*h.ItemResponse[service.IssueSyncStatus], error) {
+		status, err := runner.Configure(ctx, input.ID, input.Body.Enabled, input.Body.Direction)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*status)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "issue-sync-update"
+		op.Summary = "更新议题同步配置"
+		op.Tags = []string{issueSyncTag}
+	})
+
+	huma.Post(group, "/projects/{id}/issue-sync/run", func(ctx context.Context, input *runIssueSyncInput) (*h.ItemResponse[service.IssueSyncStatus], error) {
+		if err := runner.Trigger(ctx, input.ID, input.Body.Direction); err != nil {
+			if errors.Is(err, service.ErrIssueSyncRunning) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+			return nil, mapTaskError(err)
+		}
+		status, err := runner.Status(ctx, input.ID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*status)
+		resp.Status = http.StatusAccepted
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "issue-sync-run"
+		op.Summary = "立即同步议题"
+		op.Description = "在后台与项目远程仓库（GitHub/Gitea）的 issue 双向同步，通过状态接口查看进度与结果。"
+		op.Tags = []string{issueSyncTag}
+	})
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/service"
+	"code-kanban/utils/git"
+)
+
+type mergePreviewBody struct {
+	Target string `json:"target,omitempty" doc:"合入的目标分支，默认项目的默认分支"`
+}
+
+type worktreeMergePreviewInput struct {
+	ID   string           `path:"id"`
+	Body mergePreviewBody `json:"body"`
+}
+
+type conflictMatrixInput struct {
+	ProjectID string `path:"projectId"`
+	Base      string `query:"base" doc:"基准分支，默认项目的默认分支"`
+}
+
+func registerMergePreviewRoutes(group *huma.Group) {
+	worktreeSvc := service.NewWorktreeService()
+
+	huma.Post(group, "/worktrees/{id}/merge/preview", func(ctx context.Context, input *worktreeMergePreviewInput) (*h.ItemResponse[git.MergePreview], error) {
+		preview, err := worktreeSvc.PreviewWorktreeMerge(ctx, input.ID, input.Body.Target)
+		if err != nil {
+			return nil, mapWorktreeGitError(err)
+		}
+
+		resp := h.NewItemResponse(*preview)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-merge-preview"
+		op.Summary = "预览合并"
+		op.Description = "用 git merge-tree 在内存中计算把该 worktree 已提交的内容合入目标分支的结果，返回冲突文件和改动统计。" +
+			"不会修改任何 worktree，未提交的修改不计入。"
+		op.Tags = []string{branchTag}
+	})
+
+	huma.Get(group, "/projects/{projectId}/merge/conflict-matrix", func(ctx context.Context, input *conflictMatrixInput) (*h.ItemResponse[service.ConflictMatrix], error) {
+		matrix, err := worktreeSvc.ConflictMatrix(ctx, input.ProjectID, input.Base)
+		if err != nil {
+			return nil, mapWorktreeGitError(err)
+		}
+
+		resp := h.NewItemResponse(*matrix)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "project-merge-conflict-matrix"
+		op.Summary = "分支冲突矩阵"
+		op.Description = "对项目中所有尚未合入基准分支的 worktree 分支两两预览合并，列出会互相冲突的组合以及各自与基准分支的冲突，" +
+			"用于安排多个并行分支的合并顺序。"
+		op.Tags = []string{branchTag}
+	})
+}
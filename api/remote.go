@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/service"
+	"code-kanban/utils/git"
+)
+
+const remoteStreamDescription = "SSE 事件流：progress 为 git 输出的进度行，done 携带最新的实体，error 携带 status 和 detail，ping 为心跳；客户端断开后 git 进程随之终止。"
+
+// remoteEventHeartbeat 定期发送 ping，既保持连接不被代理断开，也借写入失败发现客户端已离开
+const remoteEventHeartbeat = 30 * time.Second
+
+type fetchBody struct {
+	Remote string `json:"remote,omitempty" doc:"只抓取该远程，默认抓取全部远程"`
+	Prune  bool   `json:"prune,omitempty" default:"true" doc:"清理远端已删除分支对应的远程跟踪分支"`
+}
+
+type projectFetchInput struct {
+	ProjectID string    `path:"projectId"`
+	Body      fetchBody `json:"body"`
+}
+
+type pullBody struct {
+	Remote string `json:"remote,omitempty" doc:"默认使用当前分支的上游"`
+	Branch string `json:"branch,omitempty" doc:"远程分支名，默认使用当前分支的上游"`
+	Mode   string `json:"mode,omitempty" enum:"ff-only,rebase" default:"ff-only" doc:"ff-only 只允许快进；rebase 把本地提交变基到远程分支之上"`
+}
+
+type worktreePullInput struct {
+	ID   string   `path:"id"`
+	Body pullBody `json:"body"`
+}
+
+type pushBody struct {
+	Remote         string `json:"remote,omitempty" doc:"默认使用上游远程，其次 origin"`
+	Branch         string `json:"branch,omitempty" doc:"远程分支名，默认与上游或本地分支同名"`
+	SetUpstream    bool   `json:"setUpstream,omitempty" doc:"推送后把远程分支设为上游"`
+	ForceWithLease bool   `json:"forceWithLease,omitempty" doc:"强制推送，但远程分支在上次抓取后有变化时拒绝"`
+}
+
+type worktreePushInput struct {
+	ID   string   `path:"id"`
+	Body pushBody `json:"body"`
+}
+
+type remoteErrorEvent struct {
+	Status int    `json:"status"`
+	Detail string `json:"detail"`
+}
+
+func registerRemoteRoutes(group *huma.Group, remoteSync *service.RemoteSyncScheduler) {
+	remoteSvc := service.NewRemoteService()
+	projectSvc := model.NewProjectService()
+	worktreeSvc := service.NewWorktreeService()
+
+	huma.Post(group, "/projects/{projectId}/fetch", func(ctx context.Context, input *projectFetchInput) (*huma.StreamResponse, error) {
+		if _, err := projectSvc.GetProject(ctx, input.ProjectID); err != nil {
+			return nil, mapBranchError(err)
+		}
+		opts := git.FetchOptions{Remote: input.Body.Remote, Prune: input.Body.Prune}
+		return h.NewEventStream(func(send h.EventSender) {
+			ctx, send, stop := remoteStream(ctx, send)
+			defer stop()
+			project, err := remoteSync.SyncProject(ctx, input.ProjectID, opts, progressSender(send))
+			finishRemoteStream(send, project, err)
+		}), nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "project-fetch"
+		op.Summary = "抓取远程"
+		op.Description = "执行 git fetch，完成后更新项目的 lastSyncAt 并刷新各 worktree 的 ahead/behind；默认分支前进时同样推送远程事件。凭据使用本机 git 配置，不会交互式询问。"
+		op.Tags = []string{branchTag}
+		h.EventStreamResponses(op, remoteStreamDescription)
+	})
+
+	huma.Get(group, "/remote-sync/events", func(ctx context.Context, input *struct {
+		ProjectID string `query:"projectId" doc:"只接收该项目的事件，留空接收全部"`
+	}) (*huma.StreamResponse, error) {
+		return h.NewEventStream(func(send h.EventSender) {
+			events := make(chan service.RemoteEvent, 16)
+			remove := remoteSync.OnRemoteEvent(func(event service.RemoteEvent) {
+				if input.ProjectID != "" && event.ProjectID != input.ProjectID {
+					return
+				}
+				// 客户端消费过慢时丢弃事件，避免阻塞后台抓取
+				select {
+				case events <- event:
+				default:
+				}
+			})
+			defer remove()
+
+			heartbeat := time.NewTicker(remoteEventHeartbeat)
+			defer heartbeat.Stop()
+			for {
+				select {
+				case event := <-events:
+					if err := send(event.Type, event); err != nil {
+						return
+					}
+				case <-heartbeat.C:
+					if err := send("ping", struct{}{}); err != nil {
+						return
+					}
+				}
+			}
+		}), nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "remote-sync-events"
+		op.Summary = "订阅远程同步事件"
+		op.Description = "后台定时抓取或手动抓取发现默认分支前进时推送 default-branch-advanced 事件，stale 列出落后于新提交、需要变基的 worktree。"
+		op.Tags = []string{branchTag}
+		h.EventStreamResponses(op, "SSE 事件流：default-branch-advanced 携带远程事件，ping 为心跳。")
+	})
+
+	huma.Post(group, "/worktrees/{id}/pull", func(ctx context.Context, input *worktreePullInput) (*huma.StreamResponse, error) {
+		if _, err := worktreeSvc.GetWorktree(ctx, input.ID); err != nil {
+			return nil, mapWorktreeError(err)
+		}
+		opts := git.PullOptions{Remote: input.Body.Remote, Branch: input.Body.Branch, Rebase: input.Body.Mode == "rebase"}
+		return h.NewEventStream(func(send h.EventSender) {
+			ctx, send, stop := remoteStream(ctx, send)
+			defer stop()
+			worktree, err := remoteSvc.Pull(ctx, input.ID, opts, progressSender(send))
+			finishRemoteStream(send, worktree, err)
+		}), nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-pull"
+		op.Summary = "拉取远程更新"
+		op.Description = "rebase 模式遇到冲突时保留变基状态并返回 409，可通过冲突处理接口继续或放弃。"
+		op.Tags = []string{worktreeTag}
+		h.EventStreamResponses(op, remoteStreamDescription)
+	})
+
+	huma.Post(group, "/worktrees/{id}/push", func(ctx context.Context, input *worktreePushInput) (*huma.StreamResponse, error) {
+		if _, err := worktreeSvc.GetWorktree(ctx, input.ID); err != nil {
+			return nil, mapWorktreeError(err)
+		}
+		opts := git.PushOptions{
+			Remote:         input.Body.Remote,
+			Branch:         input.Body.Branch,
+			SetUpstream:    input.Body.SetUpstream,
+			ForceWithLease: input.Body.ForceWithLease,
+		}
+		return h.NewEventStream(func(send h.EventSender) {
+			ctx, send, stop := remoteStream(ctx, send)
+			defer stop()
+			worktree, err := remoteSvc.Push(ctx, input.ID, opts, progressSender(send))
+			finishRemoteStream(send, worktree, err)
+		}), nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-push"
+		op.Summary = "推送到远程"
+		op.Tags = []string{worktreeTag}
+		h.EventStreamResponses(op, remoteStreamDescription)
+	})
+
+	huma.Get(group, "/worktrees/{id}/upstream", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.ItemResponse[git.Upstream], error) {
+		upstream, err := remoteSvc.Upstream(ctx, input.ID)
+		if err != nil {
+			return nil, mapRemoteError(err)
+		}
+
+		resp := h.NewItemResponse(*upstream)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-upstream"
+		op.Summary = "上游分支"
+		op.Description = "返回当前分支跟踪的远程分支，未设置上游时返回 404。"
+		op.Tags = []string{worktreeTag}
+	})
+}
+
+// remoteStream 包装长时间运行的远程操作的事件流：串行化写入，git 无输出时定期 ping，
+// 一旦写入失败（客户端已断开）即取消返回的 ctx，从而终止 git 进程
+func remoteStream(ctx context.Context, send h.EventSender) (context.Context, h.EventSender, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	var mu sync.Mutex
+	guarded := func(event string, data any) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := send(event, data); err != nil {
+			cancel()
+			return err
+		}
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		heartbeat := time.NewTicker(remoteEventHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-heartbeat.C:
+				_ = guarded("ping", struct{}{})
+			}
+		}
+	}()
+	return ctx, guarded, func() {
+		cancel()
+		<-done
+	}
+}
+
+func progressSender(send h.EventSender) git.ProgressFunc {
+	return func(progress git.RemoteProgress) {
+		_ = send("progress", progress)
+	}
+}
+
+// finishRemoteStream 发送最终结果；流已经开始，错误只能以事件的形式告知客户端
+func finishRemoteStream(send h.EventSender, result any, err error) {
+	if err == nil {
+		_ = send("done", result)
+		return
+	}
+	event := remoteErrorEvent{Status: http.StatusInternalServerError, Detail: err.Error()}
+	var statusErr huma.StatusError
+	if errors.As(mapRemoteError(err), &statusErr) {
+		event.Status = statusErr.GetStatus()
+	}
+	_ = send("error", event)
+}
+
+func mapRemoteError(err error) error {
+	switch {
+	case errors.Is(err, git.ErrNoUpstream),
+		errors.Is(err, git.ErrRemoteBranchNotFound),
+		errors.Is(err, git.ErrPullRequestNotFound):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, git.ErrNoRemote),
+		errors.Is(err, git.ErrDetachedHead):
+		return huma.Error400BadRequest(err.Error())
+	case errors.Is(err, git.ErrNotFastForward),
+		errors.Is(err, git.ErrStaleLease),
+		errors.Is(err, git.ErrPullConflicts):
+		return huma.Error409Conflict(err.Error())
+	default:
+		return mapWorktreeGitError(err)
+	}
+}
@@ -18,16 +18,20 @@ import (
 const (
 	taskTag        = "task-任务看板"
 	taskCommentTag = "task-comment-任务评论"
+	taskLinkTag    = "task-link-任务关联"
 )
 
 type createTaskBody struct {
 	Title       string     `json:"title" minLength:"1" doc:"任务标题"`
 	Description string     `json:"description" doc:"任务描述"`
-	Status      string     `json:"status" enum:"todo,in_progress,done,archived" default:"todo" doc:"任务状态"`
+	Status      string     `json:"status,omitempty" doc:"任务状态（看板列 key），为空时使用第一个待办列"`
 	Priority    int        `json:"priority" minimum:"0" maximum:"3" default:"0" doc:"优先级"`
 	Tags        []string   `json:"tags" doc:"标签"`
 	WorktreeID  *string    `json:"worktreeId" doc:"关联的 Worktree"`
 	DueDate     *time.Time `json:"dueDate" doc:"截止日期"`
+
+	EstimateMinutes *int     `json:"estimateMinutes,omitempty" minimum:"0" doc:"预估工时（分钟）"`
+	EstimatePoints  *float64 `json:"estimatePoints,omitempty" minimum:"0" doc:"故事点"`
 }
 
 type updateTaskBody struct {
@@ -36,12 +40,16 @@ type updateTaskBody struct {
 	Priority    *int       `json:"priority,omitempty" doc:"优先级"`
 	Tags        *[]string  `json:"tags,omitempty" doc:"标签"`
 	DueDate     *time.Time `json:"dueDate,omitempty" doc:"截止日期"`
+
+	EstimateMinutes *int     `json:"estimateMinutes,omitempty" minimum:"0" doc:"预估工时（分钟）"`
+	EstimatePoints  *float64 `json:"estimatePoints,omitempty" minimum:"0" doc:"故事点"`
 }
 
 type moveTaskBody struct {
-	Status     string   `json:"status,omitempty" doc:"新状态"`
-	OrderIndex *float64 `json:"orderIndex,omitempty" doc:"排序索引"`
-	WorktreeID *string  `json:"worktreeId,omitempty" doc:"关联 Worktree"`
+	Status          string   `json:"status,omitempty" doc:"新状态"`
+	OrderIndex      *float64 `json:"orderIndex,omitempty" doc:"排序索引"`
+	WorktreeID      *string  `json:"worktreeId,omitempty" doc:"关联 Worktree"`
+	EnforceBlockers bool     `json:"enforceBlockers,omitempty" doc:"存在未完成的前置任务时拒绝移入进行中/已完成列"`
 }
 
 type bindWorktreeBody struct {
@@ -49,12 +57,22 @@ type bindWorktreeBody struct {
 }
 
 type createCommentBody struct {
-	Content string `json:"content" minLength:"1" doc:"评论内容"`
+	Content       string  `json:"content,omitempty" doc:"评论内容（Markdown），结构化评论可为空"`
+	ParentID      *string `json:"parentId,omitempty" doc:"回复的评论ID，回复会挂在该评论所在的讨论串下"`
+	Kind          string  `json:"kind,omitempty" enum:"text,terminal_output,diff" default:"text" doc:"评论类型"`
+	Excerpt       string  `json:"excerpt,omitempty" doc:"终端输出片段或 diff 原文，kind 为 terminal_output/diff 时必填"`
+	ExcerptSource string  `json:"excerptSource,omitempty" doc:"片段来源，如执行的命令或 diff 的比较范围"`
+}
+
+type createTaskLinkBody struct {
+	TargetTaskID string `json:"targetTaskId" minLength:"1" doc:"关联的目标任务"`
+	Type         string `json:"type" enum:"blocks,relates_to,duplicates" doc:"关联类型：blocks 表示当前任务阻塞目标任务"`
 }
 
 func registerTaskRoutes(group *huma.Group) {
 	taskService := &model.TaskService{}
 	commentService := model.NewTaskCommentService()
+	linkService := model.NewTaskLinkService()
 
 	huma.Post(group, "/projects/{projectId}/tasks/create", func(ctx context.Context, input *struct {
 		ProjectID string `path:"projectId"`
@@ -69,6 +87,9 @@ func registerTaskRoutes(group *huma.Group) {
 			Tags:        tables.StringArray(input.Body.Tags),
 			WorktreeID:  input.Body.WorktreeID,
 			DueDate:     input.Body.DueDate,
+
+			EstimateMinutes: input.Body.EstimateMinutes,
+			EstimatePoints:  input.Body.EstimatePoints,
 		})
 		if err != nil {
 			return nil, mapTaskError(err)
@@ -88,7 +109,7 @@ func registerTaskRoutes(group *huma.Group) {
 		Status     string `query:"status"`
 		WorktreeID string `query:"worktreeId"`
 		Priority   string `query:"priority"`
-		Keyword    string `query:"keyword"`
+		Keyword    string `query:"keyword" doc:"关键字，支持与搜索接口相同的检索语法"`
 		Page       int    `query:"page" default:"1"`
 		PageSize   int    `query:"pageSize" default:"100"`
 	}) (*h.PaginatedResponse[tables.TaskTable], error) {
@@ -125,6 +146,34 @@ func registerTaskRoutes(group *huma.Group) {
 		op.Tags = []string{taskTag}
 	})
 
+	huma.Get(group, "/projects/{projectId}/tasks/search", func(ctx context.Context, input *struct {
+		ProjectID string `path:"projectId"`
+		Query     string `query:"q" doc:"检索语句，如 tag:backend status:in_progress branch:feat/* due:<2026-11-01 \"exact phrase\""`
+		Page      int    `query:"page" default:"1"`
+		PageSize  int    `query:"pageSize" default:"50"`
+	}) (*h.PaginatedResponse[model.TaskSearchResult], error) {
+		req := &model.SearchTasksRequest{
+			ProjectID: input.ProjectID,
+			Query:     input.Query,
+			Page:      input.Page,
+			PageSize:  input.PageSize,
+		}
+
+		results, total, err := taskService.SearchTasks(ctx, req)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewPaginatedResponse(results, total, req.Page, req.PageSize)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-search"
+		op.Summary = "全文检索任务"
+		op.Description = "在任务标题、描述、标签、分支和评论中检索，按相关度排序并返回高亮片段（<mark> 标记）。"
+		op.Tags = []string{taskTag}
+	})
+
 	huma.Get(group, "/tasks/{id}", func(ctx context.Context, input *struct {
 		ID string `path:"id"`
 	}) (*h.ItemResponse[tables.TaskTable], error) {
@@ -162,6 +211,12 @@ func registerTaskRoutes(group *huma.Group) {
 		if input.Body.DueDate != nil {
 			updates["due_date"] = *input.Body.DueDate
 		}
+		if input.Body.EstimateMinutes != nil {
+			updates["estimate_minutes"] = *input.Body.EstimateMinutes
+		}
+		if input.Body.EstimatePoints != nil {
+			updates["estimate_points"] = *input.Body.EstimatePoints
+		}
 
 		task, err := taskService.UpdateTask(ctx, input.ID, updates)
 		if err != nil {
@@ -198,9 +253,10 @@ func registerTaskRoutes(group *huma.Group) {
 		Body moveTaskBody
 	}) (*h.ItemResponse[tables.TaskTable], error) {
 		task, err := taskService.MoveTask(ctx, input.ID, &model.MoveTaskRequest{
-			Status:     input.Body.Status,
-			OrderIndex: input.Body.OrderIndex,
-			WorktreeID: input.Body.WorktreeID,
+			Status:          input.Body.Status,
+			OrderIndex:      input.Body.OrderIndex,
+			WorktreeID:      input.Body.WorktreeID,
+			EnforceBlockers: input.Body.EnforceBlockers,
 		})
 		if err != nil {
 			return nil, mapTaskError(err)
@@ -235,12 +291,12 @@ func registerTaskRoutes(group *huma.Group) {
 
 	huma.Get(group, "/tasks/{id}/comments", func(ctx context.Context, input *struct {
 		ID string `path:"id"`
-	}) (*h.ItemsResponse[tables.TaskCommentTable], error) {
+	}) (*h.ItemsResponse[taskCommentView], error) {
 		items, err := commentService.ListComments(ctx, input.ID)
 		if err != nil {
 			return nil, mapTaskError(err)
 		}
-		resp := h.NewItemsResponse(items)
+		resp := h.NewItemsResponse(newTaskCommentViews(items))
 		resp.Status = http.StatusOK
 		return resp, nil
 	}, func(op *huma.Operation) {
@@ -252,18 +308,26 @@ func registerTaskRoutes(group *huma.Group) {
 	huma.Post(group, "/tasks/{id}/comments/create", func(ctx context.Context, input *struct {
 		ID   string `path:"id"`
 		Body createCommentBody
-	}) (*h.ItemResponse[tables.TaskCommentTable], error) {
-		comment, err := commentService.CreateComment(ctx, input.ID, input.Body.Content)
+	}) (*h.ItemResponse[taskCommentView], error) {
+		comment, err := commentService.PostComment(ctx, &model.PostCommentRequest{
+			TaskID:        input.ID,
+			ParentID:      input.Body.ParentID,
+			Content:       input.Body.Content,
+			Kind:          input.Body.Kind,
+			Excerpt:       input.Body.Excerpt,
+			ExcerptSource: input.Body.ExcerptSource,
+		})
 		if err != nil {
 			return nil, mapTaskError(err)
 		}
 
-		resp := h.NewItemResponse(*comment)
+		resp := h.NewItemResponse(newTaskCommentView(*comment))
 		resp.Status = http.StatusCreated
 		return resp, nil
 	}, func(op *huma.Operation) {
 		op.OperationID = "task-comment-create"
 		op.Summary = "新增评论"
+		op.Description = "评论作者取自请求方（X-User-Id 或 X-Agent 请求头）。内容中的 @用户名 会给对应用户发送通知。"
 		op.Tags = []string{taskCommentTag}
 	})
 
@@ -281,6 +345,39 @@ func registerTaskRoutes(group *huma.Group) {
 		op.Summary = "删除评论"
 		op.Tags = []string{taskCommentTag}
 	})
+
+	huma.Post(group, "/tasks/{id}/links/create", func(ctx context.Context, input *struct {
+		ID   string `path:"id"`
+		Body createTaskLinkBody
+	}) (*h.ItemResponse[tables.TaskLinkTable], error) {
+		link, err := linkService.CreateLink(ctx, input.ID, input.Body.TargetTaskID, input.Body.Type)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*link)
+		resp.Status = http.StatusCreated
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-link-create"
+		op.Summary = "新增任务关联"
+		op.Tags = []string{taskLinkTag}
+	})
+
+	huma.Post(group, "/task-links/{id}/delete", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.MessageResponse, error) {
+		if err := linkService.DeleteLink(ctx, input.ID); err != nil {
+			return nil, mapTaskError(err)
+		}
+		resp := h.NewMessageResponse("Task link deleted successfully")
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-link-delete"
+		op.Summary = "删除任务关联"
+		op.Tags = []string{taskLinkTag}
+	})
 }
 
 func mapTaskError(err error) error {
@@ -293,11 +390,43 @@ func mapTaskError(err error) error {
 	case errors.Is(err, model.ErrProjectNotFound),
 		errors.Is(err, model.ErrTaskNotFound),
 		errors.Is(err, model.ErrWorktreeNotFound),
-		errors.Is(err, model.ErrTaskCommentNotFound):
+		errors.Is(err, model.ErrTaskCommentNotFound),
+		errors.Is(err, model.ErrTaskColumnNotFound),
+		errors.Is(err, model.ErrTaskLinkNotFound),
+		errors.Is(err, model.ErrTaskChecklistItemNotFound),
+		errors.Is(err, model.ErrTaskViewNotFound),
+		errors.Is(err, model.ErrTaskRecurrenceNotFound),
+		errors.Is(err, model.ErrNotificationNotFound),
+		errors.Is(err, model.ErrTaskTimeEntryNotFound),
+		errors.Is(err, model.ErrTaskTimerNotRunning):
 		return huma.Error404NotFound(err.Error())
 	case errors.Is(err, model.ErrInvalidTaskStatus),
+		errors.Is(err, model.ErrInvalidAutomationTrigger),
+		errors.Is(err, model.ErrInvalidTaskColumn),
+		errors.Is(err, model.ErrInvalidTaskLink),
+		errors.Is(err, model.ErrInvalidChecklistItem),
+		errors.Is(err, model.ErrInvalidTaskQuery),
+		errors.Is(err, model.ErrInvalidTaskView),
+		errors.Is(err, model.ErrInvalidTaskExchange),
+		errors.Is(err, model.ErrInvalidIssueSync),
+		errors.Is(err, model.ErrInvalidTaskRecurrence),
+		errors.Is(err, model.ErrInvalidRecurrenceRule),
+		errors.Is(err, model.ErrInvalidTaskComment),
+		errors.Is(err, model.ErrInvalidTaskTimeEntry),
+		errors.Is(err, model.ErrInvalidReportRange),
+		errors.Is(err, model.ErrInvalidBulkTaskRequest),
 		errors.Is(err, model.ErrInvalidProjectInput):
 		return huma.Error400BadRequest(err.Error())
+	case errors.Is(err, model.ErrTaskTransitionNotAllowed),
+		errors.Is(err, model.ErrTaskWIPLimitReached),
+		errors.Is(err, model.ErrTaskColumnExists),
+		errors.Is(err, model.ErrTaskColumnInUse),
+		errors.Is(err, model.ErrTaskLinkExists),
+		errors.Is(err, model.ErrTaskLinkCycle),
+		errors.Is(err, model.ErrTaskBlocked),
+		errors.Is(err, model.ErrTaskViewExists),
+		errors.Is(err, model.ErrTaskTimerRunning):
+		return huma.Error409Conflict(err.Error())
 	default:
 		return huma.Error500InternalServerError(err.Error())
 	}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/model/tables"
+	"code-kanban/service"
+	"code-kanban/service/terminal"
+	"code-kanban/utils"
+)
+
+type startTaskAgentBody struct {
+	Assistant  string `json:"assistant" enum:"claude-code,codex,qwen-code,gemini,cursor,copilot" default:"claude-code" doc:"要启动的 AI 助手"`
+	BranchName string `json:"branchName,omitempty" doc:"自定义分支名，默认根据任务标题生成"`
+	BaseBranch string `json:"baseBranch,omitempty" doc:"基准分支，默认使用项目默认分支"`
+	Rows       int    `json:"rows,omitempty" doc:"终端行数"`
+	Cols       int    `json:"cols,omitempty" doc:"终端列数"`
+}
+
+type taskAgentView struct {
+	Task     tables.TaskTable    `json:"task"`
+	Worktree *model.Worktree     `json:"worktree"`
+	Terminal terminalSessionView `json:"terminal"`
+}
+
+func registerTaskAgentRoutes(group *huma.Group, cfg *utils.AppConfig, manager *terminal.Manager) {
+	if manager == nil {
+		return
+	}
+	agentService := service.NewTaskAgentService(manager, cfg.Terminal.AgentCommands)
+
+	huma.Post(group, "/tasks/{id}/start-agent", func(ctx context.Context, input *struct {
+		ID   string `path:"id"`
+		Body startTaskAgentBody
+	}) (*h.ItemResponse[taskAgentView], error) {
+		result, err := agentService.StartAgent(ctx, input.ID, service.StartAgentParams{
+			Assistant:  input.Body.Assistant,
+			BranchName: input.Body.BranchName,
+			BaseBranch: input.Body.BaseBranch,
+			Rows:       input.Body.Rows,
+			Cols:       input.Body.Cols,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrUnsupportedAssistant):
+				return nil, huma.Error400BadRequest(err.Error())
+			case errors.Is(err, terminal.ErrSessionLimitReached):
+				return nil, huma.Error429TooManyRequests(err.Error())
+			case errors.Is(err, utils.ErrShellEnvUnsupported):
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			default:
+				return nil, mapTaskError(err)
+			}
+		}
+
+		resp := h.NewItemResponse(taskAgentView{
+			Task:     *result.Task,
+			Worktree: result.Worktree,
+			Terminal: newTerminalSessionView(cfg, result.Session.Snapshot()),
+		})
+		resp.Status = http.StatusCreated
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-start-agent"
+		op.Summary = "为任务启动 AI 助手"
+		op.Tags = []string{taskTag}
+	})
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"go.uber.org/zap"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/model/tables"
+	"code-kanban/service/terminal"
+)
+
+const taskAutomationTag = "task-automation-任务自动化"
+
+type updateTaskAutomationBody struct {
+	Trigger      string   `json:"trigger" enum:"ai_started,branch_merged" doc:"触发事件"`
+	FromStatuses []string `json:"fromStatuses" doc:"允许触发的原状态，为空表示任意状态"`
+	ToStatus     string   `json:"toStatus" minLength:"1" doc:"目标状态"`
+	Enabled      bool     `json:"enabled" doc:"是否启用"`
+}
+
+func registerTaskAutomationRoutes(group *huma.Group) {
+	automationService := model.NewTaskAutomationService()
+
+	huma.Get(group, "/projects/{projectId}/task-automations", func(ctx context.Context, input *struct {
+		ProjectID string `path:"projectId"`
+	}) (*h.ItemsResponse[tables.TaskAutomationTable], error) {
+		rules, err := automationService.ListRules(ctx, input.ProjectID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemsResponse(rules)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-automation-list"
+		op.Summary = "任务自动化规则列表"
+		op.Tags = []string{taskAutomationTag}
+	})
+
+	huma.Post(group, "/projects/{projectId}/task-automations/update", func(ctx context.Context, input *struct {
+		ProjectID string `path:"projectId"`
+		Body      updateTaskAutomationBody
+	}) (*h.ItemResponse[tables.TaskAutomationTable], error) {
+		rule, err := automationService.UpsertRule(ctx, &model.UpdateTaskAutomationRequest{
+			ProjectID:    input.ProjectID,
+			Trigger:      input.Body.Trigger,
+			FromStatuses: tables.StringArray(input.Body.FromStatuses),
+			ToStatus:     input.Body.ToStatus,
+			Enabled:      input.Body.Enabled,
+		})
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*rule)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-automation-update"
+		op.Summary = "更新任务自动化规则"
+		op.Tags = []string{taskAutomationTag}
+	})
+}
+
+// watchAssistantEvents 监听终端中 AI 助手的启动事件，并据此推进绑定任务的状态
+func watchAssistantEvents(ctx context.Context, manager *terminal.Manager, logger *zap.Logger) {
+	automationService := model.NewTaskAutomationService()
+	manager.OnAssistantEvent(func(event terminal.AssistantEvent) {
+		if event.Type != terminal.AssistantEventStarted {
+			return
+		}
+		_, err := automationService.HandleAssistantStarted(ctx, event.WorktreeID, event.Assistant.DisplayName)
+		if err != nil && !errors.Is(err, model.ErrWorktreeNotFound) {
+			logger.Warn("AI 助手启动后自动流转任务失败",
+				zap.Error(err),
+				zap.String("sessionId", event.SessionID),
+				zap.String("worktreeId", event.WorktreeID),
+			)
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/model/tables"
+)
+
+const taskBoardTag = "task-board-全局看板"
+
+// userIDHeader 标识保存视图的归属用户，未携带时视为本机默认用户
+const userIDHeader = "X-User-Id"
+
+type saveTaskViewBody struct {
+	Name       string   `json:"name" minLength:"1" doc:"视图名称"`
+	ProjectIDs []string `json:"projectIds,omitempty" doc:"限定的项目，为空表示全部项目"`
+	Query      string   `json:"query,omitempty" doc:"检索语法，如 tag:backend status:in_progress"`
+	Sort       string   `json:"sort,omitempty" enum:"order,priority,due,updated,created" default:"order" doc:"排序方式"`
+	GroupBy    string   `json:"groupBy,omitempty" enum:"project,status,category,priority,none" default:"project" doc:"分组方式"`
+}
+
+type taskViewBoard struct {
+	View  tables.TaskViewTable `json:"view"`
+	Board model.TaskBoard      `json:"board"`
+}
+
+func registerTaskBoardRoutes(group *huma.Group) {
+	taskService := &model.TaskService{}
+	viewService := model.NewTaskViewService()
+
+	huma.Get(group, "/task-board", func(ctx context.Context, input *struct {
+		ProjectIDs string `query:"projectIds" doc:"逗号分隔的项目 ID，为空表示全部项目"`
+		Status     string `query:"status" doc:"逗号分隔的看板列 key"`
+		WorktreeID string `query:"worktreeId"`
+		Priority   string `query:"priority"`
+		Keyword    string `query:"keyword" doc:"关键字，支持与搜索接口相同的检索语法"`
+		Sort       string `query:"sort" enum:"order,priority,due,updated,created" default:"order" doc:"排序方式"`
+		GroupBy    string `query:"groupBy" enum:"project,status,category,priority,none" default:"project" doc:"分组方式"`
+	}) (*h.ItemResponse[model.TaskBoard], error) {
+		var priorityPtr *int
+		if strings.TrimSpace(input.Priority) != "" {
+			value, err := strconv.Atoi(input.Priority)
+			if err != nil {
+				return nil, huma.Error400BadRequest("invalid priority value")
+			}
+			priorityPtr = &value
+		}
+
+		board, err := taskService.Board(ctx, &model.TaskBoardRequest{
+			ProjectIDs: strings.Split(input.ProjectIDs, ","),
+			Status:     input.Status,
+			WorktreeID: input.WorktreeID,
+			Priority:   priorityPtr,
+			Keyword:    input.Keyword,
+			Sort:       input.Sort,
+			GroupBy:    input.GroupBy,
+		})
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*board)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-board"
+		op.Summary = "跨项目任务看板"
+		op.Tags = []string{taskBoardTag}
+	})
+
+	huma.Get(group, "/task-views", func(ctx context.Context, input *struct {
+		UserID string `header:"X-User-Id"`
+	}) (*h.ItemsResponse[tables.TaskViewTable], error) {
+		views, err := viewService.ListViews(ctx, input.UserID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemsResponse(views)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-view-list"
+		op.Summary = "保存的视图列表"
+		op.Tags = []string{taskBoardTag}
+	})
+
+	huma.Post(group, "/task-views/create", func(ctx context.Context, input *struct {
+		UserID string `header:"X-User-Id"`
+		Body   saveTaskViewBody
+	}) (*h.ItemResponse[tables.TaskViewTable], error) {
+		view, err := viewService.CreateView(ctx, input.Body.toRequest(input.UserID))
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*view)
+		resp.Status = http.StatusCreated
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-view-create"
+		op.Summary = "保存视图"
+		op.Tags = []string{taskBoardTag}
+	})
+
+	huma.Get(group, "/task-views/{id}/board", func(ctx context.Context, input *struct {
+		ID     string `path:"id"`
+		UserID string `header:"X-User-Id"`
+	}) (*h.ItemResponse[taskViewBoard], error) {
+		view, board, err := viewService.OpenView(ctx, input.UserID, input.ID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(taskViewBoard{View: *view, Board: *board})
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-view-board"
+		op.Summary = "按保存的视图加载看板"
+		op.Tags = []string{taskBoardTag}
+	})
+
+	huma.Post(group, "/task-views/{id}/update", func(ctx context.Context, input *struct {
+		ID     string `path:"id"`
+		UserID string `header:"X-User-Id"`
+		Body   saveTaskViewBody
+	}) (*h.ItemResponse[tables.TaskViewTable], error) {
+		view, err := viewService.UpdateView(ctx, input.ID, input.Body.toRequest(input.UserID))
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*view)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-view-update"
+		op.Summary = "更新视图"
+		op.Tags = []string{taskBoardTag}
+	})
+
+	huma.Post(group, "/task-views/{id}/delete", func(ctx context.Context, input *struct {
+		ID     string `path:"id"`
+		UserID string `header:"X-User-Id"`
+	}) (*h.MessageResponse, error) {
+		if err := viewService.DeleteView(ctx, input.UserID, input.ID); err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewMessageResponse("Task view deleted successfully")
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-view-delete"
+		op.Summary = "删除视图"
+		op.Tags = []string{taskBoardTag}
+	})
+}
+
+func (b saveTaskViewBody) toRequest(userID string) *model.SaveTaskViewRequest {
+	return &model.SaveTaskViewRequest{
+		UserID:     userID,
+		Name:       b.Name,
+		ProjectIDs: tables.StringArray(b.ProjectIDs),
+		Query:      b.Query,
+		Sort:       b.Sort,
+		GroupBy:    b.GroupBy,
+	}
+}
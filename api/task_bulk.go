@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+)
+
+type bulkTaskBody struct {
+	TaskIDs      []string `json:"taskIds,omitempty" maxItems:"500" doc:"要操作的任务ID，按顺序处理；与 query 二选一"`
+	Query        string   `json:"query,omitempty" doc:"按检索语法选择任务，如 status:done tag:release"`
+	Status       string   `json:"status,omitempty" doc:"移动到的看板列 key"`
+	AddTags      []string `json:"addTags,omitempty" doc:"添加的标签"`
+	RemoveTags   []string `json:"removeTags,omitempty" doc:"移除的标签（不区分大小写）"`
+	Priority     *int     `json:"priority,omitempty" minimum:"0" maximum:"3" doc:"设置优先级"`
+	Archive      bool     `json:"archive,omitempty" doc:"移动到归档列，不能与 status 同时使用"`
+	Delete       bool     `json:"delete,omitempty" doc:"删除任务，不能与其他操作同时使用"`
+	Reorder      string   `json:"reorder,omitempty" enum:"top,bottom" doc:"按选择顺序移动到所在列的顶部或底部"`
+	AllowPartial bool     `json:"allowPartial,omitempty" doc:"部分任务失败时仍提交其余任务，默认任一失败即整体回滚"`
+}
+
+func registerTaskBulkRoutes(group *huma.Group) {
+	taskService := &model.TaskService{}
+
+	huma.Post(group, "/projects/{projectId}/tasks/bulk", func(ctx context.Context, input *struct {
+		ProjectID string `path:"projectId"`
+		Body      bulkTaskBody
+	}) (*h.ItemResponse[model.BulkTaskReport], error) {
+		report, err := taskService.BulkUpdateTasks(ctx, &model.BulkTaskRequest{
+			ProjectID:    input.ProjectID,
+			TaskIDs:      input.Body.TaskIDs,
+			Query:        input.Body.Query,
+			Status:       input.Body.Status,
+			AddTags:      input.Body.AddTags,
+			RemoveTags:   input.Body.RemoveTags,
+			Priority:     input.Body.Priority,
+			Archive:      input.Body.Archive,
+			Delete:       input.Body.Delete,
+			Reorder:      input.Body.Reorder,
+			AllowPartial: input.Body.AllowPartial,
+		})
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*report)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-bulk"
+		op.Summary = "批量操作任务"
+		op.Description = "在同一个事务中对选中的任务（或检索语句匹配的任务，最多 500 个）批量修改状态、标签、优先级，归档、删除或调整排序，并返回每个任务的处理结果。" +
+			"默认任一任务失败时整体回滚（committed 为 false），开启 allowPartial 后仅跳过失败的任务。"
+		op.Tags = []string{taskTag}
+	})
+}
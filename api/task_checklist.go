@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/model/tables"
+)
+
+const taskChecklistTag = "task-checklist-任务清单"
+
+type createChecklistItemsBody struct {
+	Items   []string `json:"items" minItems:"1" doc:"清单项内容，按顺序追加"`
+	Replace bool     `json:"replace,omitempty" doc:"为 true 时先清空已有清单，适合写入 AI 生成的执行计划"`
+}
+
+type updateChecklistItemBody struct {
+	Content *string `json:"content,omitempty" doc:"清单项内容"`
+	Done    *bool   `json:"done,omitempty" doc:"是否已完成"`
+}
+
+type moveChecklistItemBody struct {
+	OrderIndex float64 `json:"orderIndex" doc:"排序索引"`
+}
+
+type checkChecklistItemBody struct {
+	ItemID   string `json:"itemId,omitempty" doc:"清单项 ID"`
+	Position int    `json:"position,omitempty" minimum:"0" doc:"清单项序号（从 1 开始）"`
+	Match    string `json:"match,omitempty" doc:"按内容模糊匹配（不区分大小写）"`
+	Done     *bool  `json:"done,omitempty" doc:"目标状态，默认为已完成"`
+}
+
+func registerTaskChecklistRoutes(group *huma.Group) {
+	checklistService := model.NewTaskChecklistService()
+
+	huma.Get(group, "/tasks/{id}/checklist", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.ItemsResponse[tables.TaskChecklistItemTable], error) {
+		items, err := checklistService.ListItems(ctx, input.ID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemsResponse(items)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-checklist-list"
+		op.Summary = "任务清单列表"
+		op.Tags = []string{taskChecklistTag}
+	})
+
+	huma.Post(group, "/tasks/{id}/checklist/create", func(ctx context.Context, input *struct {
+		ID   string `path:"id"`
+		Body createChecklistItemsBody
+	}) (*h.ItemsResponse[tables.TaskChecklistItemTable], error) {
+		items, err := checklistService.AddItems(ctx, input.ID, input.Body.Items, input.Body.Replace)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemsResponse(items)
+		resp.Status = http.StatusCreated
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-checklist-create"
+		op.Summary = "添加清单项"
+		op.Tags = []string{taskChecklistTag}
+	})
+
+	huma.Post(group, "/tasks/{id}/checklist/check", func(ctx context.Context, input *struct {
+		ID   string `path:"id"`
+		Body checkChecklistItemBody
+	}) (*h.ItemResponse[tables.TaskChecklistItemTable], error) {
+		done := true
+		if input.Body.Done != nil {
+			done = *input.Body.Done
+		}
+		item, err := checklistService.CheckItem(ctx, input.ID, &model.CheckChecklistItemRequest{
+			ItemID:   input.Body.ItemID,
+			Position: input.Body.Position,
+			Match:    input.Body.Match,
+			Done:     done,
+		})
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*item)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-checklist-check"
+		op.Summary = "勾选清单项"
+		op.Description = "供 hook 或脚本使用，可通过 ID、序号或内容匹配定位清单项。"
+		op.Tags = []string{taskChecklistTag}
+	})
+
+	huma.Post(group, "/task-checklist-items/{id}/update", func(ctx context.Context, input *struct {
+		ID   string `path:"id"`
+		Body updateChecklistItemBody
+	}) (*h.ItemResponse[tables.TaskChecklistItemTable], error) {
+		item, err := checklistService.UpdateItem(ctx, input.ID, &model.UpdateChecklistItemRequest{
+			Content: input.Body.Content,
+			Done:    input.Body.Done,
+		})
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*item)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-checklist-update"
+		op.Summary = "更新清单项"
+		op.Tags = []string{taskChecklistTag}
+	})
+
+	huma.Post(group, "/task-checklist-items/{id}/move", func(ctx context.Context, input *struct {
+		ID   string `path:"id"`
+		Body moveChecklistItemBody
+	}) (*h.ItemResponse[tables.TaskChecklistItemTable], error) {
+		item, err := checklistService.MoveItem(ctx, input.ID, input.Body.OrderIndex)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*item)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-checklist-move"
+		op.Summary = "调整清单项顺序"
+		op.Tags = []string{taskChecklistTag}
+	})
+
+	huma.Post(group, "/task-checklist-items/{id}/delete", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.MessageResponse, error) {
+		if err := checklistService.DeleteItem(ctx, input.ID); err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewMessageResponse("Checklist item deleted successfully")
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-checklist-delete"
+		op.Summary = "删除清单项"
+		op.Tags = []string{taskChecklistTag}
+	})
+}
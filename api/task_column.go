@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/model/tables"
+)
+
+const taskColumnTag = "task-column-看板列"
+
+type createTaskColumnBody struct {
+	Key                string   `json:"key" minLength:"1" maxLength:"32" doc:"列标识，写入任务状态字段，创建后不可修改"`
+	Name               string   `json:"name" doc:"列名称"`
+	Color              string   `json:"color,omitempty" doc:"列颜色，如 #409eff"`
+	Category           string   `json:"category" enum:"todo,in_progress,done,archived" default:"in_progress" doc:"列语义分类"`
+	WIPLimit           int      `json:"wipLimit,omitempty" minimum:"0" doc:"在制品上限，0 表示不限制"`
+	AllowedTransitions []string `json:"allowedTransitions,omitempty" doc:"允许流转到的列 key，为空表示不限制"`
+}
+
+type updateTaskColumnBody struct {
+	Name               *string   `json:"name,omitempty" doc:"列名称"`
+	Color              *string   `json:"color,omitempty" doc:"列颜色"`
+	Category           *string   `json:"category,omitempty" enum:"todo,in_progress,done,archived" doc:"列语义分类"`
+	WIPLimit           *int      `json:"wipLimit,omitempty" minimum:"0" doc:"在制品上限，0 表示不限制"`
+	AllowedTransitions *[]string `json:"allowedTransitions,omitempty" doc:"允许流转到的列 key，空数组表示不限制"`
+}
+
+type moveTaskColumnBody struct {
+	OrderIndex float64 `json:"orderIndex" doc:"排序索引"`
+}
+
+func registerTaskColumnRoutes(group *huma.Group) {
+	columnService := &model.TaskColumnService{}
+
+	huma.Get(group, "/projects/{projectId}/task-columns", func(ctx context.Context, input *struct {
+		ProjectID string `path:"projectId"`
+	}) (*h.ItemsResponse[tables.TaskColumnTable], error) {
+		columns, err := columnService.ListColumns(ctx, input.ProjectID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemsResponse(columns)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-column-list"
+		op.Summary = "看板列列表"
+		op.Tags = []string{taskColumnTag}
+	})
+
+	huma.Post(group, "/projects/{projectId}/task-columns/create", func(ctx context.Context, input *struct {
+		ProjectID string `path:"projectId"`
+		Body      createTaskColumnBody
+	}) (*h.ItemResponse[tables.TaskColumnTable], error) {
+		column, err := columnService.CreateColumn(ctx, &model.CreateTaskColumnRequest{
+			ProjectID:          input.ProjectID,
+			Key:                input.Body.Key,
+			Name:               input.Body.Name,
+			Color:              input.Body.Color,
+			Category:           input.Body.Category,
+			WIPLimit:           input.Body.WIPLimit,
+			AllowedTransitions: tables.StringArray(input.Body.AllowedTransitions),
+		})
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*column)
+		resp.Status = http.StatusCreated
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-column-create"
+		op.Summary = "创建看板列"
+		op.Tags = []string{taskColumnTag}
+	})
+
+	huma.Post(group, "/task-columns/{id}/update", func(ctx context.Context, input *struct {
+		ID   string `path:"id"`
+		Body updateTaskColumnBody
+	}) (*h.ItemResponse[tables.TaskColumnTable], error) {
+		req := &model.UpdateTaskColumnRequest{
+			Name:     input.Body.Name,
+			Color:    input.Body.Color,
+			Category: input.Body.Category,
+			WIPLimit: input.Body.WIPLimit,
+		}
+		if input.Body.AllowedTransitions != nil {
+			transitions := tables.StringArray(*input.Body.AllowedTransitions)
+			req.AllowedTransitions = &transitions
+		}
+
+		column, err := columnService.UpdateColumn(ctx, input.ID, req)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*column)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-column-update"
+		op.Summary = "更新看板列"
+		op.Tags = []string{taskColumnTag}
+	})
+
+	huma.Post(group, "/task-columns/{id}/move", func(ctx context.Context, input *struct {
+		ID   string `path:"id"`
+		Body moveTaskColumnBody
+	}) (*h.ItemResponse[tables.TaskColumnTable], error) {
+		column, err := columnService.MoveColumn(ctx, input.ID, input.Body.OrderIndex)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*column)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-column-move"
+		op.Summary = "调整看板列顺序"
+		op.Tags = []string{taskColumnTag}
+	})
+
+	huma.Post(group, "/task-columns/{id}/delete", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.MessageResponse, error) {
+		if err := columnService.DeleteColumn(ctx, input.ID); err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewMessageResponse("Task column deleted successfully")
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-column-delete"
+		op.Summary = "删除看板列"
+		op.Tags = []string{taskColumnTag}
+	})
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/model/tables"
+	"code-kanban/service/terminal"
+	"code-kanban/utils/ai_assistant"
+	"code-kanban/utils/markdown"
+)
+
+const notificationTag = "notification-通知"
+
+// taskCommentView 在评论数据之外附带服务端渲染的 Markdown
+type taskCommentView struct {
+	tables.TaskCommentTable
+	ContentHTML string `json:"contentHtml" doc:"content 渲染后的 HTML，原始 HTML 标签会被忽略"`
+}
+
+type updateCommentBody struct {
+	Content string  `json:"content" doc:"新的评论内容"`
+	Excerpt *string `json:"excerpt,omitempty" doc:"新的终端输出片段或 diff，仅结构化评论可修改"`
+}
+
+type terminalExcerptCommentBody struct {
+	SessionID string  `json:"sessionId" minLength:"1" doc:"终端会话ID"`
+	Lines     int     `json:"lines,omitempty" default:"40" minimum:"1" maximum:"500" doc:"截取末尾的行数"`
+	Content   string  `json:"content,omitempty" doc:"附加说明（Markdown）"`
+	ParentID  *string `json:"parentId,omitempty" doc:"回复的评论ID"`
+}
+
+func newTaskCommentView(comment tables.TaskCommentTable) taskCommentView {
+	view := taskCommentView{TaskCommentTable: comment}
+	if rendered, err := markdown.Render(comment.Content); err == nil {
+		view.ContentHTML = rendered
+	}
+	return view
+}
+
+func newTaskCommentViews(comments []tables.TaskCommentTable) []taskCommentView {
+	views := make([]taskCommentView, 0, len(comments))
+	for _, comment := range comments {
+		views = append(views, newTaskCommentView(comment))
+	}
+	return views
+}
+
+func registerTaskCommentRoutes(group *huma.Group, manager *terminal.Manager) {
+	commentService := model.NewTaskCommentService()
+	notificationService := model.NewNotificationService()
+
+	huma.Post(group, "/task-comments/{id}/update", func(ctx context.Context, input *struct {
+		ID   string `path:"id"`
+		Body updateCommentBody
+	}) (*h.ItemResponse[taskCommentView], error) {
+		comment, err := commentService.UpdateComment(ctx, input.ID, input.Body.Content, input.Body.Excerpt)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(newTaskCommentView(*comment))
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-comment-update"
+		op.Summary = "编辑评论"
+		op.Description = "修改前的内容会保存为历史版本，新增的 @提及 会发送通知。"
+		op.Tags = []string{taskCommentTag}
+	})
+
+	huma.Get(group, "/task-comments/{id}/revisions", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.ItemsResponse[tables.TaskCommentRevisionTable], error) {
+		revisions, err := commentService.ListRevisions(ctx, input.ID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemsResponse(revisions)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-comment-revisions"
+		op.Summary = "评论编辑历史"
+		op.Tags = []string{taskCommentTag}
+	})
+
+	if manager != nil {
+		huma.Post(group, "/tasks/{id}/comments/terminal-excerpt", func(ctx context.Context, input *struct {
+			ID   string `path:"id"`
+			Body terminalExcerptCommentBody
+		}) (*h.ItemResponse[taskCommentView], error) {
+			session, err := manager.GetSession(input.Body.SessionID)
+			if err != nil {
+				if errors.Is(err, terminal.ErrSessionNotFound) {
+					return nil, huma.Error404NotFound(err.Error())
+				}
+				return nil, huma.Error500InternalServerError(err.Error())
+			}
+
+			output := session.NormalizeOutput(bytes.Join(session.Scrollback(), nil))
+			comment, err := commentService.PostComment(ctx, &model.PostCommentRequest{
+				TaskID:        input.ID,
+				ParentID:      input.Body.ParentID,
+				Content:       input.Body.Content,
+				Kind:          model.TaskCommentKindTerminalOutput,
+				Excerpt:       tailLines(ai_assistant.StripANSI(string(output)), input.Body.Lines),
+				ExcerptSource: "terminal:" + session.Title(),
+			})
+			if err != nil {
+				return nil, mapTaskError(err)
+			}
+
+			resp := h.NewItemResponse(newTaskCommentView(*comment))
+			resp.Status = http.StatusCreated
+			return resp, nil
+		}, func(op *huma.Operation) {
+			op.OperationID = "task-comment-terminal-excerpt"
+			op.Summary = "将终端输出发布为评论"
+			op.Description = "截取终端会话缓冲区末尾若干行（去除控制字符），作为结构化评论发布到任务上。"
+			op.Tags = []string{taskCommentTag}
+		})
+	}
+
+	huma.Get(group, "/notifications", func(ctx context.Context, input *struct {
+		UserID     string `header:"X-User-Id" required:"true" doc:"接收通知的用户ID"`
+		UnreadOnly bool   `query:"unreadOnly" doc:"仅返回未读通知"`
+	}) (*h.ItemsResponse[tables.NotificationTable], error) {
+		notifications, err := notificationService.ListNotifications(ctx, input.UserID, input.UnreadOnly)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemsResponse(notifications)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "notification-list"
+		op.Summary = "通知列表"
+		op.Description = "返回当前用户最近的 200 条通知，目前包含评论中的 @提及。"
+		op.Tags = []string{notificationTag}
+	})
+
+	huma.Post(group, "/notifications/{id}/read", func(ctx context.Context, input *struct {
+		ID     string `path:"id"`
+		UserID string `header:"X-User-Id" required:"true" doc:"接收通知的用户ID"`
+	}) (*h.MessageResponse, error) {
+		if err := notificationService.MarkRead(ctx, input.UserID, input.ID); err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewMessageResponse("ok")
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "notification-read"
+		op.Summary = "标记通知已读"
+		op.Tags = []string{notificationTag}
+	})
+
+	huma.Post(group, "/notifications/read-all", func(ctx context.Context, input *struct {
+		UserID string `header:"X-User-Id" required:"true" doc:"接收通知的用户ID"`
+	}) (*h.MessageResponse, error) {
+		if _, err := notificationService.MarkAllRead(ctx, input.UserID); err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewMessageResponse("ok")
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "notification-read-all"
+		op.Summary = "全部标记为已读"
+		op.Tags = []string{notificationTag}
+	})
+}
+
+// tailLines 返回文本末尾的 n 行，忽略结尾的空行
+func tailLines(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/model/tables"
+)
+
+const taskEventTag = "task-event-任务动态"
+
+// agentHeader 由终端中运行的 AI 助手携带，例如 X-Agent: claude-code
+const agentHeader = "X-Agent"
+
+// taskActorMiddleware 将请求方写入上下文，任务变更记录和评论作者据此标注操作者
+func taskActorMiddleware(ctx huma.Context, next func(huma.Context)) {
+	actor := model.TaskActorUser
+	if userID := strings.TrimSpace(ctx.Header(userIDHeader)); userID != "" {
+		actor = model.TaskActorUser + ":" + userID
+	}
+	if agent := strings.TrimSpace(ctx.Header(agentHeader)); agent != "" {
+		actor = "agent:" + agent
+	}
+	next(huma.WithContext(ctx, model.WithActor(ctx.Context(), actor)))
+}
+
+func registerTaskEventRoutes(group *huma.Group) {
+	eventService := model.NewTaskEventService()
+
+	huma.Get(group, "/tasks/{id}/events", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.ItemsResponse[tables.TaskEventTable], error) {
+		events, err := eventService.ListEvents(ctx, input.ID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemsResponse(events)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-event-list"
+		op.Summary = "任务变更记录"
+		op.Tags = []string{taskEventTag}
+	})
+
+	huma.Get(group, "/tasks/{id}/timeline", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.ItemsResponse[model.TaskTimelineEntry], error) {
+		entries, err := eventService.Timeline(ctx, input.ID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemsResponse(entries)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-timeline"
+		op.Summary = "任务时间线"
+		op.Description = "按时间顺序合并任务变更记录与评论。"
+		op.Tags = []string{taskEventTag}
+	})
+}
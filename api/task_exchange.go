@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+)
+
+const taskExchangeTag = "task-exchange-任务导入导出"
+
+var taskExportContentTypes = map[string]string{
+	model.TaskExchangeJSON:     "application/json; charset=utf-8",
+	model.TaskExchangeCSV:      "text/csv; charset=utf-8",
+	model.TaskExchangeMarkdown: "text/markdown; charset=utf-8",
+}
+
+var taskExportExtensions = map[string]string{
+	model.TaskExchangeJSON:     "json",
+	model.TaskExchangeCSV:      "csv",
+	model.TaskExchangeMarkdown: "md",
+}
+
+type taskExportOutput struct {
+	ContentType        string `header:"Content-Type"`
+	ContentDisposition string `header:"Content-Disposition"`
+	Body               []byte
+}
+
+type importTasksInput struct {
+	ProjectID string `path:"projectId"`
+	Body      struct {
+		Format  string `json:"format" enum:"json,csv,markdown" default:"json" doc:"导入格式"`
+		Content string `json:"content" doc:"导入内容"`
+		DryRun  bool   `json:"dryRun,omitempty" doc:"仅预览，不写入数据"`
+	}
+}
+
+type importTODOInput struct {
+	ProjectID string `path:"projectId"`
+	Body      struct {
+		Path   string `json:"path,omitempty" doc:"仓库内的相对路径，默认 TODO.md"`
+		DryRun bool   `json:"dryRun,omitempty" doc:"仅预览，不写入数据"`
+	}
+}
+
+func registerTaskExchangeRoutes(group *huma.Group) {
+	exchangeService := model.NewTaskExchangeService()
+
+	huma.Get(group, "/projects/{projectId}/tasks/export", func(ctx context.Context, input *struct {
+		ProjectID string `path:"projectId"`
+		Format    string `query:"format" enum:"json,csv,markdown" default:"json" doc:"导出格式"`
+	}) (*taskExportOutput, error) {
+		data, err := exchangeService.Export(ctx, input.ProjectID, input.Format)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		return &taskExportOutput{
+			ContentType:        taskExportContentTypes[input.Format],
+			ContentDisposition: fmt.Sprintf(`attachment; filename="tasks-%s.%s"`, input.ProjectID, taskExportExtensions[input.Format]),
+			Body:               data,
+		}, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-export"
+		op.Summary = "导出项目任务"
+		op.Description = "以 JSON、CSV 或 Markdown 清单导出任务，包含评论、标签、截止日期和关联分支。"
+		op.Tags = []string{taskExchangeTag}
+	})
+
+	huma.Post(group, "/projects/{projectId}/tasks/import", func(ctx context.Context, input *importTasksInput) (*h.ItemResponse[model.TaskImportResult], error) {
+		result, err := exchangeService.Import(ctx, &model.ImportTasksRequest{
+			ProjectID: input.ProjectID,
+			Format:    input.Body.Format,
+			Content:   input.Body.Content,
+			DryRun:    input.Body.DryRun,
+		})
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*result)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-import"
+		op.Summary = "导入项目任务"
+		op.Description = "按外部 ID 幂等更新或创建任务；dryRun 时只返回预览结果。"
+		op.Tags = []string{taskExchangeTag}
+	})
+
+	huma.Post(group, "/projects/{projectId}/tasks/import-todo", func(ctx context.Context, input *importTODOInput) (*h.ItemResponse[model.TaskImportResult], error) {
+		result, err := exchangeService.ImportRepoFile(ctx, input.ProjectID, input.Body.Path, input.Body.DryRun)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*result)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-import-todo"
+		op.Summary = "从仓库 TODO.md 导入任务"
+		op.Description = "读取项目仓库中的 Markdown 清单（默认 TODO.md），格式按扩展名识别。"
+		op.Tags = []string{taskExchangeTag}
+	})
+}
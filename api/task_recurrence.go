@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/model/tables"
+)
+
+const taskRecurrenceTag = "task-recurrence-周期任务"
+
+type saveTaskRecurrenceBody struct {
+	Title           string     `json:"title" minLength:"1" doc:"生成任务的标题"`
+	Description     string     `json:"description,omitempty" doc:"生成任务的描述"`
+	Status          string     `json:"status,omitempty" doc:"生成任务所在列，默认项目的默认列"`
+	Priority        int        `json:"priority,omitempty" doc:"生成任务的优先级"`
+	Tags            []string   `json:"tags,omitempty" doc:"生成任务的标签"`
+	Rule            string     `json:"rule" minLength:"1" doc:"重复规则（RRULE 子集），如 FREQ=WEEKLY;BYDAY=MO;BYHOUR=9;BYMINUTE=0"`
+	StartAt         *time.Time `json:"startAt,omitempty" doc:"规则起始时间（DTSTART），默认当前时间"`
+	DueAfterMinutes *int       `json:"dueAfterMinutes,omitempty" doc:"截止时间相对触发时间的分钟数，为空则不设置截止时间"`
+	SkipIfOpen      bool       `json:"skipIfOpen,omitempty" doc:"上一次生成的任务未完成时跳过本次"`
+	Enabled         *bool      `json:"enabled,omitempty" doc:"是否启用，默认启用"`
+}
+
+func (b *saveTaskRecurrenceBody) toRequest() *model.SaveTaskRecurrenceRequest {
+	return &model.SaveTaskRecurrenceRequest{
+		Title:           b.Title,
+		Description:     b.Description,
+		Status:          b.Status,
+		Priority:        b.Priority,
+		Tags:            tables.StringArray(b.Tags),
+		Rule:            b.Rule,
+		StartAt:         b.StartAt,
+		DueAfterMinutes: b.DueAfterMinutes,
+		SkipIfOpen:      b.SkipIfOpen,
+		Enabled:         b.Enabled,
+	}
+}
+
+func registerTaskRecurrenceRoutes(group *huma.Group) {
+	recurrenceService := model.NewTaskRecurrenceService()
+	taskService := &model.TaskService{}
+
+	huma.Get(group, "/projects/{projectId}/task-recurrences", func(ctx context.Context, input *struct {
+		ProjectID string `path:"projectId"`
+	}) (*h.ItemsResponse[tables.TaskRecurrenceTable], error) {
+		recurrences, err := recurrenceService.ListRecurrences(ctx, input.ProjectID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemsResponse(recurrences)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-recurrence-list"
+		op.Summary = "周期任务列表"
+		op.Tags = []string{taskRecurrenceTag}
+	})
+
+	huma.Post(group, "/projects/{projectId}/task-recurrences/create", func(ctx context.Context, input *struct {
+		ProjectID string `path:"projectId"`
+		Body      saveTaskRecurrenceBody
+	}) (*h.ItemResponse[tables.TaskRecurrenceTable], error) {
+		recurrence, err := recurrenceService.CreateRecurrence(ctx, input.ProjectID, input.Body.toRequest())
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*recurrence)
+		resp.Status = http.StatusCreated
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-recurrence-create"
+		op.Summary = "创建周期任务"
+		op.Description = "按重复规则定期在看板上生成任务，例如每周一的依赖升级或每周的不稳定测试排查。"
+		op.Tags = []string{taskRecurrenceTag}
+	})
+
+	huma.Post(group, "/task-recurrences/{id}/update", func(ctx context.Context, input *struct {
+		ID   string `path:"id"`
+		Body saveTaskRecurrenceBody
+	}) (*h.ItemResponse[tables.TaskRecurrenceTable], error) {
+		recurrence, err := recurrenceService.UpdateRecurrence(ctx, input.ID, input.Body.toRequest())
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*recurrence)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-recurrence-update"
+		op.Summary = "更新周期任务"
+		op.Tags = []string{taskRecurrenceTag}
+	})
+
+	huma.Post(group, "/task-recurrences/{id}/delete", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.MessageResponse, error) {
+		if err := recurrenceService.DeleteRecurrence(ctx, input.ID); err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewMessageResponse("deleted")
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-recurrence-delete"
+		op.Summary = "删除周期任务"
+		op.Description = "已生成的任务会保留。"
+		op.Tags = []string{taskRecurrenceTag}
+	})
+
+	huma.Get(group, "/task-recurrences/{id}/preview", func(ctx context.Context, input *struct {
+		ID    string `path:"id"`
+		Count int    `query:"count" default:"10" minimum:"1" maximum:"50" doc:"返回的触发次数"`
+	}) (*h.ItemsResponse[time.Time], error) {
+		occurrences, err := recurrenceService.Preview(ctx, input.ID, input.Count)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemsResponse(occurrences)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-recurrence-preview"
+		op.Summary = "预览周期任务触发时间"
+		op.Tags = []string{taskRecurrenceTag}
+	})
+
+	huma.Post(group, "/task-recurrences/{id}/run", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.ItemResponse[tables.TaskTable], error) {
+		task, err := recurrenceService.RunNow(ctx, input.ID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*task)
+		resp.Status = http.StatusCreated
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-recurrence-run"
+		op.Summary = "立即生成一次周期任务"
+		op.Description = "立即生成一个任务实例，不影响后续的排期。"
+		op.Tags = []string{taskRecurrenceTag}
+	})
+
+	huma.Get(group, "/projects/{projectId}/tasks/overdue", func(ctx context.Context, input *struct {
+		ProjectID string `path:"projectId"`
+	}) (*h.ItemsResponse[tables.TaskTable], error) {
+		tasks, err := taskService.ListOverdueTasks(ctx, input.ProjectID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemsResponse(tasks)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-list-overdue"
+		op.Summary = "逾期任务列表"
+		op.Description = "返回调度器标记为逾期且尚未完成的任务。"
+		op.Tags = []string{taskRecurrenceTag}
+	})
+}
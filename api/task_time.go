@@ -0,0 +1,197 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/model/tables"
+)
+
+const (
+	taskTimeTag   = "task-time-工时"
+	taskReportTag = "task-report-报表"
+)
+
+type logTaskTimeBody struct {
+	Minutes   int        `json:"minutes,omitempty" minimum:"0" doc:"工时（分钟），与起止时间二选一"`
+	StartedAt *time.Time `json:"startedAt,omitempty" doc:"开始时间"`
+	EndedAt   *time.Time `json:"endedAt,omitempty" doc:"结束时间，仅填写分钟数时默认为当前时间"`
+	Note      string     `json:"note,omitempty" doc:"备注"`
+}
+
+type reportRangeInput struct {
+	ProjectID string `path:"projectId"`
+	From      string `query:"from" doc:"起始日期 YYYY-MM-DD，默认结束日期前 29 天"`
+	To        string `query:"to" doc:"结束日期 YYYY-MM-DD（含），默认今天"`
+}
+
+func registerTaskTimeRoutes(group *huma.Group) {
+	timeService := model.NewTaskTimeService()
+	reportService := model.NewTaskReportService()
+
+	huma.Get(group, "/tasks/{id}/time-entries", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.ItemsResponse[tables.TaskTimeEntryTable], error) {
+		entries, err := timeService.ListEntries(ctx, input.ID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemsResponse(entries)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-time-entry-list"
+		op.Summary = "任务工时记录"
+		op.Description = "包含手动补录、计时器以及终端中 AI 助手运行期间自动记录的工时，未结束的记录 endedAt 为空。"
+		op.Tags = []string{taskTimeTag}
+	})
+
+	huma.Get(group, "/tasks/{id}/time-summary", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.ItemResponse[model.TaskTimeSummary], error) {
+		summary, err := timeService.Summary(ctx, input.ID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*summary)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-time-summary"
+		op.Summary = "任务工时汇总"
+		op.Description = "对比预估工时与已记录工时，进行中的记录按当前时间计入。"
+		op.Tags = []string{taskTimeTag}
+	})
+
+	huma.Post(group, "/tasks/{id}/time-entries/create", func(ctx context.Context, input *struct {
+		ID   string `path:"id"`
+		Body logTaskTimeBody
+	}) (*h.ItemResponse[tables.TaskTimeEntryTable], error) {
+		entry, err := timeService.LogTime(ctx, input.ID, &model.LogTimeRequest{
+			StartedAt: input.Body.StartedAt,
+			EndedAt:   input.Body.EndedAt,
+			Minutes:   input.Body.Minutes,
+			Note:      input.Body.Note,
+		})
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*entry)
+		resp.Status = http.StatusCreated
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-time-entry-create"
+		op.Summary = "补录工时"
+		op.Tags = []string{taskTimeTag}
+	})
+
+	huma.Post(group, "/tasks/{id}/timer/start", func(ctx context.Context, input *struct {
+		ID   string `path:"id"`
+		Body struct {
+			Note string `json:"note,omitempty" doc:"备注"`
+		}
+	}) (*h.ItemResponse[tables.TaskTimeEntryTable], error) {
+		entry, err := timeService.StartTimer(ctx, input.ID, input.Body.Note)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*entry)
+		resp.Status = http.StatusCreated
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-timer-start"
+		op.Summary = "开始计时"
+		op.Description = "每个操作者在同一任务上只能有一个进行中的计时器。"
+		op.Tags = []string{taskTimeTag}
+	})
+
+	huma.Post(group, "/tasks/{id}/timer/stop", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.ItemResponse[tables.TaskTimeEntryTable], error) {
+		entry, err := timeService.StopTimer(ctx, input.ID)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*entry)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-timer-stop"
+		op.Summary = "停止计时"
+		op.Tags = []string{taskTimeTag}
+	})
+
+	huma.Post(group, "/task-time-entries/{id}/delete", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.MessageResponse, error) {
+		if err := timeService.DeleteEntry(ctx, input.ID); err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewMessageResponse("deleted")
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-time-entry-delete"
+		op.Summary = "删除工时记录"
+		op.Tags = []string{taskTimeTag}
+	})
+
+	huma.Get(group, "/projects/{projectId}/reports/burndown", func(ctx context.Context, input *reportRangeInput) (*h.ItemResponse[model.BurndownReport], error) {
+		report, err := reportService.Burndown(ctx, input.ProjectID, input.From, input.To)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*report)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-report-burndown"
+		op.Summary = "燃尽图数据"
+		op.Description = "根据任务状态变更历史还原每天结束时的任务总数、已完成数和剩余数，归档列视为已完成；剩余故事点按任务当前的预估计算。"
+		op.Tags = []string{taskReportTag}
+	})
+
+	huma.Get(group, "/projects/{projectId}/reports/cumulative-flow", func(ctx context.Context, input *reportRangeInput) (*h.ItemResponse[model.CumulativeFlowReport], error) {
+		report, err := reportService.CumulativeFlow(ctx, input.ProjectID, input.From, input.To)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*report)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-report-cumulative-flow"
+		op.Summary = "累积流图数据"
+		op.Description = "每天结束时各列中的任务数。"
+		op.Tags = []string{taskReportTag}
+	})
+
+	huma.Get(group, "/projects/{projectId}/reports/cycle-time", func(ctx context.Context, input *reportRangeInput) (*h.ItemResponse[model.CycleTimeReport], error) {
+		report, err := reportService.CycleTime(ctx, input.ProjectID, input.From, input.To)
+		if err != nil {
+			return nil, mapTaskError(err)
+		}
+
+		resp := h.NewItemResponse(*report)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "task-report-cycle-time"
+		op.Summary = "吞吐量与周期时间"
+		op.Description = "统计区间内完成的任务数、从进入进行中到完成的周期时间，以及每列的进出次数和停留时长（平均、中位数、P85，单位小时）。"
+		op.Tags = []string{taskReportTag}
+	})
+}
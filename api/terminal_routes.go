@@ -385,6 +385,11 @@ func (c *terminalController) consumeClient(ctx context.Context, session *termina
 }
 
 func (c *terminalController) viewFromSnapshot(snapshot terminal.SessionSnapshot) terminalSessionView {
+	return newTerminalSessionView(c.cfg, snapshot)
+}
+
+// newTerminalSessionView 将会话快照转换为 API 视图，并附带 WebSocket 连接地址
+func newTerminalSessionView(cfg *utils.AppConfig, snapshot terminal.SessionSnapshot) terminalSessionView {
 	wsPath := fmt.Sprintf("%s?sessionId=%s", terminalWSPath, snapshot.ID)
 	return terminalSessionView{
 		ID:         snapshot.ID,
@@ -396,7 +401,7 @@ func (c *terminalController) viewFromSnapshot(snapshot terminal.SessionSnapshot)
 		LastActive: snapshot.LastActive,
 		Status:     string(snapshot.Status),
 		WsPath:     wsPath,
-		WsURL:      c.buildWSURL(wsPath),
+		WsURL:      buildWSURL(cfg, wsPath),
 		Rows:       snapshot.Rows,
 		Cols:       snapshot.Cols,
 		Encoding:   snapshot.Encoding,
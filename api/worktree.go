@@ -26,12 +26,14 @@ type createWorktreeInput struct {
 
 type commitWorktreeInput struct {
 	Body struct {
-		Message string `json:"message" doc:"提交信息" minLength:"1"`
+		Message    string `json:"message,omitempty" doc:"提交信息，留空时根据暂存的 diff 自动生成（需配置 commitMessage）"`
+		StagedOnly bool   `json:"stagedOnly,omitempty" doc:"仅提交已暂存的更改，不自动暂存其他文件"`
 	} `json:"body"`
 }
 
-func registerWorktreeRoutes(group *huma.Group) {
+func registerWorktreeRoutes(group *huma.Group, drafter *service.CommitMessageDrafter) {
 	worktreeSvc := service.NewWorktreeService()
+	worktreeSvc.SetCommitMessageDrafter(drafter)
 
 	huma.Post(group, "/projects/{projectId}/worktrees/create", func(
 		ctx context.Context,
@@ -125,9 +127,13 @@ func registerWorktreeRoutes(group *huma.Group) {
 			commitWorktreeInput
 		},
 	) (*h.ItemResponse[model.Worktree], error) {
-		worktree, err := worktreeSvc.CommitWorktree(ctx, input.ID, input.Body.Message)
+		commit := worktreeSvc.CommitWorktree
+		if input.Body.StagedOnly {
+			commit = worktreeSvc.CommitStagedWorktree
+		}
+		worktree, err := commit(ctx, input.ID, input.Body.Message)
 		if err != nil {
-			return nil, mapWorktreeError(err)
+			return nil, mapWorktreeGitError(err)
 		}
 		resp := h.NewItemResponse(*worktree)
 		resp.Status = http.StatusOK
@@ -135,6 +141,8 @@ func registerWorktreeRoutes(group *huma.Group) {
 	}, func(op *huma.Operation) {
 		op.OperationID = "worktree-commit"
 		op.Summary = "提交 Worktree 更改"
+		op.Description = "默认暂存全部更改后提交；stagedOnly 为 true 时只提交暂存区内容。" +
+			"message 为空时由配置的模型根据暂存的 diff 生成 Conventional Commits 格式的提交信息。"
 		op.Tags = []string{worktreeTag}
 	})
 
@@ -210,7 +218,8 @@ func mapWorktreeError(err error) error {
 		errors.Is(err, model.ErrProjectNotFound):
 		return huma.Error404NotFound(err.Error())
 	case errors.Is(err, model.ErrWorktreeIsMain),
-		errors.Is(err, model.ErrWorktreeHasTasks):
+		errors.Is(err, model.ErrWorktreeHasTasks),
+		errors.Is(err, model.ErrBranchExists):
 		return huma.Error409Conflict(err.Error())
 	case errors.Is(err, model.ErrWorktreeClean):
 		return huma.Error400BadRequest(err.Error())
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/service"
+	"code-kanban/service/terminal"
+	"code-kanban/utils"
+	"code-kanban/utils/git"
+)
+
+type worktreeConflictFileInput struct {
+	ID   string `path:"id"`
+	Path string `query:"path" required:"true" doc:"冲突文件相对 worktree 根目录的路径"`
+}
+
+type resolveConflictBody struct {
+	Path       string `json:"path" doc:"冲突文件相对 worktree 根目录的路径"`
+	Resolution string `json:"resolution" enum:"ours,theirs,content" doc:"ours/theirs 采用一侧版本，content 使用上传的合并结果"`
+	Content    string `json:"content,omitempty" doc:"resolution 为 content 时的完整文件内容，不能包含冲突标记"`
+}
+
+type worktreeResolveConflictInput struct {
+	ID   string              `path:"id"`
+	Body resolveConflictBody `json:"body"`
+}
+
+type continueOperationBody struct {
+	Message string `json:"message,omitempty" doc:"仅用于 merge：替换默认的合并提交信息"`
+}
+
+type worktreeContinueOperationInput struct {
+	ID   string                `path:"id"`
+	Body continueOperationBody `json:"body"`
+}
+
+type conflictAgentBody struct {
+	Assistant string `json:"assistant" enum:"claude-code,codex,qwen-code,gemini,cursor,copilot" default:"claude-code" doc:"要启动的 AI 助手"`
+	Rows      int    `json:"rows,omitempty" doc:"终端行数"`
+	Cols      int    `json:"cols,omitempty" doc:"终端列数"`
+}
+
+type worktreeConflictAgentInput struct {
+	ID   string            `path:"id"`
+	Body conflictAgentBody `json:"body"`
+}
+
+func registerWorktreeConflictRoutes(group *huma.Group, cfg *utils.AppConfig, manager *terminal.Manager) {
+	worktreeSvc := service.NewWorktreeService()
+
+	huma.Get(group, "/worktrees/{id}/conflicts", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.ItemResponse[git.ConflictState], error) {
+		state, err := worktreeSvc.ConflictState(ctx, input.ID)
+		if err != nil {
+			return nil, mapWorktreeGitError(err)
+		}
+
+		resp := h.NewItemResponse(*state)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-conflict-state"
+		op.Summary = "冲突状态"
+		op.Description = "检测 worktree 是否停在 merge、rebase、cherry-pick 或 revert 中，返回正在合入的提交和仍有冲突的文件。"
+		op.Tags = []string{worktreeTag}
+	})
+
+	huma.Get(group, "/worktrees/{id}/conflicts/file", func(ctx context.Context, input *worktreeConflictFileInput) (*h.ItemResponse[git.ConflictFile], error) {
+		file, err := worktreeSvc.ConflictFile(ctx, input.ID, input.Path)
+		if err != nil {
+			return nil, mapWorktreeGitError(err)
+		}
+
+		resp := h.NewItemResponse(*file)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-conflict-file"
+		op.Summary = "冲突文件内容"
+		op.Description = "返回 base、ours、theirs 三个版本及工作区中带冲突标记的内容。注意 rebase 时 ours 是目标分支，theirs 是正在重放的提交。"
+		op.Tags = []string{worktreeTag}
+	})
+
+	huma.Post(group, "/worktrees/{id}/conflicts/resolve", func(ctx context.Context, input *worktreeResolveConflictInput) (*h.ItemResponse[git.ConflictState], error) {
+		state, err := worktreeSvc.ResolveConflict(ctx, input.ID, input.Body.Path, input.Body.Resolution, []byte(input.Body.Content))
+		if err != nil {
+			return nil, mapWorktreeGitError(err)
+		}
+
+		resp := h.NewItemResponse(*state)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-conflict-resolve"
+		op.Summary = "解决冲突文件"
+		op.Description = "采用一侧版本或上传合并后的内容，并暂存该文件。若选择的一侧删除了文件，则解决为删除。"
+		op.Tags = []string{worktreeTag}
+	})
+
+	huma.Post(group, "/worktrees/{id}/conflicts/abort", func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*h.ItemResponse[git.ConflictState], error) {
+		state, err := worktreeSvc.AbortOperation(ctx, input.ID)
+		if err != nil {
+			return nil, mapWorktreeGitError(err)
+		}
+
+		resp := h.NewItemResponse(*state)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-conflict-abort"
+		op.Summary = "放弃合并/变基"
+		op.Description = "恢复到操作开始前的状态，已做的冲突解决会丢失。"
+		op.Tags = []string{worktreeTag}
+	})
+
+	huma.Post(group, "/worktrees/{id}/conflicts/continue", func(ctx context.Context, input *worktreeContinueOperationInput) (*h.ItemResponse[git.ConflictState], error) {
+		state, err := worktreeSvc.ContinueOperation(ctx, input.ID, input.Body.Message)
+		if err != nil {
+			return nil, mapWorktreeGitError(err)
+		}
+
+		resp := h.NewItemResponse(*state)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-conflict-continue"
+		op.Summary = "继续合并/变基"
+		op.Description = "所有冲突解决后提交当前步骤。rebase 可能在后续提交再次冲突，需根据返回的状态判断是否完成。"
+		op.Tags = []string{worktreeTag}
+	})
+
+	if manager == nil {
+		return
+	}
+	agentService := service.NewConflictAgentService(manager, cfg.Terminal.AgentCommands)
+
+	huma.Post(group, "/worktrees/{id}/conflicts/hand-to-agent", func(ctx context.Context, input *worktreeConflictAgentInput) (*h.ItemResponse[terminalSessionView], error) {
+		session, err := agentService.HandToAgent(ctx, input.ID, service.ConflictAgentParams{
+			Assistant: input.Body.Assistant,
+			Rows:      input.Body.Rows,
+			Cols:      input.Body.Cols,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrUnsupportedAssistant):
+				return nil, huma.Error400BadRequest(err.Error())
+			case errors.Is(err, terminal.ErrSessionLimitReached):
+				return nil, huma.Error429TooManyRequests(err.Error())
+			case errors.Is(err, utils.ErrShellEnvUnsupported):
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			default:
+				return nil, mapWorktreeGitError(err)
+			}
+		}
+
+		resp := h.NewItemResponse(newTerminalSessionView(cfg, session.Snapshot()))
+		resp.Status = http.StatusCreated
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-conflict-hand-to-agent"
+		op.Summary = "交给 AI 解决冲突"
+		op.Description = "在 worktree 中打开终端并启动 AI 助手，提示词列出冲突文件。助手只负责解决并暂存，继续或放弃仍由用户操作。"
+		op.Tags = []string{worktreeTag}
+	})
+}
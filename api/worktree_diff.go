@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/service"
+	"code-kanban/utils/commitmsg"
+	"code-kanban/utils/git"
+)
+
+type worktreeDiffInput struct {
+	ID               string   `path:"id"`
+	Mode             string   `query:"mode" enum:"working,staged,branch" default:"working" doc:"working：工作区与暂存区；staged：暂存区与 HEAD；branch：HEAD 与基准分支的 merge-base"`
+	Base             string   `query:"base" doc:"branch 模式的基准分支，默认项目的默认分支"`
+	Path             []string `query:"path" doc:"仅比较这些路径，可重复"`
+	Context          int      `query:"context" minimum:"0" maximum:"100" doc:"上下文行数，默认 3"`
+	IgnoreWhitespace bool     `query:"ignoreWhitespace" doc:"忽略空白变化"`
+	Untracked        bool     `query:"untracked" default:"true" doc:"working 模式下是否包含未跟踪文件"`
+	WordDiff         bool     `query:"wordDiff" doc:"为成对修改的行返回行内（按词）差异，下载补丁时忽略"`
+	MaxFiles         int      `query:"maxFiles" minimum:"0" maximum:"2000" doc:"最多返回的文件数，默认 300，下载补丁时忽略"`
+	MaxFileLines     int      `query:"maxFileLines" minimum:"0" maximum:"50000" doc:"单个文件超过该行数时只返回统计，默认 3000，下载补丁时忽略"`
+}
+
+func (q *worktreeDiffInput) options() git.DiffOptions {
+	return git.DiffOptions{
+		Mode:             git.DiffMode(q.Mode),
+		Base:             q.Base,
+		Paths:            q.Path,
+		ContextLines:     q.Context,
+		IgnoreWhitespace: q.IgnoreWhitespace,
+		IncludeUntracked: q.Untracked,
+		WordDiff:         q.WordDiff,
+		Limits:           git.DiffLimits{MaxFiles: q.MaxFiles, MaxFileLines: q.MaxFileLines},
+	}
+}
+
+type worktreePatchOutput struct {
+	ContentType        string `header:"Content-Type"`
+	ContentDisposition string `header:"Content-Disposition"`
+	Body               []byte
+}
+
+func registerWorktreeDiffRoutes(group *huma.Group) {
+	worktreeSvc := service.NewWorktreeService()
+
+	huma.Get(group, "/worktrees/{id}/diff", func(ctx context.Context, input *worktreeDiffInput) (*h.ItemResponse[git.Diff], error) {
+		diff, err := worktreeSvc.DiffWorktree(ctx, input.ID, input.options())
+		if err != nil {
+			return nil, mapWorktreeGitError(err)
+		}
+
+		resp := h.NewItemResponse(*diff)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-diff"
+		op.Summary = "Worktree 差异"
+		op.Description = "返回逐文件的 hunk 与新旧行号，包含重命名检测和二进制标记。" +
+			"超出大小限制的文件 truncated 为 true，仅返回增删统计，可通过 path 参数单独获取。"
+		op.Tags = []string{worktreeTag}
+	})
+
+	huma.Get(group, "/worktrees/{id}/diff/patch", func(ctx context.Context, input *worktreeDiffInput) (*worktreePatchOutput, error) {
+		patch, err := worktreeSvc.PatchWorktree(ctx, input.ID, input.options())
+		if err != nil {
+			return nil, mapWorktreeGitError(err)
+		}
+
+		return &worktreePatchOutput{
+			ContentType:        "text/x-diff; charset=utf-8",
+			ContentDisposition: fmt.Sprintf(`attachment; filename="%s-%s.patch"`, input.ID, strings.ToLower(input.Mode)),
+			Body:               patch,
+		}, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-diff-patch"
+		op.Summary = "下载 Worktree 补丁"
+		op.Description = "以原始 patch 文件形式下载差异（包含二进制内容），可直接用于 git apply。"
+		op.Tags = []string{worktreeTag}
+	})
+}
+
+// mapWorktreeGitError extends mapWorktreeError with the errors of git operations on
+// worktree content.
+func mapWorktreeGitError(err error) error {
+	switch {
+	case errors.Is(err, git.ErrInvalidDiffMode):
+		return huma.Error400BadRequest(err.Error())
+	case errors.Is(err, git.ErrBaseBranchNotFound),
+		errors.Is(err, git.ErrCommitNotFound):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, git.ErrHunkNotFound),
+		errors.Is(err, git.ErrNoOperationInProgress),
+		errors.Is(err, git.ErrUnresolvedConflicts):
+		return huma.Error409Conflict(err.Error())
+	case errors.Is(err, git.ErrInvalidPath),
+		errors.Is(err, git.ErrNothingStaged),
+		errors.Is(err, git.ErrNotConflicted),
+		errors.Is(err, git.ErrInvalidResolution):
+		return huma.Error400BadRequest(err.Error())
+	case errors.Is(err, commitmsg.ErrGeneratorFailed),
+		errors.Is(err, commitmsg.ErrEmptyMessage):
+		return huma.Error502BadGateway(err.Error())
+	case errors.Is(err, git.ErrMergePreviewUnsupported):
+		return huma.Error501NotImplemented(err.Error())
+	default:
+		return mapWorktreeError(err)
+	}
+}
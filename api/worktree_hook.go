@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/service"
+	"code-kanban/utils/worktreehook"
+)
+
+const worktreeHookStreamDescription = "SSE 事件流：hook 为钩子输出的一行（stage、step、stream、line），done 携带结果，error 携带 status 和 detail。"
+
+type worktreeHookBody struct {
+	PostCreate []string `json:"postCreate,omitempty" doc:"创建后在新 worktree 中依次执行的命令，任一失败则回滚创建"`
+	Copy       []string `json:"copy,omitempty" doc:"从主 worktree 复制的文件或 glob，例如 .env*；已存在的文件不会覆盖"`
+	Symlink    []string `json:"symlink,omitempty" doc:"链接到主 worktree 同名目录的路径，例如 node_modules"`
+	PreDelete  []string `json:"preDelete,omitempty" doc:"删除前在 worktree 中执行的命令，失败时除非 force 否则不删除"`
+	Timeout    string   `json:"timeout,omitempty" doc:"单条命令的超时，例如 5m，默认 10m"`
+}
+
+type worktreeHookUpdateInput struct {
+	ProjectID string           `path:"projectId"`
+	Body      worktreeHookBody `json:"body"`
+}
+
+type worktreeCreateStreamInput struct {
+	ProjectID string `path:"projectId"`
+	createWorktreeInput
+}
+
+type worktreeDeleteStreamInput struct {
+	ID           string `path:"id"`
+	Force        bool   `query:"force" default:"false" doc:"钩子失败或存在关联任务时仍然删除"`
+	DeleteBranch bool   `query:"deleteBranch" default:"true"`
+}
+
+func registerWorktreeHookRoutes(group *huma.Group) {
+	worktreeSvc := service.NewWorktreeService()
+	hookSvc := model.NewWorktreeHookService()
+	projectSvc := model.NewProjectService()
+
+	huma.Get(group, "/projects/{projectId}/worktree-hooks", func(ctx context.Context, input *struct {
+		ProjectID string `path:"projectId"`
+	}) (*h.ItemResponse[service.WorktreeHooks], error) {
+		hooks, err := worktreeSvc.WorktreeHooks(ctx, input.ProjectID)
+		if err != nil {
+			return nil, mapWorktreeError(err)
+		}
+
+		resp := h.NewItemResponse(*hooks)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-hooks-get"
+		op.Summary = "Worktree 生命周期钩子"
+		op.Description = "返回当前生效的钩子。项目在此保存过配置时使用数据库中的配置（source 为 database），" +
+			"否则读取仓库根目录 .codekanban.yml 的 worktree 段（source 为 file）。"
+		op.Tags = []string{worktreeTag}
+	})
+
+	huma.Post(group, "/projects/{projectId}/worktree-hooks/update", func(ctx context.Context, input *worktreeHookUpdateInput) (*h.ItemResponse[service.WorktreeHooks], error) {
+		_, err := hookSvc.SaveHooks(ctx, input.ProjectID, worktreehook.Config{
+			PostCreate: input.Body.PostCreate,
+			Copy:       input.Body.Copy,
+			Symlink:    input.Body.Symlink,
+			PreDelete:  input.Body.PreDelete,
+			Timeout:    input.Body.Timeout,
+		})
+		if err != nil {
+			return nil, mapWorktreeError(err)
+		}
+		hooks, err := worktreeSvc.WorktreeHooks(ctx, input.ProjectID)
+		if err != nil {
+			return nil, mapWorktreeError(err)
+		}
+
+		resp := h.NewItemResponse(*hooks)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-hooks-update"
+		op.Summary = "保存 Worktree 生命周期钩子"
+		op.Description = "保存后数据库中的配置覆盖仓库中的 .codekanban.yml。"
+		op.Tags = []string{worktreeTag}
+	})
+
+	huma.Post(group, "/projects/{projectId}/worktree-hooks/reset", func(ctx context.Context, input *struct {
+		ProjectID string `path:"projectId"`
+	}) (*h.ItemResponse[service.WorktreeHooks], error) {
+		if err := hookSvc.DeleteHooks(ctx, input.ProjectID); err != nil {
+			return nil, mapWorktreeError(err)
+		}
+		hooks, err := worktreeSvc.WorktreeHooks(ctx, input.ProjectID)
+		if err != nil {
+			return nil, mapWorktreeError(err)
+		}
+
+		resp := h.NewItemResponse(*hooks)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-hooks-reset"
+		op.Summary = "重置 Worktree 生命周期钩子"
+		op.Description = "删除数据库中的配置，恢复使用仓库中的 .codekanban.yml。"
+		op.Tags = []string{worktreeTag}
+	})
+
+	huma.Post(group, "/projects/{projectId}/worktrees/create-stream", func(ctx context.Context, input *worktreeCreateStreamInput) (*huma.StreamResponse, error) {
+		if _, err := projectSvc.GetProject(ctx, input.ProjectID); err != nil {
+			return nil, mapWorktreeError(err)
+		}
+		return h.NewEventStream(func(send h.EventSender) {
+			worktree, err := worktreeSvc.CreateWorktreeWithOptions(ctx, input.ProjectID, input.Body.BranchName, service.CreateWorktreeOptions{
+				BaseBranch:   input.Body.BaseBranch,
+				CreateBranch: input.Body.CreateBranch,
+				HookOutput:   hookSender(send),
+			})
+			finishRemoteStream(send, worktree, err)
+		}), nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-create-stream"
+		op.Summary = "创建 Worktree（流式输出钩子）"
+		op.Description = "与创建 Worktree 相同，并实时推送 post-create 钩子的输出。钩子失败时删除新建的 worktree 和分支。"
+		op.Tags = []string{worktreeTag}
+		h.EventStreamResponses(op, worktreeHookStreamDescription)
+	})
+
+	huma.Post(group, "/worktrees/{id}/delete-stream", func(ctx context.Context, input *worktreeDeleteStreamInput) (*huma.StreamResponse, error) {
+		if _, err := worktreeSvc.GetWorktree(ctx, input.ID); err != nil {
+			return nil, mapWorktreeError(err)
+		}
+		return h.NewEventStream(func(send h.EventSender) {
+			err := worktreeSvc.DeleteWorktreeWithOptions(ctx, input.ID, service.DeleteWorktreeOptions{
+				Force:        input.Force,
+				DeleteBranch: input.DeleteBranch,
+				HookOutput:   hookSender(send),
+			})
+			finishRemoteStream(send, h.NewMessageResponse("worktree deleted successfully").Body, err)
+		}), nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-delete-stream"
+		op.Summary = "删除 Worktree（流式输出钩子）"
+		op.Description = "与删除 Worktree 相同，并实时推送 pre-delete 钩子的输出。"
+		op.Tags = []string{worktreeTag}
+		h.EventStreamResponses(op, worktreeHookStreamDescription)
+	})
+}
+
+func hookSender(send h.EventSender) worktreehook.OutputFunc {
+	return func(event worktreehook.Event) {
+		_ = send("hook", event)
+	}
+}
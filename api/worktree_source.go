@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/service"
+)
+
+const worktreeCheckoutStreamDescription = "SSE 事件流：progress 为拉取 PR 时 git 输出的进度行，hook 为钩子输出的一行，done 携带新建的 worktree，error 携带 status 和 detail。"
+
+type worktreeCheckoutBody struct {
+	Source      string `json:"source" enum:"remote-branch,tag,commit,pull-request" doc:"remote-branch：基于远程跟踪分支创建本地跟踪分支；tag、commit：以分离 HEAD 检出；pull-request：按需拉取 refs/pull/N/head 并创建本地分支"`
+	Ref         string `json:"ref,omitempty" doc:"远程分支（如 origin/feature/login）、标签或提交，pull-request 时不需要"`
+	PullRequest int    `json:"pullRequest,omitempty" minimum:"0" doc:"PR 编号，source 为 pull-request 时必填"`
+	Remote      string `json:"remote,omitempty" doc:"拉取 PR 的远端，默认 origin"`
+	BranchName  string `json:"branchName,omitempty" doc:"本地分支名，默认为远程分支名去掉远端前缀，PR 为 pr/N；分离 HEAD 时忽略"`
+}
+
+type worktreeCheckoutInput struct {
+	ProjectID string               `path:"projectId"`
+	Body      worktreeCheckoutBody `json:"body"`
+}
+
+func (b worktreeCheckoutBody) options() service.CreateWorktreeOptions {
+	return service.CreateWorktreeOptions{
+		Source:      b.Source,
+		Ref:         b.Ref,
+		PullRequest: b.PullRequest,
+		Remote:      b.Remote,
+	}
+}
+
+func registerWorktreeSourceRoutes(group *huma.Group) {
+	worktreeSvc := service.NewWorktreeService()
+	projectSvc := model.NewProjectService()
+
+	huma.Post(group, "/projects/{projectId}/worktrees/checkout", func(ctx context.Context, input *worktreeCheckoutInput) (*h.ItemResponse[model.Worktree], error) {
+		worktree, err := worktreeSvc.CreateWorktreeWithOptions(ctx, input.ProjectID, input.Body.BranchName, input.Body.options())
+		if err != nil {
+			return nil, mapRemoteError(err)
+		}
+
+		resp := h.NewItemResponse(*worktree)
+		resp.Status = http.StatusCreated
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-checkout"
+		op.Summary = "从远程分支、标签、提交或 PR 创建 Worktree"
+		op.Description = "评审同事的 PR 只需传入 source=pull-request 和 pullRequest。" +
+			"本地分支已存在时返回 409，远程分支、标签、提交或 PR 不存在时返回 404。"
+		op.Tags = []string{worktreeTag}
+	})
+
+	huma.Post(group, "/projects/{projectId}/worktrees/checkout-stream", func(ctx context.Context, input *worktreeCheckoutInput) (*huma.StreamResponse, error) {
+		if _, err := projectSvc.GetProject(ctx, input.ProjectID); err != nil {
+			return nil, mapWorktreeError(err)
+		}
+		return h.NewEventStream(func(send h.EventSender) {
+			opts := input.Body.options()
+			opts.Progress = progressSender(send)
+			opts.HookOutput = hookSender(send)
+			worktree, err := worktreeSvc.CreateWorktreeWithOptions(ctx, input.ProjectID, input.Body.BranchName, opts)
+			finishRemoteStream(send, worktree, err)
+		}), nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-checkout-stream"
+		op.Summary = "从远程分支、标签、提交或 PR 创建 Worktree（流式输出）"
+		op.Description = "与 worktree-checkout 相同，并实时推送拉取 PR 的进度和 post-create 钩子的输出。"
+		op.Tags = []string{worktreeTag}
+		h.EventStreamResponses(op, worktreeCheckoutStreamDescription)
+	})
+}
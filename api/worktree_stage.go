@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"code-kanban/api/h"
+	"code-kanban/model"
+	"code-kanban/service"
+	"code-kanban/utils/git"
+)
+
+type changeSelectionBody struct {
+	Paths         []string      `json:"paths,omitempty" doc:"相对 worktree 根目录的路径，可以是目录"`
+	File          string        `json:"file,omitempty" doc:"按 hunk 操作时的目标文件"`
+	Hunks         []git.HunkRef `json:"hunks,omitempty" doc:"要操作的 hunk，index 对应默认参数下 diff 接口返回的顺序；附带 header 时会校验是否仍一致"`
+	IncludeStaged bool          `json:"includeStaged,omitempty" doc:"仅用于丢弃：同时丢弃已暂存的更改，只对 paths 生效"`
+}
+
+func (b changeSelectionBody) selection() service.ChangeSelection {
+	return service.ChangeSelection{Paths: b.Paths, File: b.File, Hunks: b.Hunks}
+}
+
+type worktreeStageInput struct {
+	ID   string              `path:"id"`
+	Body changeSelectionBody `json:"body"`
+}
+
+func registerWorktreeStageRoutes(group *huma.Group) {
+	worktreeSvc := service.NewWorktreeService()
+
+	huma.Post(group, "/worktrees/{id}/stage", func(ctx context.Context, input *worktreeStageInput) (*h.ItemResponse[model.Worktree], error) {
+		worktree, err := worktreeSvc.StageChanges(ctx, input.ID, input.Body.selection())
+		if err != nil {
+			return nil, mapWorktreeGitError(err)
+		}
+
+		resp := h.NewItemResponse(*worktree)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-stage"
+		op.Summary = "暂存更改"
+		op.Description = "传 paths 暂存整个文件或目录（包括删除）；传 file 与 hunks 只暂存工作区差异中的指定 hunk。"
+		op.Tags = []string{worktreeTag}
+	})
+
+	huma.Post(group, "/worktrees/{id}/unstage", func(ctx context.Context, input *worktreeStageInput) (*h.ItemResponse[model.Worktree], error) {
+		worktree, err := worktreeSvc.UnstageChanges(ctx, input.ID, input.Body.selection())
+		if err != nil {
+			return nil, mapWorktreeGitError(err)
+		}
+
+		resp := h.NewItemResponse(*worktree)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-unstage"
+		op.Summary = "取消暂存"
+		op.Description = "工作区内容保持不变。按 hunk 操作时 index 对应 mode=staged 的差异。"
+		op.Tags = []string{worktreeTag}
+	})
+
+	huma.Post(group, "/worktrees/{id}/discard", func(ctx context.Context, input *worktreeStageInput) (*h.ItemResponse[model.Worktree], error) {
+		worktree, err := worktreeSvc.DiscardChanges(ctx, input.ID, input.Body.selection(), input.Body.IncludeStaged)
+		if err != nil {
+			return nil, mapWorktreeGitError(err)
+		}
+
+		resp := h.NewItemResponse(*worktree)
+		resp.Status = http.StatusOK
+		return resp, nil
+	}, func(op *huma.Operation) {
+		op.OperationID = "worktree-discard"
+		op.Summary = "丢弃更改"
+		op.Description = "还原所选文件在工作区的修改，未跟踪文件会被删除，操作不可恢复。"
+		op.Tags = []string{worktreeTag}
+	})
+}
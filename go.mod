@@ -23,6 +23,7 @@ require (
 	github.com/samber/lo v1.51.0
 	github.com/shirou/gopsutil/v4 v4.25.10
 	github.com/valyala/fasthttp v1.62.0
+	github.com/yuin/goldmark v1.8.6
 	go.uber.org/zap v1.27.0
 	golang.org/x/text v0.28.0
 	gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,295 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"code-kanban/model/tables"
+	"code-kanban/utils/imagecompress"
+	"code-kanban/utils/storage"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// AttachmentOwnerTask attaches a file to a task.
+	AttachmentOwnerTask = "task"
+	// AttachmentOwnerComment attaches a file to a task comment.
+	AttachmentOwnerComment = "comment"
+	// AttachmentOwnerNotePad attaches a file to a notepad tab.
+	AttachmentOwnerNotePad = "notepad"
+
+	maxAttachmentFileName = 200
+)
+
+var (
+	// ErrAttachmentNotFound indicates the requested attachment does not exist.
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrInvalidAttachment indicates the upload request is malformed.
+	ErrInvalidAttachment = errors.New("invalid attachment")
+	// ErrAttachmentTooLarge indicates the upload exceeds the configured size limit.
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+)
+
+// UploadAttachmentRequest carries an uploaded file and the entity it belongs to.
+type UploadAttachmentRequest struct {
+	OwnerType   string
+	OwnerID     string
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// AttachmentOptions configures upload handling.
+type AttachmentOptions struct {
+	// SizeLimit is the maximum accepted upload in bytes; zero disables the check.
+	SizeLimit int64
+	// CompressImages re-encodes large JPEG/PNG uploads before storing them.
+	CompressImages bool
+}
+
+// AttachmentService stores attachment blobs in a storage backend and tracks them in the database.
+type AttachmentService struct {
+	store storage.Storage
+	opts  AttachmentOptions
+}
+
+// NewAttachmentService constructs an attachment service writing to store.
+func NewAttachmentService(store storage.Storage, opts AttachmentOptions) *AttachmentService {
+	return &AttachmentService{store: store, opts: opts}
+}
+
+// Upload validates the owner, optionally compresses images and stores the file.
+func (s *AttachmentService) Upload(ctx context.Context, req *UploadAttachmentRequest) (*tables.AttachmentTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil || len(req.Data) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidAttachment)
+	}
+	if s.opts.SizeLimit > 0 && int64(len(req.Data)) > s.opts.SizeLimit {
+		return nil, fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrAttachmentTooLarge, len(req.Data), s.opts.SizeLimit)
+	}
+
+	ownerType := strings.TrimSpace(req.OwnerType)
+	ownerID := strings.TrimSpace(req.OwnerID)
+	projectID, err := resolveAttachmentOwner(dbCtx, ownerType, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := sanitizeAttachmentFileName(req.FileName)
+	contentType := detectAttachmentContentType(fileName, req.ContentType, req.Data)
+	data := req.Data
+	if s.opts.CompressImages {
+		result := imagecompress.Compress(data, contentType, imagecompress.Options{})
+		data, contentType = result.Data, result.ContentType
+	}
+	sum := sha256.Sum256(data)
+
+	attachment := &tables.AttachmentTable{
+		ProjectID:    projectID,
+		OwnerType:    ownerType,
+		OwnerID:      ownerID,
+		FileName:     fileName,
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		OriginalSize: int64(len(req.Data)),
+		SHA256:       hex.EncodeToString(sum[:]),
+		Backend:      s.store.Kind(),
+	}
+	attachment.Init()
+	attachment.StorageKey = path.Join(attachment.CreatedAt.Format("200601"), attachment.ID, fileName)
+
+	if err := s.store.Put(ctx, attachment.StorageKey, data, contentType); err != nil {
+		return nil, fmt.Errorf("store attachment: %w", err)
+	}
+	if err := dbCtx.Create(attachment).Error; err != nil {
+		// 入库失败时清理已写入的文件，避免留下无人引用的对象
+		_ = s.store.Delete(ctx, attachment.StorageKey)
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// ListAttachments returns the attachments of an owner ordered by upload time.
+func (s *AttachmentService) ListAttachments(ctx context.Context, ownerType, ownerID string) ([]tables.AttachmentTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := resolveAttachmentOwner(dbCtx, ownerType, ownerID); err != nil {
+		return nil, err
+	}
+
+	var attachments []tables.AttachmentTable
+	if err := dbCtx.
+		Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).
+		Order("created_at ASC").
+		Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// GetAttachment fetches attachment metadata by id.
+func (s *AttachmentService) GetAttachment(ctx context.Context, id string) (*tables.AttachmentTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var attachment tables.AttachmentTable
+	if err := dbCtx.First(&attachment, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// ReadAttachment returns the metadata and content of an attachment.
+func (s *AttachmentService) ReadAttachment(ctx context.Context, id string) (*tables.AttachmentTable, []byte, error) {
+	attachment, err := s.GetAttachment(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if attachment.Backend != s.store.Kind() {
+		return nil, nil, fmt.Errorf("attachment %s is stored in %q but the active backend is %q", id, attachment.Backend, s.store.Kind())
+	}
+	reader, err := s.store.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		return nil, nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, data, nil
+}
+
+// DeleteAttachment removes the attachment record and its stored blob.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, id string) error {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	attachment, err := s.GetAttachment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := dbCtx.Unscoped().Delete(&tables.AttachmentTable{}, "id = ?", id).Error; err != nil {
+		return err
+	}
+	if attachment.Backend == s.store.Kind() {
+		if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+			return fmt.Errorf("delete stored attachment: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *AttachmentService) dbWithContext(ctx context.Context) (*gorm.DB, error) {
+	if db == nil {
+		return nil, ErrDBNotInitialized
+	}
+	if s.store == nil {
+		return nil, errors.New("attachment storage is not configured")
+	}
+	return db.WithContext(ensureContext(ctx)), nil
+}
+
+// resolveAttachmentOwner checks that the owner exists and returns its project.
+func resolveAttachmentOwner(dbCtx *gorm.DB, ownerType, ownerID string) (*string, error) {
+	if ownerID == "" {
+		return nil, fmt.Errorf("%w: ownerId is required", ErrInvalidAttachment)
+	}
+	switch ownerType {
+	case AttachmentOwnerTask:
+		var task tables.TaskTable
+		if err := dbCtx.Select("id", "project_id").First(&task, "id = ?", ownerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrTaskNotFound
+			}
+			return nil, err
+		}
+		return &task.ProjectID, nil
+	case AttachmentOwnerComment:
+		var task tables.TaskTable
+		err := dbCtx.
+			Select("tasks.id", "tasks.project_id").
+			Joins("JOIN task_comments ON task_comments.task_id = tasks.id AND task_comments.deleted_at IS NULL").
+			Where("task_comments.id = ?", ownerID).
+			First(&task).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrTaskCommentNotFound
+			}
+			return nil, err
+		}
+		return &task.ProjectID, nil
+	case AttachmentOwnerNotePad:
+		var notepad tables.NotePadTable
+		if err := dbCtx.Select("id", "project_id").First(&notepad, "id = ?", ownerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrNotePadNotFound
+			}
+			return nil, err
+		}
+		return notepad.ProjectID, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown owner type %q", ErrInvalidAttachment, ownerType)
+	}
+}
+
+// sanitizeAttachmentFileName keeps the base name and drops characters that are
+// unsafe in storage keys or Content-Disposition headers.
+func sanitizeAttachmentFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(strings.TrimSpace(name), "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '/' || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." {
+		return "attachment"
+	}
+	if runes := []rune(name); len(runes) > maxAttachmentFileName {
+		ext := []rune(filepath.Ext(name))
+		if len(ext) >= maxAttachmentFileName {
+			// 扩展名本身就超长时不再保留
+			ext = nil
+		}
+		name = string(runes[:maxAttachmentFileName-len(ext)]) + string(ext)
+	}
+	return name
+}
+
+// detectAttachmentContentType prefers the declared type, then the file extension,
+// then content sniffing.
+func detectAttachmentContentType(fileName, declared string, data []byte) string {
+	if mediaType, _, err := mime.ParseMediaType(declared); err == nil && mediaType != "application/octet-stream" {
+		return declared
+	}
+	if byExt := mime.TypeByExtension(strings.ToLower(filepath.Ext(fileName))); byExt != "" {
+		return byExt
+	}
+	return http.DetectContentType(data[:min(len(data), 512)])
+}
@@ -0,0 +1,162 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"code-kanban/utils/storage"
+	"code-kanban/utils/storage/s3test"
+)
+
+func TestAttachmentServiceWithS3Backend(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	server := s3test.NewServer("minio", "minio-secret", "attachments")
+	defer server.Close()
+	store, err := storage.NewS3(storage.S3Config{Endpoint: server.URL, Bucket: "attachments", AccessKey: "minio", SecretKey: "minio-secret"})
+	if err != nil {
+		t.Fatalf("NewS3 returned error: %v", err)
+	}
+	svc := NewAttachmentService(store, AttachmentOptions{SizeLimit: 1 << 20, CompressImages: true})
+
+	ctx := context.Background()
+	project := seedProject(t)
+	task, err := (&TaskService{}).CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "flaky upload"})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	comment, err := NewTaskCommentService().CreateComment(ctx, task.ID, "screenshot attached")
+	if err != nil {
+		t.Fatalf("CreateComment returned error: %v", err)
+	}
+
+	screenshot := image.NewRGBA(image.Rect(0, 0, 3000, 40))
+	for x := 0; x < 3000; x++ {
+		screenshot.Set(x, x%40, color.RGBA{R: 200, A: 255})
+	}
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, screenshot); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	attachment, err := svc.Upload(ctx, &UploadAttachmentRequest{
+		OwnerType: AttachmentOwnerComment,
+		OwnerID:   comment.ID,
+		FileName:  `C:\Users\dev\Desktop\screen.png`,
+		Data:      encoded.Bytes(),
+	})
+	if err != nil {
+		t.Fatalf("Upload returned error: %v", err)
+	}
+	if attachment.FileName != "screen.png" || attachment.ContentType != "image/png" || attachment.Backend != storage.KindS3 {
+		t.Fatalf("unexpected attachment %+v", attachment)
+	}
+	if attachment.ProjectID == nil || *attachment.ProjectID != project.ID {
+		t.Fatalf("expected attachment to inherit the task project, got %v", attachment.ProjectID)
+	}
+	if attachment.OriginalSize != int64(encoded.Len()) {
+		t.Fatalf("unexpected original size %d", attachment.OriginalSize)
+	}
+	if server.Len("attachments") != 1 {
+		t.Fatalf("expected the blob to be stored in the bucket")
+	}
+
+	_, data, err := svc.ReadAttachment(ctx, attachment.ID)
+	if err != nil {
+		t.Fatalf("ReadAttachment returned error: %v", err)
+	}
+	decoded, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode stored image: %v", err)
+	}
+	if decoded.Bounds().Dx() != 2560 {
+		t.Fatalf("expected the image to be downscaled, got %v", decoded.Bounds())
+	}
+
+	list, err := svc.ListAttachments(ctx, AttachmentOwnerComment, comment.ID)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListAttachments returned %d items, err=%v", len(list), err)
+	}
+
+	if err := svc.DeleteAttachment(ctx, attachment.ID); err != nil {
+		t.Fatalf("DeleteAttachment returned error: %v", err)
+	}
+	if server.Len("attachments") != 0 {
+		t.Fatalf("expected the blob to be removed from the bucket")
+	}
+	if _, err := svc.GetAttachment(ctx, attachment.ID); !errors.Is(err, ErrAttachmentNotFound) {
+		t.Fatalf("expected ErrAttachmentNotFound, got %v", err)
+	}
+}
+
+func TestAttachmentServiceValidation(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal returned error: %v", err)
+	}
+	svc := NewAttachmentService(store, AttachmentOptions{SizeLimit: 16})
+	ctx := context.Background()
+
+	notepad, err := (&NotePadService{}).CreateNotePad(ctx, &CreateNotePadRequest{Name: "scratch"})
+	if err != nil {
+		t.Fatalf("CreateNotePad returned error: %v", err)
+	}
+	attachment, err := svc.Upload(ctx, &UploadAttachmentRequest{
+		OwnerType: AttachmentOwnerNotePad,
+		OwnerID:   notepad.ID,
+		FileName:  "notes.md",
+		Data:      []byte("# todo"),
+	})
+	if err != nil {
+		t.Fatalf("Upload returned error: %v", err)
+	}
+	if attachment.ProjectID != nil || attachment.Backend != storage.KindLocal {
+		t.Fatalf("unexpected global notepad attachment %+v", attachment)
+	}
+
+	cases := []struct {
+		req  *UploadAttachmentRequest
+		want error
+	}{
+		{&UploadAttachmentRequest{OwnerType: AttachmentOwnerNotePad, OwnerID: notepad.ID, Data: bytes.Repeat([]byte("x"), 17)}, ErrAttachmentTooLarge},
+		{&UploadAttachmentRequest{OwnerType: AttachmentOwnerNotePad, OwnerID: notepad.ID}, ErrInvalidAttachment},
+		{&UploadAttachmentRequest{OwnerType: "project", OwnerID: notepad.ID, Data: []byte("x")}, ErrInvalidAttachment},
+		{&UploadAttachmentRequest{OwnerType: AttachmentOwnerTask, OwnerID: "missing", Data: []byte("x")}, ErrTaskNotFound},
+		{&UploadAttachmentRequest{OwnerType: AttachmentOwnerComment, OwnerID: "missing", Data: []byte("x")}, ErrTaskCommentNotFound},
+	}
+	for _, tc := range cases {
+		if _, err := svc.Upload(ctx, tc.req); !errors.Is(err, tc.want) {
+			t.Fatalf("expected %v, got %v", tc.want, err)
+		}
+	}
+}
+
+func TestSanitizeAttachmentFileName(t *testing.T) {
+	longBase := strings.Repeat("a", 250) + ".png"
+	longExt := "a." + strings.Repeat("b", 250)
+
+	got := sanitizeAttachmentFileName(longBase)
+	if utf8.RuneCountInString(got) != maxAttachmentFileName || !strings.HasSuffix(got, ".png") {
+		t.Fatalf("expected truncated name keeping the extension, got %q", got)
+	}
+
+	got = sanitizeAttachmentFileName(longExt)
+	if utf8.RuneCountInString(got) != maxAttachmentFileName || !strings.HasPrefix(got, "a.b") {
+		t.Fatalf("expected overlong extension to be truncated with the name, got %q", got)
+	}
+
+	if got := sanitizeAttachmentFileName(`C:\uploads\"report".pdf`); got != "report.pdf" {
+		t.Fatalf("unexpected sanitized name %q", got)
+	}
+}
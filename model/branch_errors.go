@@ -11,4 +11,8 @@ var (
 	ErrProtectedBranch = errors.New("branch is protected and cannot be deleted")
 	// ErrInvalidBranchName indicates user input fails git ref validation.
 	ErrInvalidBranchName = errors.New("invalid branch name")
+	// ErrBranchNotFound indicates neither a local nor a remote-tracking branch has the name.
+	ErrBranchNotFound = errors.New("branch not found")
+	// ErrBranchExists indicates a branch to be created already exists locally.
+	ErrBranchExists = errors.New("branch already exists")
 )
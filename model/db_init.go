@@ -117,3 +117,11 @@ func getDefaultQueries() (*Queries, error) {
 	}
 	return defaultQueries, nil
 }
+
+// gormForQueries returns a gorm handle bound to the connection behind q, so gorm
+// statements issued inside Transaction join the same database transaction.
+func gormForQueries(ctx context.Context, q *Queries) *gorm.DB {
+	tx := db.Session(&gorm.Session{Context: ensureContext(ctx), NewDB: true})
+	tx.Statement.ConnPool = q.db
+	return tx
+}
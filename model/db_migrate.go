@@ -14,9 +14,24 @@ func GetAllModels() []any {
 		&tables.UserAccessTokenTable{},
 		&tables.ProjectTable{},
 		&tables.WorktreeTable{},
+		&tables.WorktreeHookTable{},
 		&tables.TaskTable{},
 		&tables.TaskCommentTable{},
+		&tables.TaskCommentRevisionTable{},
+		&tables.TaskAutomationTable{},
+		&tables.TaskColumnTable{},
+		&tables.TaskLinkTable{},
+		&tables.TaskChecklistItemTable{},
+		&tables.TaskViewTable{},
+		&tables.TaskEventTable{},
+		&tables.TaskRecurrenceTable{},
+		&tables.TaskTimeEntryTable{},
+		&tables.IssueSyncTable{},
+		&tables.TaskIssueTable{},
+		&tables.TaskIssueCommentTable{},
 		&tables.NotePadTable{},
+		&tables.AttachmentTable{},
+		&tables.NotificationTable{},
 	}
 }
 
@@ -38,5 +53,15 @@ func DBMigrate(autoMigrate bool) {
 		}
 	}
 
+	if err := migrateTaskColumns(db); err != nil {
+		logger.Error("task column migration failed", zap.Error(err))
+		panic(err)
+	}
+
+	if err := migrateTaskSearch(db); err != nil {
+		logger.Error("task search index migration failed", zap.Error(err))
+		panic(err)
+	}
+
 	logger.Info("database migration finished")
 }
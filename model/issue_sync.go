@@ -0,0 +1,613 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"code-kanban/model/tables"
+	"code-kanban/utils/issuetracker"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// IssueSyncBoth pulls remote changes and pushes local ones.
+	IssueSyncBoth = "both"
+	// IssueSyncPull only applies remote issues to tasks.
+	IssueSyncPull = "pull"
+	// IssueSyncPush only writes tasks to remote issues.
+	IssueSyncPush = "push"
+
+	// TaskActorIssueSync is the actor recorded for changes applied by the issue sync.
+	TaskActorIssueSync = "issue-sync"
+)
+
+var (
+	// ErrInvalidIssueSync indicates an unknown sync direction or an unusable remote.
+	ErrInvalidIssueSync = errors.New("invalid issue sync configuration")
+)
+
+// IssueTrackerResolver returns the tracker for a project's remote repository.
+type IssueTrackerResolver func(project *tables.ProjectTable) (issuetracker.Tracker, error)
+
+// IssueSyncResult counts what a single sync run changed.
+type IssueSyncResult struct {
+	Repo           string `json:"repo"`
+	Direction      string `json:"direction"`
+	TasksCreated   int    `json:"tasksCreated"`
+	TasksUpdated   int    `json:"tasksUpdated"`
+	IssuesCreated  int    `json:"issuesCreated"`
+	IssuesUpdated  int    `json:"issuesUpdated"`
+	CommentsPulled int    `json:"commentsPulled"`
+	CommentsPushed int    `json:"commentsPushed"`
+	Conflicts      int    `json:"conflicts"`
+}
+
+// IssueSyncService keeps tasks and remote issues in step. Each linked pair remembers the
+// remote updated-at and the local sync time of the last run; when both sides changed
+// since then, the side updated most recently wins.
+type IssueSyncService struct {
+	taskSvc    *TaskService
+	commentSvc *TaskCommentService
+	resolve    IssueTrackerResolver
+}
+
+// NewIssueSyncService constructs a sync service that obtains trackers from resolve.
+func NewIssueSyncService(resolve IssueTrackerResolver) *IssueSyncService {
+	return &IssueSyncService{
+		taskSvc:    &TaskService{},
+		commentSvc: NewTaskCommentService(),
+		resolve:    resolve,
+	}
+}
+
+// GetStatus returns the sync settings and last run of a project. Projects that were
+// never configured report a disabled, never-run state.
+func (s *IssueSyncService) GetStatus(ctx context.Context, projectID string) (*tables.IssueSyncTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureProjectExists(dbCtx, projectID); err != nil {
+		return nil, err
+	}
+
+	var state tables.IssueSyncTable
+	err = dbCtx.Where("project_id = ?", projectID).First(&state).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &tables.IssueSyncTable{ProjectID: projectID, Direction: IssueSyncBoth}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// Configure enables or disables background sync for a project and sets its direction.
+func (s *IssueSyncService) Configure(ctx context.Context, projectID string, enabled bool, direction string) (*tables.IssueSyncTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	direction, err = normalizeSyncDirection(direction)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.GetStatus(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	state.Enabled = enabled
+	state.Direction = direction
+	if err := dbCtx.Save(state).Error; err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// ListEnabledProjects returns the projects that opted into background sync.
+func (s *IssueSyncService) ListEnabledProjects(ctx context.Context) ([]string, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	if err := dbCtx.
+		Model(&tables.IssueSyncTable{}).
+		Where("enabled = ?", true).
+		Pluck("project_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Sync runs one sync pass for the project. An empty direction uses the configured one.
+// The outcome is recorded on the project's sync status either way.
+func (s *IssueSyncService) Sync(ctx context.Context, projectID, direction string) (*IssueSyncResult, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.GetStatus(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(direction) == "" {
+		direction = state.Direction
+	}
+	direction, err = normalizeSyncDirection(direction)
+	if err != nil {
+		return nil, err
+	}
+
+	result, syncErr := s.run(WithActor(ctx, TaskActorIssueSync), dbCtx, projectID, direction)
+
+	now := time.Now()
+	state.LastRunAt = &now
+	state.LastError = ""
+	if result != nil {
+		state.Repo = result.Repo
+		state.Pulled = result.TasksCreated + result.TasksUpdated
+		state.Pushed = result.IssuesCreated + result.IssuesUpdated
+		state.Conflicts = result.Conflicts
+	}
+	if syncErr != nil {
+		state.LastError = syncErr.Error()
+	} else {
+		state.LastSuccessAt = &now
+	}
+	if err := dbCtx.Save(state).Error; err != nil && syncErr == nil {
+		syncErr = err
+	}
+	return result, syncErr
+}
+
+type issueSyncRun struct {
+	*IssueSyncService
+	dbCtx     *gorm.DB
+	tracker   issuetracker.Tracker
+	workflow  *taskWorkflow
+	projectID string
+	pull      bool
+	push      bool
+	result    *IssueSyncResult
+}
+
+func (s *IssueSyncService) run(ctx context.Context, dbCtx *gorm.DB, projectID, direction string) (*IssueSyncResult, error) {
+	var project tables.ProjectTable
+	if err := dbCtx.First(&project, "id = ?", projectID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	if s.resolve == nil {
+		return nil, fmt.Errorf("%w: no issue tracker configured", ErrInvalidIssueSync)
+	}
+	tracker, err := s.resolve(&project)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIssueSync, err)
+	}
+	workflow, err := loadTaskWorkflow(dbCtx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &issueSyncRun{
+		IssueSyncService: s,
+		dbCtx:            dbCtx,
+		tracker:          tracker,
+		workflow:         workflow,
+		projectID:        project.ID,
+		pull:             direction != IssueSyncPush,
+		push:             direction != IssueSyncPull,
+		result:           &IssueSyncResult{Repo: tracker.Repo(), Direction: direction},
+	}
+	return r.result, r.sync(ctx)
+}
+
+func (r *issueSyncRun) sync(ctx context.Context) error {
+	var links []tables.TaskIssueTable
+	if err := r.dbCtx.Where("project_id = ?", r.projectID).Find(&links).Error; err != nil {
+		return err
+	}
+	byNumber := make(map[int]*tables.TaskIssueTable, len(links))
+	linkedTasks := make(map[string]struct{}, len(links))
+	for i := range links {
+		byNumber[links[i].IssueNumber] = &links[i]
+		linkedTasks[links[i].TaskID] = struct{}{}
+	}
+
+	issues, err := r.tracker.ListIssues(ctx, time.Time{})
+	if err != nil {
+		return err
+	}
+	for i := range issues {
+		issue := &issues[i]
+		link := byNumber[issue.Number]
+		if link == nil {
+			if !r.pull {
+				continue
+			}
+			taskID, err := r.createTask(ctx, issue)
+			if err != nil {
+				return err
+			}
+			linkedTasks[taskID] = struct{}{}
+			continue
+		}
+		if err := r.syncLinked(ctx, link, issue); err != nil {
+			return err
+		}
+	}
+
+	if !r.push {
+		return nil
+	}
+	var tasks []tables.TaskTable
+	if err := r.dbCtx.
+		Where("project_id = ?", r.projectID).
+		Order("created_at ASC").
+		Find(&tasks).Error; err != nil {
+		return err
+	}
+	for i := range tasks {
+		if _, linked := linkedTasks[tasks[i].ID]; linked {
+			continue
+		}
+		// 已归档任务不再推送为新 issue
+		if r.workflow.category(tasks[i].Status) == TaskCategoryArchived {
+			continue
+		}
+		if err := r.createIssue(ctx, &tasks[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *issueSyncRun) syncLinked(ctx context.Context, link *tables.TaskIssueTable, issue *issuetracker.Issue) error {
+	var task tables.TaskTable
+	if err := r.dbCtx.First(&task, "id = ?", link.TaskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 本地已删除的任务保留关联，避免下次拉取时重新创建
+			return nil
+		}
+		return err
+	}
+
+	remoteChanged := issue.UpdatedAt.After(link.RemoteUpdatedAt)
+	localChanged := task.UpdatedAt.After(link.SyncedAt)
+	remoteWins := remoteChanged
+	if remoteChanged && localChanged {
+		r.result.Conflicts++
+		remoteWins = issue.UpdatedAt.After(task.UpdatedAt)
+	}
+
+	remoteUpdatedAt := link.RemoteUpdatedAt
+	syncedAt := link.SyncedAt
+	if !remoteChanged {
+		remoteUpdatedAt = issue.UpdatedAt
+	}
+	if !localChanged {
+		syncedAt = time.Now()
+	}
+
+	switch {
+	case remoteWins && r.pull:
+		changed, err := r.applyIssue(ctx, &task, issue)
+		if err != nil {
+			return err
+		}
+		if changed {
+			r.result.TasksUpdated++
+		}
+		if err := r.pullComments(ctx, task.ID, issue.Number); err != nil {
+			return err
+		}
+		remoteUpdatedAt = issue.UpdatedAt
+		syncedAt = time.Now()
+	case localChanged && !remoteWins && r.push:
+		updated, err := r.tracker.UpdateIssue(ctx, issue.Number, r.issueInput(&task))
+		if err != nil {
+			return err
+		}
+		r.result.IssuesUpdated++
+		if r.pull && remoteChanged {
+			// 本地较新时仍拉取远端新增的评论
+			if err := r.pullComments(ctx, task.ID, issue.Number); err != nil {
+				return err
+			}
+		}
+		remoteUpdatedAt = updated.UpdatedAt
+		syncedAt = time.Now()
+	}
+
+	if r.push {
+		latest, err := r.pushComments(ctx, task.ID, issue.Number)
+		if err != nil {
+			return err
+		}
+		if latest.After(remoteUpdatedAt) && !remoteUpdatedAt.Before(issue.UpdatedAt) {
+			remoteUpdatedAt = latest
+		}
+	}
+
+	// 只推进已处理的一侧，未处理的修改（如单向同步时另一侧的变更）留待下次
+	return r.dbCtx.Model(link).Updates(map[string]interface{}{
+		"issue_url":         firstNonEmpty(issue.URL, link.IssueURL),
+		"remote_updated_at": remoteUpdatedAt,
+		"synced_at":         syncedAt,
+	}).Error
+}
+
+func (r *issueSyncRun) createTask(ctx context.Context, issue *issuetracker.Issue) (string, error) {
+	status := r.workflow.defaultStatus()
+	if issue.State == issuetracker.StateClosed {
+		status = firstColumnOfCategory(r.workflow, TaskCategoryDone, status)
+	}
+	task, err := r.taskSvc.CreateTask(ctx, &CreateTaskRequest{
+		ProjectID:   r.projectID,
+		Title:       issue.Title,
+		Description: issue.Body,
+		Status:      status,
+		Tags:        tables.StringArray(issue.Labels),
+	})
+	if err != nil {
+		return "", err
+	}
+	if r.workflow.category(status) == TaskCategoryDone {
+		if _, err := r.taskSvc.UpdateTask(ctx, task.ID, map[string]interface{}{"completed_at": time.Now()}); err != nil {
+			return "", err
+		}
+	}
+	r.result.TasksCreated++
+
+	if err := r.pullComments(ctx, task.ID, issue.Number); err != nil {
+		return "", err
+	}
+	link := &tables.TaskIssueTable{
+		TaskID:          task.ID,
+		ProjectID:       r.projectID,
+		IssueNumber:     issue.Number,
+		IssueURL:        issue.URL,
+		RemoteUpdatedAt: issue.UpdatedAt,
+		SyncedAt:        time.Now(),
+	}
+	return task.ID, r.dbCtx.Create(link).Error
+}
+
+func (r *issueSyncRun) createIssue(ctx context.Context, task *tables.TaskTable) error {
+	issue, err := r.tracker.CreateIssue(ctx, r.issueInput(task))
+	if err != nil {
+		return err
+	}
+	r.result.IssuesCreated++
+
+	link := &tables.TaskIssueTable{
+		TaskID:          task.ID,
+		ProjectID:       r.projectID,
+		IssueNumber:     issue.Number,
+		IssueURL:        issue.URL,
+		RemoteUpdatedAt: issue.UpdatedAt,
+	}
+	latest, err := r.pushComments(ctx, task.ID, issue.Number)
+	if err != nil {
+		return err
+	}
+	if latest.After(link.RemoteUpdatedAt) {
+		link.RemoteUpdatedAt = latest
+	}
+	link.SyncedAt = time.Now()
+	return r.dbCtx.Create(link).Error
+}
+
+// applyIssue copies remote fields onto the task; labels map to tags and the closed
+// state maps to the first done column.
+func (r *issueSyncRun) applyIssue(ctx context.Context, task *tables.TaskTable, issue *issuetracker.Issue) (bool, error) {
+	updates := map[string]interface{}{}
+	if issue.Title != "" && issue.Title != task.Title {
+		updates["title"] = issue.Title
+	}
+	if issue.Body != task.Description {
+		updates["description"] = issue.Body
+	}
+	tags := sanitizeTags(issue.Labels)
+	if formatEventValue(tags) != formatEventValue(sanitizeTags(task.Tags)) {
+		updates["tags"] = tags
+	}
+
+	category := r.workflow.category(task.Status)
+	switch {
+	case issue.State == issuetracker.StateClosed && category != TaskCategoryDone && category != TaskCategoryArchived:
+		updates["status"] = firstColumnOfCategory(r.workflow, TaskCategoryDone, task.Status)
+		updates["completed_at"] = time.Now()
+	case issue.State == issuetracker.StateOpen && (category == TaskCategoryDone || category == TaskCategoryArchived):
+		updates["status"] = r.workflow.defaultStatus()
+		updates["completed_at"] = nil
+	}
+	if status, ok := updates["status"].(string); ok {
+		orderIndex, err := r.taskSvc.getNextOrderIndex(r.dbCtx, task.ProjectID, status)
+		if err != nil {
+			return false, err
+		}
+		updates["order_index"] = orderIndex
+	}
+
+	if len(updates) == 0 {
+		return false, nil
+	}
+	_, err := r.taskSvc.UpdateTask(ctx, task.ID, updates)
+	return err == nil, err
+}
+
+func (r *issueSyncRun) issueInput(task *tables.TaskTable) issuetracker.IssueInput {
+	state := issuetracker.StateOpen
+	if category := r.workflow.category(task.Status); category == TaskCategoryDone || category == TaskCategoryArchived {
+		state = issuetracker.StateClosed
+	}
+	labels := []string(sanitizeTags(task.Tags))
+	return issuetracker.IssueInput{
+		Title:  task.Title,
+		Body:   task.Description,
+		State:  state,
+		Labels: labels,
+	}
+}
+
+// pullComments copies remote comments that are not linked to a local comment yet.
+func (r *issueSyncRun) pullComments(ctx context.Context, taskID string, number int) error {
+	comments, err := r.tracker.ListComments(ctx, number)
+	if err != nil {
+		return err
+	}
+	if len(comments) == 0 {
+		return nil
+	}
+	var known []int64
+	if err := r.dbCtx.
+		Model(&tables.TaskIssueCommentTable{}).
+		Where("task_id = ?", taskID).
+		Pluck("remote_id", &known).Error; err != nil {
+		return err
+	}
+	seen := make(map[int64]struct{}, len(known))
+	for _, id := range known {
+		seen[id] = struct{}{}
+	}
+
+	for _, remote := range comments {
+		if _, ok := seen[remote.ID]; ok || strings.TrimSpace(remote.Body) == "" {
+			continue
+		}
+		comment, err := r.commentSvc.CreateComment(ctx, taskID, remote.Body)
+		if err != nil {
+			return err
+		}
+		if err := r.dbCtx.Create(&tables.TaskIssueCommentTable{
+			CommentID: comment.ID,
+			TaskID:    taskID,
+			RemoteID:  remote.ID,
+			Author:    remote.Author,
+		}).Error; err != nil {
+			return err
+		}
+		r.result.CommentsPulled++
+	}
+	return nil
+}
+
+// pushComments posts local comments that have no remote counterpart and returns the
+// creation time of the newest one, which also advances the issue's updated-at remotely.
+func (r *issueSyncRun) pushComments(ctx context.Context, taskID string, number int) (time.Time, error) {
+	var latest time.Time
+	var comments []tables.TaskCommentTable
+	if err := r.dbCtx.
+		Where("task_id = ?", taskID).
+		Where("id NOT IN (?)", r.dbCtx.Model(&tables.TaskIssueCommentTable{}).Select("comment_id").Where("task_id = ?", taskID)).
+		Order("created_at ASC").
+		Find(&comments).Error; err != nil {
+		return latest, err
+	}
+
+	for _, comment := range comments {
+		body := issueCommentBody(&comment)
+		if body == "" {
+			continue
+		}
+		remote, err := r.tracker.CreateComment(ctx, number, body)
+		if err != nil {
+			return latest, err
+		}
+		if err := r.dbCtx.Create(&tables.TaskIssueCommentTable{
+			CommentID: comment.ID,
+			TaskID:    taskID,
+			RemoteID:  remote.ID,
+			Author:    remote.Author,
+		}).Error; err != nil {
+			return latest, err
+		}
+		if remote.UpdatedAt.After(latest) {
+			latest = remote.UpdatedAt
+		}
+		r.result.CommentsPushed++
+	}
+	return latest, nil
+}
+
+// issueCommentBody renders a local comment as issue markdown. Structured excerpts are
+// appended as a fenced block labelled with their source, since trackers only store text.
+func issueCommentBody(comment *tables.TaskCommentTable) string {
+	body := strings.TrimSpace(comment.Content)
+	excerpt := strings.TrimRight(comment.Excerpt, "\n")
+	if strings.TrimSpace(excerpt) == "" {
+		return body
+	}
+
+	label := "Terminal output"
+	lang := "text"
+	if comment.Kind == TaskCommentKindDiff {
+		label = "Diff"
+		lang = "diff"
+	}
+	if source := strings.TrimSpace(comment.ExcerptSource); source != "" {
+		label += " from " + source
+	}
+	// 围栏长度要超过摘录中最长的反引号序列，避免摘录提前闭合代码块
+	fence := "```"
+	for strings.Contains(excerpt, fence) {
+		fence += "`"
+	}
+
+	var b strings.Builder
+	if body != "" {
+		b.WriteString(body)
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "%s:\n\n%s%s\n%s\n%s", label, fence, lang, excerpt, fence)
+	return b.String()
+}
+
+func (s *IssueSyncService) dbWithContext(ctx context.Context) (*gorm.DB, error) {
+	if db == nil {
+		return nil, ErrDBNotInitialized
+	}
+	return db.WithContext(ensureContext(ctx)), nil
+}
+
+func normalizeSyncDirection(direction string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(direction)) {
+	case "", IssueSyncBoth:
+		return IssueSyncBoth, nil
+	case IssueSyncPull:
+		return IssueSyncPull, nil
+	case IssueSyncPush:
+		return IssueSyncPush, nil
+	default:
+		return "", fmt.Errorf("%w: unknown direction %q", ErrInvalidIssueSync, direction)
+	}
+}
+
+func ensureProjectExists(dbCtx *gorm.DB, projectID string) error {
+	var count int64
+	if err := dbCtx.Model(&tables.ProjectTable{}).Where("id = ?", projectID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,243 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"code-kanban/model/tables"
+	"code-kanban/utils/issuetracker"
+	"code-kanban/utils/issuetracker/trackertest"
+)
+
+func newFakeIssueSync(t *testing.T, server *trackertest.Server) *IssueSyncService {
+	t.Helper()
+	return NewIssueSyncService(func(project *tables.ProjectTable) (issuetracker.Tracker, error) {
+		remote, err := issuetracker.ParseRemote(project.RemoteURL)
+		if err != nil {
+			return nil, err
+		}
+		return issuetracker.New(remote, issuetracker.Config{APIURL: server.URL})
+	})
+}
+
+func TestIssueSyncPullPushAndConflicts(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	server := trackertest.NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	if err := db.Model(&tables.ProjectTable{}).Where("id = ?", project.ID).Update("remote_url", "git@github.com:acme/kanban.git").Error; err != nil {
+		t.Fatalf("set remote url: %v", err)
+	}
+	tasks := &TaskService{}
+	comments := NewTaskCommentService()
+	sync := newFakeIssueSync(t, server)
+
+	remoteIssue := server.AddIssue("remote bug", "steps to reproduce", "bug")
+	server.AddComment(remoteIssue.Number, "octocat", "seen on staging")
+	local, err := tasks.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "local task", Tags: tables.StringArray{"ui"}})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	if _, err := comments.CreateComment(ctx, local.ID, "needs design"); err != nil {
+		t.Fatalf("CreateComment returned error: %v", err)
+	}
+
+	result, err := sync.Sync(ctx, project.ID, "")
+	if err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if result.Repo != "acme/kanban" || result.TasksCreated != 1 || result.IssuesCreated != 1 || result.CommentsPulled != 1 || result.CommentsPushed != 1 {
+		t.Fatalf("unexpected first sync result %+v", result)
+	}
+
+	var pulled tables.TaskTable
+	if err := db.Where("project_id = ? AND title = ?", project.ID, "remote bug").First(&pulled).Error; err != nil {
+		t.Fatalf("expected remote issue to become a task: %v", err)
+	}
+	if pulled.Description != "steps to reproduce" || len(pulled.Tags) != 1 || pulled.Tags[0] != "bug" {
+		t.Fatalf("unexpected pulled task %+v", pulled)
+	}
+	pulledComments, _ := comments.ListComments(ctx, pulled.ID)
+	if len(pulledComments) != 1 || pulledComments[0].Content != "seen on staging" {
+		t.Fatalf("expected remote comment to be pulled, got %+v", pulledComments)
+	}
+	pushed := server.Issue(2)
+	if pushed == nil || pushed.Title != "local task" || len(pushed.Labels) != 1 || pushed.Labels[0] != "ui" {
+		t.Fatalf("expected local task to be pushed, got %+v", pushed)
+	}
+	if remoteComments := server.Comments(2); len(remoteComments) != 1 || remoteComments[0].Body != "needs design" {
+		t.Fatalf("expected local comment to be pushed, got %+v", remoteComments)
+	}
+
+	again, err := sync.Sync(ctx, project.ID, "")
+	if err != nil {
+		t.Fatalf("second Sync returned error: %v", err)
+	}
+	if *again != (IssueSyncResult{Repo: "acme/kanban", Direction: IssueSyncBoth}) {
+		t.Fatalf("expected second sync to be a no-op, got %+v", again)
+	}
+
+	// 远端关闭并改名，本地跟随
+	server.EditIssue(remoteIssue.Number, func(issue *trackertest.Issue) {
+		issue.Title = "remote bug (fixed)"
+		issue.State = issuetracker.StateClosed
+	})
+	if _, err := tasks.UpdateTask(ctx, local.ID, map[string]interface{}{"title": "local task v2"}); err != nil {
+		t.Fatalf("UpdateTask returned error: %v", err)
+	}
+	result, err = sync.Sync(ctx, project.ID, "")
+	if err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if result.TasksUpdated != 1 || result.IssuesUpdated != 1 || result.Conflicts != 0 {
+		t.Fatalf("unexpected sync result %+v", result)
+	}
+	updated, _ := tasks.GetTask(ctx, pulled.ID)
+	if updated.Title != "remote bug (fixed)" || updated.Status != "done" || updated.CompletedAt == nil {
+		t.Fatalf("expected closed issue to complete the task, got %+v", updated)
+	}
+	if server.Issue(2).Title != "local task v2" {
+		t.Fatalf("expected local rename to be pushed, got %+v", server.Issue(2))
+	}
+
+	// 两端同时修改，以较晚的修改为准
+	if _, err := tasks.UpdateTask(ctx, local.ID, map[string]interface{}{"title": "local wins?"}); err != nil {
+		t.Fatalf("UpdateTask returned error: %v", err)
+	}
+	server.EditIssue(2, func(issue *trackertest.Issue) { issue.Title = "remote wins" })
+	result, err = sync.Sync(ctx, project.ID, IssueSyncBoth)
+	if err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if result.Conflicts != 1 || result.TasksUpdated != 1 || result.IssuesUpdated != 0 {
+		t.Fatalf("expected remote to win the conflict, got %+v", result)
+	}
+	winner, _ := tasks.GetTask(ctx, local.ID)
+	if winner.Title != "remote wins" {
+		t.Fatalf("expected newer remote title, got %q", winner.Title)
+	}
+
+	status, err := sync.GetStatus(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("GetStatus returned error: %v", err)
+	}
+	if status.LastSuccessAt == nil || status.LastError != "" || status.Conflicts != 1 || status.Repo != "acme/kanban" {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
+
+func TestIssueSyncDirectionsAndStatus(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	server := trackertest.NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	sync := newFakeIssueSync(t, server)
+
+	if _, err := sync.Sync(ctx, project.ID, ""); err == nil {
+		t.Fatalf("expected project without remote to fail")
+	}
+	status, err := sync.GetStatus(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("GetStatus returned error: %v", err)
+	}
+	if status.LastError == "" || status.LastSuccessAt != nil || status.LastRunAt == nil {
+		t.Fatalf("expected failed run to be recorded, got %+v", status)
+	}
+
+	if err := db.Model(&tables.ProjectTable{}).Where("id = ?", project.ID).Update("remote_url", "https://gitea.example.com/team/app.git").Error; err != nil {
+		t.Fatalf("set remote url: %v", err)
+	}
+	if _, err := sync.Configure(ctx, project.ID, true, "sideways"); err == nil {
+		t.Fatalf("expected invalid direction to be rejected")
+	}
+	if _, err := sync.Configure(ctx, project.ID, true, IssueSyncPull); err != nil {
+		t.Fatalf("Configure returned error: %v", err)
+	}
+	enabled, err := sync.ListEnabledProjects(ctx)
+	if err != nil || len(enabled) != 1 || enabled[0] != project.ID {
+		t.Fatalf("expected project to be enabled, got %v (%v)", enabled, err)
+	}
+
+	server.AddIssue("from remote", "")
+	if _, err := (&TaskService{}).CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "stays local"}); err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	result, err := sync.Sync(ctx, project.ID, "")
+	if err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if result.Direction != IssueSyncPull || result.TasksCreated != 1 || result.IssuesCreated != 0 || len(server.Issues()) != 1 {
+		t.Fatalf("expected pull-only sync, got %+v", result)
+	}
+
+	result, err = sync.Sync(ctx, project.ID, IssueSyncPush)
+	if err != nil {
+		t.Fatalf("push Sync returned error: %v", err)
+	}
+	if result.IssuesCreated != 1 || result.TasksCreated != 0 || len(server.Issues()) != 2 {
+		t.Fatalf("expected push to create one issue, got %+v", result)
+	}
+}
+
+func TestIssueSyncPushesExcerptComments(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	server := trackertest.NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	if err := db.Model(&tables.ProjectTable{}).Where("id = ?", project.ID).Update("remote_url", "git@github.com:acme/kanban.git").Error; err != nil {
+		t.Fatalf("set remote url: %v", err)
+	}
+	comments := NewTaskCommentService()
+	sync := newFakeIssueSync(t, server)
+
+	task, err := (&TaskService{}).CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "flaky build"})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	if _, err := comments.PostComment(ctx, &PostCommentRequest{
+		TaskID:        task.ID,
+		Kind:          TaskCommentKindTerminalOutput,
+		Excerpt:       "FAIL TestBuild\n```\n",
+		ExcerptSource: "go test ./...",
+	}); err != nil {
+		t.Fatalf("PostComment returned error: %v", err)
+	}
+	if _, err := comments.PostComment(ctx, &PostCommentRequest{
+		TaskID:  task.ID,
+		Content: "proposed fix",
+		Kind:    TaskCommentKindDiff,
+		Excerpt: "-old\n+new",
+	}); err != nil {
+		t.Fatalf("PostComment returned error: %v", err)
+	}
+
+	result, err := sync.Sync(ctx, project.ID, "")
+	if err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if result.IssuesCreated != 1 || result.CommentsPushed != 2 {
+		t.Fatalf("expected both comments to be pushed, got %+v", result)
+	}
+	remoteComments := server.Comments(1)
+	if len(remoteComments) != 2 {
+		t.Fatalf("expected two remote comments, got %+v", remoteComments)
+	}
+	if want := "Terminal output from go test ./...:\n\n````text\nFAIL TestBuild\n```\n````"; remoteComments[0].Body != want {
+		t.Fatalf("unexpected terminal output comment %q", remoteComments[0].Body)
+	}
+	if want := "proposed fix\n\nDiff:\n\n```diff\n-old\n+new\n```"; remoteComments[1].Body != want {
+		t.Fatalf("unexpected diff comment %q", remoteComments[1].Body)
+	}
+}
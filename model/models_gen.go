@@ -48,6 +48,8 @@ type Worktree struct {
 	Path            string     `db:"path" json:"path"`
 	IsMain          bool       `db:"is_main" json:"isMain"`
 	IsBare          bool       `db:"is_bare" json:"isBare"`
+	IsDetached      bool       `db:"is_detached" json:"isDetached"`
+	SourceRef       *string    `db:"source_ref" json:"sourceRef"`
 	HeadCommit      *string    `db:"head_commit" json:"headCommit"`
 	HeadCommitDate  *time.Time `db:"head_commit_date" json:"headCommitDate"`
 	StatusAhead     *int64     `db:"status_ahead" json:"statusAhead"`
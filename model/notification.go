@@ -0,0 +1,164 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"code-kanban/model/tables"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// NotificationTypeMention is created when a user is @mentioned in a comment.
+	NotificationTypeMention = "mention"
+
+	notificationExcerptRunes = 200
+)
+
+var (
+	// ErrNotificationNotFound indicates the requested notification does not exist.
+	ErrNotificationNotFound = errors.New("notification not found")
+
+	// mentionPattern matches @username when the @ starts a word, so e-mail
+	// addresses such as dev@example.com are not treated as mentions.
+	mentionPattern    = regexp.MustCompile(`(?:^|[^\w@./-])@([A-Za-z0-9][A-Za-z0-9_.-]{0,63})`)
+	fencedCodePattern = regexp.MustCompile("(?s)```.*?(```|$)")
+	inlineCodePattern = regexp.MustCompile("`[^`\n]*`")
+)
+
+// NotificationService manages per-user notifications.
+type NotificationService struct{}
+
+// NewNotificationService constructs a notification service.
+func NewNotificationService() *NotificationService {
+	return &NotificationService{}
+}
+
+// ListNotifications returns the notifications of a user, newest first.
+func (s *NotificationService) ListNotifications(ctx context.Context, userID string, unreadOnly bool) ([]tables.NotificationTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := dbCtx.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	var notifications []tables.NotificationTable
+	if err := query.Order("created_at DESC").Limit(200).Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// MarkRead marks a single notification of the user as read.
+func (s *NotificationService) MarkRead(ctx context.Context, userID, id string) error {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbCtx.Model(&tables.NotificationTable{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", time.Now()))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification of the user as read and returns how many changed.
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	result := dbCtx.Model(&tables.NotificationTable{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+func (s *NotificationService) dbWithContext(ctx context.Context) (*gorm.DB, error) {
+	if db == nil {
+		return nil, ErrDBNotInitialized
+	}
+	return db.WithContext(ensureContext(ctx)), nil
+}
+
+// notifyMentioned resolves usernames to enabled users and creates a mention
+// notification for each, except for the comment author.
+func (s *NotificationService) notifyMentioned(tx *gorm.DB, task *tables.TaskTable, comment *tables.TaskCommentTable, usernames []string) error {
+	if len(usernames) == 0 {
+		return nil
+	}
+	lowered := make([]string, 0, len(usernames))
+	for _, name := range usernames {
+		lowered = append(lowered, strings.ToLower(name))
+	}
+	var users []tables.UserTable
+	if err := tx.
+		Select("id", "username").
+		Where("LOWER(username) IN ? AND disabled = ?", lowered, false).
+		Find(&users).Error; err != nil {
+		return err
+	}
+
+	notifications := make([]tables.NotificationTable, 0, len(users))
+	for _, user := range users {
+		if comment.Author == TaskActorUser+":"+user.ID {
+			continue
+		}
+		notifications = append(notifications, tables.NotificationTable{
+			UserID:    user.ID,
+			Type:      NotificationTypeMention,
+			ProjectID: task.ProjectID,
+			TaskID:    task.ID,
+			CommentID: &comment.ID,
+			Actor:     comment.Author,
+			Title:     task.Title,
+			Excerpt:   truncateRunes(comment.Content, notificationExcerptRunes),
+		})
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+	return tx.Create(&notifications).Error
+}
+
+// parseMentions returns the distinct usernames mentioned in markdown content,
+// ignoring code spans and fenced code blocks.
+func parseMentions(content string) []string {
+	content = fencedCodePattern.ReplaceAllString(content, " ")
+	content = inlineCodePattern.ReplaceAllString(content, " ")
+
+	seen := map[string]struct{}{}
+	var names []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		name := strings.TrimRight(match[1], ".-")
+		if name == "" {
+			continue
+		}
+		key := strings.ToLower(name)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		names = append(names, name)
+	}
+	return names
+}
+
+func truncateRunes(value string, limit int) string {
+	runes := []rune(strings.TrimSpace(value))
+	if len(runes) <= limit {
+		return string(runes)
+	}
+	return string(runes[:limit]) + "…"
+}
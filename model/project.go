@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"code-kanban/model/tables"
 	"code-kanban/utils"
 	"code-kanban/utils/git"
 
@@ -290,6 +291,24 @@ func (s *ProjectService) UpdateProjectPriority(ctx context.Context, id string, p
 	return project, nil
 }
 
+// MarkSynced records when the project's remotes were last fetched.
+func (s *ProjectService) MarkSynced(ctx context.Context, id string, at time.Time) error {
+	if db == nil {
+		return ErrDBNotInitialized
+	}
+	result := db.WithContext(ensureContext(ctx)).
+		Model(&tables.ProjectTable{}).
+		Where("id = ?", id).
+		Update("last_sync_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
 func (s *ProjectService) dispatchWorktreeSync(ctx context.Context, projectID string, repo *git.GitRepo) {
 	if repo == nil {
 		return
@@ -356,6 +375,7 @@ func (s *ProjectService) syncWorktrees(ctx context.Context, projectID string, re
 				HeadCommit: headPtr,
 				IsMain:     gitWT.IsMain,
 				IsBare:     gitWT.IsBare,
+				IsDetached: gitWT.IsDetached,
 				Id:         existing.Id,
 			})
 			if err != nil {
@@ -384,6 +404,7 @@ func (s *ProjectService) syncWorktrees(ctx context.Context, projectID string, re
 			Path:            filepath.Clean(gitWT.Path),
 			IsMain:          gitWT.IsMain,
 			IsBare:          gitWT.IsBare,
+			IsDetached:      gitWT.IsDetached,
 			HeadCommit:      headPtr,
 			StatusAhead:     &zeroVal,
 			StatusBehind:    &zeroVal,
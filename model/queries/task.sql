@@ -1,6 +1,13 @@
 -- name: TaskCountByWorktree :one
 SELECT COUNT(1) AS count
 FROM tasks
-WHERE worktree_id = @worktree_id
-  AND deleted_at IS NULL
-  AND status IN ('todo', 'in_progress');
+WHERE tasks.worktree_id = @worktree_id
+  AND tasks.deleted_at IS NULL
+  AND tasks.status NOT IN ('done', 'archived')
+  AND tasks.status NOT IN (
+    SELECT task_columns.key
+    FROM task_columns
+    WHERE task_columns.project_id = tasks.project_id
+      AND task_columns.deleted_at IS NULL
+      AND task_columns.category IN ('done', 'archived')
+  );
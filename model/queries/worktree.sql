@@ -8,6 +8,8 @@ INSERT INTO worktrees (
   path,
   is_main,
   is_bare,
+  is_detached,
+  source_ref,
   head_commit,
   head_commit_date,
   status_ahead,
@@ -26,6 +28,8 @@ INSERT INTO worktrees (
   @path,
   @is_main,
   @is_bare,
+  @is_detached,
+  @source_ref,
   @head_commit,
   @head_commit_date,
   @status_ahead,
@@ -48,6 +52,8 @@ SELECT
   path,
   is_main,
   is_bare,
+  is_detached,
+  source_ref,
   head_commit,
   head_commit_date,
   status_ahead,
@@ -101,6 +107,8 @@ RETURNING
   path,
   is_main,
   is_bare,
+  is_detached,
+  source_ref,
   head_commit,
   head_commit_date,
   status_ahead,
@@ -118,6 +126,7 @@ SET
   branch_name = @branch_name,
   head_commit = @head_commit,
   is_main = @is_main,
-  is_bare = @is_bare
+  is_bare = @is_bare,
+  is_detached = @is_detached
 WHERE id = @id
   AND deleted_at IS NULL;
@@ -0,0 +1,395 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// RecurrenceDaily repeats every INTERVAL days.
+	RecurrenceDaily = "DAILY"
+	// RecurrenceWeekly repeats every INTERVAL weeks (weeks start on Monday).
+	RecurrenceWeekly = "WEEKLY"
+	// RecurrenceMonthly repeats every INTERVAL months.
+	RecurrenceMonthly = "MONTHLY"
+	// RecurrenceYearly repeats every INTERVAL years.
+	RecurrenceYearly = "YEARLY"
+
+	// maxRecurrencePeriods bounds the search for the next occurrence.
+	maxRecurrencePeriods = 50000
+)
+
+// ErrInvalidRecurrenceRule indicates the recurrence rule cannot be parsed.
+var ErrInvalidRecurrenceRule = errors.New("invalid recurrence rule")
+
+var recurrenceWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// RecurrenceWeekday is a BYDAY entry such as MO, 1MO (first Monday) or -1FR (last Friday).
+type RecurrenceWeekday struct {
+	Ordinal int
+	Day     time.Weekday
+}
+
+// RecurrenceRule is the supported subset of an RFC 5545 RRULE: FREQ, INTERVAL, COUNT,
+// UNTIL, BYDAY, BYMONTHDAY, BYMONTH, BYHOUR and BYMINUTE. The start time (DTSTART) is
+// kept outside the rule.
+type RecurrenceRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []RecurrenceWeekday
+	ByMonthDay []int
+	ByMonth    []int
+	ByHour     []int
+	ByMinute   []int
+}
+
+// ParseRecurrenceRule parses rules like "FREQ=WEEKLY;BYDAY=MO;BYHOUR=9".
+func ParseRecurrenceRule(value string) (*RecurrenceRule, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "RRULE:"), "rrule:")
+	if value == "" {
+		return nil, fmt.Errorf("%w: rule is empty", ErrInvalidRecurrenceRule)
+	}
+
+	rule := &RecurrenceRule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRecurrenceRule, part)
+		}
+		key = strings.ToUpper(strings.TrimSpace(key))
+		val = strings.ToUpper(strings.TrimSpace(val))
+
+		var err error
+		switch key {
+		case "FREQ":
+			switch val {
+			case RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly, RecurrenceYearly:
+				rule.Freq = val
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ %s", ErrInvalidRecurrenceRule, val)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parseRuleInt(val, 1, 1000)
+		case "COUNT":
+			rule.Count, err = parseRuleInt(val, 1, 100000)
+		case "UNTIL":
+			rule.Until, err = parseRuleUntil(val)
+		case "BYDAY":
+			rule.ByDay, err = parseRuleWeekdays(val)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseRuleInts(val, -31, 31, false)
+		case "BYMONTH":
+			rule.ByMonth, err = parseRuleInts(val, 1, 12, true)
+		case "BYHOUR":
+			rule.ByHour, err = parseRuleInts(val, 0, 23, true)
+		case "BYMINUTE":
+			rule.ByMinute, err = parseRuleInts(val, 0, 59, true)
+		case "WKST":
+			if val != "MO" {
+				return nil, fmt.Errorf("%w: only WKST=MO is supported", ErrInvalidRecurrenceRule)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %s", ErrInvalidRecurrenceRule, key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRecurrenceRule)
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL are mutually exclusive", ErrInvalidRecurrenceRule)
+	}
+	for _, day := range rule.ByDay {
+		if day.Ordinal != 0 && rule.Freq != RecurrenceMonthly && rule.Freq != RecurrenceYearly {
+			return nil, fmt.Errorf("%w: ordinal BYDAY requires MONTHLY or YEARLY", ErrInvalidRecurrenceRule)
+		}
+	}
+	return rule, nil
+}
+
+// Next returns the first occurrence strictly after `after` for a series starting at
+// start, or false when the series has ended (COUNT/UNTIL) or no occurrence was found.
+func (r *RecurrenceRule) Next(start, after time.Time) (time.Time, bool) {
+	seen := 0
+	for period := 0; period < maxRecurrencePeriods; period++ {
+		for _, candidate := range r.periodOccurrences(start, period) {
+			if candidate.Before(start) {
+				continue
+			}
+			if r.Until != nil && candidate.After(*r.Until) {
+				return time.Time{}, false
+			}
+			seen++
+			if r.Count > 0 && seen > r.Count {
+				return time.Time{}, false
+			}
+			if candidate.After(after) {
+				return candidate, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// Occurrences lists up to limit occurrences strictly after `after`.
+func (r *RecurrenceRule) Occurrences(start, after time.Time, limit int) []time.Time {
+	result := make([]time.Time, 0, limit)
+	for len(result) < limit {
+		next, ok := r.Next(start, after)
+		if !ok {
+			break
+		}
+		result = append(result, next)
+		after = next
+	}
+	return result
+}
+
+// periodOccurrences returns the sorted occurrences inside the period-th interval.
+func (r *RecurrenceRule) periodOccurrences(start time.Time, period int) []time.Time {
+	loc := start.Location()
+	step := period * r.Interval
+	var days []time.Time
+
+	switch r.Freq {
+	case RecurrenceDaily:
+		day := time.Date(start.Year(), start.Month(), start.Day()+step, 0, 0, 0, 0, loc)
+		if r.matchesDay(day) {
+			days = append(days, day)
+		}
+	case RecurrenceWeekly:
+		offset := (int(start.Weekday()) + 6) % 7 // 周一为一周的第一天
+		monday := time.Date(start.Year(), start.Month(), start.Day()-offset+7*step, 0, 0, 0, 0, loc)
+		weekdays := []time.Weekday{start.Weekday()}
+		if len(r.ByDay) > 0 {
+			weekdays = weekdays[:0]
+			for _, day := range r.ByDay {
+				weekdays = append(weekdays, day.Day)
+			}
+		}
+		for _, weekday := range weekdays {
+			day := monday.AddDate(0, 0, (int(weekday)+6)%7)
+			if r.matchesMonth(day) && r.matchesMonthDay(day) {
+				days = append(days, day)
+			}
+		}
+	case RecurrenceMonthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1, 0, 0, 0, 0, loc)
+		if r.matchesMonth(first) {
+			days = r.monthDays(first, start.Day())
+		}
+	case RecurrenceYearly:
+		year := start.Year() + step
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []int{int(start.Month())}
+		}
+		for _, month := range months {
+			days = append(days, r.monthDays(time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc), start.Day())...)
+		}
+	}
+
+	hours := r.ByHour
+	minutes := r.ByMinute
+	second := 0
+	if len(hours) == 0 && len(minutes) == 0 {
+		second = start.Second()
+	}
+	if len(hours) == 0 {
+		hours = []int{start.Hour()}
+	}
+	if len(minutes) == 0 {
+		minutes = []int{start.Minute()}
+	}
+
+	result := make([]time.Time, 0, len(days)*len(hours)*len(minutes))
+	for _, day := range days {
+		for _, hour := range hours {
+			for _, minute := range minutes {
+				result = append(result, time.Date(day.Year(), day.Month(), day.Day(), hour, minute, second, 0, loc))
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return dedupeTimes(result)
+}
+
+// monthDays expands BYMONTHDAY/BYDAY within one month, defaulting to the start day.
+func (r *RecurrenceRule) monthDays(first time.Time, defaultDay int) []time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	var days []time.Time
+	add := func(day int) {
+		if day >= 1 && day <= last {
+			days = append(days, first.AddDate(0, 0, day-1))
+		}
+	}
+
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, day := range r.ByMonthDay {
+			if day < 0 {
+				day = last + day + 1
+			}
+			add(day)
+		}
+	case len(r.ByDay) > 0:
+		for _, weekday := range r.ByDay {
+			var matches []int
+			for day := 1; day <= last; day++ {
+				if first.AddDate(0, 0, day-1).Weekday() == weekday.Day {
+					matches = append(matches, day)
+				}
+			}
+			switch {
+			case weekday.Ordinal == 0:
+				for _, day := range matches {
+					add(day)
+				}
+			case weekday.Ordinal > 0 && weekday.Ordinal <= len(matches):
+				add(matches[weekday.Ordinal-1])
+			case weekday.Ordinal < 0 && -weekday.Ordinal <= len(matches):
+				add(matches[len(matches)+weekday.Ordinal])
+			}
+		}
+	default:
+		add(defaultDay)
+	}
+	return days
+}
+
+func (r *RecurrenceRule) matchesDay(day time.Time) bool {
+	if !r.matchesMonth(day) || !r.matchesMonthDay(day) {
+		return false
+	}
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, weekday := range r.ByDay {
+		if weekday.Day == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RecurrenceRule) matchesMonth(day time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, month := range r.ByMonth {
+		if time.Month(month) == day.Month() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RecurrenceRule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+	for _, monthDay := range r.ByMonthDay {
+		if monthDay == day.Day() || (monthDay < 0 && last+monthDay+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupeTimes(values []time.Time) []time.Time {
+	result := values[:0]
+	for i, value := range values {
+		if i > 0 && value.Equal(values[i-1]) {
+			continue
+		}
+		result = append(result, value)
+	}
+	return result
+}
+
+func parseRuleInt(value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%w: %q out of range", ErrInvalidRecurrenceRule, value)
+	}
+	return n, nil
+}
+
+func parseRuleInts(value string, min, max int, allowZero bool) ([]int, error) {
+	var result []int
+	for _, part := range strings.Split(value, ",") {
+		n, err := parseRuleInt(strings.TrimSpace(part), min, max)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 && !allowZero {
+			return nil, fmt.Errorf("%w: %q out of range", ErrInvalidRecurrenceRule, part)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+func parseRuleWeekdays(value string) ([]RecurrenceWeekday, error) {
+	var result []RecurrenceWeekday
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) < 2 {
+			return nil, fmt.Errorf("%w: BYDAY %q", ErrInvalidRecurrenceRule, part)
+		}
+		day, ok := recurrenceWeekdays[part[len(part)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("%w: BYDAY %q", ErrInvalidRecurrenceRule, part)
+		}
+		weekday := RecurrenceWeekday{Day: day}
+		if prefix := part[:len(part)-2]; prefix != "" {
+			ordinal, err := strconv.Atoi(strings.TrimPrefix(prefix, "+"))
+			if err != nil || ordinal == 0 || ordinal < -5 || ordinal > 5 {
+				return nil, fmt.Errorf("%w: BYDAY %q", ErrInvalidRecurrenceRule, part)
+			}
+			weekday.Ordinal = ordinal
+		}
+		result = append(result, weekday)
+	}
+	return result, nil
+}
+
+func parseRuleUntil(value string) (*time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		loc := time.Local
+		if strings.HasSuffix(layout, "Z") {
+			loc = time.UTC
+		}
+		if parsed, err := time.ParseInLocation(layout, value, loc); err == nil {
+			if layout == "20060102" {
+				// 仅日期时包含当天
+				parsed = parsed.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			return &parsed, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: UNTIL %q", ErrInvalidRecurrenceRule, value)
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecurrenceRuleOccurrences(t *testing.T) {
+	loc := time.UTC
+	start := time.Date(2026, 1, 1, 8, 30, 0, 0, loc) // Thursday
+	format := func(values []time.Time) []string {
+		result := make([]string, 0, len(values))
+		for _, value := range values {
+			result = append(result, value.Format("2006-01-02 15:04 Mon"))
+		}
+		return result
+	}
+
+	cases := []struct {
+		rule     string
+		expected []string
+	}{
+		{"FREQ=DAILY;INTERVAL=2", []string{"2026-01-01 08:30 Thu", "2026-01-03 08:30 Sat", "2026-01-05 08:30 Mon"}},
+		{"FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9;BYMINUTE=0", []string{"2026-01-01 09:00 Thu", "2026-01-02 09:00 Fri", "2026-01-05 09:00 Mon"}},
+		{"FREQ=WEEKLY;BYDAY=MO,FR", []string{"2026-01-02 08:30 Fri", "2026-01-05 08:30 Mon", "2026-01-09 08:30 Fri"}},
+		{"FREQ=WEEKLY;INTERVAL=2", []string{"2026-01-01 08:30 Thu", "2026-01-15 08:30 Thu", "2026-01-29 08:30 Thu"}},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", []string{"2026-01-31 08:30 Sat", "2026-02-28 08:30 Sat", "2026-03-31 08:30 Tue"}},
+		{"FREQ=MONTHLY;BYDAY=1MO", []string{"2026-01-05 08:30 Mon", "2026-02-02 08:30 Mon", "2026-03-02 08:30 Mon"}},
+		{"FREQ=MONTHLY;BYDAY=-1FR", []string{"2026-01-30 08:30 Fri", "2026-02-27 08:30 Fri", "2026-03-27 08:30 Fri"}},
+		{"RRULE:FREQ=YEARLY;BYMONTH=3,9", []string{"2026-03-01 08:30 Sun", "2026-09-01 08:30 Tue", "2027-03-01 08:30 Mon"}},
+		{"FREQ=DAILY;COUNT=2", []string{"2026-01-01 08:30 Thu", "2026-01-02 08:30 Fri"}},
+		{"FREQ=WEEKLY;UNTIL=20260108", []string{"2026-01-01 08:30 Thu", "2026-01-08 08:30 Thu"}},
+	}
+	for _, tc := range cases {
+		rule, err := ParseRecurrenceRule(tc.rule)
+		if err != nil {
+			t.Fatalf("ParseRecurrenceRule(%q) returned error: %v", tc.rule, err)
+		}
+		got := format(rule.Occurrences(start, start.Add(-time.Nanosecond), 3))
+		if len(got) != len(tc.expected) {
+			t.Fatalf("%s: expected %v, got %v", tc.rule, tc.expected, got)
+		}
+		for i := range got {
+			if got[i] != tc.expected[i] {
+				t.Fatalf("%s: expected %v, got %v", tc.rule, tc.expected, got)
+			}
+		}
+	}
+
+	rule, _ := ParseRecurrenceRule("FREQ=DAILY;COUNT=3")
+	if _, ok := rule.Next(start, start.AddDate(0, 0, 5)); ok {
+		t.Fatalf("expected COUNT to end the series")
+	}
+
+	for _, invalid := range []string{"", "FREQ=HOURLY", "INTERVAL=2", "FREQ=WEEKLY;BYDAY=1MO", "FREQ=DAILY;COUNT=2;UNTIL=20260101", "FREQ=MONTHLY;BYMONTHDAY=0", "FREQ=DAILY;BYHOUR=24"} {
+		if _, err := ParseRecurrenceRule(invalid); !errors.Is(err, ErrInvalidRecurrenceRule) {
+			t.Fatalf("expected %q to be rejected, got %v", invalid, err)
+		}
+	}
+}
@@ -1,7 +1,7 @@
 -- 数据库建表语句
--- 生成时间: 2025-11-16 02:49:18
+-- 生成时间: 2026-10-18 21:51:31
 -- 数据库方言: sqlite
--- 总共 36 条语句
+-- 总共 116 条语句
 
 
 CREATE TABLE "users" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"nickname" text,"avatar" text,"brief" text,"username" text NOT NULL,"password" text NOT NULL,"salt" text NOT NULL,"disabled" numeric NOT NULL DEFAULT false,PRIMARY KEY ("id"));
@@ -20,14 +20,21 @@ CREATE INDEX "idx_projects_name" ON "projects"("name");
 CREATE INDEX "idx_projects_deleted_at" ON "projects"("deleted_at");
 
 
-CREATE TABLE "worktrees" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"project_id" text NOT NULL,"branch_name" text NOT NULL,"path" text NOT NULL,"is_main" boolean DEFAULT false,"is_bare" boolean DEFAULT false,"head_commit" text,"head_commit_date" datetime,"status_ahead" integer DEFAULT 0,"status_behind" integer DEFAULT 0,"status_modified" integer DEFAULT 0,"status_staged" integer DEFAULT 0,"status_untracked" integer DEFAULT 0,"status_conflicts" integer DEFAULT 0,"status_updated_at" datetime,PRIMARY KEY ("id"));
+CREATE TABLE "worktrees" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"project_id" text NOT NULL,"branch_name" text NOT NULL,"path" text NOT NULL,"is_main" boolean DEFAULT false,"is_bare" boolean DEFAULT false,"is_detached" boolean DEFAULT false,"source_ref" text,"head_commit" text,"head_commit_date" datetime,"status_ahead" integer DEFAULT 0,"status_behind" integer DEFAULT 0,"status_modified" integer DEFAULT 0,"status_staged" integer DEFAULT 0,"status_untracked" integer DEFAULT 0,"status_conflicts" integer DEFAULT 0,"status_updated_at" datetime,PRIMARY KEY ("id"));
 CREATE UNIQUE INDEX "idx_worktrees_path" ON "worktrees"("path") WHERE deleted_at IS NULL;
 CREATE INDEX "idx_worktrees_branch_name" ON "worktrees"("branch_name");
 CREATE INDEX "idx_worktrees_project_id" ON "worktrees"("project_id");
 CREATE INDEX "idx_worktrees_deleted_at" ON "worktrees"("deleted_at");
 
 
-CREATE TABLE "tasks" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"project_id" text NOT NULL,"worktree_id" text,"branch_name" text,"title" text NOT NULL,"description" text,"status" text NOT NULL,"priority" integer DEFAULT 0,"order_index" real NOT NULL,"tags" text,"due_date" datetime,"completed_at" datetime,PRIMARY KEY ("id"));
+CREATE TABLE "worktree_hooks" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"project_id" text NOT NULL,"post_create" text,"copy" text,"symlink" text,"pre_delete" text,"timeout" text,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX "idx_worktree_hooks_project_id" ON "worktree_hooks"("project_id");
+CREATE INDEX "idx_worktree_hooks_deleted_at" ON "worktree_hooks"("deleted_at");
+
+
+CREATE TABLE "tasks" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"project_id" text NOT NULL,"worktree_id" text,"branch_name" text,"title" text NOT NULL,"description" text,"status" text NOT NULL,"priority" integer DEFAULT 0,"order_index" real NOT NULL,"tags" text,"due_date" datetime,"completed_at" datetime,"external_id" text,"recurrence_id" text,"overdue_at" datetime,"estimate_minutes" integer,"estimate_points" real,PRIMARY KEY ("id"));
+CREATE INDEX "idx_tasks_recurrence_id" ON "tasks"("recurrence_id");
+CREATE INDEX "idx_tasks_external_id" ON "tasks"("external_id");
 CREATE INDEX "idx_tasks_order_index" ON "tasks"("order_index");
 CREATE INDEX "idx_tasks_priority" ON "tasks"("priority");
 CREATE INDEX "idx_tasks_status" ON "tasks"("status");
@@ -36,13 +43,101 @@ CREATE INDEX "idx_tasks_project_id" ON "tasks"("project_id");
 CREATE INDEX "idx_tasks_deleted_at" ON "tasks"("deleted_at");
 
 
-CREATE TABLE "task_comments" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"task_id" text NOT NULL,"content" text NOT NULL,PRIMARY KEY ("id"));
+CREATE TABLE "task_comments" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"task_id" text NOT NULL,"parent_id" text,"author" text NOT NULL DEFAULT "","kind" text NOT NULL DEFAULT "text","content" text NOT NULL,"excerpt" text,"excerpt_source" text,"edited_at" datetime,"edit_count" integer NOT NULL DEFAULT 0,PRIMARY KEY ("id"));
+CREATE INDEX "idx_task_comments_parent_id" ON "task_comments"("parent_id");
 CREATE INDEX "idx_task_comments_task_id" ON "task_comments"("task_id");
 CREATE INDEX "idx_task_comments_deleted_at" ON "task_comments"("deleted_at");
 
 
+CREATE TABLE "task_comment_revisions" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"comment_id" text NOT NULL,"content" text NOT NULL,"excerpt" text,"editor" text NOT NULL DEFAULT "",PRIMARY KEY ("id"));
+CREATE INDEX "idx_task_comment_revisions_comment_id" ON "task_comment_revisions"("comment_id");
+CREATE INDEX "idx_task_comment_revisions_deleted_at" ON "task_comment_revisions"("deleted_at");
+
+
+CREATE TABLE "task_automations" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"project_id" text NOT NULL,"trigger" text NOT NULL,"from_statuses" text,"to_status" text NOT NULL,"enabled" boolean NOT NULL DEFAULT false,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX "idx_task_automations_project_trigger" ON "task_automations"("project_id","trigger") WHERE deleted_at IS NULL;
+CREATE INDEX "idx_task_automations_deleted_at" ON "task_automations"("deleted_at");
+
+
+CREATE TABLE "task_columns" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"project_id" text NOT NULL,"key" text NOT NULL,"name" text NOT NULL,"color" text,"category" text NOT NULL,"order_index" real NOT NULL,"wip_limit" integer NOT NULL DEFAULT 0,"allowed_transitions" text,PRIMARY KEY ("id"));
+CREATE INDEX "idx_task_columns_order_index" ON "task_columns"("order_index");
+CREATE UNIQUE INDEX "idx_task_columns_project_key" ON "task_columns"("project_id","key") WHERE deleted_at IS NULL;
+CREATE INDEX "idx_task_columns_deleted_at" ON "task_columns"("deleted_at");
+
+
+CREATE TABLE "task_links" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"source_task_id" text NOT NULL,"target_task_id" text NOT NULL,"type" text NOT NULL,PRIMARY KEY ("id"));
+CREATE INDEX "idx_task_links_target_task_id" ON "task_links"("target_task_id");
+CREATE UNIQUE INDEX "idx_task_links_unique" ON "task_links"("source_task_id","target_task_id","type") WHERE deleted_at IS NULL;
+CREATE INDEX "idx_task_links_source_task_id" ON "task_links"("source_task_id");
+CREATE INDEX "idx_task_links_deleted_at" ON "task_links"("deleted_at");
+
+
+CREATE TABLE "task_checklist_items" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"task_id" text NOT NULL,"content" text NOT NULL,"done" numeric NOT NULL DEFAULT false,"order_index" real NOT NULL,"completed_at" datetime,PRIMARY KEY ("id"));
+CREATE INDEX "idx_task_checklist_items_order_index" ON "task_checklist_items"("order_index");
+CREATE INDEX "idx_task_checklist_items_task_id" ON "task_checklist_items"("task_id");
+CREATE INDEX "idx_task_checklist_items_deleted_at" ON "task_checklist_items"("deleted_at");
+
+
+CREATE TABLE "task_views" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"user_id" text NOT NULL DEFAULT "","name" text NOT NULL,"project_ids" text,"query" text,"sort" text NOT NULL DEFAULT "order","group_by" text NOT NULL DEFAULT "project",PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX "idx_task_views_user_name" ON "task_views"("user_id","name") WHERE deleted_at IS NULL;
+CREATE INDEX "idx_task_views_deleted_at" ON "task_views"("deleted_at");
+
+
+CREATE TABLE "task_events" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"task_id" text NOT NULL,"project_id" text NOT NULL,"type" text NOT NULL,"field" text,"old_value" text,"new_value" text,"actor" text NOT NULL DEFAULT "","branch_name" text,"worktree_id" text,"comment_id" text,PRIMARY KEY ("id"));
+CREATE INDEX "idx_task_events_type" ON "task_events"("type");
+CREATE INDEX "idx_task_events_project_id" ON "task_events"("project_id");
+CREATE INDEX "idx_task_events_task_id" ON "task_events"("task_id");
+CREATE INDEX "idx_task_events_deleted_at" ON "task_events"("deleted_at");
+
+
+CREATE TABLE "task_recurrences" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"project_id" text NOT NULL,"title" text NOT NULL,"description" text,"status" text,"priority" integer DEFAULT 0,"tags" text,"rule" text NOT NULL,"start_at" datetime NOT NULL,"due_after_minutes" integer,"skip_if_open" boolean NOT NULL DEFAULT false,"enabled" boolean NOT NULL DEFAULT true,"next_run_at" datetime,"last_run_at" datetime,"last_task_id" text,"spawned_count" integer NOT NULL DEFAULT 0,PRIMARY KEY ("id"));
+CREATE INDEX "idx_task_recurrences_next_run_at" ON "task_recurrences"("next_run_at");
+CREATE INDEX "idx_task_recurrences_project_id" ON "task_recurrences"("project_id");
+CREATE INDEX "idx_task_recurrences_deleted_at" ON "task_recurrences"("deleted_at");
+
+
+CREATE TABLE "task_time_entries" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"task_id" text NOT NULL,"project_id" text NOT NULL,"source" text NOT NULL,"actor" text NOT NULL DEFAULT "","session_id" text,"assistant" text,"started_at" datetime NOT NULL,"ended_at" datetime,"last_seen_at" datetime,"duration_seconds" integer NOT NULL DEFAULT 0,"note" text,PRIMARY KEY ("id"));
+CREATE INDEX "idx_task_time_entries_started_at" ON "task_time_entries"("started_at");
+CREATE INDEX "idx_task_time_entries_session_id" ON "task_time_entries"("session_id");
+CREATE INDEX "idx_task_time_entries_source" ON "task_time_entries"("source");
+CREATE INDEX "idx_task_time_entries_project_id" ON "task_time_entries"("project_id");
+CREATE INDEX "idx_task_time_entries_task_id" ON "task_time_entries"("task_id");
+CREATE INDEX "idx_task_time_entries_deleted_at" ON "task_time_entries"("deleted_at");
+
+
+CREATE TABLE "issue_syncs" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"project_id" text NOT NULL,"enabled" boolean NOT NULL DEFAULT false,"direction" text NOT NULL DEFAULT "both","repo" text,"last_run_at" datetime,"last_success_at" datetime,"last_error" text,"pulled" integer NOT NULL DEFAULT 0,"pushed" integer NOT NULL DEFAULT 0,"conflicts" integer NOT NULL DEFAULT 0,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX "idx_issue_syncs_project_id" ON "issue_syncs"("project_id");
+CREATE INDEX "idx_issue_syncs_deleted_at" ON "issue_syncs"("deleted_at");
+
+
+CREATE TABLE "task_issues" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"task_id" text NOT NULL,"project_id" text NOT NULL,"issue_number" integer NOT NULL,"issue_url" text,"remote_updated_at" datetime,"synced_at" datetime,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX "idx_task_issues_project_number" ON "task_issues"("project_id","issue_number");
+CREATE UNIQUE INDEX "idx_task_issues_task_id" ON "task_issues"("task_id");
+CREATE INDEX "idx_task_issues_deleted_at" ON "task_issues"("deleted_at");
+
+
+CREATE TABLE "task_issue_comments" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"comment_id" text NOT NULL,"task_id" text NOT NULL,"remote_id" integer NOT NULL,"author" text,PRIMARY KEY ("id"));
+CREATE INDEX "idx_task_issue_comments_remote_id" ON "task_issue_comments"("remote_id");
+CREATE INDEX "idx_task_issue_comments_task_id" ON "task_issue_comments"("task_id");
+CREATE UNIQUE INDEX "idx_task_issue_comments_comment_id" ON "task_issue_comments"("comment_id");
+CREATE INDEX "idx_task_issue_comments_deleted_at" ON "task_issue_comments"("deleted_at");
+
+
 CREATE TABLE "notepads" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"project_id" text,"name" text NOT NULL,"content" text,"order_index" real NOT NULL,PRIMARY KEY ("id"));
 CREATE INDEX "idx_notepads_order_index" ON "notepads"("order_index");
 CREATE INDEX "idx_notepads_project_id" ON "notepads"("project_id");
 CREATE INDEX "idx_notepads_deleted_at" ON "notepads"("deleted_at");
 
+
+CREATE TABLE "attachments" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"project_id" text,"owner_type" text NOT NULL,"owner_id" text NOT NULL,"file_name" text NOT NULL,"content_type" text NOT NULL,"size" integer NOT NULL,"original_size" integer NOT NULL,"sha256" text NOT NULL,"backend" text NOT NULL,"storage_key" text NOT NULL,PRIMARY KEY ("id"));
+CREATE INDEX "idx_attachments_owner" ON "attachments"("owner_type","owner_id");
+CREATE INDEX "idx_attachments_project_id" ON "attachments"("project_id");
+CREATE INDEX "idx_attachments_deleted_at" ON "attachments"("deleted_at");
+
+
+CREATE TABLE "notifications" ("id" text NOT NULL,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"user_id" text NOT NULL,"read_at" datetime,"type" text NOT NULL,"project_id" text,"task_id" text,"comment_id" text,"actor" text NOT NULL DEFAULT "","title" text,"excerpt" text,PRIMARY KEY ("id"));
+CREATE INDEX "idx_notifications_task_id" ON "notifications"("task_id");
+CREATE INDEX "idx_notifications_project_id" ON "notifications"("project_id");
+CREATE INDEX "idx_notifications_user_read" ON "notifications"("user_id","read_at");
+CREATE INDEX "idx_notifications_deleted_at" ON "notifications"("deleted_at");
+
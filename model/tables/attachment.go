@@ -0,0 +1,25 @@
+package tables
+
+import "code-kanban/utils/model_base"
+
+// AttachmentTable records an uploaded file attached to a task, comment or notepad.
+// The blob itself lives in the configured storage backend under StorageKey.
+type AttachmentTable struct {
+	model_base.StringPKBaseModel
+
+	ProjectID    *string `gorm:"type:text;index" json:"projectId"`                                // 全局笔记的附件为空
+	OwnerType    string  `gorm:"type:text;not null;index:idx_attachments_owner" json:"ownerType"` // task/comment/notepad
+	OwnerID      string  `gorm:"type:text;not null;index:idx_attachments_owner" json:"ownerId"`
+	FileName     string  `gorm:"type:text;not null" json:"fileName"`
+	ContentType  string  `gorm:"type:text;not null" json:"contentType"`
+	Size         int64   `gorm:"type:integer;not null" json:"size"`
+	OriginalSize int64   `gorm:"type:integer;not null" json:"originalSize"` // 压缩前的大小，未压缩时与 Size 相同
+	SHA256       string  `gorm:"type:text;not null" json:"sha256"`
+	Backend      string  `gorm:"type:text;not null" json:"backend"` // local/s3
+	StorageKey   string  `gorm:"type:text;not null" json:"-"`
+}
+
+// TableName maps the gorm model to the attachments table.
+func (AttachmentTable) TableName() string {
+	return "attachments"
+}
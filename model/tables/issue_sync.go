@@ -0,0 +1,60 @@
+package tables
+
+import (
+	"time"
+
+	"code-kanban/utils/model_base"
+)
+
+// IssueSyncTable stores the issue tracker sync settings and the outcome of the last run for a project.
+type IssueSyncTable struct {
+	model_base.StringPKBaseModel
+
+	ProjectID     string     `gorm:"type:text;not null;uniqueIndex" json:"projectId"`
+	Enabled       bool       `gorm:"type:boolean;not null;default:false" json:"enabled"` // 是否参与后台定时同步
+	Direction     string     `gorm:"type:text;not null;default:'both'" json:"direction"` // both/pull/push
+	Repo          string     `gorm:"type:text" json:"repo"`                              // 上次同步解析出的 owner/name
+	LastRunAt     *time.Time `gorm:"type:datetime" json:"lastRunAt"`
+	LastSuccessAt *time.Time `gorm:"type:datetime" json:"lastSuccessAt"`
+	LastError     string     `gorm:"type:text" json:"lastError"`
+	Pulled        int        `gorm:"type:integer;not null;default:0" json:"pulled"`    // 上次同步从远端写入的任务数
+	Pushed        int        `gorm:"type:integer;not null;default:0" json:"pushed"`    // 上次同步写入远端的 issue 数
+	Conflicts     int        `gorm:"type:integer;not null;default:0" json:"conflicts"` // 两端同时修改、按更新时间取舍的次数
+}
+
+// TableName maps the gorm model to the issue_syncs table.
+func (IssueSyncTable) TableName() string {
+	return "issue_syncs"
+}
+
+// TaskIssueTable links a task to an issue in the project's remote repository.
+type TaskIssueTable struct {
+	model_base.StringPKBaseModel
+
+	TaskID          string    `gorm:"type:text;not null;uniqueIndex" json:"taskId"`
+	ProjectID       string    `gorm:"type:text;not null;uniqueIndex:idx_task_issues_project_number" json:"projectId"`
+	IssueNumber     int       `gorm:"type:integer;not null;uniqueIndex:idx_task_issues_project_number" json:"issueNumber"`
+	IssueURL        string    `gorm:"type:text" json:"issueUrl"`
+	RemoteUpdatedAt time.Time `gorm:"type:datetime" json:"remoteUpdatedAt"` // 上次同步时远端 issue 的更新时间
+	SyncedAt        time.Time `gorm:"type:datetime" json:"syncedAt"`        // 上次同步完成时间，晚于此时间的本地修改视为待推送
+}
+
+// TableName maps the gorm model to the task_issues table.
+func (TaskIssueTable) TableName() string {
+	return "task_issues"
+}
+
+// TaskIssueCommentTable links a task comment to the remote issue comment it was synced with.
+type TaskIssueCommentTable struct {
+	model_base.StringPKBaseModel
+
+	CommentID string `gorm:"type:text;not null;uniqueIndex" json:"commentId"`
+	TaskID    string `gorm:"type:text;not null;index" json:"taskId"`
+	RemoteID  int64  `gorm:"type:integer;not null;index" json:"remoteId"`
+	Author    string `gorm:"type:text" json:"author"` // 远端评论作者
+}
+
+// TableName maps the gorm model to the task_issue_comments table.
+func (TaskIssueCommentTable) TableName() string {
+	return "task_issue_comments"
+}
@@ -0,0 +1,27 @@
+package tables
+
+import (
+	"time"
+
+	"code-kanban/utils/model_base"
+)
+
+// NotificationTable is an inbox entry for a user, such as being @mentioned in a comment.
+type NotificationTable struct {
+	model_base.StringPKBaseModel
+
+	UserID    string     `gorm:"type:text;not null;index:idx_notifications_user_read" json:"userId"`
+	ReadAt    *time.Time `gorm:"type:datetime;index:idx_notifications_user_read" json:"readAt"`
+	Type      string     `gorm:"type:text;not null" json:"type"` // mention
+	ProjectID string     `gorm:"type:text;index" json:"projectId"`
+	TaskID    string     `gorm:"type:text;index" json:"taskId"`
+	CommentID *string    `gorm:"type:text" json:"commentId"`
+	Actor     string     `gorm:"type:text;not null;default:''" json:"actor"`
+	Title     string     `gorm:"type:text" json:"title"`   // 任务标题
+	Excerpt   string     `gorm:"type:text" json:"excerpt"` // 评论内容摘要
+}
+
+// TableName maps the gorm model to the notifications table.
+func (NotificationTable) TableName() string {
+	return "notifications"
+}
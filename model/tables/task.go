@@ -53,20 +53,28 @@ func (a *StringArray) Scan(value any) error {
 type TaskTable struct {
 	model_base.StringPKBaseModel
 
-	ProjectID   string      `gorm:"type:text;not null;index" json:"projectId"`
-	WorktreeID  *string     `gorm:"type:text;index" json:"worktreeId"`
-	BranchName  string      `gorm:"type:text" json:"branchName"` // 存储关联的分支名称，即使worktree被删除也能显示
-	Title       string      `gorm:"type:text;not null" json:"title"`
-	Description string      `gorm:"type:text" json:"description"`
-	Status      string      `gorm:"type:text;not null;index" json:"status"` // todo/in_progress/done/archived
-	Priority    int         `gorm:"type:integer;default:0;index" json:"priority"`
-	OrderIndex  float64     `gorm:"type:real;not null;index" json:"orderIndex"`
-	Tags        StringArray `gorm:"type:text" json:"tags"`
-	DueDate     *time.Time  `gorm:"type:datetime" json:"dueDate"`
-	CompletedAt *time.Time  `gorm:"type:datetime" json:"completedAt"`
+	ProjectID       string      `gorm:"type:text;not null;index" json:"projectId"`
+	WorktreeID      *string     `gorm:"type:text;index" json:"worktreeId"`
+	BranchName      string      `gorm:"type:text" json:"branchName"` // 存储关联的分支名称，即使worktree被删除也能显示
+	Title           string      `gorm:"type:text;not null" json:"title"`
+	Description     string      `gorm:"type:text" json:"description"`
+	Status          string      `gorm:"type:text;not null;index" json:"status"` // 对应项目看板列（task_columns）的 key
+	Priority        int         `gorm:"type:integer;default:0;index" json:"priority"`
+	OrderIndex      float64     `gorm:"type:real;not null;index" json:"orderIndex"`
+	Tags            StringArray `gorm:"type:text" json:"tags"`
+	DueDate         *time.Time  `gorm:"type:datetime" json:"dueDate"`
+	CompletedAt     *time.Time  `gorm:"type:datetime" json:"completedAt"`
+	ExternalID      string      `gorm:"type:text;index" json:"externalId,omitempty"`   // 导入来源中的标识，用于幂等更新
+	RecurrenceID    *string     `gorm:"type:text;index" json:"recurrenceId,omitempty"` // 由周期任务规则生成时指向该规则
+	OverdueAt       *time.Time  `gorm:"type:datetime" json:"overdueAt,omitempty"`      // 调度器发现已逾期的时间，修改截止日期后清空
+	EstimateMinutes *int        `gorm:"type:integer" json:"estimateMinutes,omitempty"` // 预估工时（分钟）
+	EstimatePoints  *float64    `gorm:"type:real" json:"estimatePoints,omitempty"`     // 故事点
 
 	Project  *ProjectTable  `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"project,omitempty"`
 	Worktree *WorktreeTable `gorm:"foreignKey:WorktreeID;constraint:OnDelete:SET NULL" json:"worktree,omitempty"`
+
+	Links     []LinkedTask       `gorm:"-" json:"links,omitempty"`     // 仅在任务详情中填充
+	Checklist *ChecklistProgress `gorm:"-" json:"checklist,omitempty"` // 清单进度，无清单项时为空
 }
 
 // TableName maps the gorm model to the tasks table.
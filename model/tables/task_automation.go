@@ -0,0 +1,21 @@
+package tables
+
+import "code-kanban/utils/model_base"
+
+// TaskAutomationTable stores per-project rules that move tasks between columns automatically.
+type TaskAutomationTable struct {
+	model_base.StringPKBaseModel
+
+	ProjectID    string      `gorm:"type:text;not null;uniqueIndex:idx_task_automations_project_trigger,where:deleted_at IS NULL" json:"projectId"`
+	Trigger      string      `gorm:"type:text;not null;uniqueIndex:idx_task_automations_project_trigger,where:deleted_at IS NULL" json:"trigger"` // ai_started/branch_merged
+	FromStatuses StringArray `gorm:"type:text" json:"fromStatuses"`                                                                               // 为空表示任意状态
+	ToStatus     string      `gorm:"type:text;not null" json:"toStatus"`
+	Enabled      bool        `gorm:"type:boolean;not null;default:false" json:"enabled"`
+
+	Project *ProjectTable `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"project,omitempty"`
+}
+
+// TableName maps the gorm model to the task_automations table.
+func (TaskAutomationTable) TableName() string {
+	return "task_automations"
+}
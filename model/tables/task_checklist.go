@@ -0,0 +1,31 @@
+package tables
+
+import (
+	"time"
+
+	"code-kanban/utils/model_base"
+)
+
+// TaskChecklistItemTable stores an ordered checklist step belonging to a task.
+type TaskChecklistItemTable struct {
+	model_base.StringPKBaseModel
+
+	TaskID      string     `gorm:"type:text;not null;index" json:"taskId"`
+	Content     string     `gorm:"type:text;not null" json:"content"`
+	Done        bool       `gorm:"not null;default:false" json:"done"`
+	OrderIndex  float64    `gorm:"type:real;not null;index" json:"orderIndex"`
+	CompletedAt *time.Time `gorm:"type:datetime" json:"completedAt"`
+
+	Task *TaskTable `gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName maps the gorm model to the task_checklist_items table.
+func (TaskChecklistItemTable) TableName() string {
+	return "task_checklist_items"
+}
+
+// ChecklistProgress summarizes how many checklist items of a task are done.
+type ChecklistProgress struct {
+	Total int `json:"total"`
+	Done  int `json:"done"`
+}
@@ -0,0 +1,24 @@
+package tables
+
+import "code-kanban/utils/model_base"
+
+// TaskColumnTable stores the kanban columns (workflow states) configured for a project.
+type TaskColumnTable struct {
+	model_base.StringPKBaseModel
+
+	ProjectID          string      `gorm:"type:text;not null;uniqueIndex:idx_task_columns_project_key,where:deleted_at IS NULL" json:"projectId"`
+	Key                string      `gorm:"type:text;not null;uniqueIndex:idx_task_columns_project_key,where:deleted_at IS NULL" json:"key"` // 写入 tasks.status 的值
+	Name               string      `gorm:"type:text;not null" json:"name"`
+	Color              string      `gorm:"type:text" json:"color"`
+	Category           string      `gorm:"type:text;not null" json:"category"` // todo/in_progress/done/archived，决定列的语义
+	OrderIndex         float64     `gorm:"type:real;not null;index" json:"orderIndex"`
+	WIPLimit           int         `gorm:"column:wip_limit;type:integer;not null;default:0" json:"wipLimit"` // 0 表示不限制
+	AllowedTransitions StringArray `gorm:"type:text" json:"allowedTransitions"`                              // 可流转到的列 key，为空表示不限制
+
+	Project *ProjectTable `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"project,omitempty"`
+}
+
+// TableName maps the gorm model to the task_columns table.
+func (TaskColumnTable) TableName() string {
+	return "task_columns"
+}
@@ -1,13 +1,24 @@
 package tables
 
-import "code-kanban/utils/model_base"
+import (
+	"time"
+
+	"code-kanban/utils/model_base"
+)
 
 // TaskCommentTable stores comment threads associated with a task.
 type TaskCommentTable struct {
 	model_base.StringPKBaseModel
 
-	TaskID  string `gorm:"type:text;not null;index" json:"taskId"`
-	Content string `gorm:"type:text;not null" json:"content"`
+	TaskID        string     `gorm:"type:text;not null;index" json:"taskId"`
+	ParentID      *string    `gorm:"type:text;index" json:"parentId"` // 回复的根评论，为空表示顶层评论
+	Author        string     `gorm:"type:text;not null;default:''" json:"author"`
+	Kind          string     `gorm:"type:text;not null;default:'text'" json:"kind"` // text/terminal_output/diff
+	Content       string     `gorm:"type:text;not null" json:"content"`
+	Excerpt       string     `gorm:"type:text" json:"excerpt,omitempty"`       // 结构化评论附带的终端输出或 diff 原文
+	ExcerptSource string     `gorm:"type:text" json:"excerptSource,omitempty"` // 如终端会话 ID、命令或 diff 的比较范围
+	EditedAt      *time.Time `gorm:"type:datetime" json:"editedAt"`
+	EditCount     int        `gorm:"type:integer;not null;default:0" json:"editCount"`
 
 	Task *TaskTable `gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE" json:"task,omitempty"`
 }
@@ -16,3 +27,18 @@ type TaskCommentTable struct {
 func (TaskCommentTable) TableName() string {
 	return "task_comments"
 }
+
+// TaskCommentRevisionTable keeps the previous content of a comment each time it is edited.
+type TaskCommentRevisionTable struct {
+	model_base.StringPKBaseModel
+
+	CommentID string `gorm:"type:text;not null;index" json:"commentId"`
+	Content   string `gorm:"type:text;not null" json:"content"`
+	Excerpt   string `gorm:"type:text" json:"excerpt,omitempty"`
+	Editor    string `gorm:"type:text;not null;default:''" json:"editor"`
+}
+
+// TableName maps the gorm model to the task_comment_revisions table.
+func (TaskCommentRevisionTable) TableName() string {
+	return "task_comment_revisions"
+}
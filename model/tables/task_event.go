@@ -0,0 +1,24 @@
+package tables
+
+import "code-kanban/utils/model_base"
+
+// TaskEventTable is an append-only record of a change made to a task.
+type TaskEventTable struct {
+	model_base.StringPKBaseModel
+
+	TaskID     string  `gorm:"type:text;not null;index" json:"taskId"`
+	ProjectID  string  `gorm:"type:text;not null;index" json:"projectId"`
+	Type       string  `gorm:"type:text;not null;index" json:"type"` // created/field_changed/status_changed/worktree_bound/worktree_unbound/comment_added/comment_edited/comment_deleted/deleted/overdue
+	Field      string  `gorm:"type:text" json:"field"`
+	OldValue   string  `gorm:"type:text" json:"oldValue"`
+	NewValue   string  `gorm:"type:text" json:"newValue"`
+	Actor      string  `gorm:"type:text;not null;default:''" json:"actor"` // 如 user、user:<id>、automation:branch_merged、agent:claude-code
+	BranchName string  `gorm:"type:text" json:"branchName"`                // 事件发生时任务关联的分支
+	WorktreeID *string `gorm:"type:text" json:"worktreeId"`                // 事件发生时任务关联的 Worktree
+	CommentID  *string `gorm:"type:text" json:"commentId,omitempty"`       // 评论相关事件对应的评论
+}
+
+// TableName maps the gorm model to the task_events table.
+func (TaskEventTable) TableName() string {
+	return "task_events"
+}
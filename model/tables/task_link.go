@@ -0,0 +1,33 @@
+package tables
+
+import "code-kanban/utils/model_base"
+
+// TaskLinkTable stores a directed relation between two tasks, e.g. source blocks target.
+type TaskLinkTable struct {
+	model_base.StringPKBaseModel
+
+	SourceTaskID string `gorm:"type:text;not null;index;uniqueIndex:idx_task_links_unique,where:deleted_at IS NULL" json:"sourceTaskId"`
+	TargetTaskID string `gorm:"type:text;not null;index;uniqueIndex:idx_task_links_unique,where:deleted_at IS NULL" json:"targetTaskId"`
+	Type         string `gorm:"type:text;not null;uniqueIndex:idx_task_links_unique,where:deleted_at IS NULL" json:"type"` // blocks/relates_to/duplicates
+
+	SourceTask *TaskTable `gorm:"foreignKey:SourceTaskID;constraint:OnDelete:CASCADE" json:"-"`
+	TargetTask *TaskTable `gorm:"foreignKey:TargetTaskID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName maps the gorm model to the task_links table.
+func (TaskLinkTable) TableName() string {
+	return "task_links"
+}
+
+// LinkedTask describes a task related to the current one, as returned with task details.
+type LinkedTask struct {
+	LinkID    string `json:"linkId"`
+	Type      string `json:"type"`
+	Direction string `json:"direction"` // outgoing: 当前任务为 source；incoming: 当前任务为 target
+	TaskID    string `json:"taskId"`
+	ProjectID string `json:"projectId"`
+	Title     string `json:"title"`
+	Status    string `json:"status"`
+	Branch    string `json:"branchName"`
+	Resolved  bool   `json:"resolved"` // 关联任务是否已处于完成/归档列
+}
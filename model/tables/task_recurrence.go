@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"time"
+
+	"code-kanban/utils/model_base"
+)
+
+// TaskRecurrenceTable describes a task template that is instantiated on a schedule.
+type TaskRecurrenceTable struct {
+	model_base.StringPKBaseModel
+
+	ProjectID       string      `gorm:"type:text;not null;index" json:"projectId"`
+	Title           string      `gorm:"type:text;not null" json:"title"`
+	Description     string      `gorm:"type:text" json:"description"`
+	Status          string      `gorm:"type:text" json:"status"` // 生成任务所在列，留空时使用默认列
+	Priority        int         `gorm:"type:integer;default:0" json:"priority"`
+	Tags            StringArray `gorm:"type:text" json:"tags"`
+	Rule            string      `gorm:"type:text;not null" json:"rule"`                        // RRULE 子集，如 FREQ=WEEKLY;BYDAY=MO;BYHOUR=9
+	StartAt         time.Time   `gorm:"type:datetime;not null" json:"startAt"`                 // 相当于 DTSTART
+	DueAfterMinutes *int        `gorm:"type:integer" json:"dueAfterMinutes"`                   // 生成任务的截止时间相对触发时间的偏移，为空则不设置
+	SkipIfOpen      bool        `gorm:"type:boolean;not null;default:false" json:"skipIfOpen"` // 上一次生成的任务未完成时跳过本次
+	Enabled         bool        `gorm:"type:boolean;not null;default:true" json:"enabled"`
+	NextRunAt       *time.Time  `gorm:"type:datetime;index" json:"nextRunAt"` // 为空表示规则已结束
+	LastRunAt       *time.Time  `gorm:"type:datetime" json:"lastRunAt"`
+	LastTaskID      *string     `gorm:"type:text" json:"lastTaskId"`
+	SpawnedCount    int         `gorm:"type:integer;not null;default:0" json:"spawnedCount"`
+}
+
+// TableName maps the gorm model to the task_recurrences table.
+func (TaskRecurrenceTable) TableName() string {
+	return "task_recurrences"
+}
@@ -0,0 +1,30 @@
+package tables
+
+import (
+	"time"
+
+	"code-kanban/utils/model_base"
+)
+
+// TaskTimeEntryTable records time spent on a task, either logged by hand, measured
+// with a timer, or inferred from an AI assistant running in the task's worktree.
+type TaskTimeEntryTable struct {
+	model_base.StringPKBaseModel
+
+	TaskID          string     `gorm:"type:text;not null;index" json:"taskId"`
+	ProjectID       string     `gorm:"type:text;not null;index" json:"projectId"`
+	Source          string     `gorm:"type:text;not null;index" json:"source"` // manual/timer/assistant
+	Actor           string     `gorm:"type:text;not null;default:''" json:"actor"`
+	SessionID       string     `gorm:"type:text;index" json:"sessionId,omitempty"` // 自动记录对应的终端会话
+	Assistant       string     `gorm:"type:text" json:"assistant,omitempty"`       // 自动记录对应的 AI 助手
+	StartedAt       time.Time  `gorm:"type:datetime;not null;index" json:"startedAt"`
+	EndedAt         *time.Time `gorm:"type:datetime" json:"endedAt"` // 为空表示仍在计时
+	LastSeenAt      *time.Time `gorm:"type:datetime" json:"-"`       // 自动记录的心跳，服务重启后据此补齐结束时间
+	DurationSeconds int64      `gorm:"type:integer;not null;default:0" json:"durationSeconds"`
+	Note            string     `gorm:"type:text" json:"note"`
+}
+
+// TableName maps the gorm model to the task_time_entries table.
+func (TaskTimeEntryTable) TableName() string {
+	return "task_time_entries"
+}
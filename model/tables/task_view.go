@@ -0,0 +1,20 @@
+package tables
+
+import "code-kanban/utils/model_base"
+
+// TaskViewTable stores a named board filter that a user can recall with one call.
+type TaskViewTable struct {
+	model_base.StringPKBaseModel
+
+	UserID     string      `gorm:"type:text;not null;default:'';uniqueIndex:idx_task_views_user_name,where:deleted_at IS NULL" json:"userId"` // 为空表示本机默认用户
+	Name       string      `gorm:"type:text;not null;uniqueIndex:idx_task_views_user_name,where:deleted_at IS NULL" json:"name"`
+	ProjectIDs StringArray `gorm:"type:text" json:"projectIds"` // 为空表示全部项目
+	Query      string      `gorm:"type:text" json:"query"`      // 检索语法，如 tag:backend status:in_progress
+	Sort       string      `gorm:"type:text;not null;default:'order'" json:"sort"`
+	GroupBy    string      `gorm:"type:text;not null;default:'project'" json:"groupBy"`
+}
+
+// TableName maps the gorm model to the task_views table.
+func (TaskViewTable) TableName() string {
+	return "task_views"
+}
@@ -10,12 +10,14 @@ import (
 type WorktreeTable struct {
 	model_base.StringPKBaseModel
 
-	ProjectID  string `gorm:"type:text;not null;index" json:"projectId"`
-	BranchName string `gorm:"type:text;not null;index" json:"branchName"`
-	Path       string `gorm:"type:text;not null;uniqueIndex:idx_worktrees_path,where:deleted_at IS NULL" json:"path"`
-	IsMain        bool       `gorm:"type:boolean;default:false" json:"isMain"`
-	IsBare        bool       `gorm:"type:boolean;default:false" json:"isBare"`
-	HeadCommit    string     `gorm:"type:text" json:"headCommit"`
+	ProjectID      string     `gorm:"type:text;not null;index" json:"projectId"`
+	BranchName     string     `gorm:"type:text;not null;index" json:"branchName"`
+	Path           string     `gorm:"type:text;not null;uniqueIndex:idx_worktrees_path,where:deleted_at IS NULL" json:"path"`
+	IsMain         bool       `gorm:"type:boolean;default:false" json:"isMain"`
+	IsBare         bool       `gorm:"type:boolean;default:false" json:"isBare"`
+	IsDetached     bool       `gorm:"type:boolean;default:false" json:"isDetached"` // HEAD 未指向分支，例如基于标签或提交创建
+	SourceRef      string     `gorm:"type:text" json:"sourceRef"`                   // 创建时使用的来源，例如 origin/feature、v1.2.0、refs/pull/42/head
+	HeadCommit     string     `gorm:"type:text" json:"headCommit"`
 	HeadCommitDate *time.Time `gorm:"type:datetime" json:"headCommitDate"`
 
 	StatusAhead     int        `gorm:"type:integer;default:0" json:"statusAhead"`
@@ -0,0 +1,23 @@
+package tables
+
+import "code-kanban/utils/model_base"
+
+// WorktreeHookTable stores the worktree lifecycle hooks of a project. Projects without
+// a row fall back to the hook file committed in the repository.
+type WorktreeHookTable struct {
+	model_base.StringPKBaseModel
+
+	ProjectID  string      `gorm:"type:text;not null;uniqueIndex" json:"projectId"`
+	PostCreate StringArray `gorm:"type:text" json:"postCreate"` // 创建后执行的命令
+	Copy       StringArray `gorm:"type:text" json:"copy"`       // 从主 worktree 复制的文件或 glob
+	Symlink    StringArray `gorm:"type:text" json:"symlink"`    // 链接到主 worktree 的目录
+	PreDelete  StringArray `gorm:"type:text" json:"preDelete"`  // 删除前执行的命令
+	Timeout    string      `gorm:"type:text" json:"timeout"`    // 单条命令的超时，留空为 10m
+
+	Project *ProjectTable `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"project,omitempty"`
+}
+
+// TableName maps the gorm model to the worktree_hooks table.
+func (WorktreeHookTable) TableName() string {
+	return "worktree_hooks"
+}
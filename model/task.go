@@ -19,26 +19,24 @@ var (
 	ErrInvalidTaskStatus = errors.New("invalid task status")
 )
 
-var taskStatusSet = map[string]struct{}{
-	"todo":        {},
-	"in_progress": {},
-	"done":        {},
-	"archived":    {},
-}
-
 // TaskService coordinates CRUD operations for kanban tasks.
 type TaskService struct{}
 
 // CreateTaskRequest captures inputs required to create a task.
 type CreateTaskRequest struct {
-	ProjectID   string
-	WorktreeID  *string
-	Title       string
-	Description string
-	Status      string
-	Priority    int
-	Tags        tables.StringArray
-	DueDate     *time.Time
+	ProjectID    string
+	WorktreeID   *string
+	Title        string
+	Description  string
+	Status       string
+	Priority     int
+	Tags         tables.StringArray
+	DueDate      *time.Time
+	ExternalID   string
+	RecurrenceID *string
+
+	EstimateMinutes *int
+	EstimatePoints  *float64
 }
 
 // ListTasksRequest configures list filtering and pagination.
@@ -57,6 +55,10 @@ type MoveTaskRequest struct {
 	Status     string
 	OrderIndex *float64
 	WorktreeID *string
+	// EnforceBlockers refuses moves into in-progress or done columns while blocking tasks are unfinished.
+	EnforceBlockers bool
+	// MarkCompleted stamps completed_at in the same update when the task lands in a done column.
+	MarkCompleted bool
 }
 
 // CreateTask inserts a new task row after validating related entities.
@@ -79,11 +81,6 @@ func (s *TaskService) CreateTask(ctx context.Context, req *CreateTaskRequest) (*
 		return nil, fmt.Errorf("task title is required")
 	}
 
-	status, err := normalizeTaskStatus(req.Status)
-	if err != nil {
-		return nil, err
-	}
-
 	if req.Priority < 0 {
 		req.Priority = 0
 	}
@@ -96,6 +93,18 @@ func (s *TaskService) CreateTask(ctx context.Context, req *CreateTaskRequest) (*
 		return nil, err
 	}
 
+	workflow, err := loadTaskWorkflow(dbCtx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	status, err := normalizeTaskStatus(workflow, req.Status)
+	if err != nil {
+		return nil, err
+	}
+	if err := workflow.ensureCapacity(dbCtx, status, ""); err != nil {
+		return nil, err
+	}
+
 	var worktreeID *string
 	var branchName string
 	if req.WorktreeID != nil && strings.TrimSpace(*req.WorktreeID) != "" {
@@ -113,19 +122,33 @@ func (s *TaskService) CreateTask(ctx context.Context, req *CreateTaskRequest) (*
 	}
 
 	task := &tables.TaskTable{
-		ProjectID:   projectID,
-		WorktreeID:  worktreeID,
-		BranchName:  branchName,
-		Title:       title,
-		Description: strings.TrimSpace(req.Description),
-		Status:      status,
-		Priority:    req.Priority,
-		OrderIndex:  orderIndex,
-		Tags:        sanitizeTags(req.Tags),
-		DueDate:     req.DueDate,
-	}
-
-	if err := dbCtx.Create(task).Error; err != nil {
+		ProjectID:    projectID,
+		WorktreeID:   worktreeID,
+		BranchName:   branchName,
+		Title:        title,
+		Description:  strings.TrimSpace(req.Description),
+		Status:       status,
+		Priority:     req.Priority,
+		OrderIndex:   orderIndex,
+		Tags:         sanitizeTags(req.Tags),
+		DueDate:      req.DueDate,
+		ExternalID:   strings.TrimSpace(req.ExternalID),
+		RecurrenceID: req.RecurrenceID,
+
+		EstimateMinutes: req.EstimateMinutes,
+		EstimatePoints:  req.EstimatePoints,
+	}
+
+	err = dbCtx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		event := newTaskEvent(ctx, task, TaskEventCreated)
+		event.Field = "status"
+		event.NewValue = task.Status
+		return recordTaskEvents(tx, []tables.TaskEventTable{event})
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -162,8 +185,13 @@ func (s *TaskService) ListTasks(ctx context.Context, req *ListTasksRequest) ([]t
 		query = query.Where("priority = ?", *req.Priority)
 	}
 	if keyword := strings.TrimSpace(req.Keyword); keyword != "" {
-		like := "%" + keyword + "%"
-		query = query.Where("title LIKE ? OR description LIKE ?", like, like)
+		// 关键字支持完整检索语法，文本部分走全文索引；无法解析时按字面匹配
+		if parsed, err := ParseTaskQuery(keyword); err == nil {
+			query = applyTaskQuery(query, parsed)
+		} else {
+			like := "%" + keyword + "%"
+			query = query.Where("title LIKE ? OR description LIKE ?", like, like)
+		}
 	}
 
 	var total int64
@@ -194,6 +222,9 @@ func (s *TaskService) ListTasks(ctx context.Context, req *ListTasksRequest) ([]t
 		Find(&tasks).Error; err != nil {
 		return nil, 0, err
 	}
+	if err := attachChecklistProgress(dbCtx, tasks); err != nil {
+		return nil, 0, err
+	}
 
 	req.Page = page
 	req.PageSize = pageSize
@@ -214,6 +245,18 @@ func (s *TaskService) GetTask(ctx context.Context, id string) (*tables.TaskTable
 		}
 		return nil, err
 	}
+
+	links, err := listLinkedTasks(dbCtx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	task.Links = links
+
+	single := []tables.TaskTable{task}
+	if err := attachChecklistProgress(dbCtx, single); err != nil {
+		return nil, err
+	}
+	task.Checklist = single[0].Checklist
 	return &task, nil
 }
 
@@ -232,23 +275,34 @@ func (s *TaskService) UpdateTask(ctx context.Context, id string, updates map[str
 		return nil, err
 	}
 
-	if value, ok := updates["worktree_id"]; ok {
-		if err := dbCtx.
-			Model(&tables.TaskTable{}).
-			Where("id = ?", id).
-			Update("worktree_id", value).Error; err != nil {
-			return nil, err
-		}
-		delete(updates, "worktree_id")
+	err = dbCtx.Transaction(func(tx *gorm.DB) error {
+		return applyTaskUpdates(ctx, tx, task, updates)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if len(updates) > 0 {
-		if err := dbCtx.Model(task).Updates(updates).Error; err != nil {
-			return nil, err
+	return s.GetTask(ctx, id)
+}
+
+// applyTaskUpdates writes the updates and the matching history events within tx.
+func applyTaskUpdates(ctx context.Context, tx *gorm.DB, task *tables.TaskTable, updates map[string]interface{}) error {
+	if _, ok := updates["due_date"]; ok {
+		if _, explicit := updates["overdue_at"]; !explicit {
+			// 截止日期变更后重新由调度器判断是否逾期
+			updates["overdue_at"] = nil
 		}
 	}
 
-	return s.GetTask(ctx, id)
+	events := diffTaskEvents(ctx, task, updates)
+	// 使用空模型更新，避免预加载的 Worktree 关联把 worktree_id 写回
+	if err := tx.
+		Model(&tables.TaskTable{}).
+		Where("id = ?", task.ID).
+		Updates(updates).Error; err != nil {
+		return err
+	}
+	return recordTaskEvents(tx, events)
 }
 
 // DeleteTask removes a task softly.
@@ -258,14 +312,33 @@ func (s *TaskService) DeleteTask(ctx context.Context, id string) error {
 		return err
 	}
 
-	result := dbCtx.Delete(&tables.TaskTable{}, "id = ?", id)
-	if result.Error != nil {
-		return result.Error
+	task, err := s.GetTask(ctx, id)
+	if err != nil {
+		return err
 	}
-	if result.RowsAffected == 0 {
-		return ErrTaskNotFound
+	return dbCtx.Transaction(func(tx *gorm.DB) error {
+		return deleteTaskRecords(ctx, tx, task)
+	})
+}
+
+// deleteTaskRecords soft-deletes the task together with its links and checklist.
+// Callers run it inside a transaction.
+func deleteTaskRecords(ctx context.Context, dbCtx *gorm.DB, task *tables.TaskTable) error {
+	if err := dbCtx.Delete(&tables.TaskTable{}, "id = ?", task.ID).Error; err != nil {
+		return err
 	}
-	return nil
+	if err := recordTaskEvents(dbCtx, []tables.TaskEventTable{newTaskEvent(ctx, task, TaskEventDeleted)}); err != nil {
+		return err
+	}
+
+	if err := dbCtx.
+		Where("source_task_id = ? OR target_task_id = ?", task.ID, task.ID).
+		Delete(&tables.TaskLinkTable{}).Error; err != nil {
+		return err
+	}
+	return dbCtx.
+		Where("task_id = ?", task.ID).
+		Delete(&tables.TaskChecklistItemTable{}).Error
 }
 
 // MoveTask updates the task status/order/worktree when dragged.
@@ -284,13 +357,42 @@ func (s *TaskService) MoveTask(ctx context.Context, id string, req *MoveTaskRequ
 		return nil, err
 	}
 
+	workflow, err := loadTaskWorkflow(dbCtx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
 	updates := map[string]interface{}{}
 
 	if status := strings.TrimSpace(req.Status); status != "" && status != task.Status {
-		if _, err := normalizeTaskStatus(status); err != nil {
+		if _, err := normalizeTaskStatus(workflow, status); err != nil {
+			return nil, err
+		}
+		if !workflow.canTransition(task.Status, status) {
+			return nil, fmt.Errorf("%w: %s -> %s", ErrTaskTransitionNotAllowed, task.Status, status)
+		}
+		if err := workflow.ensureCapacity(dbCtx, status, task.ID); err != nil {
 			return nil, err
 		}
+		if req.EnforceBlockers {
+			if category := workflow.category(status); category == TaskCategoryInProgress || category == TaskCategoryDone {
+				blockers, err := openBlockers(dbCtx, task.ID)
+				if err != nil {
+					return nil, err
+				}
+				if len(blockers) > 0 {
+					titles := make([]string, 0, len(blockers))
+					for _, blocker := range blockers {
+						titles = append(titles, blocker.Title)
+					}
+					return nil, fmt.Errorf("%w: %s", ErrTaskBlocked, strings.Join(titles, ", "))
+				}
+			}
+		}
 		updates["status"] = status
+		if req.MarkCompleted && workflow.category(status) == TaskCategoryDone {
+			updates["completed_at"] = time.Now()
+		}
 		task.Status = status
 	}
 
@@ -334,7 +436,7 @@ func (s *TaskService) MoveTask(ctx context.Context, id string, req *MoveTaskRequ
 	}
 
 	newStatus := task.Status
-	if originalStatus == "done" && newStatus != "" && newStatus != "done" {
+	if workflow.category(originalStatus) == TaskCategoryDone && newStatus != "" && workflow.category(newStatus) != TaskCategoryDone {
 		if task.WorktreeID != nil && task.BranchName != "" {
 			// 尝试获取worktree，如果不存在则清除绑定
 			_, err := s.getWorktreeWithBranch(dbCtx, *task.WorktreeID, task.ProjectID)
@@ -392,12 +494,13 @@ func ensureContext(ctx context.Context) context.Context {
 	return ctx
 }
 
-func normalizeTaskStatus(status string) (string, error) {
+// normalizeTaskStatus validates the status against the project workflow, defaulting to its first todo column.
+func normalizeTaskStatus(workflow *taskWorkflow, status string) (string, error) {
 	st := strings.TrimSpace(status)
 	if st == "" {
-		st = "todo"
+		st = workflow.defaultStatus()
 	}
-	if _, ok := taskStatusSet[st]; !ok {
+	if workflow.column(st) == nil {
 		return "", ErrInvalidTaskStatus
 	}
 	return st, nil
@@ -12,9 +12,16 @@ import (
 const taskCountByWorktree = `-- name: TaskCountByWorktree :one
 SELECT COUNT(1) AS count
 FROM tasks
-WHERE worktree_id = ?1
-  AND deleted_at IS NULL
-  AND status IN ('todo', 'in_progress')
+WHERE tasks.worktree_id = ?1
+  AND tasks.deleted_at IS NULL
+  AND tasks.status NOT IN ('done', 'archived')
+  AND tasks.status NOT IN (
+    SELECT task_columns.key
+    FROM task_columns
+    WHERE task_columns.project_id = tasks.project_id
+      AND task_columns.deleted_at IS NULL
+      AND task_columns.category IN ('done', 'archived')
+  )
 `
 
 func (q *Queries) TaskCountByWorktree(ctx context.Context, worktreeID *string) (int64, error) {
@@ -0,0 +1,339 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"code-kanban/model/tables"
+	"code-kanban/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// TaskTriggerAIStarted fires when an AI assistant starts in a terminal bound to the task's worktree.
+	TaskTriggerAIStarted = "ai_started"
+	// TaskTriggerBranchMerged fires when the task's branch is merged through BranchService.MergeBranch.
+	TaskTriggerBranchMerged = "branch_merged"
+)
+
+var (
+	// ErrInvalidAutomationTrigger indicates the automation trigger is not supported.
+	ErrInvalidAutomationTrigger = errors.New("invalid automation trigger")
+)
+
+// taskAutomationDefault describes a built-in rule by column category so it fits any project workflow.
+type taskAutomationDefault struct {
+	fromCategories []string
+	toCategory     string
+}
+
+// defaultTaskAutomations are applied for triggers a project has not configured yet.
+var defaultTaskAutomations = map[string]taskAutomationDefault{
+	TaskTriggerAIStarted: {
+		fromCategories: []string{TaskCategoryTodo},
+		toCategory:     TaskCategoryInProgress,
+	},
+	TaskTriggerBranchMerged: {
+		fromCategories: []string{TaskCategoryTodo, TaskCategoryInProgress},
+		toCategory:     TaskCategoryDone,
+	},
+}
+
+var taskAutomationTriggers = []string{TaskTriggerAIStarted, TaskTriggerBranchMerged}
+
+// TaskAutomationService moves tasks automatically in reaction to AI and git activity.
+type TaskAutomationService struct {
+	taskSvc    *TaskService
+	commentSvc *TaskCommentService
+}
+
+// UpdateTaskAutomationRequest captures inputs for configuring an automation rule.
+type UpdateTaskAutomationRequest struct {
+	ProjectID    string
+	Trigger      string
+	FromStatuses tables.StringArray
+	ToStatus     string
+	Enabled      bool
+}
+
+// NewTaskAutomationService constructs an automation service with task and comment dependencies.
+func NewTaskAutomationService() *TaskAutomationService {
+	return &TaskAutomationService{
+		taskSvc:    &TaskService{},
+		commentSvc: NewTaskCommentService(),
+	}
+}
+
+// ListRules returns the effective rules of a project, filling in defaults for unconfigured triggers.
+func (s *TaskAutomationService) ListRules(ctx context.Context, projectID string) ([]tables.TaskAutomationTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	projectID = strings.TrimSpace(projectID)
+	if projectID == "" {
+		return nil, fmt.Errorf("project id is required")
+	}
+
+	var stored []tables.TaskAutomationTable
+	if err := dbCtx.Where("project_id = ?", projectID).Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	workflow, err := loadTaskWorkflow(dbCtx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	byTrigger := make(map[string]tables.TaskAutomationTable, len(stored))
+	for _, rule := range stored {
+		byTrigger[rule.Trigger] = rule
+	}
+
+	rules := make([]tables.TaskAutomationTable, 0, len(taskAutomationTriggers))
+	for _, trigger := range taskAutomationTriggers {
+		if rule, ok := byTrigger[trigger]; ok {
+			rules = append(rules, rule)
+			continue
+		}
+		rules = append(rules, defaultTaskAutomations[trigger].resolve(workflow, trigger))
+	}
+	return rules, nil
+}
+
+// resolve maps the default rule onto the project's columns. Projects without a column of the
+// target category get a disabled rule.
+func (d taskAutomationDefault) resolve(workflow *taskWorkflow, trigger string) tables.TaskAutomationTable {
+	rule := tables.TaskAutomationTable{
+		ProjectID:    workflow.projectID,
+		Trigger:      trigger,
+		FromStatuses: tables.StringArray{},
+		ToStatus:     firstColumnOfCategory(workflow, d.toCategory, ""),
+	}
+	for _, column := range workflow.columns {
+		for _, category := range d.fromCategories {
+			if column.Category == category {
+				rule.FromStatuses = append(rule.FromStatuses, column.Key)
+			}
+		}
+	}
+	rule.Enabled = rule.ToStatus != "" && len(rule.FromStatuses) > 0
+	return rule
+}
+
+// UpsertRule creates or replaces the rule configured for a project trigger.
+func (s *TaskAutomationService) UpsertRule(ctx context.Context, req *UpdateTaskAutomationRequest) (*tables.TaskAutomationTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+
+	projectID := strings.TrimSpace(req.ProjectID)
+	if projectID == "" {
+		return nil, fmt.Errorf("project id is required")
+	}
+	trigger := strings.TrimSpace(req.Trigger)
+	if _, ok := defaultTaskAutomations[trigger]; !ok {
+		return nil, ErrInvalidAutomationTrigger
+	}
+
+	workflow, err := loadTaskWorkflow(dbCtx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.ToStatus) == "" {
+		return nil, ErrInvalidTaskStatus
+	}
+	toStatus, err := normalizeTaskStatus(workflow, req.ToStatus)
+	if err != nil {
+		return nil, err
+	}
+	fromStatuses := sanitizeTags(req.FromStatuses)
+	for _, status := range fromStatuses {
+		if _, err := normalizeTaskStatus(workflow, status); err != nil {
+			return nil, err
+		}
+	}
+
+	var rule tables.TaskAutomationTable
+	err = dbCtx.Where("project_id = ? AND trigger = ?", projectID, trigger).First(&rule).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		rule = tables.TaskAutomationTable{
+			ProjectID:    projectID,
+			Trigger:      trigger,
+			FromStatuses: fromStatuses,
+			ToStatus:     toStatus,
+			Enabled:      req.Enabled,
+		}
+		if err := dbCtx.Create(&rule).Error; err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if err := dbCtx.Model(&rule).Updates(map[string]interface{}{
+			"from_statuses": fromStatuses,
+			"to_status":     toStatus,
+			"enabled":       req.Enabled,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := dbCtx.First(&rule, "id = ?", rule.ID).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// HandleAssistantStarted moves tasks bound to the worktree when an AI assistant starts in one of its terminals.
+func (s *TaskAutomationService) HandleAssistantStarted(ctx context.Context, worktreeID, assistantName string) ([]tables.TaskTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	worktreeID = strings.TrimSpace(worktreeID)
+	if worktreeID == "" {
+		return nil, nil
+	}
+
+	var worktree tables.WorktreeTable
+	if err := dbCtx.First(&worktree, "id = ?", worktreeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorktreeNotFound
+		}
+		return nil, err
+	}
+
+	var tasks []tables.TaskTable
+	if err := dbCtx.
+		Where("project_id = ? AND worktree_id = ?", worktree.ProjectID, worktree.ID).
+		Order("order_index ASC").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(assistantName)
+	if name == "" {
+		name = "AI assistant"
+	}
+	reason := fmt.Sprintf("%s started in worktree %s", name, worktree.BranchName)
+	return s.apply(ctx, worktree.ProjectID, TaskTriggerAIStarted, tasks, reason)
+}
+
+// HandleBranchMerged moves tasks associated with a feature branch once it has been merged into the
+// integration branch. Callers decide which merges count; merging a branch into itself is ignored.
+func (s *TaskAutomationService) HandleBranchMerged(ctx context.Context, projectID, sourceBranch, targetBranch string) ([]tables.TaskTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	source := strings.TrimSpace(sourceBranch)
+	if strings.TrimSpace(projectID) == "" || source == "" || source == strings.TrimSpace(targetBranch) {
+		return nil, nil
+	}
+
+	var tasks []tables.TaskTable
+	if err := dbCtx.
+		Where("project_id = ? AND branch_name = ?", projectID, source).
+		Order("order_index ASC").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	reason := fmt.Sprintf("branch %s was merged into %s", source, strings.TrimSpace(targetBranch))
+	return s.apply(ctx, projectID, TaskTriggerBranchMerged, tasks, reason)
+}
+
+func (s *TaskAutomationService) apply(ctx context.Context, projectID, trigger string, tasks []tables.TaskTable, reason string) ([]tables.TaskTable, error) {
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+
+	rule, err := s.ruleFor(ctx, projectID, trigger)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil || !rule.Enabled {
+		return nil, nil
+	}
+	ctx = WithActor(ctx, "automation:"+trigger)
+
+	logger := utils.Logger().Named("task-automation")
+	moved := make([]tables.TaskTable, 0, len(tasks))
+	for _, task := range tasks {
+		if task.Status == rule.ToStatus || !automationMatchesStatus(rule, task.Status) {
+			continue
+		}
+
+		updated, err := s.moveTask(ctx, &task, rule.ToStatus)
+		if err != nil {
+			logger.Warn("automatic task move failed",
+				zap.Error(err),
+				zap.String("taskId", task.ID),
+				zap.String("trigger", trigger),
+			)
+			continue
+		}
+
+		content := fmt.Sprintf("Automatically moved from %s to %s: %s.", task.Status, rule.ToStatus, reason)
+		if _, err := s.commentSvc.CreateComment(ctx, task.ID, content); err != nil {
+			logger.Warn("failed to record automation comment",
+				zap.Error(err),
+				zap.String("taskId", task.ID),
+			)
+		}
+
+		logger.Info("task moved automatically",
+			zap.String("taskId", task.ID),
+			zap.String("trigger", trigger),
+			zap.String("from", task.Status),
+			zap.String("to", rule.ToStatus),
+		)
+		moved = append(moved, *updated)
+	}
+	return moved, nil
+}
+
+func (s *TaskAutomationService) moveTask(ctx context.Context, task *tables.TaskTable, status string) (*tables.TaskTable, error) {
+	return s.taskSvc.MoveTask(ctx, task.ID, &MoveTaskRequest{Status: status, MarkCompleted: true})
+}
+
+func (s *TaskAutomationService) ruleFor(ctx context.Context, projectID, trigger string) (*tables.TaskAutomationTable, error) {
+	rules, err := s.ListRules(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		if rules[i].Trigger == trigger {
+			return &rules[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *TaskAutomationService) dbWithContext(ctx context.Context) (*gorm.DB, error) {
+	if db == nil {
+		return nil, ErrDBNotInitialized
+	}
+	return db.WithContext(ensureContext(ctx)), nil
+}
+
+func automationMatchesStatus(rule *tables.TaskAutomationTable, status string) bool {
+	if len(rule.FromStatuses) == 0 {
+		return true
+	}
+	for _, candidate := range rule.FromStatuses {
+		if candidate == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTaskAutomationTransitions(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	worktree := seedWorktree(t, project.ID, "feature/auto")
+
+	taskService := &TaskService{}
+	commentService := NewTaskCommentService()
+	automation := NewTaskAutomationService()
+
+	task, err := taskService.CreateTask(ctx, &CreateTaskRequest{
+		ProjectID:  project.ID,
+		WorktreeID: &worktree.ID,
+		Title:      "Automate the board",
+		Status:     "todo",
+	})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+
+	moved, err := automation.HandleAssistantStarted(ctx, worktree.ID, "Claude Code")
+	if err != nil {
+		t.Fatalf("HandleAssistantStarted returned error: %v", err)
+	}
+	if len(moved) != 1 || moved[0].Status != "in_progress" {
+		t.Fatalf("expected task to move to in_progress, got %+v", moved)
+	}
+
+	moved, err = automation.HandleBranchMerged(ctx, project.ID, "feature/auto", "main")
+	if err != nil {
+		t.Fatalf("HandleBranchMerged returned error: %v", err)
+	}
+	if len(moved) != 1 || moved[0].Status != "done" {
+		t.Fatalf("expected task to move to done, got %+v", moved)
+	}
+	if moved[0].CompletedAt == nil {
+		t.Fatalf("expected completedAt to be set")
+	}
+
+	comments, err := commentService.ListComments(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("ListComments returned error: %v", err)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("expected 2 automation comments, got %d", len(comments))
+	}
+	if !strings.Contains(comments[0].Content, "todo to in_progress") {
+		t.Fatalf("unexpected automation comment %q", comments[0].Content)
+	}
+}
+
+func TestTaskAutomationDisabledRule(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	worktree := seedWorktree(t, project.ID, "feature/manual")
+
+	taskService := &TaskService{}
+	automation := NewTaskAutomationService()
+
+	rule, err := automation.UpsertRule(ctx, &UpdateTaskAutomationRequest{
+		ProjectID: project.ID,
+		Trigger:   TaskTriggerAIStarted,
+		ToStatus:  "in_progress",
+		Enabled:   false,
+	})
+	if err != nil {
+		t.Fatalf("UpsertRule returned error: %v", err)
+	}
+	if rule.Enabled {
+		t.Fatalf("expected rule to be disabled")
+	}
+
+	if _, err := taskService.CreateTask(ctx, &CreateTaskRequest{
+		ProjectID:  project.ID,
+		WorktreeID: &worktree.ID,
+		Title:      "Stay in todo",
+	}); err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+
+	moved, err := automation.HandleAssistantStarted(ctx, worktree.ID, "Codex")
+	if err != nil {
+		t.Fatalf("HandleAssistantStarted returned error: %v", err)
+	}
+	if len(moved) != 0 {
+		t.Fatalf("expected disabled rule to skip tasks, moved %d", len(moved))
+	}
+
+	rules, err := automation.ListRules(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("ListRules returned error: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected rules for every trigger, got %d", len(rules))
+	}
+
+	if _, err := automation.UpsertRule(ctx, &UpdateTaskAutomationRequest{
+		ProjectID: project.ID,
+		Trigger:   "unknown",
+		ToStatus:  "done",
+	}); !errors.Is(err, ErrInvalidAutomationTrigger) {
+		t.Fatalf("expected ErrInvalidAutomationTrigger, got %v", err)
+	}
+}
+
+func TestTaskAutomationDefaultsFollowCustomColumns(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	worktree := seedWorktree(t, project.ID, "feature/custom")
+
+	taskService := &TaskService{}
+	columnService := &TaskColumnService{}
+	automation := NewTaskAutomationService()
+
+	columns, err := columnService.ListColumns(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("ListColumns returned error: %v", err)
+	}
+	for _, column := range columns {
+		if column.Key == "in_progress" || column.Key == "done" {
+			if err := columnService.DeleteColumn(ctx, column.ID); err != nil {
+				t.Fatalf("DeleteColumn returned error: %v", err)
+			}
+		}
+	}
+	for _, req := range []CreateTaskColumnRequest{
+		{ProjectID: project.ID, Key: "doing", Category: TaskCategoryInProgress},
+		{ProjectID: project.ID, Key: "shipped", Category: TaskCategoryDone},
+	} {
+		req := req
+		if _, err := columnService.CreateColumn(ctx, &req); err != nil {
+			t.Fatalf("CreateColumn returned error: %v", err)
+		}
+	}
+
+	if _, err := taskService.CreateTask(ctx, &CreateTaskRequest{
+		ProjectID:  project.ID,
+		WorktreeID: &worktree.ID,
+		Title:      "Follow the custom workflow",
+	}); err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+
+	moved, err := automation.HandleAssistantStarted(ctx, worktree.ID, "Codex")
+	if err != nil {
+		t.Fatalf("HandleAssistantStarted returned error: %v", err)
+	}
+	if len(moved) != 1 || moved[0].Status != "doing" {
+		t.Fatalf("expected task to move to doing, got %+v", moved)
+	}
+
+	moved, err = automation.HandleBranchMerged(ctx, project.ID, "feature/custom", "main")
+	if err != nil {
+		t.Fatalf("HandleBranchMerged returned error: %v", err)
+	}
+	if len(moved) != 1 || moved[0].Status != "shipped" {
+		t.Fatalf("expected task to move to shipped, got %+v", moved)
+	}
+	if moved[0].CompletedAt == nil {
+		t.Fatalf("expected completedAt to be set")
+	}
+}
@@ -0,0 +1,259 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code-kanban/model/tables"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// TaskSortOrder keeps the manual board order (column, then drag position).
+	TaskSortOrder = "order"
+	// TaskSortPriority shows the highest priority first.
+	TaskSortPriority = "priority"
+	// TaskSortDue shows the nearest due date first; tasks without a due date go last.
+	TaskSortDue = "due"
+	// TaskSortUpdated shows recently updated tasks first.
+	TaskSortUpdated = "updated"
+	// TaskSortCreated shows recently created tasks first.
+	TaskSortCreated = "created"
+
+	// TaskGroupProject groups board tasks by project.
+	TaskGroupProject = "project"
+	// TaskGroupStatus groups board tasks by column key.
+	TaskGroupStatus = "status"
+	// TaskGroupCategory groups board tasks by column category, which is comparable across projects.
+	TaskGroupCategory = "category"
+	// TaskGroupPriority groups board tasks by priority.
+	TaskGroupPriority = "priority"
+	// TaskGroupNone returns a single group.
+	TaskGroupNone = "none"
+
+	// taskBoardLimit caps how many tasks a board call loads.
+	taskBoardLimit = 500
+)
+
+// ErrInvalidTaskView indicates an unsupported sort or grouping, or an invalid saved view.
+var ErrInvalidTaskView = errors.New("invalid task view")
+
+var taskSortSet = map[string]struct{}{
+	TaskSortOrder:    {},
+	TaskSortPriority: {},
+	TaskSortDue:      {},
+	TaskSortUpdated:  {},
+	TaskSortCreated:  {},
+}
+
+var taskGroupSet = map[string]struct{}{
+	TaskGroupProject:  {},
+	TaskGroupStatus:   {},
+	TaskGroupCategory: {},
+	TaskGroupPriority: {},
+	TaskGroupNone:     {},
+}
+
+// TaskBoardRequest configures the cross-project board. An empty ProjectIDs covers every project.
+type TaskBoardRequest struct {
+	ProjectIDs []string
+	Status     string
+	WorktreeID string
+	Priority   *int
+	Keyword    string
+	Sort       string
+	GroupBy    string
+}
+
+// TaskBoard is the grouped result of a board query.
+type TaskBoard struct {
+	Groups    []TaskBoardGroup `json:"groups"`
+	Total     int64            `json:"total"`
+	Truncated bool             `json:"truncated"`
+	Sort      string           `json:"sort"`
+	GroupBy   string           `json:"groupBy"`
+}
+
+// TaskBoardGroup holds the tasks sharing one grouping key.
+type TaskBoardGroup struct {
+	Key   string             `json:"key"`
+	Label string             `json:"label"`
+	Tasks []tables.TaskTable `json:"tasks"`
+}
+
+// Board aggregates tasks across projects with the same filters as ListTasks.
+func (s *TaskService) Board(ctx context.Context, req *TaskBoardRequest) (*TaskBoard, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		req = &TaskBoardRequest{}
+	}
+
+	sortBy, groupBy, err := normalizeTaskViewLayout(req.Sort, req.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	query := dbCtx.Model(&tables.TaskTable{}).
+		Joins("JOIN projects ON projects.id = tasks.project_id AND projects.deleted_at IS NULL")
+	if projectIDs := sanitizeTags(req.ProjectIDs); len(projectIDs) > 0 {
+		query = query.Where("tasks.project_id IN ?", []string(projectIDs))
+	}
+	if statuses := splitQueryValues(req.Status); len(statuses) > 0 {
+		query = query.Where("tasks.status IN ?", statuses)
+	}
+	if worktreeID := strings.TrimSpace(req.WorktreeID); worktreeID != "" {
+		query = query.Where("tasks.worktree_id = ?", worktreeID)
+	}
+	if req.Priority != nil {
+		query = query.Where("tasks.priority = ?", *req.Priority)
+	}
+	if keyword := strings.TrimSpace(req.Keyword); keyword != "" {
+		parsed, err := ParseTaskQuery(keyword)
+		if err != nil {
+			return nil, err
+		}
+		query = applyTaskQuery(query, parsed)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var tasks []tables.TaskTable
+	if err := applyTaskSort(query, sortBy).
+		Preload("Project").
+		Preload("Worktree").
+		Limit(taskBoardLimit).
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	if err := attachChecklistProgress(dbCtx, tasks); err != nil {
+		return nil, err
+	}
+
+	groups, err := groupBoardTasks(dbCtx, tasks, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	return &TaskBoard{
+		Groups:    groups,
+		Total:     total,
+		Truncated: total > int64(len(tasks)),
+		Sort:      sortBy,
+		GroupBy:   groupBy,
+	}, nil
+}
+
+func normalizeTaskViewLayout(sortBy, groupBy string) (string, string, error) {
+	sortBy = strings.TrimSpace(sortBy)
+	if sortBy == "" {
+		sortBy = TaskSortOrder
+	}
+	if _, ok := taskSortSet[sortBy]; !ok {
+		return "", "", fmt.Errorf("%w: unsupported sort %q", ErrInvalidTaskView, sortBy)
+	}
+	groupBy = strings.TrimSpace(groupBy)
+	if groupBy == "" {
+		groupBy = TaskGroupProject
+	}
+	if _, ok := taskGroupSet[groupBy]; !ok {
+		return "", "", fmt.Errorf("%w: unsupported grouping %q", ErrInvalidTaskView, groupBy)
+	}
+	return sortBy, groupBy, nil
+}
+
+func applyTaskSort(query *gorm.DB, sortBy string) *gorm.DB {
+	switch sortBy {
+	case TaskSortPriority:
+		return query.Order("tasks.priority DESC").Order("tasks.updated_at DESC")
+	case TaskSortDue:
+		return query.Order("tasks.due_date IS NULL").Order("tasks.due_date ASC").Order("tasks.priority DESC")
+	case TaskSortUpdated:
+		return query.Order("tasks.updated_at DESC")
+	case TaskSortCreated:
+		return query.Order("tasks.created_at DESC")
+	default:
+		return query.Order("tasks.project_id ASC").Order("tasks.status ASC").Order("tasks.order_index ASC")
+	}
+}
+
+// groupBoardTasks splits tasks into groups while keeping the sorted order inside each group.
+func groupBoardTasks(dbCtx *gorm.DB, tasks []tables.TaskTable, groupBy string) ([]TaskBoardGroup, error) {
+	groups := make([]TaskBoardGroup, 0)
+	index := map[string]int{}
+	add := func(key, label string, task tables.TaskTable) {
+		pos, ok := index[key]
+		if !ok {
+			pos = len(groups)
+			index[key] = pos
+			groups = append(groups, TaskBoardGroup{Key: key, Label: label, Tasks: []tables.TaskTable{}})
+		}
+		groups[pos].Tasks = append(groups[pos].Tasks, task)
+	}
+
+	workflows := map[string]*taskWorkflow{}
+	workflowFor := func(projectID string) (*taskWorkflow, error) {
+		if workflow, ok := workflows[projectID]; ok {
+			return workflow, nil
+		}
+		workflow, err := loadTaskWorkflow(dbCtx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		workflows[projectID] = workflow
+		return workflow, nil
+	}
+
+	for _, task := range tasks {
+		switch groupBy {
+		case TaskGroupProject:
+			label := task.ProjectID
+			if task.Project != nil {
+				label = task.Project.Name
+			}
+			add(task.ProjectID, label, task)
+		case TaskGroupStatus:
+			workflow, err := workflowFor(task.ProjectID)
+			if err != nil {
+				return nil, err
+			}
+			label := task.Status
+			if column := workflow.column(task.Status); column != nil {
+				label = column.Name
+			}
+			add(task.Status, label, task)
+		case TaskGroupCategory:
+			workflow, err := workflowFor(task.ProjectID)
+			if err != nil {
+				return nil, err
+			}
+			category := workflow.category(task.Status)
+			add(category, category, task)
+		case TaskGroupPriority:
+			key := strconv.Itoa(task.Priority)
+			add(key, "P"+key, task)
+		default:
+			add("all", "all", task)
+		}
+	}
+
+	switch groupBy {
+	case TaskGroupCategory:
+		rank := map[string]int{TaskCategoryTodo: 0, TaskCategoryInProgress: 1, TaskCategoryDone: 2, TaskCategoryArchived: 3}
+		sort.SliceStable(groups, func(i, j int) bool { return rank[groups[i].Key] < rank[groups[j].Key] })
+	case TaskGroupPriority:
+		sort.SliceStable(groups, func(i, j int) bool { return groups[i].Key > groups[j].Key })
+	case TaskGroupProject:
+		sort.SliceStable(groups, func(i, j int) bool { return groups[i].Label < groups[j].Label })
+	}
+	return groups, nil
+}
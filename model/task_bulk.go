@@ -0,0 +1,420 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"code-kanban/model/tables"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxBulkTasks caps how many tasks one bulk request may touch.
+	MaxBulkTasks = 500
+
+	// BulkReorderTop moves the selected tasks to the top of their column.
+	BulkReorderTop = "top"
+	// BulkReorderBottom moves the selected tasks to the bottom of their column.
+	BulkReorderBottom = "bottom"
+
+	// BulkResultUpdated marks a task changed by the bulk request.
+	BulkResultUpdated = "updated"
+	// BulkResultDeleted marks a task deleted by the bulk request.
+	BulkResultDeleted = "deleted"
+	// BulkResultUnchanged marks a task the operations did not modify.
+	BulkResultUnchanged = "unchanged"
+	// BulkResultFailed marks a task whose operations failed.
+	BulkResultFailed = "failed"
+	// BulkResultRolledBack marks a task whose changes were undone because another task failed.
+	BulkResultRolledBack = "rolled_back"
+)
+
+var (
+	// ErrInvalidBulkTaskRequest indicates the bulk request selects or changes nothing, or conflicts with itself.
+	ErrInvalidBulkTaskRequest = errors.New("invalid bulk task request")
+
+	errBulkRollback = errors.New("bulk task request rolled back")
+)
+
+// BulkTaskRequest selects tasks by id or by a search query and applies the same
+// operations to each of them.
+type BulkTaskRequest struct {
+	ProjectID string
+	TaskIDs   []string // 按给定顺序处理；为空时使用 Query 选择
+	Query     string   // 与检索接口相同的语法，如 status:done tag:release
+
+	Status     string
+	AddTags    []string
+	RemoveTags []string
+	Priority   *int
+	Archive    bool
+	Delete     bool
+	Reorder    string // top/bottom
+
+	// AllowPartial commits the tasks that succeeded even when others fail.
+	// By default a single failure rolls back the whole request.
+	AllowPartial bool
+}
+
+// BulkTaskResult reports what happened to one task.
+type BulkTaskResult struct {
+	TaskID  string   `json:"taskId"`
+	Title   string   `json:"title,omitempty"`
+	Result  string   `json:"result"` // updated/deleted/unchanged/failed/rolled_back
+	Changes []string `json:"changes,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// BulkTaskReport summarises a bulk request.
+type BulkTaskReport struct {
+	Matched   int              `json:"matched"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Committed bool             `json:"committed"` // 为 false 时所有修改均已回滚
+	Results   []BulkTaskResult `json:"results"`
+}
+
+func (r *BulkTaskRequest) hasOperation() bool {
+	return strings.TrimSpace(r.Status) != "" || len(r.AddTags) > 0 || len(r.RemoveTags) > 0 ||
+		r.Priority != nil || r.Archive || r.Delete || r.Reorder != ""
+}
+
+func (r *BulkTaskRequest) validate() error {
+	if strings.TrimSpace(r.ProjectID) == "" {
+		return fmt.Errorf("%w: project id is required", ErrInvalidBulkTaskRequest)
+	}
+	if len(r.TaskIDs) == 0 && strings.TrimSpace(r.Query) == "" {
+		return fmt.Errorf("%w: taskIds or query is required", ErrInvalidBulkTaskRequest)
+	}
+	if len(r.TaskIDs) > 0 && strings.TrimSpace(r.Query) != "" {
+		return fmt.Errorf("%w: taskIds and query are mutually exclusive", ErrInvalidBulkTaskRequest)
+	}
+	if len(r.TaskIDs) > MaxBulkTasks {
+		return fmt.Errorf("%w: at most %d tasks per request", ErrInvalidBulkTaskRequest, MaxBulkTasks)
+	}
+	if !r.hasOperation() {
+		return fmt.Errorf("%w: no operation given", ErrInvalidBulkTaskRequest)
+	}
+	if r.Archive && strings.TrimSpace(r.Status) != "" {
+		return fmt.Errorf("%w: archive and status are mutually exclusive", ErrInvalidBulkTaskRequest)
+	}
+	if r.Delete && (strings.TrimSpace(r.Status) != "" || len(r.AddTags) > 0 || len(r.RemoveTags) > 0 ||
+		r.Priority != nil || r.Archive || r.Reorder != "") {
+		return fmt.Errorf("%w: delete cannot be combined with other operations", ErrInvalidBulkTaskRequest)
+	}
+	if r.Priority != nil && (*r.Priority < 0 || *r.Priority > 3) {
+		return fmt.Errorf("%w: priority must be between 0 and 3", ErrInvalidBulkTaskRequest)
+	}
+	switch r.Reorder {
+	case "", BulkReorderTop, BulkReorderBottom:
+	default:
+		return fmt.Errorf("%w: unknown reorder %q", ErrInvalidBulkTaskRequest, r.Reorder)
+	}
+	return nil
+}
+
+// BulkUpdateTasks applies the request to every selected task inside one
+// transaction and reports the outcome per task.
+func (s *TaskService) BulkUpdateTasks(ctx context.Context, req *BulkTaskRequest) (*BulkTaskReport, error) {
+	if db == nil {
+		return nil, ErrDBNotInitialized
+	}
+	ctx = ensureContext(ctx)
+	if req == nil {
+		return nil, fmt.Errorf("%w: request is required", ErrInvalidBulkTaskRequest)
+	}
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	var query *TaskQuery
+	if strings.TrimSpace(req.Query) != "" {
+		parsed, err := ParseTaskQuery(req.Query)
+		if err != nil {
+			return nil, err
+		}
+		query = parsed
+	}
+
+	report := &BulkTaskReport{Results: []BulkTaskResult{}}
+	err := Transaction(ctx, func(q *Queries) error {
+		tx := gormForQueries(ctx, q)
+		if err := ensureProjectExists(tx, req.ProjectID); err != nil {
+			return err
+		}
+		workflow, err := loadTaskWorkflow(tx, req.ProjectID)
+		if err != nil {
+			return err
+		}
+		tasks, missing, err := selectBulkTasks(tx, req, query)
+		if err != nil {
+			return err
+		}
+		report.Matched = len(tasks)
+
+		for _, id := range missing {
+			report.Results = append(report.Results, BulkTaskResult{TaskID: id, Result: BulkResultFailed, Error: ErrTaskNotFound.Error()})
+		}
+
+		var reordered []*tables.TaskTable
+		for _, task := range tasks {
+			result := BulkTaskResult{TaskID: task.ID, Title: task.Title}
+			if req.AllowPartial {
+				if err := tx.Exec("SAVEPOINT bulk_task").Error; err != nil {
+					return err
+				}
+			}
+			changes, err := s.applyBulkOperations(ctx, tx, workflow, task, req)
+			if req.AllowPartial {
+				statement := "RELEASE SAVEPOINT bulk_task"
+				if err != nil {
+					statement = "ROLLBACK TO SAVEPOINT bulk_task"
+				}
+				if spErr := tx.Exec(statement).Error; spErr != nil {
+					return spErr
+				}
+			}
+			switch {
+			case err != nil:
+				result.Result = BulkResultFailed
+				result.Error = err.Error()
+			case req.Delete:
+				result.Result = BulkResultDeleted
+			case len(changes) == 0:
+				result.Result = BulkResultUnchanged
+			default:
+				result.Result = BulkResultUpdated
+				result.Changes = changes
+			}
+			if err == nil && !req.Delete {
+				reordered = append(reordered, task)
+			}
+			report.Results = append(report.Results, result)
+		}
+
+		for _, result := range report.Results {
+			if result.Result == BulkResultFailed {
+				report.Failed++
+			} else {
+				report.Succeeded++
+			}
+		}
+		if report.Failed > 0 && !req.AllowPartial {
+			return errBulkRollback
+		}
+
+		if req.Reorder != "" {
+			if err := reorderBulkTasks(tx, req.ProjectID, reordered, req.Reorder, report); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errBulkRollback):
+		for i := range report.Results {
+			if report.Results[i].Result != BulkResultFailed {
+				report.Results[i].Result = BulkResultRolledBack
+				report.Results[i].Changes = nil
+			}
+		}
+		report.Succeeded = 0
+		return report, nil
+	case err != nil:
+		return nil, err
+	}
+	report.Committed = true
+	return report, nil
+}
+
+// selectBulkTasks loads the selected tasks in request order and lists the ids
+// that do not exist in the project.
+func selectBulkTasks(tx *gorm.DB, req *BulkTaskRequest, query *TaskQuery) ([]*tables.TaskTable, []string, error) {
+	base := tx.Model(&tables.TaskTable{}).Where("tasks.project_id = ?", req.ProjectID)
+
+	if query != nil {
+		var tasks []*tables.TaskTable
+		if err := applyTaskQuery(base, query).
+			Order("tasks.status ASC, tasks.order_index ASC").
+			Limit(MaxBulkTasks + 1).
+			Find(&tasks).Error; err != nil {
+			return nil, nil, err
+		}
+		if len(tasks) > MaxBulkTasks {
+			return nil, nil, fmt.Errorf("%w: query matches more than %d tasks", ErrInvalidBulkTaskRequest, MaxBulkTasks)
+		}
+		return tasks, nil, nil
+	}
+
+	ids := make([]string, 0, len(req.TaskIDs))
+	seen := make(map[string]struct{}, len(req.TaskIDs))
+	for _, id := range req.TaskIDs {
+		id = strings.TrimSpace(id)
+		if _, ok := seen[id]; ok || id == "" {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	var found []*tables.TaskTable
+	if err := base.Where("tasks.id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[string]*tables.TaskTable, len(found))
+	for _, task := range found {
+		byID[task.ID] = task
+	}
+	tasks := make([]*tables.TaskTable, 0, len(found))
+	var missing []string
+	for _, id := range ids {
+		if task, ok := byID[id]; ok {
+			tasks = append(tasks, task)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return tasks, missing, nil
+}
+
+// applyBulkOperations changes one task and returns the names of the fields it changed.
+func (s *TaskService) applyBulkOperations(ctx context.Context, tx *gorm.DB, workflow *taskWorkflow, task *tables.TaskTable, req *BulkTaskRequest) ([]string, error) {
+	if req.Delete {
+		return nil, deleteTaskRecords(ctx, tx, task)
+	}
+
+	updates := map[string]interface{}{}
+	status := strings.TrimSpace(req.Status)
+	if req.Archive {
+		status = firstColumnOfCategory(workflow, TaskCategoryArchived, "")
+		if status == "" {
+			return nil, fmt.Errorf("%w: project has no archive column", ErrInvalidTaskStatus)
+		}
+	}
+	if status != "" && status != task.Status {
+		if workflow.column(status) == nil {
+			return nil, ErrInvalidTaskStatus
+		}
+		if !workflow.canTransition(task.Status, status) {
+			return nil, fmt.Errorf("%w: %s -> %s", ErrTaskTransitionNotAllowed, task.Status, status)
+		}
+		if err := workflow.ensureCapacity(tx, status, task.ID); err != nil {
+			return nil, err
+		}
+		orderIndex, err := s.getNextOrderIndex(tx, task.ProjectID, status)
+		if err != nil {
+			return nil, err
+		}
+		updates["status"] = status
+		updates["order_index"] = orderIndex
+		if workflow.category(status) == TaskCategoryDone && task.CompletedAt == nil {
+			updates["completed_at"] = time.Now()
+		}
+	}
+
+	if len(req.AddTags) > 0 || len(req.RemoveTags) > 0 {
+		if tags := mergeBulkTags(task.Tags, req.AddTags, req.RemoveTags); formatEventValue(tags) != formatEventValue(sanitizeTags(task.Tags)) {
+			updates["tags"] = tags
+		}
+	}
+	if req.Priority != nil && *req.Priority != task.Priority {
+		updates["priority"] = *req.Priority
+	}
+
+	if len(updates) == 0 {
+		return nil, nil
+	}
+	if err := applyTaskUpdates(ctx, tx, task, updates); err != nil {
+		return nil, err
+	}
+
+	changes := make([]string, 0, len(updates))
+	for _, field := range []string{"status", "tags", "priority"} {
+		if _, ok := updates[field]; ok {
+			changes = append(changes, field)
+		}
+	}
+	if value, ok := updates["status"]; ok {
+		task.Status = value.(string)
+	}
+	return changes, nil
+}
+
+// mergeBulkTags adds and removes tags case-insensitively, keeping the existing order.
+func mergeBulkTags(current tables.StringArray, add, remove []string) tables.StringArray {
+	removed := make(map[string]struct{}, len(remove))
+	for _, tag := range sanitizeTags(remove) {
+		removed[strings.ToLower(tag)] = struct{}{}
+	}
+	result := tables.StringArray{}
+	seen := map[string]struct{}{}
+	for _, tag := range append(sanitizeTags(current), sanitizeTags(add)...) {
+		key := strings.ToLower(tag)
+		if _, ok := removed[key]; ok {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, tag)
+	}
+	return result
+}
+
+// reorderBulkTasks places the tasks at the top or bottom of their columns, keeping
+// their relative order.
+func reorderBulkTasks(tx *gorm.DB, projectID string, tasks []*tables.TaskTable, position string, report *BulkTaskReport) error {
+	byStatus := map[string][]*tables.TaskTable{}
+	var statuses []string
+	selected := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		if _, ok := byStatus[task.Status]; !ok {
+			statuses = append(statuses, task.Status)
+		}
+		byStatus[task.Status] = append(byStatus[task.Status], task)
+		selected = append(selected, task.ID)
+	}
+
+	reordered := map[string]struct{}{}
+	for _, status := range statuses {
+		group := byStatus[status]
+		aggregate := "COALESCE(MAX(order_index), 0)"
+		if position == BulkReorderTop {
+			aggregate = "COALESCE(MIN(order_index), 0)"
+		}
+		var edge float64
+		if err := tx.Model(&tables.TaskTable{}).
+			Where("project_id = ? AND status = ? AND id NOT IN ?", projectID, status, selected).
+			Select(aggregate).
+			Scan(&edge).Error; err != nil {
+			return err
+		}
+		for i, task := range group {
+			orderIndex := edge + float64(i+1)*1000
+			if position == BulkReorderTop {
+				orderIndex = edge - float64(len(group)-i)*1000
+			}
+			if err := tx.Model(&tables.TaskTable{}).
+				Where("id = ?", task.ID).
+				UpdateColumn("order_index", orderIndex).Error; err != nil {
+				return err
+			}
+			reordered[task.ID] = struct{}{}
+		}
+	}
+
+	for i := range report.Results {
+		result := &report.Results[i]
+		if _, ok := reordered[result.TaskID]; !ok {
+			continue
+		}
+		result.Changes = append(result.Changes, "order_index")
+		if result.Result == BulkResultUnchanged {
+			result.Result = BulkResultUpdated
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"code-kanban/model/tables"
+)
+
+func TestTaskServiceBulkUpdateTasks(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	svc := &TaskService{}
+	create := func(title, status string, tags ...string) *tables.TaskTable {
+		task, err := svc.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: title, Status: status, Tags: tags})
+		if err != nil {
+			t.Fatalf("CreateTask returned error: %v", err)
+		}
+		return task
+	}
+	a := create("A", "done", "release")
+	b := create("B", "done", "Release", "backend")
+	c := create("C", "todo")
+	d := create("D", "todo")
+
+	// Archive everything done through a filter query.
+	report, err := svc.BulkUpdateTasks(ctx, &BulkTaskRequest{ProjectID: project.ID, Query: "status:done", Archive: true, RemoveTags: []string{"RELEASE"}})
+	if err != nil {
+		t.Fatalf("BulkUpdateTasks returned error: %v", err)
+	}
+	if !report.Committed || report.Matched != 2 || report.Succeeded != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	for _, id := range []string{a.ID, b.ID} {
+		task, _ := svc.GetTask(ctx, id)
+		if task.Status != "archived" {
+			t.Fatalf("expected %s to be archived, got %s", task.Title, task.Status)
+		}
+	}
+	if task, _ := svc.GetTask(ctx, b.ID); len(task.Tags) != 1 || task.Tags[0] != "backend" {
+		t.Fatalf("expected release tag removed, got %v", task.Tags)
+	}
+	var events int64
+	db.Model(&tables.TaskEventTable{}).Where("task_id = ? AND type = ?", a.ID, TaskEventStatusChanged).Count(&events)
+	if events != 1 {
+		t.Fatalf("expected a status_changed event, got %d", events)
+	}
+
+	// One bad id rolls back the whole request by default.
+	priority := 3
+	report, err = svc.BulkUpdateTasks(ctx, &BulkTaskRequest{ProjectID: project.ID, TaskIDs: []string{c.ID, "missing"}, Priority: &priority})
+	if err != nil {
+		t.Fatalf("BulkUpdateTasks returned error: %v", err)
+	}
+	if report.Committed || report.Failed != 1 {
+		t.Fatalf("expected rolled back report, got %+v", report)
+	}
+	if task, _ := svc.GetTask(ctx, c.ID); task.Priority != 0 {
+		t.Fatalf("expected priority change to be rolled back, got %d", task.Priority)
+	}
+
+	// With AllowPartial the valid tasks are committed.
+	report, err = svc.BulkUpdateTasks(ctx, &BulkTaskRequest{ProjectID: project.ID, TaskIDs: []string{c.ID, "missing"}, Priority: &priority, AllowPartial: true})
+	if err != nil {
+		t.Fatalf("BulkUpdateTasks returned error: %v", err)
+	}
+	if !report.Committed || report.Succeeded != 1 || report.Failed != 1 {
+		t.Fatalf("unexpected partial report: %+v", report)
+	}
+	if task, _ := svc.GetTask(ctx, c.ID); task.Priority != 3 {
+		t.Fatalf("expected priority 3, got %d", task.Priority)
+	}
+
+	// Reorder keeps the selection order at the top of the column.
+	if _, err := svc.BulkUpdateTasks(ctx, &BulkTaskRequest{ProjectID: project.ID, TaskIDs: []string{d.ID, c.ID}, Reorder: BulkReorderTop}); err != nil {
+		t.Fatalf("BulkUpdateTasks returned error: %v", err)
+	}
+	first, _ := svc.GetTask(ctx, d.ID)
+	second, _ := svc.GetTask(ctx, c.ID)
+	if first.OrderIndex >= second.OrderIndex {
+		t.Fatalf("expected D before C, got %v and %v", first.OrderIndex, second.OrderIndex)
+	}
+
+	report, err = svc.BulkUpdateTasks(ctx, &BulkTaskRequest{ProjectID: project.ID, TaskIDs: []string{d.ID}, Delete: true})
+	if err != nil || report.Results[0].Result != BulkResultDeleted {
+		t.Fatalf("expected delete, got %+v (%v)", report, err)
+	}
+	if _, err := svc.GetTask(ctx, d.ID); err == nil {
+		t.Fatalf("expected deleted task to be gone")
+	}
+
+	for _, invalid := range []*BulkTaskRequest{
+		{ProjectID: project.ID, Query: "status:todo"},
+		{ProjectID: project.ID, Archive: true},
+		{ProjectID: project.ID, TaskIDs: []string{c.ID}, Delete: true, Archive: true},
+		{ProjectID: project.ID, TaskIDs: []string{c.ID}, Reorder: "middle"},
+	} {
+		if _, err := svc.BulkUpdateTasks(ctx, invalid); err == nil {
+			t.Fatalf("expected %+v to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"code-kanban/model/tables"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTaskChecklistItemNotFound indicates the requested checklist item does not exist.
+	ErrTaskChecklistItemNotFound = errors.New("task checklist item not found")
+	// ErrInvalidChecklistItem indicates the checklist payload is invalid.
+	ErrInvalidChecklistItem = errors.New("invalid checklist item")
+)
+
+// UpdateChecklistItemRequest carries optional checklist item changes.
+type UpdateChecklistItemRequest struct {
+	Content *string
+	Done    *bool
+}
+
+// CheckChecklistItemRequest locates a checklist item for scripts and hooks.
+// Exactly one of ItemID, Position (1-based) or Match should be set.
+type CheckChecklistItemRequest struct {
+	ItemID   string
+	Position int
+	Match    string
+	Done     bool
+}
+
+// TaskChecklistService manages checklist items embedded in tasks.
+type TaskChecklistService struct {
+	taskSvc *TaskService
+}
+
+// NewTaskChecklistService constructs a checklist service with a task dependency.
+func NewTaskChecklistService() *TaskChecklistService {
+	return &TaskChecklistService{taskSvc: &TaskService{}}
+}
+
+// ListItems returns the checklist of a task in display order.
+func (s *TaskChecklistService) ListItems(ctx context.Context, taskID string) ([]tables.TaskChecklistItemTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.taskSvc.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	var items []tables.TaskChecklistItemTable
+	if err := dbCtx.
+		Where("task_id = ?", taskID).
+		Order("order_index ASC").
+		Order("created_at ASC").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// AddItems appends the given steps to the checklist. When replace is true the
+// existing items are removed first, which suits capturing a fresh agent plan.
+func (s *TaskChecklistService) AddItems(ctx context.Context, taskID string, contents []string, replace bool) ([]tables.TaskChecklistItemTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.taskSvc.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	items := make([]tables.TaskChecklistItemTable, 0, len(contents))
+	for _, content := range contents {
+		content = strings.TrimSpace(content)
+		if content == "" {
+			continue
+		}
+		items = append(items, tables.TaskChecklistItemTable{TaskID: taskID, Content: content})
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: content is required", ErrInvalidChecklistItem)
+	}
+
+	err = dbCtx.Transaction(func(tx *gorm.DB) error {
+		if replace {
+			if err := tx.Where("task_id = ?", taskID).Delete(&tables.TaskChecklistItemTable{}).Error; err != nil {
+				return err
+			}
+		}
+		next, err := nextChecklistOrderIndex(tx, taskID)
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].OrderIndex = next
+			next += 1000
+		}
+		return tx.Create(&items).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.ListItems(ctx, taskID)
+}
+
+// GetItem loads a checklist item by identifier.
+func (s *TaskChecklistService) GetItem(ctx context.Context, id string) (*tables.TaskChecklistItemTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var item tables.TaskChecklistItemTable
+	if err := dbCtx.First(&item, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskChecklistItemNotFound
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpdateItem changes the content or done flag of a checklist item.
+func (s *TaskChecklistService) UpdateItem(ctx context.Context, id string, req *UpdateChecklistItemRequest) (*tables.TaskChecklistItemTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return s.GetItem(ctx, id)
+	}
+
+	item, err := s.GetItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Content != nil {
+		content := strings.TrimSpace(*req.Content)
+		if content == "" {
+			return nil, fmt.Errorf("%w: content is required", ErrInvalidChecklistItem)
+		}
+		updates["content"] = content
+	}
+	if req.Done != nil && *req.Done != item.Done {
+		updates["done"] = *req.Done
+		if *req.Done {
+			now := time.Now()
+			updates["completed_at"] = &now
+		} else {
+			updates["completed_at"] = nil
+		}
+	}
+	if len(updates) == 0 {
+		return item, nil
+	}
+
+	if err := dbCtx.
+		Model(&tables.TaskChecklistItemTable{}).
+		Where("id = ?", id).
+		Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return s.GetItem(ctx, id)
+}
+
+// MoveItem changes the position of a checklist item.
+func (s *TaskChecklistService) MoveItem(ctx context.Context, id string, orderIndex float64) (*tables.TaskChecklistItemTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.GetItem(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if err := dbCtx.
+		Model(&tables.TaskChecklistItemTable{}).
+		Where("id = ?", id).
+		Update("order_index", orderIndex).Error; err != nil {
+		return nil, err
+	}
+	return s.GetItem(ctx, id)
+}
+
+// DeleteItem removes a checklist item.
+func (s *TaskChecklistService) DeleteItem(ctx context.Context, id string) error {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	result := dbCtx.Delete(&tables.TaskChecklistItemTable{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTaskChecklistItemNotFound
+	}
+	return nil
+}
+
+// CheckItem ticks (or unticks) a checklist item of a task. Scripts usually do not
+// know item ids, so the item may also be addressed by position or by a content match;
+// a match prefers the first item whose state still differs from the requested one.
+func (s *TaskChecklistService) CheckItem(ctx context.Context, taskID string, req *CheckChecklistItemRequest) (*tables.TaskChecklistItemTable, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%w: request is required", ErrInvalidChecklistItem)
+	}
+
+	items, err := s.ListItems(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *tables.TaskChecklistItemTable
+	switch {
+	case strings.TrimSpace(req.ItemID) != "":
+		for i := range items {
+			if items[i].ID == strings.TrimSpace(req.ItemID) {
+				target = &items[i]
+				break
+			}
+		}
+	case req.Position > 0:
+		if req.Position <= len(items) {
+			target = &items[req.Position-1]
+		}
+	case strings.TrimSpace(req.Match) != "":
+		needle := strings.ToLower(strings.TrimSpace(req.Match))
+		for i := range items {
+			if !strings.Contains(strings.ToLower(items[i].Content), needle) {
+				continue
+			}
+			if target == nil {
+				target = &items[i]
+			}
+			if items[i].Done != req.Done {
+				target = &items[i]
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: itemId, position or match is required", ErrInvalidChecklistItem)
+	}
+	if target == nil {
+		return nil, ErrTaskChecklistItemNotFound
+	}
+
+	done := req.Done
+	return s.UpdateItem(ctx, target.ID, &UpdateChecklistItemRequest{Done: &done})
+}
+
+func (s *TaskChecklistService) dbWithContext(ctx context.Context) (*gorm.DB, error) {
+	if db == nil {
+		return nil, ErrDBNotInitialized
+	}
+	return db.WithContext(ensureContext(ctx)), nil
+}
+
+func nextChecklistOrderIndex(dbCtx *gorm.DB, taskID string) (float64, error) {
+	var maxOrder float64
+	if err := dbCtx.
+		Model(&tables.TaskChecklistItemTable{}).
+		Where("task_id = ?", taskID).
+		Select("COALESCE(MAX(order_index), 0)").
+		Scan(&maxOrder).Error; err != nil {
+		return 0, err
+	}
+	return maxOrder + 1000, nil
+}
+
+// attachChecklistProgress fills the checklist summary of the given tasks with a single query.
+func attachChecklistProgress(dbCtx *gorm.DB, tasks []tables.TaskTable) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+
+	var rows []struct {
+		TaskID string
+		Total  int
+		Done   int
+	}
+	if err := dbCtx.
+		Model(&tables.TaskChecklistItemTable{}).
+		Select("task_id, COUNT(*) AS total, SUM(CASE WHEN done THEN 1 ELSE 0 END) AS done").
+		Where("task_id IN ?", ids).
+		Group("task_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	progress := make(map[string]*tables.ChecklistProgress, len(rows))
+	for _, row := range rows {
+		progress[row.TaskID] = &tables.ChecklistProgress{Total: row.Total, Done: row.Done}
+	}
+	for i := range tasks {
+		tasks[i].Checklist = progress[tasks[i].ID]
+	}
+	return nil
+}
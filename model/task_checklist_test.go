@@ -0,0 +1,101 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTaskChecklistService(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	tasks := &TaskService{}
+	checklist := NewTaskChecklistService()
+
+	task, err := tasks.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "agent plan"})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	if _, err := tasks.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "no checklist"}); err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+
+	if _, err := checklist.AddItems(ctx, task.ID, []string{"  "}, false); !errors.Is(err, ErrInvalidChecklistItem) {
+		t.Fatalf("expected ErrInvalidChecklistItem, got %v", err)
+	}
+	if _, err := checklist.AddItems(ctx, task.ID, []string{"old step"}, false); err != nil {
+		t.Fatalf("AddItems returned error: %v", err)
+	}
+	items, err := checklist.AddItems(ctx, task.ID, []string{"Write migration", "Add API", "Write tests"}, true)
+	if err != nil {
+		t.Fatalf("AddItems replace returned error: %v", err)
+	}
+	if len(items) != 3 || items[0].Content != "Write migration" || items[2].Content != "Write tests" {
+		t.Fatalf("unexpected checklist after replace: %+v", items)
+	}
+
+	if _, err := checklist.CheckItem(ctx, task.ID, &CheckChecklistItemRequest{Match: "write", Done: true}); err != nil {
+		t.Fatalf("CheckItem by match returned error: %v", err)
+	}
+	second, err := checklist.CheckItem(ctx, task.ID, &CheckChecklistItemRequest{Match: "write", Done: true})
+	if err != nil {
+		t.Fatalf("CheckItem by match returned error: %v", err)
+	}
+	if second.Content != "Write tests" || !second.Done || second.CompletedAt == nil {
+		t.Fatalf("expected second match to tick the next open item, got %+v", second)
+	}
+	if _, err := checklist.CheckItem(ctx, task.ID, &CheckChecklistItemRequest{Position: 9, Done: true}); !errors.Is(err, ErrTaskChecklistItemNotFound) {
+		t.Fatalf("expected ErrTaskChecklistItemNotFound, got %v", err)
+	}
+
+	list, _, err := tasks.ListTasks(ctx, &ListTasksRequest{ProjectID: project.ID})
+	if err != nil {
+		t.Fatalf("ListTasks returned error: %v", err)
+	}
+	for _, item := range list {
+		switch item.ID {
+		case task.ID:
+			if item.Checklist == nil || item.Checklist.Total != 3 || item.Checklist.Done != 2 {
+				t.Fatalf("unexpected checklist progress %+v", item.Checklist)
+			}
+		default:
+			if item.Checklist != nil {
+				t.Fatalf("expected no progress for task without checklist, got %+v", item.Checklist)
+			}
+		}
+	}
+
+	undone := false
+	reopened, err := checklist.UpdateItem(ctx, second.ID, &UpdateChecklistItemRequest{Done: &undone})
+	if err != nil {
+		t.Fatalf("UpdateItem returned error: %v", err)
+	}
+	if reopened.Done || reopened.CompletedAt != nil {
+		t.Fatalf("expected item to be reopened, got %+v", reopened)
+	}
+
+	if _, err := checklist.MoveItem(ctx, items[2].ID, 0); err != nil {
+		t.Fatalf("MoveItem returned error: %v", err)
+	}
+	ordered, err := checklist.ListItems(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("ListItems returned error: %v", err)
+	}
+	if ordered[0].ID != items[2].ID {
+		t.Fatalf("expected moved item first, got %+v", ordered)
+	}
+
+	if err := checklist.DeleteItem(ctx, items[1].ID); err != nil {
+		t.Fatalf("DeleteItem returned error: %v", err)
+	}
+	detail, err := tasks.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTask returned error: %v", err)
+	}
+	if detail.Checklist == nil || detail.Checklist.Total != 2 || detail.Checklist.Done != 1 {
+		t.Fatalf("unexpected detail progress %+v", detail.Checklist)
+	}
+}
@@ -0,0 +1,495 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"code-kanban/model/tables"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// TaskCategoryTodo marks columns holding work that has not started yet.
+	TaskCategoryTodo = "todo"
+	// TaskCategoryInProgress marks columns holding active work.
+	TaskCategoryInProgress = "in_progress"
+	// TaskCategoryDone marks columns holding finished work.
+	TaskCategoryDone = "done"
+	// TaskCategoryArchived marks columns hidden from the active board.
+	TaskCategoryArchived = "archived"
+)
+
+var (
+	// ErrTaskColumnNotFound indicates the requested column does not exist.
+	ErrTaskColumnNotFound = errors.New("task column not found")
+	// ErrTaskColumnExists indicates another column of the project already uses the key.
+	ErrTaskColumnExists = errors.New("task column key already exists")
+	// ErrTaskColumnInUse indicates the column still holds tasks or is the last column.
+	ErrTaskColumnInUse = errors.New("task column is in use")
+	// ErrInvalidTaskColumn indicates the column definition is invalid.
+	ErrInvalidTaskColumn = errors.New("invalid task column")
+	// ErrTaskTransitionNotAllowed indicates the workflow forbids moving between the two columns.
+	ErrTaskTransitionNotAllowed = errors.New("task transition is not allowed")
+	// ErrTaskWIPLimitReached indicates the target column reached its work-in-progress limit.
+	ErrTaskWIPLimitReached = errors.New("task column WIP limit reached")
+)
+
+var taskCategorySet = map[string]struct{}{
+	TaskCategoryTodo:       {},
+	TaskCategoryInProgress: {},
+	TaskCategoryDone:       {},
+	TaskCategoryArchived:   {},
+}
+
+var taskColumnKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// defaultTaskColumns mirrors the statuses used before workflows became configurable.
+var defaultTaskColumns = []tables.TaskColumnTable{
+	{Key: "todo", Name: "待办", Color: "#909399", Category: TaskCategoryTodo},
+	{Key: "in_progress", Name: "进行中", Color: "#409eff", Category: TaskCategoryInProgress},
+	{Key: "done", Name: "已完成", Color: "#67c23a", Category: TaskCategoryDone},
+	{Key: "archived", Name: "已归档", Color: "#c0c4cc", Category: TaskCategoryArchived},
+}
+
+// TaskColumnService manages the per-project kanban workflow.
+type TaskColumnService struct{}
+
+// CreateTaskColumnRequest captures inputs required to add a column.
+type CreateTaskColumnRequest struct {
+	ProjectID          string
+	Key                string
+	Name               string
+	Color              string
+	Category           string
+	WIPLimit           int
+	AllowedTransitions tables.StringArray
+}
+
+// UpdateTaskColumnRequest captures partial updates of a column. The key is immutable.
+type UpdateTaskColumnRequest struct {
+	Name               *string
+	Color              *string
+	Category           *string
+	WIPLimit           *int
+	AllowedTransitions *tables.StringArray
+}
+
+// ListColumns returns the workflow columns of a project ordered for display, seeding defaults on first use.
+func (s *TaskColumnService) ListColumns(ctx context.Context, projectID string) ([]tables.TaskColumnTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workflow, err := loadTaskWorkflow(dbCtx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return workflow.columns, nil
+}
+
+// GetColumn loads a column by identifier.
+func (s *TaskColumnService) GetColumn(ctx context.Context, id string) (*tables.TaskColumnTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var column tables.TaskColumnTable
+	if err := dbCtx.First(&column, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskColumnNotFound
+		}
+		return nil, err
+	}
+	return &column, nil
+}
+
+// CreateColumn appends a new column to the project workflow.
+func (s *TaskColumnService) CreateColumn(ctx context.Context, req *CreateTaskColumnRequest) (*tables.TaskColumnTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+
+	workflow, err := loadTaskWorkflow(dbCtx, req.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := strings.TrimSpace(req.Key)
+	if !taskColumnKeyPattern.MatchString(key) {
+		return nil, fmt.Errorf("%w: key must match %s", ErrInvalidTaskColumn, taskColumnKeyPattern.String())
+	}
+	if workflow.column(key) != nil {
+		return nil, ErrTaskColumnExists
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = key
+	}
+	category, err := normalizeTaskCategory(req.Category)
+	if err != nil {
+		return nil, err
+	}
+	if req.WIPLimit < 0 {
+		return nil, fmt.Errorf("%w: wip limit must not be negative", ErrInvalidTaskColumn)
+	}
+	transitions, err := workflow.sanitizeTransitions(req.AllowedTransitions, key)
+	if err != nil {
+		return nil, err
+	}
+
+	column := &tables.TaskColumnTable{
+		ProjectID:          workflow.projectID,
+		Key:                key,
+		Name:               name,
+		Color:              strings.TrimSpace(req.Color),
+		Category:           category,
+		OrderIndex:         workflow.nextOrderIndex(),
+		WIPLimit:           req.WIPLimit,
+		AllowedTransitions: transitions,
+	}
+	if err := dbCtx.Create(column).Error; err != nil {
+		return nil, err
+	}
+	return column, nil
+}
+
+// UpdateColumn applies partial updates to a column.
+func (s *TaskColumnService) UpdateColumn(ctx context.Context, id string, req *UpdateTaskColumnRequest) (*tables.TaskColumnTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	column, err := s.GetColumn(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return column, nil
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		if name := strings.TrimSpace(*req.Name); name != "" {
+			updates["name"] = name
+		}
+	}
+	if req.Color != nil {
+		updates["color"] = strings.TrimSpace(*req.Color)
+	}
+	if req.Category != nil {
+		category, err := normalizeTaskCategory(*req.Category)
+		if err != nil {
+			return nil, err
+		}
+		updates["category"] = category
+	}
+	if req.WIPLimit != nil {
+		if *req.WIPLimit < 0 {
+			return nil, fmt.Errorf("%w: wip limit must not be negative", ErrInvalidTaskColumn)
+		}
+		updates["wip_limit"] = *req.WIPLimit
+	}
+	if req.AllowedTransitions != nil {
+		workflow, err := loadTaskWorkflow(dbCtx, column.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		transitions, err := workflow.sanitizeTransitions(*req.AllowedTransitions, column.Key)
+		if err != nil {
+			return nil, err
+		}
+		updates["allowed_transitions"] = transitions
+	}
+
+	if len(updates) > 0 {
+		if err := dbCtx.Model(column).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return s.GetColumn(ctx, id)
+}
+
+// MoveColumn updates the display order of a column.
+func (s *TaskColumnService) MoveColumn(ctx context.Context, id string, orderIndex float64) (*tables.TaskColumnTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	column, err := s.GetColumn(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := dbCtx.Model(column).Update("order_index", orderIndex).Error; err != nil {
+		return nil, err
+	}
+	return s.GetColumn(ctx, id)
+}
+
+// DeleteColumn removes an empty column. The last column of a project cannot be removed.
+func (s *TaskColumnService) DeleteColumn(ctx context.Context, id string) error {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	column, err := s.GetColumn(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var taskCount int64
+	if err := dbCtx.
+		Model(&tables.TaskTable{}).
+		Where("project_id = ? AND status = ?", column.ProjectID, column.Key).
+		Count(&taskCount).Error; err != nil {
+		return err
+	}
+	if taskCount > 0 {
+		return fmt.Errorf("%w: column still holds %d tasks", ErrTaskColumnInUse, taskCount)
+	}
+
+	var columnCount int64
+	if err := dbCtx.
+		Model(&tables.TaskColumnTable{}).
+		Where("project_id = ?", column.ProjectID).
+		Count(&columnCount).Error; err != nil {
+		return err
+	}
+	if columnCount <= 1 {
+		return fmt.Errorf("%w: a project needs at least one column", ErrTaskColumnInUse)
+	}
+
+	return dbCtx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(column).Error; err != nil {
+			return err
+		}
+
+		// 同步移除其他列指向该列的流转，避免出现悬空的目标
+		var siblings []tables.TaskColumnTable
+		if err := tx.
+			Where("project_id = ? AND id <> ?", column.ProjectID, column.ID).
+			Find(&siblings).Error; err != nil {
+			return err
+		}
+		for _, sibling := range siblings {
+			transitions := tables.StringArray{}
+			for _, key := range sibling.AllowedTransitions {
+				if key != column.Key {
+					transitions = append(transitions, key)
+				}
+			}
+			if len(transitions) == len(sibling.AllowedTransitions) {
+				continue
+			}
+			if err := tx.Model(&sibling).Update("allowed_transitions", transitions).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *TaskColumnService) dbWithContext(ctx context.Context) (*gorm.DB, error) {
+	if db == nil {
+		return nil, ErrDBNotInitialized
+	}
+	return db.WithContext(ensureContext(ctx)), nil
+}
+
+// taskWorkflow is the in-memory view of a project's columns used for validation.
+type taskWorkflow struct {
+	projectID string
+	columns   []tables.TaskColumnTable
+	byKey     map[string]*tables.TaskColumnTable
+}
+
+// loadTaskWorkflow reads the project's columns, seeding the default workflow when none exist yet.
+func loadTaskWorkflow(dbCtx *gorm.DB, projectID string) (*taskWorkflow, error) {
+	projectID = strings.TrimSpace(projectID)
+	if projectID == "" {
+		return nil, fmt.Errorf("project id is required")
+	}
+
+	var columns []tables.TaskColumnTable
+	if err := dbCtx.
+		Where("project_id = ?", projectID).
+		Order("order_index ASC").
+		Find(&columns).Error; err != nil {
+		return nil, err
+	}
+
+	if len(columns) == 0 {
+		var project tables.ProjectTable
+		if err := dbCtx.First(&project, "id = ?", projectID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrProjectNotFound
+			}
+			return nil, err
+		}
+		seeded, err := seedDefaultTaskColumns(dbCtx, projectID)
+		if err != nil {
+			// 并发请求可能已完成初始化，重新读取一次
+			if reloadErr := dbCtx.
+				Where("project_id = ?", projectID).
+				Order("order_index ASC").
+				Find(&columns).Error; reloadErr != nil || len(columns) == 0 {
+				return nil, err
+			}
+		} else {
+			columns = seeded
+		}
+	}
+
+	workflow := &taskWorkflow{
+		projectID: projectID,
+		columns:   columns,
+		byKey:     make(map[string]*tables.TaskColumnTable, len(columns)),
+	}
+	for i := range workflow.columns {
+		workflow.byKey[workflow.columns[i].Key] = &workflow.columns[i]
+	}
+	return workflow, nil
+}
+
+func seedDefaultTaskColumns(dbCtx *gorm.DB, projectID string) ([]tables.TaskColumnTable, error) {
+	columns := make([]tables.TaskColumnTable, 0, len(defaultTaskColumns))
+	for i, column := range defaultTaskColumns {
+		column.ProjectID = projectID
+		column.OrderIndex = float64(i+1) * 1000
+		column.AllowedTransitions = tables.StringArray{}
+		columns = append(columns, column)
+	}
+	if err := dbCtx.Create(&columns).Error; err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+// migrateTaskColumns gives every existing project the default workflow so stored statuses stay valid.
+func migrateTaskColumns(dbCtx *gorm.DB) error {
+	var projectIDs []string
+	if err := dbCtx.
+		Model(&tables.ProjectTable{}).
+		Where("id NOT IN (?)", dbCtx.Model(&tables.TaskColumnTable{}).Select("project_id")).
+		Pluck("id", &projectIDs).Error; err != nil {
+		return err
+	}
+	for _, projectID := range projectIDs {
+		if _, err := seedDefaultTaskColumns(dbCtx, projectID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *taskWorkflow) column(key string) *tables.TaskColumnTable {
+	return w.byKey[key]
+}
+
+// defaultStatus picks the first todo column, falling back to the first column of the board.
+func (w *taskWorkflow) defaultStatus() string {
+	for _, column := range w.columns {
+		if column.Category == TaskCategoryTodo {
+			return column.Key
+		}
+	}
+	if len(w.columns) > 0 {
+		return w.columns[0].Key
+	}
+	return ""
+}
+
+// category returns the semantic category of a status, treating unknown statuses as their own name.
+func (w *taskWorkflow) category(status string) string {
+	if column := w.column(status); column != nil {
+		return column.Category
+	}
+	return status
+}
+
+func (w *taskWorkflow) canTransition(from, to string) bool {
+	if from == to {
+		return true
+	}
+	source := w.column(from)
+	if source == nil || len(source.AllowedTransitions) == 0 {
+		return true
+	}
+	for _, candidate := range source.AllowedTransitions {
+		if candidate == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *taskWorkflow) nextOrderIndex() float64 {
+	var maxOrder float64
+	for _, column := range w.columns {
+		if column.OrderIndex > maxOrder {
+			maxOrder = column.OrderIndex
+		}
+	}
+	return maxOrder + 1000
+}
+
+func (w *taskWorkflow) sanitizeTransitions(transitions tables.StringArray, self string) (tables.StringArray, error) {
+	result := tables.StringArray{}
+	for _, key := range sanitizeTags(transitions) {
+		if key == self {
+			continue
+		}
+		if w.column(key) == nil {
+			return nil, fmt.Errorf("%w: unknown transition target %q", ErrInvalidTaskColumn, key)
+		}
+		result = append(result, key)
+	}
+	return result, nil
+}
+
+// ensureCapacity enforces the WIP limit of the target column, ignoring the task being moved.
+func (w *taskWorkflow) ensureCapacity(dbCtx *gorm.DB, status, excludeTaskID string) error {
+	column := w.column(status)
+	if column == nil || column.WIPLimit <= 0 {
+		return nil
+	}
+
+	query := dbCtx.
+		Model(&tables.TaskTable{}).
+		Where("project_id = ? AND status = ?", w.projectID, status)
+	if excludeTaskID != "" {
+		query = query.Where("id <> ?", excludeTaskID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count >= int64(column.WIPLimit) {
+		return fmt.Errorf("%w: %s allows %d tasks", ErrTaskWIPLimitReached, column.Name, column.WIPLimit)
+	}
+	return nil
+}
+
+func normalizeTaskCategory(category string) (string, error) {
+	value := strings.TrimSpace(category)
+	if value == "" {
+		value = TaskCategoryInProgress
+	}
+	if _, ok := taskCategorySet[value]; !ok {
+		return "", fmt.Errorf("%w: unknown category %q", ErrInvalidTaskColumn, value)
+	}
+	return value, nil
+}
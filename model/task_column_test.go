@@ -0,0 +1,202 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"code-kanban/model/tables"
+)
+
+func TestTaskColumnWorkflow(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	columns := &TaskColumnService{}
+	tasks := &TaskService{}
+
+	defaults, err := columns.ListColumns(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("ListColumns returned error: %v", err)
+	}
+	if len(defaults) != 4 || defaults[0].Key != "todo" || defaults[3].Key != "archived" {
+		t.Fatalf("expected default columns, got %+v", defaults)
+	}
+
+	review, err := columns.CreateColumn(ctx, &CreateTaskColumnRequest{
+		ProjectID:          project.ID,
+		Key:                "review",
+		Name:               "Review",
+		Category:           TaskCategoryInProgress,
+		WIPLimit:           1,
+		AllowedTransitions: tables.StringArray{"done", "in_progress"},
+	})
+	if err != nil {
+		t.Fatalf("CreateColumn returned error: %v", err)
+	}
+	if _, err := columns.CreateColumn(ctx, &CreateTaskColumnRequest{
+		ProjectID: project.ID,
+		Key:       "blocked",
+		Name:      "Blocked",
+	}); err != nil {
+		t.Fatalf("CreateColumn blocked returned error: %v", err)
+	}
+	if _, err := columns.CreateColumn(ctx, &CreateTaskColumnRequest{
+		ProjectID: project.ID,
+		Key:       "review",
+	}); !errors.Is(err, ErrTaskColumnExists) {
+		t.Fatalf("expected ErrTaskColumnExists, got %v", err)
+	}
+
+	first, err := tasks.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "first"})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	if first.Status != "todo" {
+		t.Fatalf("expected default status todo, got %s", first.Status)
+	}
+	second, err := tasks.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "second"})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+
+	if _, err := tasks.MoveTask(ctx, first.ID, &MoveTaskRequest{Status: "unknown"}); !errors.Is(err, ErrInvalidTaskStatus) {
+		t.Fatalf("expected ErrInvalidTaskStatus, got %v", err)
+	}
+	if _, err := tasks.MoveTask(ctx, first.ID, &MoveTaskRequest{Status: "review"}); err != nil {
+		t.Fatalf("MoveTask to review returned error: %v", err)
+	}
+	if _, err := tasks.MoveTask(ctx, second.ID, &MoveTaskRequest{Status: "review"}); !errors.Is(err, ErrTaskWIPLimitReached) {
+		t.Fatalf("expected ErrTaskWIPLimitReached, got %v", err)
+	}
+	if _, err := tasks.MoveTask(ctx, first.ID, &MoveTaskRequest{Status: "blocked"}); !errors.Is(err, ErrTaskTransitionNotAllowed) {
+		t.Fatalf("expected ErrTaskTransitionNotAllowed, got %v", err)
+	}
+	if _, err := tasks.MoveTask(ctx, first.ID, &MoveTaskRequest{Status: "done"}); err != nil {
+		t.Fatalf("MoveTask to done returned error: %v", err)
+	}
+
+	if err := columns.DeleteColumn(ctx, review.ID); err != nil {
+		t.Fatalf("DeleteColumn returned error: %v", err)
+	}
+	defaultsAfter, err := columns.ListColumns(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("ListColumns returned error: %v", err)
+	}
+	for _, column := range defaultsAfter {
+		if column.Key == "todo" {
+			if err := columns.DeleteColumn(ctx, column.ID); !errors.Is(err, ErrTaskColumnInUse) {
+				t.Fatalf("expected ErrTaskColumnInUse, got %v", err)
+			}
+		}
+	}
+}
+
+func TestTaskCountByWorktreeUsesColumnCategories(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	worktree := seedWorktree(t, project.ID, "feature/count")
+	columns := &TaskColumnService{}
+	tasks := &TaskService{}
+
+	if _, err := columns.CreateColumn(ctx, &CreateTaskColumnRequest{
+		ProjectID: project.ID,
+		Key:       "shipped",
+		Category:  TaskCategoryDone,
+	}); err != nil {
+		t.Fatalf("CreateColumn returned error: %v", err)
+	}
+	for _, status := range []string{"todo", "shipped", "done"} {
+		if _, err := tasks.CreateTask(ctx, &CreateTaskRequest{
+			ProjectID:  project.ID,
+			WorktreeID: &worktree.ID,
+			Title:      status,
+			Status:     status,
+		}); err != nil {
+			t.Fatalf("CreateTask(%s) returned error: %v", status, err)
+		}
+	}
+
+	q, err := resolveQueries(nil)
+	if err != nil {
+		t.Fatalf("resolveQueries: %v", err)
+	}
+	count, err := q.TaskCountByWorktree(ctx, &worktree.ID)
+	if err != nil {
+		t.Fatalf("TaskCountByWorktree returned error: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 active task, got %d", count)
+	}
+}
+
+func TestTaskColumnUpdateAndDeleteKeepWorkflowConsistent(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	columns := &TaskColumnService{}
+	tasks := &TaskService{}
+
+	review, err := columns.CreateColumn(ctx, &CreateTaskColumnRequest{
+		ProjectID: project.ID,
+		Key:       "review",
+		Category:  TaskCategoryInProgress,
+	})
+	if err != nil {
+		t.Fatalf("CreateColumn returned error: %v", err)
+	}
+	qa, err := columns.CreateColumn(ctx, &CreateTaskColumnRequest{
+		ProjectID:          project.ID,
+		Key:                "qa",
+		Category:           TaskCategoryInProgress,
+		AllowedTransitions: tables.StringArray{"review", "done"},
+	})
+	if err != nil {
+		t.Fatalf("CreateColumn returned error: %v", err)
+	}
+
+	limit := 1
+	updated, err := columns.UpdateColumn(ctx, review.ID, &UpdateTaskColumnRequest{WIPLimit: &limit})
+	if err != nil {
+		t.Fatalf("UpdateColumn returned error: %v", err)
+	}
+	if updated.WIPLimit != 1 {
+		t.Fatalf("expected wip limit 1, got %d", updated.WIPLimit)
+	}
+
+	var taskIDs []string
+	for _, title := range []string{"first", "second"} {
+		task, err := tasks.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: title})
+		if err != nil {
+			t.Fatalf("CreateTask returned error: %v", err)
+		}
+		taskIDs = append(taskIDs, task.ID)
+	}
+	if _, err := tasks.MoveTask(ctx, taskIDs[0], &MoveTaskRequest{Status: "review"}); err != nil {
+		t.Fatalf("MoveTask to review returned error: %v", err)
+	}
+	if _, err := tasks.MoveTask(ctx, taskIDs[1], &MoveTaskRequest{Status: "review"}); !errors.Is(err, ErrTaskWIPLimitReached) {
+		t.Fatalf("expected ErrTaskWIPLimitReached after update, got %v", err)
+	}
+	if _, err := tasks.MoveTask(ctx, taskIDs[0], &MoveTaskRequest{Status: "todo"}); err != nil {
+		t.Fatalf("MoveTask back to todo returned error: %v", err)
+	}
+
+	if err := columns.DeleteColumn(ctx, review.ID); err != nil {
+		t.Fatalf("DeleteColumn returned error: %v", err)
+	}
+	qa, err = columns.GetColumn(ctx, qa.ID)
+	if err != nil {
+		t.Fatalf("GetColumn returned error: %v", err)
+	}
+	if len(qa.AllowedTransitions) != 1 || qa.AllowedTransitions[0] != "done" {
+		t.Fatalf("expected deleted column to be dropped from transitions, got %v", qa.AllowedTransitions)
+	}
+}
@@ -3,63 +3,133 @@ package model
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"code-kanban/model/tables"
+	"code-kanban/utils/ai_assistant"
 
 	"gorm.io/gorm"
 )
 
+const (
+	// TaskCommentKindText is a plain markdown comment.
+	TaskCommentKindText = "text"
+	// TaskCommentKindTerminalOutput carries an excerpt of terminal output.
+	TaskCommentKindTerminalOutput = "terminal_output"
+	// TaskCommentKindDiff carries a unified diff.
+	TaskCommentKindDiff = "diff"
+
+	// maxCommentExcerptBytes bounds structured excerpts; longer ones keep their tail.
+	maxCommentExcerptBytes = 64 * 1024
+)
+
 var (
 	// ErrTaskCommentNotFound indicates the requested task comment does not exist.
 	ErrTaskCommentNotFound = errors.New("task comment not found")
+	// ErrInvalidTaskComment indicates the comment payload is invalid.
+	ErrInvalidTaskComment = errors.New("invalid task comment")
 )
 
+// PostCommentRequest describes a new comment, optionally a reply or a structured excerpt.
+type PostCommentRequest struct {
+	TaskID        string
+	ParentID      *string
+	Content       string
+	Kind          string
+	Excerpt       string
+	ExcerptSource string
+}
+
 // TaskCommentService coordinates CRUD operations for task comments.
 type TaskCommentService struct {
-	taskSvc *TaskService
+	taskSvc         *TaskService
+	notificationSvc *NotificationService
 }
 
 // NewTaskCommentService constructs a task comment service with a task dependency.
 func NewTaskCommentService() *TaskCommentService {
-	return &TaskCommentService{taskSvc: &TaskService{}}
+	return &TaskCommentService{taskSvc: &TaskService{}, notificationSvc: NewNotificationService()}
 }
 
-// CreateComment inserts a comment for the given task.
+// CreateComment inserts a plain comment for the given task.
 func (s *TaskCommentService) CreateComment(ctx context.Context, taskID, content string) (*tables.TaskCommentTable, error) {
+	return s.PostComment(ctx, &PostCommentRequest{TaskID: taskID, Content: content})
+}
+
+// PostComment inserts a comment authored by the context actor. Replies are attached
+// to the root of the thread, and @mentions of known users create notifications.
+func (s *TaskCommentService) PostComment(ctx context.Context, req *PostCommentRequest) (*tables.TaskCommentTable, error) {
 	dbCtx, err := s.dbWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if req == nil {
+		return nil, fmt.Errorf("%w: request is required", ErrInvalidTaskComment)
+	}
 
-	taskID = strings.TrimSpace(taskID)
+	taskID := strings.TrimSpace(req.TaskID)
 	if taskID == "" {
 		return nil, ErrTaskNotFound
 	}
 
-	body := strings.TrimSpace(content)
-	if body == "" {
-		return nil, errors.New("comment content is required")
+	kind, excerpt, err := normalizeCommentExcerpt(req.Kind, req.Excerpt)
+	if err != nil {
+		return nil, err
+	}
+	body := strings.TrimSpace(req.Content)
+	if body == "" && excerpt == "" {
+		return nil, fmt.Errorf("%w: comment content is required", ErrInvalidTaskComment)
 	}
 
+	var task *tables.TaskTable
 	if s.taskSvc != nil {
-		if _, err := s.taskSvc.GetTask(ctx, taskID); err != nil {
+		if task, err = s.taskSvc.GetTask(ctx, taskID); err != nil {
 			return nil, err
 		}
 	}
 
 	comment := &tables.TaskCommentTable{
-		TaskID:  taskID,
-		Content: body,
+		TaskID:        taskID,
+		Author:        ActorFromContext(ctx),
+		Kind:          kind,
+		Content:       body,
+		Excerpt:       excerpt,
+		ExcerptSource: strings.TrimSpace(req.ExcerptSource),
+	}
+	if req.ParentID != nil && strings.TrimSpace(*req.ParentID) != "" {
+		rootID, err := s.threadRoot(dbCtx, taskID, strings.TrimSpace(*req.ParentID))
+		if err != nil {
+			return nil, err
+		}
+		comment.ParentID = &rootID
 	}
 
-	if err := dbCtx.Create(comment).Error; err != nil {
+	err = dbCtx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		if task == nil {
+			return nil
+		}
+		event := newTaskEvent(ctx, task, TaskEventCommentAdded)
+		event.CommentID = &comment.ID
+		event.NewValue = body
+		if err := recordTaskEvents(tx, []tables.TaskEventTable{event}); err != nil {
+			return err
+		}
+		return s.notifyMentions(tx, task, comment, nil)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return comment, nil
 }
 
-// ListComments fetches comments for a task ordered by creation time.
+// ListComments fetches comments for a task ordered by creation time. Replies carry
+// the id of their thread root in ParentID.
 func (s *TaskCommentService) ListComments(ctx context.Context, taskID string) ([]tables.TaskCommentTable, error) {
 	dbCtx, err := s.dbWithContext(ctx)
 	if err != nil {
@@ -76,21 +146,132 @@ func (s *TaskCommentService) ListComments(ctx context.Context, taskID string) ([
 	return comments, nil
 }
 
-// DeleteComment removes a comment by identifier.
+// UpdateComment replaces the content of a comment and keeps the previous version
+// as a revision. Users mentioned for the first time are notified.
+func (s *TaskCommentService) UpdateComment(ctx context.Context, id, content string, excerpt *string) (*tables.TaskCommentTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var comment tables.TaskCommentTable
+	if err := dbCtx.Preload("Task").First(&comment, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskCommentNotFound
+		}
+		return nil, err
+	}
+
+	body := strings.TrimSpace(content)
+	newExcerpt := comment.Excerpt
+	if excerpt != nil {
+		if _, newExcerpt, err = normalizeCommentExcerpt(comment.Kind, *excerpt); err != nil {
+			return nil, err
+		}
+	}
+	if body == "" && newExcerpt == "" {
+		return nil, fmt.Errorf("%w: comment content is required", ErrInvalidTaskComment)
+	}
+	if body == comment.Content && newExcerpt == comment.Excerpt {
+		return &comment, nil
+	}
+
+	previous := comment
+	editor := ActorFromContext(ctx)
+	now := time.Now()
+	err = dbCtx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&tables.TaskCommentRevisionTable{
+			CommentID: comment.ID,
+			Content:   previous.Content,
+			Excerpt:   previous.Excerpt,
+			Editor:    editor,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&tables.TaskCommentTable{}).
+			Where("id = ?", comment.ID).
+			Updates(map[string]interface{}{
+				"content":    body,
+				"excerpt":    newExcerpt,
+				"edited_at":  now,
+				"edit_count": gorm.Expr("edit_count + 1"),
+			}).Error; err != nil {
+			return err
+		}
+		comment.Content = body
+		comment.Excerpt = newExcerpt
+		comment.EditedAt = &now
+		comment.EditCount++
+		if comment.Task == nil {
+			return nil
+		}
+		event := newTaskEvent(ctx, comment.Task, TaskEventCommentEdited)
+		event.CommentID = &comment.ID
+		event.OldValue = previous.Content
+		event.NewValue = body
+		if err := recordTaskEvents(tx, []tables.TaskEventTable{event}); err != nil {
+			return err
+		}
+		return s.notifyMentions(tx, comment.Task, &comment, parseMentions(previous.Content))
+	})
+	if err != nil {
+		return nil, err
+	}
+	comment.Task = nil
+	return &comment, nil
+}
+
+// ListRevisions returns the previous versions of a comment, oldest first.
+func (s *TaskCommentService) ListRevisions(ctx context.Context, id string) ([]tables.TaskCommentRevisionTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var count int64
+	if err := dbCtx.Model(&tables.TaskCommentTable{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrTaskCommentNotFound
+	}
+
+	var revisions []tables.TaskCommentRevisionTable
+	if err := dbCtx.
+		Where("comment_id = ?", id).
+		Order("created_at ASC").
+		Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// DeleteComment removes a comment by identifier together with its replies.
 func (s *TaskCommentService) DeleteComment(ctx context.Context, id string) error {
 	dbCtx, err := s.dbWithContext(ctx)
 	if err != nil {
 		return err
 	}
 
-	result := dbCtx.Delete(&tables.TaskCommentTable{}, "id = ?", id)
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected == 0 {
-		return ErrTaskCommentNotFound
+	var comment tables.TaskCommentTable
+	if err := dbCtx.Preload("Task").First(&comment, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTaskCommentNotFound
+		}
+		return err
 	}
-	return nil
+
+	return dbCtx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&tables.TaskCommentTable{}, "id = ? OR parent_id = ?", id, id).Error; err != nil {
+			return err
+		}
+		if comment.Task == nil {
+			return nil
+		}
+		event := newTaskEvent(ctx, comment.Task, TaskEventCommentDeleted)
+		event.CommentID = &comment.ID
+		event.OldValue = comment.Content
+		return recordTaskEvents(tx, []tables.TaskEventTable{event})
+	})
 }
 
 func (s *TaskCommentService) dbWithContext(ctx context.Context) (*gorm.DB, error) {
@@ -99,3 +280,81 @@ func (s *TaskCommentService) dbWithContext(ctx context.Context) (*gorm.DB, error
 	}
 	return db.WithContext(ensureContext(ctx)), nil
 }
+
+// threadRoot resolves the comment a reply should hang off. Threads are one level
+// deep, so replying to a reply attaches to the same root.
+func (s *TaskCommentService) threadRoot(dbCtx *gorm.DB, taskID, parentID string) (string, error) {
+	var parent tables.TaskCommentTable
+	if err := dbCtx.Select("id", "task_id", "parent_id").First(&parent, "id = ?", parentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrTaskCommentNotFound
+		}
+		return "", err
+	}
+	if parent.TaskID != taskID {
+		return "", fmt.Errorf("%w: parent comment belongs to another task", ErrInvalidTaskComment)
+	}
+	if parent.ParentID != nil {
+		return *parent.ParentID, nil
+	}
+	return parent.ID, nil
+}
+
+// notifyMentions creates a mention notification for every user mentioned in the
+// comment, skipping the author and the names listed in already.
+func (s *TaskCommentService) notifyMentions(tx *gorm.DB, task *tables.TaskTable, comment *tables.TaskCommentTable, already []string) error {
+	if s.notificationSvc == nil {
+		return nil
+	}
+	mentions := parseMentions(comment.Content)
+	if len(mentions) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(already))
+	for _, name := range already {
+		seen[strings.ToLower(name)] = struct{}{}
+	}
+	fresh := make([]string, 0, len(mentions))
+	for _, name := range mentions {
+		if _, ok := seen[strings.ToLower(name)]; !ok {
+			fresh = append(fresh, name)
+		}
+	}
+	return s.notificationSvc.notifyMentioned(tx, task, comment, fresh)
+}
+
+// normalizeCommentExcerpt validates the kind and cleans up the excerpt it carries.
+func normalizeCommentExcerpt(kind, excerpt string) (string, string, error) {
+	kind = strings.TrimSpace(kind)
+	if kind == "" {
+		kind = TaskCommentKindText
+	}
+	switch kind {
+	case TaskCommentKindText:
+		if strings.TrimSpace(excerpt) != "" {
+			return "", "", fmt.Errorf("%w: an excerpt requires kind terminal_output or diff", ErrInvalidTaskComment)
+		}
+		return kind, "", nil
+	case TaskCommentKindTerminalOutput:
+		excerpt = strings.Trim(ai_assistant.StripANSI(excerpt), "\n")
+	case TaskCommentKindDiff:
+		excerpt = strings.Trim(strings.ReplaceAll(excerpt, "\r\n", "\n"), "\n")
+	default:
+		return "", "", fmt.Errorf("%w: unknown comment kind %q", ErrInvalidTaskComment, kind)
+	}
+	if strings.TrimSpace(excerpt) == "" {
+		return "", "", fmt.Errorf("%w: %s comments require an excerpt", ErrInvalidTaskComment, kind)
+	}
+	if len(excerpt) > maxCommentExcerptBytes {
+		// 保留结尾部分：终端输出和 diff 的关键信息通常在最后
+		cut := len(excerpt) - maxCommentExcerptBytes
+		if newline := strings.IndexByte(excerpt[cut:], '\n'); newline >= 0 {
+			cut += newline + 1
+		}
+		for cut < len(excerpt) && !utf8.RuneStart(excerpt[cut]) {
+			cut++
+		}
+		excerpt = excerpt[cut:]
+	}
+	return kind, excerpt, nil
+}
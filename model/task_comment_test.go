@@ -0,0 +1,187 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"code-kanban/model/tables"
+)
+
+func TestTaskCommentThreadsAndEdits(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	project := seedProject(t)
+	task, err := (&TaskService{}).CreateTask(context.Background(), &CreateTaskRequest{ProjectID: project.ID, Title: "fix login redirect"})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	svc := NewTaskCommentService()
+	alice := WithActor(context.Background(), "user:alice")
+	agent := WithActor(context.Background(), "agent:claude-code")
+
+	root, err := svc.CreateComment(alice, task.ID, "Can someone reproduce this?")
+	if err != nil {
+		t.Fatalf("CreateComment returned error: %v", err)
+	}
+	if root.Author != "user:alice" || root.Kind != TaskCommentKindText {
+		t.Fatalf("unexpected comment %+v", root)
+	}
+
+	reply, err := svc.PostComment(agent, &PostCommentRequest{TaskID: task.ID, ParentID: &root.ID, Content: "Reproduced, see output"})
+	if err != nil {
+		t.Fatalf("PostComment returned error: %v", err)
+	}
+	nested, err := svc.PostComment(alice, &PostCommentRequest{TaskID: task.ID, ParentID: &reply.ID, Content: "thanks"})
+	if err != nil {
+		t.Fatalf("PostComment returned error: %v", err)
+	}
+	if reply.ParentID == nil || *reply.ParentID != root.ID || nested.ParentID == nil || *nested.ParentID != root.ID {
+		t.Fatalf("expected replies to attach to the thread root")
+	}
+
+	other, err := (&TaskService{}).CreateTask(context.Background(), &CreateTaskRequest{ProjectID: project.ID, Title: "other"})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	if _, err := svc.PostComment(alice, &PostCommentRequest{TaskID: other.ID, ParentID: &root.ID, Content: "x"}); !errors.Is(err, ErrInvalidTaskComment) {
+		t.Fatalf("expected cross-task reply to be rejected, got %v", err)
+	}
+
+	edited, err := svc.UpdateComment(WithActor(context.Background(), "user:bob"), root.ID, "Can someone reproduce this on Safari?", nil)
+	if err != nil {
+		t.Fatalf("UpdateComment returned error: %v", err)
+	}
+	if edited.EditCount != 1 || edited.EditedAt == nil || edited.Author != "user:alice" {
+		t.Fatalf("unexpected edited comment %+v", edited)
+	}
+	revisions, err := svc.ListRevisions(context.Background(), root.ID)
+	if err != nil {
+		t.Fatalf("ListRevisions returned error: %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].Content != "Can someone reproduce this?" || revisions[0].Editor != "user:bob" {
+		t.Fatalf("unexpected revisions %+v", revisions)
+	}
+
+	if err := svc.DeleteComment(context.Background(), root.ID); err != nil {
+		t.Fatalf("DeleteComment returned error: %v", err)
+	}
+	remaining, err := svc.ListComments(context.Background(), task.ID)
+	if err != nil {
+		t.Fatalf("ListComments returned error: %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Fatalf("expected the whole thread to be deleted, %d comments left", len(remaining))
+	}
+}
+
+func TestTaskCommentStructuredExcerpt(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	project := seedProject(t)
+	task, err := (&TaskService{}).CreateTask(context.Background(), &CreateTaskRequest{ProjectID: project.ID, Title: "flaky test"})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	svc := NewTaskCommentService()
+	ctx := context.Background()
+
+	comment, err := svc.PostComment(ctx, &PostCommentRequest{
+		TaskID:        task.ID,
+		Kind:          TaskCommentKindTerminalOutput,
+		Excerpt:       "\x1b[31mFAIL\x1b[0m TestLogin\n",
+		ExcerptSource: "go test ./...",
+	})
+	if err != nil {
+		t.Fatalf("PostComment returned error: %v", err)
+	}
+	if comment.Excerpt != "FAIL TestLogin" {
+		t.Fatalf("expected ANSI codes to be stripped, got %q", comment.Excerpt)
+	}
+
+	long := strings.Repeat("+added line\n", maxCommentExcerptBytes/8)
+	diff, err := svc.PostComment(ctx, &PostCommentRequest{TaskID: task.ID, Kind: TaskCommentKindDiff, Excerpt: long, Content: "proposed fix"})
+	if err != nil {
+		t.Fatalf("PostComment returned error: %v", err)
+	}
+	if len(diff.Excerpt) > maxCommentExcerptBytes || !strings.HasPrefix(diff.Excerpt, "+added line") {
+		t.Fatalf("expected the diff to be truncated on a line boundary, got %d bytes", len(diff.Excerpt))
+	}
+
+	for _, req := range []*PostCommentRequest{
+		{TaskID: task.ID, Kind: TaskCommentKindDiff},
+		{TaskID: task.ID, Content: "x", Excerpt: "loose excerpt"},
+		{TaskID: task.ID, Kind: "screenshot", Excerpt: "x"},
+		{TaskID: task.ID, Content: "   "},
+	} {
+		if _, err := svc.PostComment(ctx, req); !errors.Is(err, ErrInvalidTaskComment) {
+			t.Fatalf("expected %+v to be rejected, got %v", req, err)
+		}
+	}
+}
+
+func TestTaskCommentMentionsCreateNotifications(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	users := map[string]*tables.UserTable{}
+	for _, name := range []string{"alice", "Bob", "carol"} {
+		user := &tables.UserTable{Username: name, Password: "x", Salt: "x"}
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		users[name] = user
+	}
+
+	project := seedProject(t)
+	task, err := (&TaskService{}).CreateTask(context.Background(), &CreateTaskRequest{ProjectID: project.ID, Title: "release checklist"})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	svc := NewTaskCommentService()
+	notifications := NewNotificationService()
+	ctx := WithActor(context.Background(), "user:"+users["alice"].ID)
+
+	comment, err := svc.CreateComment(ctx, task.ID, "@bob @alice please review, mail ops@example.com, not `@carol` or @nobody.")
+	if err != nil {
+		t.Fatalf("CreateComment returned error: %v", err)
+	}
+
+	bobInbox, err := notifications.ListNotifications(context.Background(), users["Bob"].ID, true)
+	if err != nil {
+		t.Fatalf("ListNotifications returned error: %v", err)
+	}
+	if len(bobInbox) != 1 || bobInbox[0].CommentID == nil || *bobInbox[0].CommentID != comment.ID || bobInbox[0].Title != "release checklist" {
+		t.Fatalf("unexpected notifications for bob %+v", bobInbox)
+	}
+	for _, name := range []string{"alice", "carol"} {
+		inbox, _ := notifications.ListNotifications(context.Background(), users[name].ID, false)
+		if len(inbox) != 0 {
+			t.Fatalf("expected no notification for %s, got %d", name, len(inbox))
+		}
+	}
+
+	// Editing notifies only users that were not mentioned before.
+	if _, err := svc.UpdateComment(ctx, comment.ID, "@bob @carol please review", nil); err != nil {
+		t.Fatalf("UpdateComment returned error: %v", err)
+	}
+	if inbox, _ := notifications.ListNotifications(context.Background(), users["Bob"].ID, false); len(inbox) != 1 {
+		t.Fatalf("expected bob not to be notified twice, got %d", len(inbox))
+	}
+	if inbox, _ := notifications.ListNotifications(context.Background(), users["carol"].ID, false); len(inbox) != 1 {
+		t.Fatalf("expected carol to be notified after the edit, got %d", len(inbox))
+	}
+
+	if err := notifications.MarkRead(context.Background(), users["Bob"].ID, bobInbox[0].ID); err != nil {
+		t.Fatalf("MarkRead returned error: %v", err)
+	}
+	if inbox, _ := notifications.ListNotifications(context.Background(), users["Bob"].ID, true); len(inbox) != 0 {
+		t.Fatalf("expected no unread notifications, got %d", len(inbox))
+	}
+	if err := notifications.MarkRead(context.Background(), users["carol"].ID, bobInbox[0].ID); !errors.Is(err, ErrNotificationNotFound) {
+		t.Fatalf("expected other users not to mark bob's notification, got %v", err)
+	}
+}
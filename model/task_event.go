@@ -0,0 +1,289 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code-kanban/model/tables"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// TaskEventCreated records task creation.
+	TaskEventCreated = "created"
+	// TaskEventFieldChanged records a change of a plain field such as title or priority.
+	TaskEventFieldChanged = "field_changed"
+	// TaskEventStatusChanged records a move between columns.
+	TaskEventStatusChanged = "status_changed"
+	// TaskEventWorktreeBound records attaching a worktree (and its branch) to the task.
+	TaskEventWorktreeBound = "worktree_bound"
+	// TaskEventWorktreeUnbound records detaching the worktree from the task.
+	TaskEventWorktreeUnbound = "worktree_unbound"
+	// TaskEventCommentAdded records a new comment.
+	TaskEventCommentAdded = "comment_added"
+	// TaskEventCommentEdited records an edit of an existing comment.
+	TaskEventCommentEdited = "comment_edited"
+	// TaskEventCommentDeleted records a removed comment.
+	TaskEventCommentDeleted = "comment_deleted"
+	// TaskEventDeleted records task deletion.
+	TaskEventDeleted = "deleted"
+	// TaskEventOverdue records the scheduler noticing that the due date has passed.
+	TaskEventOverdue = "overdue"
+
+	// TaskActorUser is the actor used for changes made through the API without a user id.
+	TaskActorUser = "user"
+	// TaskActorSystem is the actor used when no actor was attached to the context.
+	TaskActorSystem = "system"
+)
+
+// taskEventFields lists the columns whose changes are tracked as field_changed events.
+// order_index 和 branch_name 分别随拖拽和 worktree 绑定变化，不单独记录
+var taskEventFields = map[string]func(*tables.TaskTable) interface{}{
+	"title":        func(t *tables.TaskTable) interface{} { return t.Title },
+	"description":  func(t *tables.TaskTable) interface{} { return t.Description },
+	"priority":     func(t *tables.TaskTable) interface{} { return t.Priority },
+	"tags":         func(t *tables.TaskTable) interface{} { return t.Tags },
+	"due_date":     func(t *tables.TaskTable) interface{} { return t.DueDate },
+	"completed_at": func(t *tables.TaskTable) interface{} { return t.CompletedAt },
+
+	"estimate_minutes": func(t *tables.TaskTable) interface{} { return t.EstimateMinutes },
+	"estimate_points":  func(t *tables.TaskTable) interface{} { return t.EstimatePoints },
+}
+
+type taskActorKey struct{}
+
+// WithActor attaches the actor responsible for subsequent task changes to ctx.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ensureContext(ctx), taskActorKey{}, strings.TrimSpace(actor))
+}
+
+// ActorFromContext returns the actor attached by WithActor, or TaskActorSystem.
+func ActorFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if actor, ok := ctx.Value(taskActorKey{}).(string); ok && actor != "" {
+			return actor
+		}
+	}
+	return TaskActorSystem
+}
+
+// TaskTimelineEntry is one item of a task timeline: either an event or a comment.
+type TaskTimelineEntry struct {
+	Kind    string                   `json:"kind"` // event 或 comment
+	At      time.Time                `json:"at"`
+	Event   *tables.TaskEventTable   `json:"event,omitempty"`
+	Comment *tables.TaskCommentTable `json:"comment,omitempty"`
+}
+
+// TaskEventService exposes the change history of tasks.
+type TaskEventService struct {
+	taskSvc *TaskService
+}
+
+// NewTaskEventService constructs a task event service with a task dependency.
+func NewTaskEventService() *TaskEventService {
+	return &TaskEventService{taskSvc: &TaskService{}}
+}
+
+// ListEvents returns the events of a task in chronological order.
+func (s *TaskEventService) ListEvents(ctx context.Context, taskID string) ([]tables.TaskEventTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.taskSvc.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	var events []tables.TaskEventTable
+	if err := dbCtx.
+		Where("task_id = ?", taskID).
+		Order("created_at ASC").
+		Order("rowid ASC").
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Timeline merges task events with the current comments. Comment creation is shown
+// through the comment itself, so comment_added events are folded into it.
+func (s *TaskEventService) Timeline(ctx context.Context, taskID string) ([]TaskTimelineEntry, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.ListEvents(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	var comments []tables.TaskCommentTable
+	if err := dbCtx.
+		Where("task_id = ?", taskID).
+		Order("created_at ASC").
+		Find(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]TaskTimelineEntry, 0, len(events)+len(comments))
+	for i := range events {
+		if events[i].Type == TaskEventCommentAdded {
+			continue
+		}
+		entries = append(entries, TaskTimelineEntry{Kind: "event", At: events[i].CreatedAt, Event: &events[i]})
+	}
+	for i := range comments {
+		entries = append(entries, TaskTimelineEntry{Kind: "comment", At: comments[i].CreatedAt, Comment: &comments[i]})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].At.Before(entries[j].At) })
+	return entries, nil
+}
+
+func (s *TaskEventService) dbWithContext(ctx context.Context) (*gorm.DB, error) {
+	if db == nil {
+		return nil, ErrDBNotInitialized
+	}
+	return db.WithContext(ensureContext(ctx)), nil
+}
+
+// newTaskEvent builds an event carrying the task's current branch and actor.
+func newTaskEvent(ctx context.Context, task *tables.TaskTable, eventType string) tables.TaskEventTable {
+	return tables.TaskEventTable{
+		TaskID:     task.ID,
+		ProjectID:  task.ProjectID,
+		Type:       eventType,
+		Actor:      ActorFromContext(ctx),
+		BranchName: task.BranchName,
+		WorktreeID: task.WorktreeID,
+	}
+}
+
+// diffTaskEvents derives events from an UpdateTask call by comparing the stored task with the updates.
+func diffTaskEvents(ctx context.Context, before *tables.TaskTable, updates map[string]interface{}) []tables.TaskEventTable {
+	events := make([]tables.TaskEventTable, 0)
+
+	after := *before
+	if value, ok := updates["branch_name"]; ok {
+		after.BranchName = formatEventValue(value)
+	}
+	if value, ok := updates["worktree_id"]; ok {
+		after.WorktreeID = nil
+		if id := formatEventValue(value); id != "" {
+			after.WorktreeID = &id
+		}
+	}
+
+	if value, ok := updates["status"]; ok {
+		if status := formatEventValue(value); status != before.Status {
+			event := newTaskEvent(ctx, &after, TaskEventStatusChanged)
+			event.Field = "status"
+			event.OldValue = before.Status
+			event.NewValue = status
+			events = append(events, event)
+		}
+	}
+
+	if _, ok := updates["worktree_id"]; ok {
+		oldID := formatEventValue(before.WorktreeID)
+		newID := formatEventValue(after.WorktreeID)
+		if oldID != newID {
+			if oldID != "" {
+				// 解绑事件保留原分支，便于追溯任务当时挂在哪个分支上
+				event := newTaskEvent(ctx, before, TaskEventWorktreeUnbound)
+				event.Field = "worktree_id"
+				event.OldValue = oldID
+				events = append(events, event)
+			}
+			if newID != "" {
+				event := newTaskEvent(ctx, &after, TaskEventWorktreeBound)
+				event.Field = "worktree_id"
+				event.NewValue = newID
+				events = append(events, event)
+			}
+		}
+	}
+
+	fields := make([]string, 0, len(updates))
+	for field := range updates {
+		if _, tracked := taskEventFields[field]; tracked {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		oldValue := formatEventValue(taskEventFields[field](before))
+		newValue := formatEventValue(updates[field])
+		if oldValue == newValue {
+			continue
+		}
+		event := newTaskEvent(ctx, &after, TaskEventFieldChanged)
+		event.Field = field
+		event.OldValue = oldValue
+		event.NewValue = newValue
+		events = append(events, event)
+	}
+	return events
+}
+
+func recordTaskEvents(dbCtx *gorm.DB, events []tables.TaskEventTable) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return dbCtx.Create(&events).Error
+}
+
+// formatEventValue renders update values consistently so that equal values compare equal.
+func formatEventValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return *v
+	case int:
+		return strconv.Itoa(v)
+	case *int:
+		if v == nil {
+			return ""
+		}
+		return strconv.Itoa(*v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case *float64:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	case tables.StringArray:
+		return formatEventValue([]string(v))
+	case []string:
+		if len(v) == 0 {
+			return "[]"
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,155 @@
+package model
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTaskEventsRecordChanges(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := WithActor(context.Background(), "user:alice")
+	project := seedProject(t)
+	worktree := seedWorktree(t, project.ID, "feature/agent")
+	tasks := &TaskService{}
+	comments := NewTaskCommentService()
+	events := NewTaskEventService()
+
+	task, err := tasks.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "history"})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	if _, err := tasks.UpdateTask(ctx, task.ID, map[string]interface{}{"title": "history log", "priority": 0}); err != nil {
+		t.Fatalf("UpdateTask returned error: %v", err)
+	}
+	agentCtx := WithActor(ctx, "agent:claude-code")
+	if _, err := tasks.BindWorktree(agentCtx, task.ID, &worktree.ID); err != nil {
+		t.Fatalf("BindWorktree returned error: %v", err)
+	}
+	if _, err := tasks.MoveTask(ctx, task.ID, &MoveTaskRequest{Status: "done"}); err != nil {
+		t.Fatalf("MoveTask returned error: %v", err)
+	}
+	comment, err := comments.CreateComment(ctx, task.ID, "shipped")
+	if err != nil {
+		t.Fatalf("CreateComment returned error: %v", err)
+	}
+	if _, err := tasks.BindWorktree(ctx, task.ID, nil); err != nil {
+		t.Fatalf("BindWorktree unbind returned error: %v", err)
+	}
+
+	list, err := events.ListEvents(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("ListEvents returned error: %v", err)
+	}
+	types := make([]string, 0, len(list))
+	for _, event := range list {
+		types = append(types, event.Type)
+	}
+	expected := []string{
+		TaskEventCreated,
+		TaskEventFieldChanged,
+		TaskEventWorktreeBound,
+		TaskEventStatusChanged,
+		TaskEventCommentAdded,
+		TaskEventWorktreeUnbound,
+	}
+	if len(types) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Fatalf("expected events %v, got %v", expected, types)
+		}
+	}
+
+	title := list[1]
+	if title.Field != "title" || title.OldValue != "history" || title.NewValue != "history log" || title.Actor != "user:alice" {
+		t.Fatalf("unexpected field event %+v", title)
+	}
+	bound := list[2]
+	if bound.Actor != "agent:claude-code" || bound.BranchName != "feature/agent" {
+		t.Fatalf("unexpected bind event %+v", bound)
+	}
+	done := list[3]
+	if done.OldValue != "todo" || done.NewValue != "done" || done.BranchName != "feature/agent" {
+		t.Fatalf("expected done move to record the attached branch, got %+v", done)
+	}
+	unbound := list[5]
+	if unbound.BranchName != "feature/agent" || unbound.OldValue != worktree.ID {
+		t.Fatalf("expected unbind event to keep the previous branch, got %+v", unbound)
+	}
+
+	timeline, err := events.Timeline(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("Timeline returned error: %v", err)
+	}
+	if len(timeline) != len(expected) {
+		t.Fatalf("expected %d timeline entries, got %d", len(expected), len(timeline))
+	}
+	var sawComment bool
+	for _, entry := range timeline {
+		if entry.Kind == "comment" && entry.Comment.ID == comment.ID {
+			sawComment = true
+		}
+		if entry.Kind == "event" && entry.Event.Type == TaskEventCommentAdded {
+			t.Fatalf("expected comment_added to be folded into the comment entry")
+		}
+	}
+	if !sawComment {
+		t.Fatalf("expected timeline to include the comment")
+	}
+
+	if err := comments.DeleteComment(ctx, comment.ID); err != nil {
+		t.Fatalf("DeleteComment returned error: %v", err)
+	}
+	list, err = events.ListEvents(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("ListEvents returned error: %v", err)
+	}
+	if last := list[len(list)-1]; last.Type != TaskEventCommentDeleted || last.OldValue != "shipped" {
+		t.Fatalf("unexpected comment deletion event %+v", last)
+	}
+}
+
+func TestTaskEventsRecordEstimateValues(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	tasks := &TaskService{}
+	events := NewTaskEventService()
+
+	task, err := tasks.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "estimate"})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	for _, updates := range []map[string]interface{}{
+		{"estimate_minutes": 30, "estimate_points": 1.5},
+		{"estimate_minutes": 45, "estimate_points": 3.0},
+		{"estimate_minutes": 45, "estimate_points": 3.0},
+	} {
+		if _, err := tasks.UpdateTask(ctx, task.ID, updates); err != nil {
+			t.Fatalf("UpdateTask returned error: %v", err)
+		}
+	}
+
+	timeline, err := events.Timeline(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("Timeline returned error: %v", err)
+	}
+	changes := make(map[string][]string)
+	for _, entry := range timeline {
+		if entry.Event == nil || entry.Event.Type != TaskEventFieldChanged {
+			continue
+		}
+		changes[entry.Event.Field] = append(changes[entry.Event.Field], entry.Event.OldValue+"->"+entry.Event.NewValue)
+	}
+	if got := changes["estimate_minutes"]; len(got) != 2 || got[0] != "->30" || got[1] != "30->45" {
+		t.Fatalf("unexpected estimate_minutes events %v", got)
+	}
+	if got := changes["estimate_points"]; len(got) != 2 || got[0] != "->1.5" || got[1] != "1.5->3" {
+		t.Fatalf("unexpected estimate_points events %v", got)
+	}
+}
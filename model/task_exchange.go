@@ -0,0 +1,971 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code-kanban/model/tables"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// TaskExchangeJSON exports/imports a JSON document (or a bare array of tasks).
+	TaskExchangeJSON = "json"
+	// TaskExchangeCSV exports/imports one task per row with a header line.
+	TaskExchangeCSV = "csv"
+	// TaskExchangeMarkdown exports/imports a Markdown checklist grouped by column headings.
+	TaskExchangeMarkdown = "markdown"
+
+	// TaskImportCreate marks an item that creates a new task.
+	TaskImportCreate = "create"
+	// TaskImportUpdate marks an item that updates an existing task.
+	TaskImportUpdate = "update"
+	// TaskImportUnchanged marks an item that already matches the stored task.
+	TaskImportUnchanged = "unchanged"
+	// TaskImportError marks an item that could not be imported.
+	TaskImportError = "error"
+
+	taskExchangeVersion = 1
+)
+
+// ErrInvalidTaskExchange indicates the import payload or format is invalid.
+var ErrInvalidTaskExchange = errors.New("invalid task import data")
+
+var taskCSVHeader = []string{"externalId", "title", "description", "status", "priority", "tags", "dueDate", "branchName", "comments"}
+
+// TaskExchangeDocument is the JSON export envelope.
+type TaskExchangeDocument struct {
+	Version     int                `json:"version"`
+	ProjectID   string             `json:"projectId"`
+	ProjectName string             `json:"projectName"`
+	ExportedAt  time.Time          `json:"exportedAt"`
+	Tasks       []TaskExchangeItem `json:"tasks"`
+}
+
+// TaskExchangeItem is the portable representation of a task. Optional fields left
+// empty on import keep the stored value of an existing task.
+type TaskExchangeItem struct {
+	ExternalID  string                `json:"externalId"`
+	Title       string                `json:"title"`
+	Description string                `json:"description,omitempty"`
+	Status      string                `json:"status,omitempty"`
+	Done        bool                  `json:"done,omitempty"`
+	Priority    *int                  `json:"priority,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	DueDate     string                `json:"dueDate,omitempty"`
+	BranchName  string                `json:"branchName,omitempty"`
+	Comments    []TaskExchangeComment `json:"comments,omitempty"`
+
+	line int
+}
+
+// TaskExchangeComment is an exported task comment.
+type TaskExchangeComment struct {
+	Content   string     `json:"content"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+}
+
+// ImportTasksRequest configures an import run.
+type ImportTasksRequest struct {
+	ProjectID string
+	Format    string
+	Content   string
+	DryRun    bool
+}
+
+// TaskImportResult summarizes an import run or its dry-run preview.
+type TaskImportResult struct {
+	DryRun    bool             `json:"dryRun"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Failed    int              `json:"failed"`
+	Items     []TaskImportItem `json:"items"`
+}
+
+// TaskImportItem reports what happened (or would happen) to one imported task.
+type TaskImportItem struct {
+	Line       int      `json:"line,omitempty"`
+	ExternalID string   `json:"externalId"`
+	Title      string   `json:"title"`
+	Action     string   `json:"action"`
+	TaskID     string   `json:"taskId,omitempty"`
+	Changes    []string `json:"changes,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// TaskExchangeService moves tasks in and out of a project as JSON, CSV or Markdown.
+type TaskExchangeService struct {
+	taskSvc    *TaskService
+	commentSvc *TaskCommentService
+}
+
+// NewTaskExchangeService constructs an import/export service.
+func NewTaskExchangeService() *TaskExchangeService {
+	return &TaskExchangeService{
+		taskSvc:    &TaskService{},
+		commentSvc: NewTaskCommentService(),
+	}
+}
+
+// Export renders all tasks of a project, including comments, in the requested format.
+func (s *TaskExchangeService) Export(ctx context.Context, projectID, format string) ([]byte, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	format, err = normalizeExchangeFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	var project tables.ProjectTable
+	if err := dbCtx.First(&project, "id = ?", projectID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	workflow, err := loadTaskWorkflow(dbCtx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []tables.TaskTable
+	if err := dbCtx.
+		Where("project_id = ?", project.ID).
+		Order("order_index ASC").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	columnRank := make(map[string]int, len(workflow.columns))
+	for i, column := range workflow.columns {
+		columnRank[column.Key] = i
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return columnRank[tasks[i].Status] < columnRank[tasks[j].Status]
+	})
+
+	comments, err := loadCommentsByTask(dbCtx, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]TaskExchangeItem, 0, len(tasks))
+	for _, task := range tasks {
+		priority := task.Priority
+		item := TaskExchangeItem{
+			ExternalID:  task.ExternalID,
+			Title:       task.Title,
+			Description: task.Description,
+			Status:      task.Status,
+			Done:        workflow.category(task.Status) == TaskCategoryDone,
+			Priority:    &priority,
+			Tags:        []string(task.Tags),
+			DueDate:     formatExchangeDate(task.DueDate),
+			BranchName:  task.BranchName,
+		}
+		if item.ExternalID == "" {
+			item.ExternalID = task.ID
+		}
+		for _, comment := range comments[task.ID] {
+			createdAt := comment.CreatedAt
+			item.Comments = append(item.Comments, TaskExchangeComment{Content: comment.Content, CreatedAt: &createdAt})
+		}
+		items = append(items, item)
+	}
+
+	switch format {
+	case TaskExchangeCSV:
+		return encodeTasksCSV(items)
+	case TaskExchangeMarkdown:
+		return encodeTasksMarkdown(&project, workflow, items), nil
+	default:
+		return json.MarshalIndent(TaskExchangeDocument{
+			Version:     taskExchangeVersion,
+			ProjectID:   project.ID,
+			ProjectName: project.Name,
+			ExportedAt:  time.Now(),
+			Tasks:       items,
+		}, "", "  ")
+	}
+}
+
+// Import upserts tasks by external ID. With DryRun set nothing is written and the
+// result previews the actions an import would take.
+func (s *TaskExchangeService) Import(ctx context.Context, req *ImportTasksRequest) (*TaskImportResult, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+	format, err := normalizeExchangeFormat(req.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	var project tables.ProjectTable
+	if err := dbCtx.First(&project, "id = ?", req.ProjectID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	var items []TaskExchangeItem
+	switch format {
+	case TaskExchangeCSV:
+		items, err = decodeTasksCSV(req.Content)
+	case TaskExchangeMarkdown:
+		items = decodeTasksMarkdown(req.Content)
+	default:
+		items, err = decodeTasksJSON(req.Content)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.importItems(ctx, dbCtx, &project, items, req.DryRun)
+}
+
+// ImportRepoFile imports a file from the project repository, TODO.md by default.
+// The format is derived from the file extension.
+func (s *TaskExchangeService) ImportRepoFile(ctx context.Context, projectID, relPath string, dryRun bool) (*TaskImportResult, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var project tables.ProjectTable
+	if err := dbCtx.First(&project, "id = ?", projectID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	relPath = strings.TrimSpace(relPath)
+	if relPath == "" {
+		relPath = "TODO.md"
+	}
+	fullPath := filepath.Join(project.Path, filepath.FromSlash(relPath))
+	rel, err := filepath.Rel(project.Path, fullPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("%w: path must stay inside the repository", ErrInvalidTaskExchange)
+	}
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTaskExchange, err)
+	}
+
+	format := TaskExchangeMarkdown
+	switch strings.ToLower(filepath.Ext(fullPath)) {
+	case ".json":
+		format = TaskExchangeJSON
+	case ".csv":
+		format = TaskExchangeCSV
+	}
+	return s.Import(ctx, &ImportTasksRequest{
+		ProjectID: project.ID,
+		Format:    format,
+		Content:   string(content),
+		DryRun:    dryRun,
+	})
+}
+
+func (s *TaskExchangeService) importItems(ctx context.Context, dbCtx *gorm.DB, project *tables.ProjectTable, items []TaskExchangeItem, dryRun bool) (*TaskImportResult, error) {
+	workflow, err := loadTaskWorkflow(dbCtx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(items))
+	for i := range items {
+		items[i].Title = strings.TrimSpace(items[i].Title)
+		items[i].ExternalID = strings.TrimSpace(items[i].ExternalID)
+		if items[i].ExternalID == "" && items[i].Title != "" {
+			items[i].ExternalID = deriveExternalID(items[i].Title)
+		}
+		ids = append(ids, items[i].ExternalID)
+	}
+
+	var existing []tables.TaskTable
+	if len(ids) > 0 {
+		if err := dbCtx.
+			Where("project_id = ?", project.ID).
+			Where("external_id IN ? OR id IN ?", ids, ids).
+			Find(&existing).Error; err != nil {
+			return nil, err
+		}
+	}
+	byExternalID := make(map[string]*tables.TaskTable, len(existing))
+	for i := range existing {
+		byExternalID[existing[i].ID] = &existing[i]
+	}
+	for i := range existing {
+		// 显式的 external_id 优先于任务 ID
+		if existing[i].ExternalID != "" {
+			byExternalID[existing[i].ExternalID] = &existing[i]
+		}
+	}
+	comments, err := loadCommentsByTask(dbCtx, existing)
+	if err != nil {
+		return nil, err
+	}
+
+	var worktrees []tables.WorktreeTable
+	if err := dbCtx.Where("project_id = ?", project.ID).Find(&worktrees).Error; err != nil {
+		return nil, err
+	}
+	worktreeByBranch := make(map[string]string, len(worktrees))
+	for _, worktree := range worktrees {
+		worktreeByBranch[worktree.BranchName] = worktree.ID
+	}
+
+	result := &TaskImportResult{DryRun: dryRun, Items: make([]TaskImportItem, 0, len(items))}
+	seen := map[string]struct{}{}
+	for _, item := range items {
+		report := TaskImportItem{Line: item.line, ExternalID: item.ExternalID, Title: item.Title}
+		if err := s.importItem(ctx, workflow, item, byExternalID, comments, worktreeByBranch, seen, dryRun, &report); err != nil {
+			report.Action = TaskImportError
+			report.Error = err.Error()
+			report.Changes = nil
+		}
+		switch report.Action {
+		case TaskImportCreate:
+			result.Created++
+		case TaskImportUpdate:
+			result.Updated++
+		case TaskImportUnchanged:
+			result.Unchanged++
+		default:
+			result.Failed++
+		}
+		result.Items = append(result.Items, report)
+	}
+	return result, nil
+}
+
+func (s *TaskExchangeService) importItem(
+	ctx context.Context,
+	workflow *taskWorkflow,
+	item TaskExchangeItem,
+	byExternalID map[string]*tables.TaskTable,
+	comments map[string][]tables.TaskCommentTable,
+	worktreeByBranch map[string]string,
+	seen map[string]struct{},
+	dryRun bool,
+	report *TaskImportItem,
+) error {
+	if item.Title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidTaskExchange)
+	}
+	if _, dup := seen[item.ExternalID]; dup {
+		return fmt.Errorf("%w: duplicate external id %s", ErrInvalidTaskExchange, item.ExternalID)
+	}
+	seen[item.ExternalID] = struct{}{}
+
+	var dueDate *time.Time
+	if item.DueDate != "" {
+		parsed, err := parseExchangeDate(item.DueDate)
+		if err != nil {
+			return err
+		}
+		dueDate = parsed
+	}
+	status, err := resolveImportStatus(workflow, item.Status)
+	if err != nil {
+		return err
+	}
+
+	task := byExternalID[item.ExternalID]
+	if task == nil {
+		if status == "" {
+			status = workflow.defaultStatus()
+			if item.Done {
+				status = firstColumnOfCategory(workflow, TaskCategoryDone, status)
+			}
+		}
+		report.Action = TaskImportCreate
+		if dryRun {
+			return nil
+		}
+		return s.createImported(ctx, workflow, item, status, dueDate, worktreeByBranch, report)
+	}
+
+	report.TaskID = task.ID
+	updates := map[string]interface{}{}
+	if item.Title != task.Title {
+		updates["title"] = item.Title
+	}
+	if item.Description != "" && item.Description != task.Description {
+		updates["description"] = item.Description
+	}
+	if item.Priority != nil && *item.Priority != task.Priority {
+		updates["priority"] = *item.Priority
+	}
+	if len(item.Tags) > 0 && formatEventValue(sanitizeTags(item.Tags)) != formatEventValue(task.Tags) {
+		updates["tags"] = sanitizeTags(item.Tags)
+	}
+	if dueDate != nil && (task.DueDate == nil || !task.DueDate.Equal(*dueDate)) {
+		updates["due_date"] = *dueDate
+	}
+	if item.BranchName != "" && item.BranchName != task.BranchName {
+		updates["branch_name"] = item.BranchName
+		if worktreeID, ok := worktreeByBranch[item.BranchName]; ok {
+			updates["worktree_id"] = worktreeID
+		} else {
+			updates["worktree_id"] = nil
+		}
+	}
+
+	if status == "" {
+		// 未指定列时只根据勾选状态在完成/未完成之间切换，保留原有进行中等状态
+		category := workflow.category(task.Status)
+		switch {
+		case item.Done && category != TaskCategoryDone:
+			status = firstColumnOfCategory(workflow, TaskCategoryDone, task.Status)
+		case !item.Done && category == TaskCategoryDone:
+			status = workflow.defaultStatus()
+		}
+	}
+	if status != "" && status != task.Status {
+		updates["status"] = status
+		if workflow.category(status) == TaskCategoryDone {
+			updates["completed_at"] = time.Now()
+		}
+	}
+
+	newComments := missingComments(item.Comments, comments[task.ID])
+	for field := range updates {
+		if field != "completed_at" && field != "worktree_id" {
+			report.Changes = append(report.Changes, field)
+		}
+	}
+	sort.Strings(report.Changes)
+	if len(newComments) > 0 {
+		report.Changes = append(report.Changes, "comments")
+	}
+	if len(report.Changes) == 0 {
+		report.Action = TaskImportUnchanged
+		return nil
+	}
+	report.Action = TaskImportUpdate
+	if dryRun {
+		return nil
+	}
+
+	if status, ok := updates["status"].(string); ok {
+		orderIndex, err := s.taskSvc.getNextOrderIndex(db.WithContext(ensureContext(ctx)), task.ProjectID, status)
+		if err != nil {
+			return err
+		}
+		updates["order_index"] = orderIndex
+	}
+	if len(updates) > 0 {
+		if _, err := s.taskSvc.UpdateTask(ctx, task.ID, updates); err != nil {
+			return err
+		}
+	}
+	for _, content := range newComments {
+		if _, err := s.commentSvc.CreateComment(ctx, task.ID, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TaskExchangeService) createImported(
+	ctx context.Context,
+	workflow *taskWorkflow,
+	item TaskExchangeItem,
+	status string,
+	dueDate *time.Time,
+	worktreeByBranch map[string]string,
+	report *TaskImportItem,
+) error {
+	req := &CreateTaskRequest{
+		ProjectID:   workflow.projectID,
+		Title:       item.Title,
+		Description: item.Description,
+		Status:      status,
+		Tags:        tables.StringArray(item.Tags),
+		DueDate:     dueDate,
+		ExternalID:  item.ExternalID,
+	}
+	if item.Priority != nil {
+		req.Priority = *item.Priority
+	}
+	if worktreeID, ok := worktreeByBranch[item.BranchName]; ok && item.BranchName != "" {
+		req.WorktreeID = &worktreeID
+	}
+
+	task, err := s.taskSvc.CreateTask(ctx, req)
+	if err != nil {
+		return err
+	}
+	report.TaskID = task.ID
+
+	updates := map[string]interface{}{}
+	if item.BranchName != "" && task.BranchName != item.BranchName {
+		// 分支对应的 worktree 不存在时仍保留分支名
+		updates["branch_name"] = item.BranchName
+	}
+	if workflow.category(status) == TaskCategoryDone {
+		updates["completed_at"] = time.Now()
+	}
+	if len(updates) > 0 {
+		if _, err := s.taskSvc.UpdateTask(ctx, task.ID, updates); err != nil {
+			return err
+		}
+	}
+	for _, comment := range item.Comments {
+		if strings.TrimSpace(comment.Content) == "" {
+			continue
+		}
+		if _, err := s.commentSvc.CreateComment(ctx, task.ID, comment.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TaskExchangeService) dbWithContext(ctx context.Context) (*gorm.DB, error) {
+	if db == nil {
+		return nil, ErrDBNotInitialized
+	}
+	return db.WithContext(ensureContext(ctx)), nil
+}
+
+func normalizeExchangeFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", TaskExchangeJSON:
+		return TaskExchangeJSON, nil
+	case TaskExchangeCSV:
+		return TaskExchangeCSV, nil
+	case TaskExchangeMarkdown, "md":
+		return TaskExchangeMarkdown, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported format %q", ErrInvalidTaskExchange, format)
+	}
+}
+
+func loadCommentsByTask(dbCtx *gorm.DB, tasks []tables.TaskTable) (map[string][]tables.TaskCommentTable, error) {
+	result := make(map[string][]tables.TaskCommentTable, len(tasks))
+	if len(tasks) == 0 {
+		return result, nil
+	}
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	var comments []tables.TaskCommentTable
+	if err := dbCtx.
+		Where("task_id IN ?", ids).
+		Order("created_at ASC").
+		Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	for _, comment := range comments {
+		result[comment.TaskID] = append(result[comment.TaskID], comment)
+	}
+	return result, nil
+}
+
+// resolveImportStatus accepts a column key or, for hand-written files, a column name
+// or a spelled-out key such as "In Progress".
+func resolveImportStatus(workflow *taskWorkflow, status string) (string, error) {
+	status = strings.TrimSpace(status)
+	if status == "" {
+		return "", nil
+	}
+	if workflow.column(status) != nil {
+		return status, nil
+	}
+	key := strings.ToLower(strings.Join(strings.FieldsFunc(status, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "_"))
+	for _, column := range workflow.columns {
+		if strings.EqualFold(column.Name, status) || column.Key == key {
+			return column.Key, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrInvalidTaskStatus, status)
+}
+
+func firstColumnOfCategory(workflow *taskWorkflow, category, fallback string) string {
+	for _, column := range workflow.columns {
+		if column.Category == category {
+			return column.Key
+		}
+	}
+	return fallback
+}
+
+func missingComments(incoming []TaskExchangeComment, stored []tables.TaskCommentTable) []string {
+	known := make(map[string]struct{}, len(stored))
+	for _, comment := range stored {
+		known[strings.TrimSpace(comment.Content)] = struct{}{}
+	}
+	var missing []string
+	for _, comment := range incoming {
+		content := strings.TrimSpace(comment.Content)
+		if content == "" {
+			continue
+		}
+		if _, ok := known[content]; ok {
+			continue
+		}
+		known[content] = struct{}{}
+		missing = append(missing, content)
+	}
+	return missing
+}
+
+// deriveExternalID gives hand-written items without an id a stable identity based on the title.
+func deriveExternalID(title string) string {
+	sum := sha1.Sum([]byte(strings.ToLower(strings.Join(strings.Fields(title), " "))))
+	return "md:" + hex.EncodeToString(sum[:])[:12]
+}
+
+func formatExchangeDate(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	local := value.In(time.Local)
+	if local.Hour() == 0 && local.Minute() == 0 && local.Second() == 0 {
+		return local.Format("2006-01-02")
+	}
+	return value.Format(time.RFC3339)
+}
+
+func parseExchangeDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if parsed, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return &parsed, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return &parsed, nil
+	}
+	return nil, fmt.Errorf("%w: invalid due date %q", ErrInvalidTaskExchange, value)
+}
+
+func decodeTasksJSON(content string) ([]TaskExchangeItem, error) {
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		return nil, fmt.Errorf("%w: content is empty", ErrInvalidTaskExchange)
+	}
+	if strings.HasPrefix(trimmed, "[") {
+		var items []TaskExchangeItem
+		if err := json.Unmarshal([]byte(trimmed), &items); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTaskExchange, err)
+		}
+		return items, nil
+	}
+	var document TaskExchangeDocument
+	if err := json.Unmarshal([]byte(trimmed), &document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTaskExchange, err)
+	}
+	return document.Tasks, nil
+}
+
+func encodeTasksCSV(items []TaskExchangeItem) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(taskCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		priority := ""
+		if item.Priority != nil {
+			priority = strconv.Itoa(*item.Priority)
+		}
+		comments := ""
+		if len(item.Comments) > 0 {
+			contents := make([]string, 0, len(item.Comments))
+			for _, comment := range item.Comments {
+				contents = append(contents, comment.Content)
+			}
+			data, err := json.Marshal(contents)
+			if err != nil {
+				return nil, err
+			}
+			comments = string(data)
+		}
+		if err := writer.Write([]string{
+			item.ExternalID,
+			item.Title,
+			item.Description,
+			item.Status,
+			priority,
+			strings.Join(item.Tags, ","),
+			item.DueDate,
+			item.BranchName,
+			comments,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+func decodeTasksCSV(content string) ([]TaskExchangeItem, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(content, "\ufeff")))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header: %v", ErrInvalidTaskExchange, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("%w: title column is required", ErrInvalidTaskExchange)
+	}
+
+	var items []TaskExchangeItem
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTaskExchange, err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[strings.ToLower(name)]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		item := TaskExchangeItem{
+			ExternalID:  field("externalId"),
+			Title:       field("title"),
+			Description: field("description"),
+			Status:      field("status"),
+			DueDate:     field("dueDate"),
+			BranchName:  field("branchName"),
+			line:        line,
+		}
+		if value := field("priority"); value != "" {
+			priority, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: invalid priority %q", ErrInvalidTaskExchange, line, value)
+			}
+			item.Priority = &priority
+		}
+		if value := field("tags"); value != "" {
+			item.Tags = splitQueryValues(value)
+		}
+		if value := field("comments"); value != "" {
+			var contents []string
+			if err := json.Unmarshal([]byte(value), &contents); err != nil {
+				// 非 JSON 时视为单条评论
+				contents = []string{value}
+			}
+			for _, content := range contents {
+				item.Comments = append(item.Comments, TaskExchangeComment{Content: content})
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+var (
+	markdownItemPattern   = regexp.MustCompile(`^[-*+]\s+\[([ xX])\]\s+(.*)$`)
+	markdownIDPattern     = regexp.MustCompile(`<!--\s*id:(\S+)\s*-->`)
+	markdownStatusPattern = regexp.MustCompile(`<!--\s*status:(\S+)\s*-->`)
+)
+
+// encodeTasksMarkdown writes one checklist section per column. Metadata is kept inline
+// (#tag, priority:N, due:DATE, branch:NAME) and the identity in an HTML comment.
+func encodeTasksMarkdown(project *tables.ProjectTable, workflow *taskWorkflow, items []TaskExchangeItem) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", project.Name)
+
+	byStatus := map[string][]TaskExchangeItem{}
+	for _, item := range items {
+		byStatus[item.Status] = append(byStatus[item.Status], item)
+	}
+	for _, column := range workflow.columns {
+		group := byStatus[column.Key]
+		if len(group) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s <!-- status:%s -->\n\n", column.Name, column.Key)
+		for _, item := range group {
+			mark := " "
+			if item.Done {
+				mark = "x"
+			}
+			parts := []string{fmt.Sprintf("- [%s] %s", mark, strings.ReplaceAll(item.Title, "\n", " "))}
+			for _, tag := range item.Tags {
+				if !strings.ContainsAny(tag, " \t") {
+					parts = append(parts, "#"+tag)
+				}
+			}
+			if item.Priority != nil && *item.Priority > 0 {
+				parts = append(parts, fmt.Sprintf("priority:%d", *item.Priority))
+			}
+			if item.DueDate != "" {
+				parts = append(parts, "due:"+item.DueDate)
+			}
+			if item.BranchName != "" {
+				parts = append(parts, "branch:"+item.BranchName)
+			}
+			parts = append(parts, fmt.Sprintf("<!-- id:%s -->", item.ExternalID))
+			b.WriteString(strings.Join(parts, " "))
+			b.WriteString("\n")
+
+			if description := strings.TrimSpace(item.Description); description != "" {
+				for _, line := range strings.Split(description, "\n") {
+					b.WriteString("  " + strings.TrimRight(line, " \t") + "\n")
+				}
+			}
+			for _, comment := range item.Comments {
+				for _, line := range strings.Split(strings.TrimSpace(comment.Content), "\n") {
+					b.WriteString("  > " + line + "\n")
+				}
+				b.WriteString("  >\n")
+			}
+		}
+	}
+	return []byte(b.String())
+}
+
+// decodeTasksMarkdown reads checklist items. Headings select the column for the items
+// below them; indented lines belong to the previous item (`>` lines are comments).
+func decodeTasksMarkdown(content string) []TaskExchangeItem {
+	var items []TaskExchangeItem
+	var current *TaskExchangeItem
+	var description []string
+	var comment []string
+	status := ""
+
+	flushComment := func() {
+		if current != nil && len(comment) > 0 {
+			current.Comments = append(current.Comments, TaskExchangeComment{Content: strings.TrimSpace(strings.Join(comment, "\n"))})
+		}
+		comment = nil
+	}
+	flush := func() {
+		flushComment()
+		if current != nil {
+			current.Description = strings.TrimSpace(strings.Join(description, "\n"))
+			items = append(items, *current)
+		}
+		current = nil
+		description = nil
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for index, raw := range lines {
+		indented := strings.HasPrefix(raw, "  ") || strings.HasPrefix(raw, "\t")
+		line := strings.TrimSpace(raw)
+
+		if current != nil && indented && line != "" {
+			if strings.HasPrefix(line, ">") {
+				text := strings.TrimSpace(strings.TrimPrefix(line, ">"))
+				if text == "" {
+					flushComment()
+				} else {
+					comment = append(comment, text)
+				}
+				continue
+			}
+			flushComment()
+			description = append(description, strings.TrimPrefix(strings.TrimPrefix(raw, "\t"), "  "))
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") && !indented {
+			heading := strings.TrimSpace(strings.TrimLeft(line, "#"))
+			if strings.HasPrefix(line, "##") {
+				flush()
+				status = heading
+				if match := markdownStatusPattern.FindStringSubmatch(heading); match != nil {
+					status = match[1]
+				}
+			}
+			continue
+		}
+
+		match := markdownItemPattern.FindStringSubmatch(line)
+		if match == nil || indented {
+			if line == "" && current != nil && len(description) > 0 {
+				description = append(description, "")
+			}
+			continue
+		}
+		flush()
+		item := parseMarkdownItem(match[2])
+		item.Done = match[1] != " "
+		item.line = index + 1
+		if item.Status == "" {
+			item.Status = status
+		}
+		current = &item
+	}
+	flush()
+	return items
+}
+
+func parseMarkdownItem(text string) TaskExchangeItem {
+	item := TaskExchangeItem{}
+	if match := markdownIDPattern.FindStringSubmatch(text); match != nil {
+		item.ExternalID = match[1]
+		text = markdownIDPattern.ReplaceAllString(text, "")
+	}
+
+	words := strings.Fields(text)
+	title := make([]string, 0, len(words))
+	for _, word := range words {
+		key, value, found := strings.Cut(word, ":")
+		switch {
+		case strings.HasPrefix(word, "#") && len(word) > 1 && !isDigits(word[1:]):
+			item.Tags = append(item.Tags, word[1:])
+		case found && value != "" && key == "due":
+			item.DueDate = value
+		case found && value != "" && key == "branch":
+			item.BranchName = value
+		case found && value != "" && key == "status":
+			item.Status = value
+		case found && key == "priority" && isDigits(value) && value != "":
+			priority, _ := strconv.Atoi(value)
+			item.Priority = &priority
+		default:
+			title = append(title, word)
+		}
+	}
+	item.Title = strings.Join(title, " ")
+	return item
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
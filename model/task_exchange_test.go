@@ -0,0 +1,217 @@
+package model
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"code-kanban/model/tables"
+)
+
+func TestTaskExchangeJSONRoundTripIsIdempotent(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	worktree := seedWorktree(t, project.ID, "feature/export")
+	tasks := &TaskService{}
+	exchange := NewTaskExchangeService()
+
+	due := time.Date(2026, 11, 1, 0, 0, 0, 0, time.Local)
+	task, err := tasks.CreateTask(ctx, &CreateTaskRequest{
+		ProjectID:  project.ID,
+		Title:      "export me",
+		Priority:   2,
+		Tags:       tables.StringArray{"backend", "api"},
+		DueDate:    &due,
+		WorktreeID: &worktree.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	if _, err := NewTaskCommentService().CreateComment(ctx, task.ID, "looks good"); err != nil {
+		t.Fatalf("CreateComment returned error: %v", err)
+	}
+
+	data, err := exchange.Export(ctx, project.ID, TaskExchangeJSON)
+	if err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+	if !strings.Contains(string(data), `"dueDate": "2026-11-01"`) || !strings.Contains(string(data), "looks good") {
+		t.Fatalf("unexpected export: %s", data)
+	}
+
+	result, err := exchange.Import(ctx, &ImportTasksRequest{ProjectID: project.ID, Format: TaskExchangeJSON, Content: string(data)})
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	if result.Unchanged != 1 || result.Created != 0 || result.Updated != 0 {
+		t.Fatalf("expected re-import to be a no-op, got %+v", result)
+	}
+
+	other := seedProject(t)
+	result, err = exchange.Import(ctx, &ImportTasksRequest{ProjectID: other.ID, Format: TaskExchangeJSON, Content: string(data)})
+	if err != nil {
+		t.Fatalf("Import into another project returned error: %v", err)
+	}
+	if result.Created != 1 {
+		t.Fatalf("expected one created task, got %+v", result)
+	}
+	again, err := exchange.Import(ctx, &ImportTasksRequest{ProjectID: other.ID, Format: TaskExchangeJSON, Content: string(data)})
+	if err != nil {
+		t.Fatalf("second Import returned error: %v", err)
+	}
+	if again.Unchanged != 1 {
+		t.Fatalf("expected second import to match by external id, got %+v", again)
+	}
+
+	imported, err := tasks.GetTask(ctx, result.Items[0].TaskID)
+	if err != nil {
+		t.Fatalf("GetTask returned error: %v", err)
+	}
+	if imported.ExternalID != task.ID || imported.BranchName != "feature/export" || imported.WorktreeID != nil {
+		t.Fatalf("unexpected imported task %+v", imported)
+	}
+	if imported.DueDate == nil || !imported.DueDate.Equal(due) || len(imported.Tags) != 2 || imported.Priority != 2 {
+		t.Fatalf("expected fields to be imported, got %+v", imported)
+	}
+	comments, err := NewTaskCommentService().ListComments(ctx, imported.ID)
+	if err != nil {
+		t.Fatalf("ListComments returned error: %v", err)
+	}
+	if len(comments) != 1 || comments[0].Content != "looks good" {
+		t.Fatalf("expected comment to be imported once, got %+v", comments)
+	}
+}
+
+func TestTaskExchangeCSVDryRun(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	exchange := NewTaskExchangeService()
+
+	content := "externalId,title,status,priority,tags,comments\n" +
+		"JIRA-1,Set up CI,In Progress,3,\"ci,infra\",\"[\"\"first\"\"]\"\n" +
+		"JIRA-2,Write docs,todo,,,\n"
+
+	preview, err := exchange.Import(ctx, &ImportTasksRequest{ProjectID: project.ID, Format: TaskExchangeCSV, Content: content, DryRun: true})
+	if err != nil {
+		t.Fatalf("dry-run Import returned error: %v", err)
+	}
+	if !preview.DryRun || preview.Created != 2 {
+		t.Fatalf("unexpected preview %+v", preview)
+	}
+	var count int64
+	db.Model(&tables.TaskTable{}).Where("project_id = ?", project.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected dry run to write nothing, found %d tasks", count)
+	}
+
+	if _, err := exchange.Import(ctx, &ImportTasksRequest{ProjectID: project.ID, Format: TaskExchangeCSV, Content: content}); err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	updated := strings.Replace(content, "Write docs,todo", "Write docs,done", 1)
+	preview, err = exchange.Import(ctx, &ImportTasksRequest{ProjectID: project.ID, Format: TaskExchangeCSV, Content: updated, DryRun: true})
+	if err != nil {
+		t.Fatalf("dry-run Import returned error: %v", err)
+	}
+	if preview.Updated != 1 || preview.Unchanged != 1 {
+		t.Fatalf("unexpected preview %+v", preview)
+	}
+	for _, item := range preview.Items {
+		if item.ExternalID == "JIRA-2" && (item.Action != TaskImportUpdate || len(item.Changes) != 1 || item.Changes[0] != "status") {
+			t.Fatalf("expected status change preview, got %+v", item)
+		}
+	}
+
+	var task tables.TaskTable
+	if err := db.Where("external_id = ?", "JIRA-1").First(&task).Error; err != nil {
+		t.Fatalf("expected JIRA-1 to be imported: %v", err)
+	}
+	if task.Status != "in_progress" || task.Priority != 3 || len(task.Tags) != 2 {
+		t.Fatalf("unexpected imported task %+v", task)
+	}
+}
+
+func TestTaskExchangeImportTODO(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	exchange := NewTaskExchangeService()
+
+	todo := "# TODO\n\n" +
+		"- [ ] Add login page #frontend due:2026-12-01 branch:feat/login\n" +
+		"  Use the new design system.\n" +
+		"  > agreed in standup\n" +
+		"- [x] Fix #123 crash priority:2\n" +
+		"* [ ] Ship it <!-- id:ship -->\n"
+	if err := os.WriteFile(filepath.Join(project.Path, "TODO.md"), []byte(todo), 0o644); err != nil {
+		t.Fatalf("write TODO.md: %v", err)
+	}
+
+	result, err := exchange.ImportRepoFile(ctx, project.ID, "", false)
+	if err != nil {
+		t.Fatalf("ImportRepoFile returned error: %v", err)
+	}
+	if result.Created != 3 || result.Failed != 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	var login tables.TaskTable
+	if err := db.Where("title = ?", "Add login page").First(&login).Error; err != nil {
+		t.Fatalf("expected login task: %v", err)
+	}
+	if login.BranchName != "feat/login" || login.Description != "Use the new design system." || len(login.Tags) != 1 || login.DueDate == nil {
+		t.Fatalf("unexpected login task %+v", login)
+	}
+	var fix tables.TaskTable
+	if err := db.Where("title = ?", "Fix #123 crash").First(&fix).Error; err != nil {
+		t.Fatalf("expected numeric hash to stay in the title: %v", err)
+	}
+	if fix.Status != "done" || fix.Priority != 2 || fix.CompletedAt == nil {
+		t.Fatalf("expected checked item to be done, got %+v", fix)
+	}
+	var ship tables.TaskTable
+	if err := db.Where("external_id = ?", "ship").First(&ship).Error; err != nil {
+		t.Fatalf("expected explicit id to be kept: %v", err)
+	}
+
+	checked := strings.Replace(todo, "- [ ] Add login page", "- [x] Add login page", 1)
+	if err := os.WriteFile(filepath.Join(project.Path, "TODO.md"), []byte(checked), 0o644); err != nil {
+		t.Fatalf("write TODO.md: %v", err)
+	}
+	result, err = exchange.ImportRepoFile(ctx, project.ID, "TODO.md", false)
+	if err != nil {
+		t.Fatalf("ImportRepoFile returned error: %v", err)
+	}
+	if result.Updated != 1 || result.Unchanged != 2 {
+		t.Fatalf("expected only the checked item to update, got %+v", result)
+	}
+
+	if _, err := exchange.ImportRepoFile(ctx, project.ID, "../outside.md", true); err == nil {
+		t.Fatalf("expected path outside the repository to be rejected")
+	}
+
+	markdown, err := exchange.Export(ctx, project.ID, TaskExchangeMarkdown)
+	if err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+	if !strings.Contains(string(markdown), "- [x] Add login page #frontend due:2026-12-01 branch:feat/login") {
+		t.Fatalf("unexpected markdown export:\n%s", markdown)
+	}
+	result, err = exchange.Import(ctx, &ImportTasksRequest{ProjectID: project.ID, Format: TaskExchangeMarkdown, Content: string(markdown), DryRun: true})
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	if result.Unchanged != 3 {
+		t.Fatalf("expected markdown export to round-trip, got %+v\n%s", result, markdown)
+	}
+}
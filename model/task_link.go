@@ -0,0 +1,241 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"code-kanban/model/tables"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// TaskLinkBlocks means the source task must be finished before the target can proceed.
+	TaskLinkBlocks = "blocks"
+	// TaskLinkRelatesTo is a symmetric, informational relation.
+	TaskLinkRelatesTo = "relates_to"
+	// TaskLinkDuplicates marks the source task as a duplicate of the target.
+	TaskLinkDuplicates = "duplicates"
+)
+
+var (
+	// ErrTaskLinkNotFound indicates the requested task link does not exist.
+	ErrTaskLinkNotFound = errors.New("task link not found")
+	// ErrInvalidTaskLink indicates the link type or endpoints are invalid.
+	ErrInvalidTaskLink = errors.New("invalid task link")
+	// ErrTaskLinkExists indicates the same relation is already recorded.
+	ErrTaskLinkExists = errors.New("task link already exists")
+	// ErrTaskLinkCycle indicates the link would create a dependency cycle.
+	ErrTaskLinkCycle = errors.New("task link would create a cycle")
+	// ErrTaskBlocked indicates the task still has open blockers.
+	ErrTaskBlocked = errors.New("task is blocked by open tasks")
+)
+
+var taskLinkTypeSet = map[string]struct{}{
+	TaskLinkBlocks:     {},
+	TaskLinkRelatesTo:  {},
+	TaskLinkDuplicates: {},
+}
+
+// TaskLinkService manages relations between tasks.
+type TaskLinkService struct {
+	taskSvc *TaskService
+}
+
+// NewTaskLinkService constructs a task link service with a task dependency.
+func NewTaskLinkService() *TaskLinkService {
+	return &TaskLinkService{taskSvc: &TaskService{}}
+}
+
+// CreateLink records a relation from sourceID to targetID, rejecting duplicates and cycles.
+func (s *TaskLinkService) CreateLink(ctx context.Context, sourceID, targetID, linkType string) (*tables.TaskLinkTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceID = strings.TrimSpace(sourceID)
+	targetID = strings.TrimSpace(targetID)
+	linkType = strings.TrimSpace(linkType)
+	if _, ok := taskLinkTypeSet[linkType]; !ok {
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidTaskLink, linkType)
+	}
+	if sourceID == "" || targetID == "" || sourceID == targetID {
+		return nil, fmt.Errorf("%w: a task cannot link to itself", ErrInvalidTaskLink)
+	}
+
+	if _, err := s.taskSvc.GetTask(ctx, sourceID); err != nil {
+		return nil, err
+	}
+	if _, err := s.taskSvc.GetTask(ctx, targetID); err != nil {
+		return nil, err
+	}
+
+	existing := dbCtx.Model(&tables.TaskLinkTable{}).
+		Where("type = ? AND source_task_id = ? AND target_task_id = ?", linkType, sourceID, targetID)
+	if linkType == TaskLinkRelatesTo {
+		existing = dbCtx.Model(&tables.TaskLinkTable{}).
+			Where("type = ?", linkType).
+			Where("(source_task_id = ? AND target_task_id = ?) OR (source_task_id = ? AND target_task_id = ?)",
+				sourceID, targetID, targetID, sourceID)
+	}
+	var count int64
+	if err := existing.Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrTaskLinkExists
+	}
+
+	if linkType != TaskLinkRelatesTo {
+		reachable, err := s.reaches(dbCtx, linkType, targetID, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		if reachable {
+			return nil, ErrTaskLinkCycle
+		}
+	}
+
+	link := &tables.TaskLinkTable{
+		SourceTaskID: sourceID,
+		TargetTaskID: targetID,
+		Type:         linkType,
+	}
+	if err := dbCtx.Create(link).Error; err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// DeleteLink removes a link by identifier.
+func (s *TaskLinkService) DeleteLink(ctx context.Context, id string) error {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	result := dbCtx.Delete(&tables.TaskLinkTable{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTaskLinkNotFound
+	}
+	return nil
+}
+
+// ListLinkedTasks returns tasks related to taskID from both directions.
+func (s *TaskLinkService) ListLinkedTasks(ctx context.Context, taskID string) ([]tables.LinkedTask, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return listLinkedTasks(dbCtx, taskID)
+}
+
+// reaches reports whether `to` is reachable from `from` following links of the given type.
+func (s *TaskLinkService) reaches(dbCtx *gorm.DB, linkType, from, to string) (bool, error) {
+	visited := map[string]struct{}{from: {}}
+	frontier := []string{from}
+	for len(frontier) > 0 {
+		var next []string
+		if err := dbCtx.
+			Model(&tables.TaskLinkTable{}).
+			Where("type = ? AND source_task_id IN ?", linkType, frontier).
+			Pluck("target_task_id", &next).Error; err != nil {
+			return false, err
+		}
+
+		frontier = frontier[:0]
+		for _, id := range next {
+			if id == to {
+				return true, nil
+			}
+			if _, seen := visited[id]; seen {
+				continue
+			}
+			visited[id] = struct{}{}
+			frontier = append(frontier, id)
+		}
+	}
+	return false, nil
+}
+
+func (s *TaskLinkService) dbWithContext(ctx context.Context) (*gorm.DB, error) {
+	if db == nil {
+		return nil, ErrDBNotInitialized
+	}
+	return db.WithContext(ensureContext(ctx)), nil
+}
+
+func listLinkedTasks(dbCtx *gorm.DB, taskID string) ([]tables.LinkedTask, error) {
+	var links []tables.TaskLinkTable
+	if err := dbCtx.
+		Preload("SourceTask").
+		Preload("TargetTask").
+		Where("source_task_id = ? OR target_task_id = ?", taskID, taskID).
+		Order("created_at ASC").
+		Find(&links).Error; err != nil {
+		return nil, err
+	}
+
+	workflows := map[string]*taskWorkflow{}
+	result := make([]tables.LinkedTask, 0, len(links))
+	for _, link := range links {
+		direction := "outgoing"
+		other := link.TargetTask
+		if link.TargetTaskID == taskID {
+			direction = "incoming"
+			other = link.SourceTask
+		}
+		if other == nil {
+			// 关联任务已被删除
+			continue
+		}
+
+		workflow, ok := workflows[other.ProjectID]
+		if !ok {
+			var err error
+			workflow, err = loadTaskWorkflow(dbCtx, other.ProjectID)
+			if err != nil {
+				return nil, err
+			}
+			workflows[other.ProjectID] = workflow
+		}
+
+		result = append(result, tables.LinkedTask{
+			LinkID:    link.ID,
+			Type:      link.Type,
+			Direction: direction,
+			TaskID:    other.ID,
+			ProjectID: other.ProjectID,
+			Title:     other.Title,
+			Status:    other.Status,
+			Branch:    other.BranchName,
+			Resolved:  isResolvedCategory(workflow.category(other.Status)),
+		})
+	}
+	return result, nil
+}
+
+// openBlockers lists the tasks that block taskID and are not finished yet.
+func openBlockers(dbCtx *gorm.DB, taskID string) ([]tables.LinkedTask, error) {
+	linked, err := listLinkedTasks(dbCtx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	blockers := make([]tables.LinkedTask, 0)
+	for _, item := range linked {
+		if item.Type == TaskLinkBlocks && item.Direction == "incoming" && !item.Resolved {
+			blockers = append(blockers, item)
+		}
+	}
+	return blockers, nil
+}
+
+func isResolvedCategory(category string) bool {
+	return category == TaskCategoryDone || category == TaskCategoryArchived
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTaskLinkService(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	tasks := &TaskService{}
+	links := NewTaskLinkService()
+
+	create := func(title string) string {
+		t.Helper()
+		task, err := tasks.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: title})
+		if err != nil {
+			t.Fatalf("CreateTask(%s) returned error: %v", title, err)
+		}
+		return task.ID
+	}
+	api := create("api")
+	ui := create("ui")
+	docs := create("docs")
+
+	if _, err := links.CreateLink(ctx, api, ui, TaskLinkBlocks); err != nil {
+		t.Fatalf("CreateLink api->ui returned error: %v", err)
+	}
+	if _, err := links.CreateLink(ctx, ui, docs, TaskLinkBlocks); err != nil {
+		t.Fatalf("CreateLink ui->docs returned error: %v", err)
+	}
+	if _, err := links.CreateLink(ctx, docs, api, TaskLinkBlocks); !errors.Is(err, ErrTaskLinkCycle) {
+		t.Fatalf("expected ErrTaskLinkCycle, got %v", err)
+	}
+	if _, err := links.CreateLink(ctx, api, ui, TaskLinkBlocks); !errors.Is(err, ErrTaskLinkExists) {
+		t.Fatalf("expected ErrTaskLinkExists, got %v", err)
+	}
+	if _, err := links.CreateLink(ctx, docs, api, TaskLinkRelatesTo); err != nil {
+		t.Fatalf("CreateLink relates_to returned error: %v", err)
+	}
+	if _, err := links.CreateLink(ctx, api, docs, TaskLinkRelatesTo); !errors.Is(err, ErrTaskLinkExists) {
+		t.Fatalf("expected symmetric relates_to to be rejected, got %v", err)
+	}
+	if _, err := links.CreateLink(ctx, api, api, TaskLinkRelatesTo); !errors.Is(err, ErrInvalidTaskLink) {
+		t.Fatalf("expected ErrInvalidTaskLink for self link, got %v", err)
+	}
+
+	detail, err := tasks.GetTask(ctx, ui)
+	if err != nil {
+		t.Fatalf("GetTask returned error: %v", err)
+	}
+	if len(detail.Links) != 2 {
+		t.Fatalf("expected 2 linked tasks, got %+v", detail.Links)
+	}
+	if detail.Links[0].TaskID != api || detail.Links[0].Direction != "incoming" || detail.Links[0].Resolved {
+		t.Fatalf("unexpected blocker entry %+v", detail.Links[0])
+	}
+
+	if _, err := tasks.MoveTask(ctx, ui, &MoveTaskRequest{Status: "in_progress", EnforceBlockers: true}); !errors.Is(err, ErrTaskBlocked) {
+		t.Fatalf("expected ErrTaskBlocked, got %v", err)
+	}
+	if _, err := tasks.MoveTask(ctx, ui, &MoveTaskRequest{Status: "in_progress"}); err != nil {
+		t.Fatalf("MoveTask without enforcement returned error: %v", err)
+	}
+	if _, err := tasks.MoveTask(ctx, api, &MoveTaskRequest{Status: "done"}); err != nil {
+		t.Fatalf("MoveTask api to done returned error: %v", err)
+	}
+	if _, err := tasks.MoveTask(ctx, ui, &MoveTaskRequest{Status: "done", EnforceBlockers: true}); err != nil {
+		t.Fatalf("expected move to succeed once blocker is done, got %v", err)
+	}
+
+	if err := tasks.DeleteTask(ctx, docs); err != nil {
+		t.Fatalf("DeleteTask returned error: %v", err)
+	}
+	detail, err = tasks.GetTask(ctx, ui)
+	if err != nil {
+		t.Fatalf("GetTask returned error: %v", err)
+	}
+	if len(detail.Links) != 1 {
+		t.Fatalf("expected links to deleted task to be removed, got %+v", detail.Links)
+	}
+	if err := links.DeleteLink(ctx, detail.Links[0].LinkID); err != nil {
+		t.Fatalf("DeleteLink returned error: %v", err)
+	}
+	if err := links.DeleteLink(ctx, detail.Links[0].LinkID); !errors.Is(err, ErrTaskLinkNotFound) {
+		t.Fatalf("expected ErrTaskLinkNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"code-kanban/model/tables"
+
+	"gorm.io/gorm"
+)
+
+// FlagOverdueTasks marks open tasks whose due date has passed and records an overdue
+// event for each. Tasks already flagged are skipped, so every task is reported once
+// per due date.
+func (s *TaskService) FlagOverdueTasks(ctx context.Context, now time.Time) ([]tables.TaskTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = WithActor(ctx, TaskActorScheduler)
+
+	// 已完成或已归档列中的任务不会被标记，需在查询中排除，否则每次调度都会重新加载
+	closedColumns := dbCtx.
+		Model(&tables.TaskColumnTable{}).
+		Select("1").
+		Where("task_columns.project_id = tasks.project_id AND task_columns.key = tasks.status").
+		Where("task_columns.category IN ?", []string{TaskCategoryDone, TaskCategoryArchived})
+
+	var candidates []tables.TaskTable
+	if err := dbCtx.
+		Where("due_date IS NOT NULL AND due_date < ? AND overdue_at IS NULL", now).
+		Where("NOT EXISTS (?)", closedColumns).
+		Order("due_date ASC").
+		Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	flagged := make([]tables.TaskTable, 0, len(candidates))
+	for i := range candidates {
+		task := &candidates[i]
+		event := newTaskEvent(ctx, task, TaskEventOverdue)
+		event.Field = "due_date"
+		event.NewValue = formatEventValue(task.DueDate)
+		err := dbCtx.Transaction(func(tx *gorm.DB) error {
+			if err := tx.
+				Model(&tables.TaskTable{}).
+				Where("id = ?", task.ID).
+				UpdateColumn("overdue_at", now).Error; err != nil {
+				return err
+			}
+			return recordTaskEvents(tx, []tables.TaskEventTable{event})
+		})
+		if err != nil {
+			return flagged, err
+		}
+		task.OverdueAt = &now
+		flagged = append(flagged, *task)
+	}
+	return flagged, nil
+}
+
+// ListOverdueTasks returns the open tasks of a project that the scheduler flagged as overdue.
+func (s *TaskService) ListOverdueTasks(ctx context.Context, projectID string) ([]tables.TaskTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workflow, err := loadTaskWorkflow(dbCtx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	open := make([]string, 0, len(workflow.columns))
+	for _, column := range workflow.columns {
+		if column.Category != TaskCategoryDone && column.Category != TaskCategoryArchived {
+			open = append(open, column.Key)
+		}
+	}
+
+	var tasks []tables.TaskTable
+	if err := dbCtx.
+		Where("project_id = ? AND overdue_at IS NOT NULL AND status IN ?", projectID, open).
+		Order("due_date ASC").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
@@ -0,0 +1,381 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"code-kanban/model/tables"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// TaskActorScheduler is the actor recorded for tasks spawned or flagged by the scheduler.
+	TaskActorScheduler = "scheduler"
+
+	maxRecurrencePreview = 50
+)
+
+var (
+	// ErrTaskRecurrenceNotFound indicates the requested recurrence does not exist.
+	ErrTaskRecurrenceNotFound = errors.New("task recurrence not found")
+	// ErrInvalidTaskRecurrence indicates the recurrence definition is invalid.
+	ErrInvalidTaskRecurrence = errors.New("invalid task recurrence")
+)
+
+// SaveTaskRecurrenceRequest describes a recurring task template.
+type SaveTaskRecurrenceRequest struct {
+	Title           string
+	Description     string
+	Status          string
+	Priority        int
+	Tags            tables.StringArray
+	Rule            string
+	StartAt         *time.Time
+	DueAfterMinutes *int
+	SkipIfOpen      bool
+	Enabled         *bool
+}
+
+// TaskRecurrenceService manages recurring task templates and spawns their instances.
+type TaskRecurrenceService struct {
+	taskSvc *TaskService
+}
+
+// NewTaskRecurrenceService constructs a recurrence service with a task dependency.
+func NewTaskRecurrenceService() *TaskRecurrenceService {
+	return &TaskRecurrenceService{taskSvc: &TaskService{}}
+}
+
+// ListRecurrences returns the recurrences of a project ordered by creation time.
+func (s *TaskRecurrenceService) ListRecurrences(ctx context.Context, projectID string) ([]tables.TaskRecurrenceTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureProjectExists(dbCtx, projectID); err != nil {
+		return nil, err
+	}
+
+	var recurrences []tables.TaskRecurrenceTable
+	if err := dbCtx.
+		Where("project_id = ?", projectID).
+		Order("created_at ASC").
+		Find(&recurrences).Error; err != nil {
+		return nil, err
+	}
+	return recurrences, nil
+}
+
+// GetRecurrence loads a recurrence by id.
+func (s *TaskRecurrenceService) GetRecurrence(ctx context.Context, id string) (*tables.TaskRecurrenceTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var recurrence tables.TaskRecurrenceTable
+	if err := dbCtx.First(&recurrence, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskRecurrenceNotFound
+		}
+		return nil, err
+	}
+	return &recurrence, nil
+}
+
+// CreateRecurrence stores a recurrence and schedules its first run.
+func (s *TaskRecurrenceService) CreateRecurrence(ctx context.Context, projectID string, req *SaveTaskRecurrenceRequest) (*tables.TaskRecurrenceTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureProjectExists(dbCtx, projectID); err != nil {
+		return nil, err
+	}
+
+	recurrence := &tables.TaskRecurrenceTable{ProjectID: projectID, Enabled: true}
+	if err := s.apply(dbCtx, recurrence, req, time.Now()); err != nil {
+		return nil, err
+	}
+	// gorm 会忽略 false 零值并使用列默认值，显式创建后再写入
+	if err := dbCtx.Create(recurrence).Error; err != nil {
+		return nil, err
+	}
+	if !recurrence.Enabled {
+		if err := dbCtx.Model(recurrence).Update("enabled", false).Error; err != nil {
+			return nil, err
+		}
+	}
+	return recurrence, nil
+}
+
+// UpdateRecurrence replaces the definition of a recurrence and reschedules it.
+func (s *TaskRecurrenceService) UpdateRecurrence(ctx context.Context, id string, req *SaveTaskRecurrenceRequest) (*tables.TaskRecurrenceTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recurrence, err := s.GetRecurrence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(dbCtx, recurrence, req, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := dbCtx.
+		Model(&tables.TaskRecurrenceTable{}).
+		Where("id = ?", recurrence.ID).
+		Updates(map[string]interface{}{
+			"title":             recurrence.Title,
+			"description":       recurrence.Description,
+			"status":            recurrence.Status,
+			"priority":          recurrence.Priority,
+			"tags":              recurrence.Tags,
+			"rule":              recurrence.Rule,
+			"start_at":          recurrence.StartAt,
+			"due_after_minutes": recurrence.DueAfterMinutes,
+			"skip_if_open":      recurrence.SkipIfOpen,
+			"enabled":           recurrence.Enabled,
+			"next_run_at":       recurrence.NextRunAt,
+		}).Error; err != nil {
+		return nil, err
+	}
+	return s.GetRecurrence(ctx, recurrence.ID)
+}
+
+// DeleteRecurrence removes a recurrence. Tasks it already spawned are kept.
+func (s *TaskRecurrenceService) DeleteRecurrence(ctx context.Context, id string) error {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbCtx.Delete(&tables.TaskRecurrenceTable{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTaskRecurrenceNotFound
+	}
+	return nil
+}
+
+// Preview lists the next occurrences of a recurrence.
+func (s *TaskRecurrenceService) Preview(ctx context.Context, id string, limit int) ([]time.Time, error) {
+	recurrence, err := s.GetRecurrence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := ParseRecurrenceRule(recurrence.Rule)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > maxRecurrencePreview {
+		limit = 10
+	}
+	after := time.Now()
+	if recurrence.StartAt.After(after) {
+		after = recurrence.StartAt.Add(-time.Nanosecond)
+	}
+	return rule.Occurrences(recurrence.StartAt, after, limit), nil
+}
+
+// RunNow spawns an instance immediately without changing the schedule.
+func (s *TaskRecurrenceService) RunNow(ctx context.Context, id string) (*tables.TaskTable, error) {
+	recurrence, err := s.GetRecurrence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.spawn(ctx, recurrence, time.Now())
+}
+
+// SpawnDue instantiates every enabled recurrence whose next run is due. Occurrences
+// missed while the server was down collapse into a single instance for the latest one.
+func (s *TaskRecurrenceService) SpawnDue(ctx context.Context, now time.Time) ([]tables.TaskTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = WithActor(ctx, TaskActorScheduler)
+
+	var due []tables.TaskRecurrenceTable
+	if err := dbCtx.
+		Where("enabled = ? AND next_run_at IS NOT NULL AND next_run_at <= ?", true, now).
+		Order("next_run_at ASC").
+		Find(&due).Error; err != nil {
+		return nil, err
+	}
+
+	var spawned []tables.TaskTable
+	var errs []error
+	for i := range due {
+		recurrence := &due[i]
+		rule, err := ParseRecurrenceRule(recurrence.Rule)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("recurrence %s: %w", recurrence.ID, err))
+			continue
+		}
+
+		occurrence := *recurrence.NextRunAt
+		for {
+			next, ok := rule.Next(recurrence.StartAt, occurrence)
+			if !ok || next.After(now) {
+				break
+			}
+			occurrence = next
+		}
+
+		skip, err := s.lastInstanceOpen(dbCtx, recurrence)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !skip {
+			task, err := s.spawn(ctx, recurrence, occurrence)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("recurrence %s: %w", recurrence.ID, err))
+				continue
+			}
+			spawned = append(spawned, *task)
+		}
+
+		var nextRunAt *time.Time
+		if next, ok := rule.Next(recurrence.StartAt, now); ok {
+			nextRunAt = &next
+		}
+		if err := dbCtx.
+			Model(&tables.TaskRecurrenceTable{}).
+			Where("id = ?", recurrence.ID).
+			Update("next_run_at", nextRunAt).Error; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return spawned, errors.Join(errs...)
+}
+
+func (s *TaskRecurrenceService) spawn(ctx context.Context, recurrence *tables.TaskRecurrenceTable, occurrence time.Time) (*tables.TaskTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &CreateTaskRequest{
+		ProjectID:    recurrence.ProjectID,
+		Title:        recurrence.Title,
+		Description:  recurrence.Description,
+		Status:       recurrence.Status,
+		Priority:     recurrence.Priority,
+		Tags:         recurrence.Tags,
+		RecurrenceID: &recurrence.ID,
+	}
+	if recurrence.DueAfterMinutes != nil {
+		dueDate := occurrence.Add(time.Duration(*recurrence.DueAfterMinutes) * time.Minute)
+		req.DueDate = &dueDate
+	}
+	task, err := s.taskSvc.CreateTask(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := dbCtx.
+		Model(&tables.TaskRecurrenceTable{}).
+		Where("id = ?", recurrence.ID).
+		Updates(map[string]interface{}{
+			"last_run_at":   now,
+			"last_task_id":  task.ID,
+			"spawned_count": gorm.Expr("spawned_count + 1"),
+		}).Error; err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// lastInstanceOpen reports whether SkipIfOpen applies because the previous instance is still open.
+func (s *TaskRecurrenceService) lastInstanceOpen(dbCtx *gorm.DB, recurrence *tables.TaskRecurrenceTable) (bool, error) {
+	if !recurrence.SkipIfOpen || recurrence.LastTaskID == nil {
+		return false, nil
+	}
+	var last tables.TaskTable
+	if err := dbCtx.First(&last, "id = ?", *recurrence.LastTaskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	workflow, err := loadTaskWorkflow(dbCtx, last.ProjectID)
+	if err != nil {
+		return false, err
+	}
+	category := workflow.category(last.Status)
+	return category != TaskCategoryDone && category != TaskCategoryArchived, nil
+}
+
+// apply validates req onto recurrence and recomputes the next run.
+func (s *TaskRecurrenceService) apply(dbCtx *gorm.DB, recurrence *tables.TaskRecurrenceTable, req *SaveTaskRecurrenceRequest, now time.Time) error {
+	if req == nil {
+		return fmt.Errorf("%w: request is required", ErrInvalidTaskRecurrence)
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidTaskRecurrence)
+	}
+	rule, err := ParseRecurrenceRule(req.Rule)
+	if err != nil {
+		return err
+	}
+	if req.DueAfterMinutes != nil && *req.DueAfterMinutes < 0 {
+		return fmt.Errorf("%w: dueAfterMinutes must not be negative", ErrInvalidTaskRecurrence)
+	}
+
+	status := strings.TrimSpace(req.Status)
+	if status != "" {
+		workflow, err := loadTaskWorkflow(dbCtx, recurrence.ProjectID)
+		if err != nil {
+			return err
+		}
+		if workflow.column(status) == nil {
+			return fmt.Errorf("%w: %s", ErrInvalidTaskStatus, status)
+		}
+	}
+
+	startAt := now.Truncate(time.Minute)
+	if req.StartAt != nil {
+		startAt = *req.StartAt
+	}
+
+	recurrence.Title = title
+	recurrence.Description = req.Description
+	recurrence.Status = status
+	recurrence.Priority = req.Priority
+	recurrence.Tags = sanitizeTags(req.Tags)
+	recurrence.Rule = strings.TrimSpace(req.Rule)
+	recurrence.StartAt = startAt
+	recurrence.DueAfterMinutes = req.DueAfterMinutes
+	recurrence.SkipIfOpen = req.SkipIfOpen
+	if req.Enabled != nil {
+		recurrence.Enabled = *req.Enabled
+	}
+
+	// 起始时间在过去时从现在开始排期，不补建历史实例
+	after := now
+	if startAt.After(now) {
+		after = startAt.Add(-time.Nanosecond)
+	}
+	recurrence.NextRunAt = nil
+	if next, ok := rule.Next(startAt, after); ok {
+		recurrence.NextRunAt = &next
+	}
+	return nil
+}
+
+func (s *TaskRecurrenceService) dbWithContext(ctx context.Context) (*gorm.DB, error) {
+	if db == nil {
+		return nil, ErrDBNotInitialized
+	}
+	return db.WithContext(ensureContext(ctx)), nil
+}
@@ -0,0 +1,175 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code-kanban/model/tables"
+)
+
+func TestTaskRecurrenceSpawnDue(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	svc := NewTaskRecurrenceService()
+
+	// 起始时间须在未来，否则创建时会从当前时间开始排期
+	base := time.Now().UTC().AddDate(0, 0, 7)
+	base = base.AddDate(0, 0, (8-int(base.Weekday()))%7)
+	start := time.Date(base.Year(), base.Month(), base.Day(), 9, 0, 0, 0, time.UTC)
+	dueAfter := 60 * 24
+	recurrence, err := svc.CreateRecurrence(ctx, project.ID, &SaveTaskRecurrenceRequest{
+		Title:           "Weekly dependency upgrade",
+		Tags:            tables.StringArray{"chore"},
+		Rule:            "FREQ=WEEKLY;BYDAY=MO;BYHOUR=9;BYMINUTE=0",
+		StartAt:         &start,
+		DueAfterMinutes: &dueAfter,
+		SkipIfOpen:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateRecurrence returned error: %v", err)
+	}
+	if recurrence.NextRunAt == nil {
+		t.Fatalf("expected next run to be scheduled")
+	}
+
+	// Three Mondays were missed; only one instance is spawned for the latest.
+	now := start.AddDate(0, 0, 15)
+	spawned, err := svc.SpawnDue(ctx, now)
+	if err != nil {
+		t.Fatalf("SpawnDue returned error: %v", err)
+	}
+	if len(spawned) != 1 {
+		t.Fatalf("expected one spawned task, got %d", len(spawned))
+	}
+	task := spawned[0]
+	if task.RecurrenceID == nil || *task.RecurrenceID != recurrence.ID {
+		t.Fatalf("expected task to reference recurrence, got %+v", task.RecurrenceID)
+	}
+	if task.DueDate == nil || !task.DueDate.Equal(start.AddDate(0, 0, 15)) {
+		t.Fatalf("unexpected due date %v", task.DueDate)
+	}
+
+	recurrence, err = svc.GetRecurrence(ctx, recurrence.ID)
+	if err != nil {
+		t.Fatalf("GetRecurrence returned error: %v", err)
+	}
+	if recurrence.NextRunAt == nil || !recurrence.NextRunAt.Equal(start.AddDate(0, 0, 21)) {
+		t.Fatalf("unexpected next run %v", recurrence.NextRunAt)
+	}
+	if recurrence.SpawnedCount != 1 || recurrence.LastTaskID == nil || *recurrence.LastTaskID != task.ID {
+		t.Fatalf("unexpected recurrence bookkeeping %+v", recurrence)
+	}
+
+	// The previous instance is still open, so the next occurrence is skipped.
+	spawned, err = svc.SpawnDue(ctx, start.AddDate(0, 0, 21).Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("SpawnDue returned error: %v", err)
+	}
+	if len(spawned) != 0 {
+		t.Fatalf("expected SkipIfOpen to skip the occurrence, got %d tasks", len(spawned))
+	}
+
+	if _, err := (&TaskService{}).UpdateTask(ctx, task.ID, map[string]interface{}{"status": "done"}); err != nil {
+		t.Fatalf("UpdateTask returned error: %v", err)
+	}
+	spawned, err = svc.SpawnDue(ctx, start.AddDate(0, 0, 28).Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("SpawnDue returned error: %v", err)
+	}
+	if len(spawned) != 1 {
+		t.Fatalf("expected a new instance once the previous one is done, got %d", len(spawned))
+	}
+}
+
+func TestTaskRecurrenceRejectsInvalidRule(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	project := seedProject(t)
+	_, err := NewTaskRecurrenceService().CreateRecurrence(context.Background(), project.ID, &SaveTaskRecurrenceRequest{
+		Title: "flaky-test triage",
+		Rule:  "FREQ=FORTNIGHTLY",
+	})
+	if err == nil {
+		t.Fatalf("expected invalid rule to be rejected")
+	}
+}
+
+func TestFlagOverdueTasks(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	tasks := &TaskService{}
+
+	due := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	late, err := tasks.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "flaky-test triage", DueDate: &due})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	done, err := tasks.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "already shipped", Status: "done", DueDate: &due})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	if _, err := (&TaskColumnService{}).CreateColumn(ctx, &CreateTaskColumnRequest{
+		ProjectID: project.ID,
+		Key:       "released",
+		Category:  TaskCategoryDone,
+	}); err != nil {
+		t.Fatalf("CreateColumn returned error: %v", err)
+	}
+	if _, err := tasks.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "released", Status: "released", DueDate: &due}); err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+
+	now := due.Add(time.Hour)
+	flagged, err := tasks.FlagOverdueTasks(ctx, now)
+	if err != nil {
+		t.Fatalf("FlagOverdueTasks returned error: %v", err)
+	}
+	if len(flagged) != 1 || flagged[0].ID != late.ID {
+		t.Fatalf("expected only the open task to be flagged, got %+v", flagged)
+	}
+	if flagged, _ = tasks.FlagOverdueTasks(ctx, now.Add(time.Hour)); len(flagged) != 0 {
+		t.Fatalf("expected task to be flagged only once, got %d", len(flagged))
+	}
+
+	events, err := NewTaskEventService().ListEvents(ctx, late.ID)
+	if err != nil {
+		t.Fatalf("ListEvents returned error: %v", err)
+	}
+	overdueEvents := 0
+	for _, event := range events {
+		if event.Type == TaskEventOverdue {
+			overdueEvents++
+			if event.Actor != TaskActorScheduler {
+				t.Fatalf("expected scheduler actor, got %q", event.Actor)
+			}
+		}
+	}
+	if overdueEvents != 1 {
+		t.Fatalf("expected one overdue event, got %d", overdueEvents)
+	}
+
+	overdue, err := tasks.ListOverdueTasks(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("ListOverdueTasks returned error: %v", err)
+	}
+	if len(overdue) != 1 || overdue[0].ID == done.ID {
+		t.Fatalf("unexpected overdue list %+v", overdue)
+	}
+
+	extended := due.AddDate(0, 0, 7)
+	updated, err := tasks.UpdateTask(ctx, late.ID, map[string]interface{}{"due_date": extended})
+	if err != nil {
+		t.Fatalf("UpdateTask returned error: %v", err)
+	}
+	if updated.OverdueAt != nil {
+		t.Fatalf("expected rescheduling to clear the overdue flag")
+	}
+}
@@ -0,0 +1,486 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"code-kanban/model/tables"
+
+	"gorm.io/gorm"
+)
+
+const (
+	reportDateLayout       = "2006-01-02"
+	defaultReportRangeDays = 30
+	maxReportRangeDays     = 366
+)
+
+// ErrInvalidReportRange indicates the requested report date range is malformed.
+var ErrInvalidReportRange = errors.New("invalid report range")
+
+// ReportColumn describes a board column in a report.
+type ReportColumn struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+// BurndownPoint is the state of a project at the end of one day.
+type BurndownPoint struct {
+	Date            string  `json:"date"`
+	Scope           int     `json:"scope"`      // 当天结束时看板上（未删除）的任务数
+	Completed       int     `json:"completed"`  // 处于完成或归档列的任务数
+	Remaining       int     `json:"remaining"`  // 尚未完成的任务数
+	Throughput      int     `json:"throughput"` // 当天进入完成列的任务数
+	RemainingPoints float64 `json:"remainingPoints"`
+	CompletedPoints float64 `json:"completedPoints"`
+}
+
+// BurndownReport lists daily burndown points for a project.
+type BurndownReport struct {
+	ProjectID string          `json:"projectId"`
+	From      string          `json:"from"`
+	To        string          `json:"to"`
+	Points    []BurndownPoint `json:"points"`
+}
+
+// CumulativeFlowDay holds the number of tasks in each column at the end of a day.
+type CumulativeFlowDay struct {
+	Date   string         `json:"date"`
+	Counts map[string]int `json:"counts"` // 列 key -> 任务数
+}
+
+// CumulativeFlowReport is the data behind a cumulative flow diagram.
+type CumulativeFlowReport struct {
+	ProjectID string              `json:"projectId"`
+	From      string              `json:"from"`
+	To        string              `json:"to"`
+	Columns   []ReportColumn      `json:"columns"`
+	Days      []CumulativeFlowDay `json:"days"`
+}
+
+// FlowStats summarises a set of durations in hours.
+type FlowStats struct {
+	Samples     int     `json:"samples"`
+	AvgHours    float64 `json:"avgHours"`
+	MedianHours float64 `json:"medianHours"`
+	P85Hours    float64 `json:"p85Hours"`
+}
+
+// ColumnFlowStats reports how work moved through one column.
+type ColumnFlowStats struct {
+	ReportColumn
+	Entered int       `json:"entered"` // 区间内进入该列的次数
+	Exited  int       `json:"exited"`  // 区间内离开该列的次数（即该列的吞吐量）
+	WIP     int       `json:"wip"`     // 区间结束时停留在该列的任务数
+	Time    FlowStats `json:"time"`    // 区间内离开该列时在列中停留的时长
+}
+
+// CycleTimeReport reports throughput and time spent per column.
+type CycleTimeReport struct {
+	ProjectID  string            `json:"projectId"`
+	From       string            `json:"from"`
+	To         string            `json:"to"`
+	Throughput int               `json:"throughput"` // 区间内完成的任务数
+	CycleTime  FlowStats         `json:"cycleTime"`  // 首次进入进行中到完成的时长
+	LeadTime   FlowStats         `json:"leadTime"`   // 创建到完成的时长
+	Columns    []ColumnFlowStats `json:"columns"`
+}
+
+// TaskReportService computes project reports from the task status history.
+type TaskReportService struct {
+	now func() time.Time
+}
+
+// NewTaskReportService constructs a report service.
+func NewTaskReportService() *TaskReportService {
+	return &TaskReportService{now: time.Now}
+}
+
+type reportRange struct {
+	from time.Time // 起始日 00:00
+	to   time.Time // 结束日 00:00
+}
+
+func (r reportRange) days() []time.Time {
+	var days []time.Time
+	for day := r.from; !day.After(r.to); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+func (r reportRange) end() time.Time {
+	return r.to.AddDate(0, 0, 1)
+}
+
+// parseReportRange reads from/to dates, defaulting to the last 30 days ending today.
+func parseReportRange(from, to string, now time.Time) (reportRange, error) {
+	loc := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	parse := func(value string, fallback time.Time) (time.Time, error) {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return fallback, nil
+		}
+		parsed, err := time.ParseInLocation(reportDateLayout, value, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %q is not a YYYY-MM-DD date", ErrInvalidReportRange, value)
+		}
+		return parsed, nil
+	}
+
+	end, err := parse(to, today)
+	if err != nil {
+		return reportRange{}, err
+	}
+	start, err := parse(from, end.AddDate(0, 0, -(defaultReportRangeDays-1)))
+	if err != nil {
+		return reportRange{}, err
+	}
+	if end.Before(start) {
+		return reportRange{}, fmt.Errorf("%w: from must not be after to", ErrInvalidReportRange)
+	}
+	if start.AddDate(0, 0, maxReportRangeDays).Before(end) {
+		return reportRange{}, fmt.Errorf("%w: at most %d days", ErrInvalidReportRange, maxReportRangeDays)
+	}
+	return reportRange{from: start, to: end}, nil
+}
+
+type statusSegment struct {
+	status string
+	start  time.Time
+	end    time.Time // 零值表示仍停留在该状态
+}
+
+type taskTimeline struct {
+	task     tables.TaskTable
+	segments []statusSegment
+}
+
+func (tl *taskTimeline) statusAt(at time.Time) (string, bool) {
+	for _, segment := range tl.segments {
+		if segment.start.After(at) {
+			break
+		}
+		if segment.end.IsZero() || at.Before(segment.end) {
+			return segment.status, true
+		}
+	}
+	return "", false
+}
+
+func (tl *taskTimeline) open(status string, at time.Time) {
+	tl.close(at)
+	tl.segments = append(tl.segments, statusSegment{status: status, start: at})
+}
+
+func (tl *taskTimeline) close(at time.Time) {
+	if n := len(tl.segments); n > 0 && tl.segments[n-1].end.IsZero() {
+		if at.Before(tl.segments[n-1].start) {
+			at = tl.segments[n-1].start
+		}
+		tl.segments[n-1].end = at
+	}
+}
+
+// loadTaskTimelines rebuilds the status history of every task of a project,
+// including deleted ones, from the event log.
+func loadTaskTimelines(dbCtx *gorm.DB, projectID string) ([]*taskTimeline, error) {
+	var tasks []tables.TaskTable
+	if err := dbCtx.Unscoped().
+		Where("project_id = ?", projectID).
+		Order("created_at ASC").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	var events []tables.TaskEventTable
+	if err := dbCtx.
+		Where("project_id = ? AND type IN ?", projectID, []string{TaskEventCreated, TaskEventStatusChanged, TaskEventDeleted}).
+		Order("created_at ASC").
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	eventsByTask := make(map[string][]tables.TaskEventTable, len(tasks))
+	for _, event := range events {
+		eventsByTask[event.TaskID] = append(eventsByTask[event.TaskID], event)
+	}
+
+	timelines := make([]*taskTimeline, 0, len(tasks))
+	for _, task := range tasks {
+		tl := &taskTimeline{task: task}
+		taskEvents := eventsByTask[task.ID]
+
+		// 早于事件日志的任务没有 created 事件，用第一次状态变更的旧值或当前状态补齐
+		if len(taskEvents) == 0 || taskEvents[0].Type != TaskEventCreated {
+			initial := task.Status
+			for _, event := range taskEvents {
+				if event.Type == TaskEventStatusChanged {
+					initial = event.OldValue
+					break
+				}
+			}
+			tl.open(initial, task.CreatedAt)
+		}
+
+		removed := false
+		for _, event := range taskEvents {
+			switch event.Type {
+			case TaskEventCreated:
+				tl.open(firstNonEmpty(event.NewValue, task.Status), event.CreatedAt)
+			case TaskEventStatusChanged:
+				tl.open(event.NewValue, event.CreatedAt)
+			case TaskEventDeleted:
+				tl.close(event.CreatedAt)
+				removed = true
+			}
+		}
+		if !removed && task.DeletedAt.Valid {
+			tl.close(task.DeletedAt.Time)
+		}
+		timelines = append(timelines, tl)
+	}
+	return timelines, nil
+}
+
+func (s *TaskReportService) prepare(ctx context.Context, projectID, from, to string) (*taskWorkflow, []*taskTimeline, reportRange, error) {
+	if db == nil {
+		return nil, nil, reportRange{}, ErrDBNotInitialized
+	}
+	dbCtx := db.WithContext(ensureContext(ctx))
+	if err := ensureProjectExists(dbCtx, projectID); err != nil {
+		return nil, nil, reportRange{}, err
+	}
+	rng, err := parseReportRange(from, to, s.now())
+	if err != nil {
+		return nil, nil, reportRange{}, err
+	}
+	workflow, err := loadTaskWorkflow(dbCtx, projectID)
+	if err != nil {
+		return nil, nil, reportRange{}, err
+	}
+	timelines, err := loadTaskTimelines(dbCtx, projectID)
+	if err != nil {
+		return nil, nil, reportRange{}, err
+	}
+	return workflow, timelines, rng, nil
+}
+
+// snapshotAt caps a day boundary at the current time so today reflects the live board.
+func (s *TaskReportService) snapshotAt(dayEnd time.Time) time.Time {
+	if now := s.now(); dayEnd.After(now) {
+		return now
+	}
+	return dayEnd.Add(-time.Nanosecond)
+}
+
+func isFinishedCategory(category string) bool {
+	return category == TaskCategoryDone || category == TaskCategoryArchived
+}
+
+// Burndown reports scope, completed and remaining work at the end of every day.
+func (s *TaskReportService) Burndown(ctx context.Context, projectID, from, to string) (*BurndownReport, error) {
+	workflow, timelines, rng, err := s.prepare(ctx, projectID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BurndownReport{
+		ProjectID: projectID,
+		From:      rng.from.Format(reportDateLayout),
+		To:        rng.to.Format(reportDateLayout),
+		Points:    []BurndownPoint{},
+	}
+	for _, day := range rng.days() {
+		dayEnd := day.AddDate(0, 0, 1)
+		at := s.snapshotAt(dayEnd)
+		point := BurndownPoint{Date: day.Format(reportDateLayout)}
+		for _, tl := range timelines {
+			points := 0.0
+			if tl.task.EstimatePoints != nil {
+				points = *tl.task.EstimatePoints
+			}
+			if status, ok := tl.statusAt(at); ok {
+				point.Scope++
+				if isFinishedCategory(workflow.category(status)) {
+					point.Completed++
+					point.CompletedPoints += points
+				} else {
+					point.Remaining++
+					point.RemainingPoints += points
+				}
+			}
+			for i, segment := range tl.segments {
+				if segment.start.Before(day) || !segment.start.Before(dayEnd) {
+					continue
+				}
+				if workflow.category(segment.status) != TaskCategoryDone {
+					continue
+				}
+				// 从完成列之间或归档列移回完成列不重复计入吞吐量
+				if i > 0 && isFinishedCategory(workflow.category(tl.segments[i-1].status)) {
+					continue
+				}
+				point.Throughput++
+			}
+		}
+		report.Points = append(report.Points, point)
+	}
+	return report, nil
+}
+
+// CumulativeFlow reports how many tasks sat in each column at the end of every day.
+func (s *TaskReportService) CumulativeFlow(ctx context.Context, projectID, from, to string) (*CumulativeFlowReport, error) {
+	workflow, timelines, rng, err := s.prepare(ctx, projectID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CumulativeFlowReport{
+		ProjectID: projectID,
+		From:      rng.from.Format(reportDateLayout),
+		To:        rng.to.Format(reportDateLayout),
+		Columns:   reportColumns(workflow),
+		Days:      []CumulativeFlowDay{},
+	}
+	for _, day := range rng.days() {
+		at := s.snapshotAt(day.AddDate(0, 0, 1))
+		counts := make(map[string]int, len(report.Columns))
+		for _, column := range report.Columns {
+			counts[column.Key] = 0
+		}
+		for _, tl := range timelines {
+			if status, ok := tl.statusAt(at); ok {
+				counts[status]++
+			}
+		}
+		report.Days = append(report.Days, CumulativeFlowDay{Date: day.Format(reportDateLayout), Counts: counts})
+	}
+	return report, nil
+}
+
+// CycleTime reports throughput, cycle/lead time and the time tasks spent in each column.
+func (s *TaskReportService) CycleTime(ctx context.Context, projectID, from, to string) (*CycleTimeReport, error) {
+	workflow, timelines, rng, err := s.prepare(ctx, projectID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := rng.from, rng.end()
+	inRange := func(at time.Time) bool {
+		return !at.Before(start) && at.Before(end)
+	}
+	wipAt := s.snapshotAt(end)
+
+	columns := reportColumns(workflow)
+	stats := make(map[string]*ColumnFlowStats, len(columns))
+	durations := make(map[string][]float64, len(columns))
+	for _, column := range columns {
+		stats[column.Key] = &ColumnFlowStats{ReportColumn: column}
+	}
+	columnStats := func(status string) *ColumnFlowStats {
+		if stat, ok := stats[status]; ok {
+			return stat
+		}
+		// 已删除列中的历史状态单独成行
+		stat := &ColumnFlowStats{ReportColumn: ReportColumn{Key: status, Name: status, Category: workflow.category(status)}}
+		stats[status] = stat
+		columns = append(columns, stat.ReportColumn)
+		return stat
+	}
+
+	report := &CycleTimeReport{
+		ProjectID: projectID,
+		From:      rng.from.Format(reportDateLayout),
+		To:        rng.to.Format(reportDateLayout),
+	}
+	var cycleTimes, leadTimes []float64
+	for _, tl := range timelines {
+		var started time.Time
+		for i, segment := range tl.segments {
+			stat := columnStats(segment.status)
+			if inRange(segment.start) {
+				stat.Entered++
+			}
+			if !segment.end.IsZero() && inRange(segment.end) {
+				stat.Exited++
+				durations[segment.status] = append(durations[segment.status], segment.end.Sub(segment.start).Hours())
+			}
+
+			category := workflow.category(segment.status)
+			if category == TaskCategoryInProgress && started.IsZero() {
+				started = segment.start
+			}
+			if category != TaskCategoryDone || !inRange(segment.start) {
+				continue
+			}
+			if i > 0 && isFinishedCategory(workflow.category(tl.segments[i-1].status)) {
+				continue
+			}
+			report.Throughput++
+			leadTimes = append(leadTimes, segment.start.Sub(tl.segments[0].start).Hours())
+			if !started.IsZero() {
+				cycleTimes = append(cycleTimes, segment.start.Sub(started).Hours())
+			}
+		}
+		if status, ok := tl.statusAt(wipAt); ok {
+			columnStats(status).WIP++
+		}
+	}
+
+	report.CycleTime = computeFlowStats(cycleTimes)
+	report.LeadTime = computeFlowStats(leadTimes)
+	report.Columns = make([]ColumnFlowStats, 0, len(columns))
+	for _, column := range columns {
+		stat := stats[column.Key]
+		stat.Time = computeFlowStats(durations[column.Key])
+		report.Columns = append(report.Columns, *stat)
+	}
+	return report, nil
+}
+
+func reportColumns(workflow *taskWorkflow) []ReportColumn {
+	columns := make([]ReportColumn, 0, len(workflow.columns))
+	for _, column := range workflow.columns {
+		columns = append(columns, ReportColumn{Key: column.Key, Name: column.Name, Category: column.Category})
+	}
+	return columns
+}
+
+func computeFlowStats(hours []float64) FlowStats {
+	if len(hours) == 0 {
+		return FlowStats{}
+	}
+	sorted := append([]float64(nil), hours...)
+	sort.Float64s(sorted)
+	total := 0.0
+	for _, value := range sorted {
+		total += value
+	}
+	return FlowStats{
+		Samples:     len(sorted),
+		AvgHours:    roundHours(total / float64(len(sorted))),
+		MedianHours: roundHours(percentile(sorted, 0.5)),
+		P85Hours:    roundHours(percentile(sorted, 0.85)),
+	}
+}
+
+// percentile interpolates linearly between the closest ranks of a sorted slice.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+func roundHours(value float64) float64 {
+	return math.Round(value*100) / 100
+}
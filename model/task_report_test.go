@@ -0,0 +1,123 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code-kanban/model/tables"
+)
+
+// backdateLatestEvent moves the newest event of a task to a fixed time so the
+// reconstructed history does not depend on when the test runs.
+func backdateLatestEvent(t *testing.T, taskID string, at time.Time) {
+	t.Helper()
+	var event tables.TaskEventTable
+	if err := db.Where("task_id = ?", taskID).Order("created_at DESC").First(&event).Error; err != nil {
+		t.Fatalf("load latest event: %v", err)
+	}
+	if err := db.Model(&tables.TaskEventTable{}).Where("id = ?", event.ID).UpdateColumn("created_at", at).Error; err != nil {
+		t.Fatalf("backdate event: %v", err)
+	}
+}
+
+func TestTaskReports(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	taskSvc := &TaskService{}
+	day := func(d, hour int) time.Time {
+		return time.Date(2026, 3, d, hour, 0, 0, 0, time.Local)
+	}
+	create := func(title string, points float64, at time.Time) *tables.TaskTable {
+		task, err := taskSvc.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: title, EstimatePoints: &points})
+		if err != nil {
+			t.Fatalf("CreateTask returned error: %v", err)
+		}
+		backdateLatestEvent(t, task.ID, at)
+		return task
+	}
+	move := func(task *tables.TaskTable, status string, at time.Time) {
+		if _, err := taskSvc.MoveTask(ctx, task.ID, &MoveTaskRequest{Status: status}); err != nil {
+			t.Fatalf("MoveTask returned error: %v", err)
+		}
+		backdateLatestEvent(t, task.ID, at)
+	}
+
+	a := create("A", 3, day(2, 10))
+	b := create("B", 2, day(2, 11))
+	c := create("C", 1, day(3, 12))
+	move(a, "in_progress", day(3, 10))
+	move(a, "done", day(4, 10))
+	move(b, "in_progress", day(4, 9))
+	if err := taskSvc.DeleteTask(ctx, c.ID); err != nil {
+		t.Fatalf("DeleteTask returned error: %v", err)
+	}
+	backdateLatestEvent(t, c.ID, day(4, 15))
+
+	svc := NewTaskReportService()
+	svc.now = func() time.Time { return day(5, 12) }
+
+	burndown, err := svc.Burndown(ctx, project.ID, "2026-03-02", "2026-03-05")
+	if err != nil {
+		t.Fatalf("Burndown returned error: %v", err)
+	}
+	expected := []BurndownPoint{
+		{Date: "2026-03-02", Scope: 2, Remaining: 2, RemainingPoints: 5},
+		{Date: "2026-03-03", Scope: 3, Remaining: 3, RemainingPoints: 6},
+		{Date: "2026-03-04", Scope: 2, Completed: 1, Remaining: 1, Throughput: 1, RemainingPoints: 2, CompletedPoints: 3},
+		{Date: "2026-03-05", Scope: 2, Completed: 1, Remaining: 1, RemainingPoints: 2, CompletedPoints: 3},
+	}
+	if len(burndown.Points) != len(expected) {
+		t.Fatalf("expected %d points, got %+v", len(expected), burndown.Points)
+	}
+	for i, point := range burndown.Points {
+		if point != expected[i] {
+			t.Fatalf("point %d: expected %+v, got %+v", i, expected[i], point)
+		}
+	}
+
+	flow, err := svc.CumulativeFlow(ctx, project.ID, "2026-03-03", "2026-03-04")
+	if err != nil {
+		t.Fatalf("CumulativeFlow returned error: %v", err)
+	}
+	if len(flow.Columns) != 4 || len(flow.Days) != 2 {
+		t.Fatalf("unexpected cumulative flow shape: %+v", flow)
+	}
+	if got := flow.Days[0].Counts; got["todo"] != 2 || got["in_progress"] != 1 || got["done"] != 0 {
+		t.Fatalf("unexpected counts on 03-03: %v", got)
+	}
+	if got := flow.Days[1].Counts; got["todo"] != 0 || got["in_progress"] != 1 || got["done"] != 1 {
+		t.Fatalf("unexpected counts on 03-04: %v", got)
+	}
+
+	cycle, err := svc.CycleTime(ctx, project.ID, "2026-03-02", "2026-03-05")
+	if err != nil {
+		t.Fatalf("CycleTime returned error: %v", err)
+	}
+	if cycle.Throughput != 1 || cycle.CycleTime.MedianHours != 24 || cycle.LeadTime.MedianHours != 48 {
+		t.Fatalf("unexpected throughput/cycle/lead: %d %+v %+v", cycle.Throughput, cycle.CycleTime, cycle.LeadTime)
+	}
+	columns := map[string]ColumnFlowStats{}
+	for _, column := range cycle.Columns {
+		columns[column.Key] = column
+	}
+	todo := columns["todo"]
+	if todo.Entered != 3 || todo.Exited != 3 || todo.WIP != 0 || todo.Time.MedianHours != 27 || todo.Time.AvgHours != 32.33 {
+		t.Fatalf("unexpected todo stats: %+v", todo)
+	}
+	if progress := columns["in_progress"]; progress.Entered != 2 || progress.Exited != 1 || progress.WIP != 1 || progress.Time.AvgHours != 24 {
+		t.Fatalf("unexpected in_progress stats: %+v", progress)
+	}
+	if done := columns["done"]; done.Entered != 1 || done.WIP != 1 {
+		t.Fatalf("unexpected done stats: %+v", done)
+	}
+
+	for _, invalid := range [][2]string{{"2026-03-05", "2026-03-01"}, {"03/01/2026", ""}, {"2025-01-01", "2026-03-01"}} {
+		if _, err := svc.Burndown(ctx, project.ID, invalid[0], invalid[1]); err == nil {
+			t.Fatalf("expected range %v to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,536 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"code-kanban/model/tables"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidTaskQuery indicates the search query could not be parsed.
+var ErrInvalidTaskQuery = errors.New("invalid task query")
+
+const (
+	taskSearchTable = "task_search"
+	// trigram 分词器要求检索词至少 3 个字符，更短的词退化为 LIKE 匹配
+	taskSearchMinTermRunes = 3
+	taskHighlightOpen      = "<mark>"
+	taskHighlightClose     = "</mark>"
+	// 高亮先用私用区字符占位，转义 HTML 后再替换为标签，避免用户文本被当作 HTML 渲染
+	taskHighlightOpenSentinel  = "\uE000"
+	taskHighlightCloseSentinel = "\uE001"
+)
+
+// TaskQuery is the parsed form of a task search string such as
+// `tag:backend status:in_progress branch:feat/* due:<2026-11-01 "exact phrase"`.
+type TaskQuery struct {
+	Terms    []string
+	Phrases  []string
+	Tags     [][]string // 每组之间为 AND，组内（逗号分隔）为 OR
+	Statuses []string
+	Branches []string // 支持 * 和 ? 通配
+	Priority *int
+	Due      []TaskDueFilter
+	NoDue    bool
+}
+
+// TaskDueFilter compares the task due date against a day boundary.
+type TaskDueFilter struct {
+	Op   string
+	Date time.Time
+}
+
+// SearchTasksRequest configures a ranked search. ProjectID may be empty to search all projects.
+type SearchTasksRequest struct {
+	ProjectID string
+	Query     string
+	Page      int
+	PageSize  int
+}
+
+// TaskSearchResult is a ranked search hit with highlighted fragments. TitleHighlight and
+// Snippet are HTML-escaped with matches wrapped in <mark>.
+type TaskSearchResult struct {
+	Task           tables.TaskTable `json:"task"`
+	Score          float64          `json:"score"`
+	TitleHighlight string           `json:"titleHighlight"`
+	Snippet        string           `json:"snippet"`
+}
+
+// IsEmpty reports whether the query carries neither filters nor text.
+func (q *TaskQuery) IsEmpty() bool {
+	return q == nil || (!q.HasText() && len(q.Tags) == 0 && len(q.Statuses) == 0 &&
+		len(q.Branches) == 0 && q.Priority == nil && len(q.Due) == 0 && !q.NoDue)
+}
+
+// HasText reports whether the query contains free text or phrases.
+func (q *TaskQuery) HasText() bool {
+	return q != nil && (len(q.Terms) > 0 || len(q.Phrases) > 0)
+}
+
+// ParseTaskQuery parses the search syntax. Supported qualifiers are tag:, status:,
+// branch:, priority: and due: (due:<2026-11-01, due:>=2026-10-01, due:2026-11-01, due:none);
+// unknown qualifiers are searched as plain text.
+func ParseTaskQuery(raw string) (*TaskQuery, error) {
+	tokens, err := tokenizeTaskQuery(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &TaskQuery{}
+	for _, token := range tokens {
+		if token.quoted {
+			if token.value != "" {
+				query.Phrases = append(query.Phrases, token.value)
+			}
+			continue
+		}
+
+		key, value, found := strings.Cut(token.value, ":")
+		if !found {
+			query.Terms = append(query.Terms, token.value)
+			continue
+		}
+		key = strings.ToLower(key)
+		switch key {
+		case "tag", "tags":
+			group := splitQueryValues(value)
+			if len(group) == 0 {
+				return nil, fmt.Errorf("%w: tag requires a value", ErrInvalidTaskQuery)
+			}
+			query.Tags = append(query.Tags, group)
+		case "status":
+			values := splitQueryValues(value)
+			if len(values) == 0 {
+				return nil, fmt.Errorf("%w: status requires a value", ErrInvalidTaskQuery)
+			}
+			query.Statuses = append(query.Statuses, values...)
+		case "branch":
+			values := splitQueryValues(value)
+			if len(values) == 0 {
+				return nil, fmt.Errorf("%w: branch requires a value", ErrInvalidTaskQuery)
+			}
+			query.Branches = append(query.Branches, values...)
+		case "priority":
+			priority, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid priority %q", ErrInvalidTaskQuery, value)
+			}
+			query.Priority = &priority
+		case "due":
+			if strings.EqualFold(value, "none") {
+				query.NoDue = true
+				continue
+			}
+			filter, err := parseDueFilter(value)
+			if err != nil {
+				return nil, err
+			}
+			query.Due = append(query.Due, filter)
+		default:
+			query.Terms = append(query.Terms, token.value)
+		}
+	}
+	return query, nil
+}
+
+// SearchTasks runs a ranked full-text search over tasks, their tags, branches and comments.
+func (s *TaskService) SearchTasks(ctx context.Context, req *SearchTasksRequest) ([]TaskSearchResult, int64, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if req == nil {
+		return nil, 0, fmt.Errorf("request is required")
+	}
+
+	query, err := ParseTaskQuery(req.Query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page, pageSize := normalizePage(req.Page, req.PageSize)
+	req.Page = page
+	req.PageSize = pageSize
+	offset := (page - 1) * pageSize
+
+	matchExpr, shortTerms := query.matchExpression()
+	var base *gorm.DB
+	if matchExpr != "" {
+		base = dbCtx.Table(taskSearchTable).
+			Joins("JOIN tasks ON tasks.id = task_search.task_id AND tasks.deleted_at IS NULL").
+			Where("task_search MATCH ?", matchExpr)
+	} else {
+		base = dbCtx.Model(&tables.TaskTable{})
+	}
+	if strings.TrimSpace(req.ProjectID) != "" {
+		base = base.Where("tasks.project_id = ?", req.ProjectID)
+	}
+	base = applyTaskQueryFilters(base, query)
+	base = applyShortTermFilters(base, shortTerms)
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []struct {
+		ID             string
+		Score          float64
+		TitleHighlight string
+		Snippet        string
+	}
+	ranked := base.Session(&gorm.Session{})
+	if matchExpr != "" {
+		// bm25 列权重顺序：task_id, project_id, title, description, tags, branch, comments；
+		// trigram 下 snippet 的长度按三元组计数，约等于字符数
+		ranked = ranked.Select(fmt.Sprintf(
+			"tasks.id AS id, -bm25(task_search, 0, 0, 10.0, 4.0, 6.0, 6.0, 2.0) AS score, "+
+				"highlight(task_search, 2, '%[1]s', '%[2]s') AS title_highlight, "+
+				"snippet(task_search, -1, '%[1]s', '%[2]s', '…', 48) AS snippet",
+			taskHighlightOpenSentinel, taskHighlightCloseSentinel)).
+			Order("score DESC")
+	} else {
+		ranked = ranked.Select("tasks.id AS id").Order("tasks.updated_at DESC")
+	}
+	if err := ranked.Offset(offset).Limit(pageSize).Scan(&hits).Error; err != nil {
+		return nil, 0, err
+	}
+	if len(hits) == 0 {
+		return []TaskSearchResult{}, total, nil
+	}
+
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	var tasks []tables.TaskTable
+	if err := dbCtx.Preload("Worktree").Where("id IN ?", ids).Find(&tasks).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := attachChecklistProgress(dbCtx, tasks); err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[string]tables.TaskTable, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	results := make([]TaskSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		task, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		result := TaskSearchResult{
+			Task:           task,
+			Score:          hit.Score,
+			TitleHighlight: renderTaskHighlight(hit.TitleHighlight),
+			Snippet:        renderTaskHighlight(hit.Snippet),
+		}
+		if matchExpr == "" && len(shortTerms) > 0 {
+			// 仅有短词时无法使用 FTS 高亮函数，退化为按词标记标题
+			result.TitleHighlight = renderTaskHighlight(highlightTerms(task.Title, shortTerms))
+		}
+		results = append(results, result)
+	}
+	return results, total, nil
+}
+
+// applyTaskQuery restricts a tasks query to rows matching the parsed query, without ranking.
+func applyTaskQuery(query *gorm.DB, q *TaskQuery) *gorm.DB {
+	if q.IsEmpty() {
+		return query
+	}
+	query = applyTaskQueryFilters(query, q)
+	matchExpr, shortTerms := q.matchExpression()
+	if matchExpr != "" {
+		query = query.Where("tasks.id IN (SELECT task_id FROM task_search WHERE task_search MATCH ?)", matchExpr)
+	}
+	return applyShortTermFilters(query, shortTerms)
+}
+
+func applyTaskQueryFilters(query *gorm.DB, q *TaskQuery) *gorm.DB {
+	if len(q.Statuses) > 0 {
+		query = query.Where("tasks.status IN ?", q.Statuses)
+	}
+	if len(q.Branches) > 0 {
+		clauses := make([]string, 0, len(q.Branches))
+		args := make([]any, 0, len(q.Branches))
+		for _, pattern := range q.Branches {
+			clauses = append(clauses, "tasks.branch_name GLOB ?")
+			args = append(args, pattern)
+		}
+		query = query.Where("("+strings.Join(clauses, " OR ")+")", args...)
+	}
+	for _, group := range q.Tags {
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM json_each(CASE WHEN json_valid(tasks.tags) THEN tasks.tags ELSE '[]' END) WHERE lower(value) IN ?)",
+			lowerAll(group))
+	}
+	if q.Priority != nil {
+		query = query.Where("tasks.priority = ?", *q.Priority)
+	}
+	if q.NoDue {
+		query = query.Where("tasks.due_date IS NULL")
+	}
+	for _, due := range q.Due {
+		next := due.Date.AddDate(0, 0, 1)
+		switch due.Op {
+		case "<":
+			query = query.Where("tasks.due_date < ?", due.Date)
+		case "<=":
+			query = query.Where("tasks.due_date < ?", next)
+		case ">":
+			query = query.Where("tasks.due_date >= ?", next)
+		case ">=":
+			query = query.Where("tasks.due_date >= ?", due.Date)
+		default:
+			query = query.Where("tasks.due_date >= ? AND tasks.due_date < ?", due.Date, next)
+		}
+	}
+	return query
+}
+
+// applyShortTermFilters matches terms too short for the trigram index with LIKE.
+func applyShortTermFilters(query *gorm.DB, terms []string) *gorm.DB {
+	for _, term := range terms {
+		like := "%" + escapeLike(term) + "%"
+		query = query.Where(
+			"tasks.id IN (SELECT task_id FROM task_search WHERE title LIKE ? ESCAPE '\\' OR description LIKE ? ESCAPE '\\' "+
+				"OR tags LIKE ? ESCAPE '\\' OR branch LIKE ? ESCAPE '\\' OR comments LIKE ? ESCAPE '\\')",
+			like, like, like, like, like)
+	}
+	return query
+}
+
+// matchExpression builds the FTS5 MATCH string and returns the terms that must fall back to LIKE.
+func (q *TaskQuery) matchExpression() (string, []string) {
+	var parts []string
+	var short []string
+	for _, text := range append(append([]string{}, q.Terms...), q.Phrases...) {
+		if utf8.RuneCountInString(text) < taskSearchMinTermRunes {
+			short = append(short, text)
+			continue
+		}
+		parts = append(parts, `"`+strings.ReplaceAll(text, `"`, `""`)+`"`)
+	}
+	return strings.Join(parts, " "), short
+}
+
+type taskQueryToken struct {
+	value  string
+	quoted bool
+}
+
+func tokenizeTaskQuery(raw string) ([]taskQueryToken, error) {
+	var tokens []taskQueryToken
+	var current strings.Builder
+	inQuote := false
+	// key:"quoted value" 视为带引号值的限定符，而非短语
+	qualified := false
+
+	flush := func(quoted bool) {
+		value := current.String()
+		current.Reset()
+		if quoted {
+			tokens = append(tokens, taskQueryToken{value: strings.TrimSpace(value), quoted: true})
+			return
+		}
+		if value != "" {
+			tokens = append(tokens, taskQueryToken{value: value})
+		}
+	}
+
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			if inQuote {
+				inQuote = false
+				flush(!qualified)
+				qualified = false
+				continue
+			}
+			inQuote = true
+			qualified = current.Len() > 0 && strings.HasSuffix(current.String(), ":")
+			if !qualified {
+				flush(false)
+			}
+		case unicode.IsSpace(r) && !inQuote:
+			flush(false)
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidTaskQuery)
+	}
+	flush(false)
+	return tokens, nil
+}
+
+func parseDueFilter(value string) (TaskDueFilter, error) {
+	filter := TaskDueFilter{Op: "="}
+	for _, op := range []string{"<=", ">=", "<", ">", "="} {
+		if strings.HasPrefix(value, op) {
+			filter.Op = op
+			value = strings.TrimPrefix(value, op)
+			break
+		}
+	}
+	date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(value), time.Local)
+	if err != nil {
+		return filter, fmt.Errorf("%w: invalid due date %q", ErrInvalidTaskQuery, value)
+	}
+	filter.Date = date
+	return filter, nil
+}
+
+func splitQueryValues(value string) []string {
+	parts := strings.Split(value, ",")
+	values := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+func lowerAll(values []string) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = strings.ToLower(value)
+	}
+	return result
+}
+
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
+
+// highlightTerms wraps case-insensitive occurrences of terms in text with the highlight sentinels.
+func highlightTerms(text string, terms []string) string {
+	for _, term := range terms {
+		lower := strings.ToLower(text)
+		needle := strings.ToLower(term)
+		// 少数字符大小写转换会改变字节长度，此时放弃高亮，避免切坏字符串
+		if needle == "" || len(lower) != len(text) {
+			continue
+		}
+		var b strings.Builder
+		start := 0
+		for {
+			idx := strings.Index(lower[start:], needle)
+			if idx < 0 {
+				break
+			}
+			idx += start
+			b.WriteString(text[start:idx])
+			b.WriteString(taskHighlightOpenSentinel)
+			b.WriteString(text[idx : idx+len(needle)])
+			b.WriteString(taskHighlightCloseSentinel)
+			start = idx + len(needle)
+		}
+		b.WriteString(text[start:])
+		text = b.String()
+	}
+	return text
+}
+
+// renderTaskHighlight escapes text for HTML and turns the highlight sentinels into <mark> tags.
+func renderTaskHighlight(text string) string {
+	return strings.NewReplacer(
+		taskHighlightOpenSentinel, taskHighlightOpen,
+		taskHighlightCloseSentinel, taskHighlightClose,
+	).Replace(html.EscapeString(text))
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	if pageSize > 200 {
+		pageSize = 200
+	}
+	return page, pageSize
+}
+
+// taskSearchInsertSQL indexes live tasks; callers append extra conditions on t.
+const taskSearchInsertSQL = `INSERT INTO task_search (task_id, project_id, title, description, tags, branch, comments)
+SELECT t.id, t.project_id, t.title, COALESCE(t.description, ''),
+	COALESCE(CASE WHEN json_valid(t.tags) THEN (SELECT group_concat(value, ' ') FROM json_each(t.tags)) ELSE t.tags END, ''),
+	COALESCE(t.branch_name, ''),
+	COALESCE((SELECT group_concat(c.content, char(10)) FROM task_comments c WHERE c.task_id = t.id AND c.deleted_at IS NULL), '')
+FROM tasks t WHERE t.deleted_at IS NULL`
+
+// migrateTaskSearch creates the FTS5 index and the triggers that keep it in sync with
+// tasks and task_comments, so every write path (including raw updates) refreshes it.
+func migrateTaskSearch(database *gorm.DB) error {
+	var existing int64
+	if err := database.
+		Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", taskSearchTable).
+		Scan(&existing).Error; err != nil {
+		return err
+	}
+
+	statements := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS task_search USING fts5(
+			task_id UNINDEXED, project_id UNINDEXED, title, description, tags, branch, comments,
+			tokenize = 'trigram case_sensitive 0'
+		)`,
+	}
+	triggers := []struct {
+		name  string
+		event string
+		table string
+		id    string
+	}{
+		{"task_search_after_insert", "AFTER INSERT", "tasks", "NEW.id"},
+		{"task_search_after_update", "AFTER UPDATE", "tasks", "NEW.id"},
+		{"task_search_after_delete", "AFTER DELETE", "tasks", "OLD.id"},
+		{"task_comment_search_after_insert", "AFTER INSERT", "task_comments", "NEW.task_id"},
+		{"task_comment_search_after_update", "AFTER UPDATE", "task_comments", "NEW.task_id"},
+		{"task_comment_search_after_delete", "AFTER DELETE", "task_comments", "OLD.task_id"},
+	}
+	for _, trigger := range triggers {
+		statements = append(statements, fmt.Sprintf(
+			"CREATE TRIGGER IF NOT EXISTS %s %s ON %s BEGIN %s END",
+			trigger.name, trigger.event, trigger.table,
+			fmt.Sprintf("DELETE FROM task_search WHERE task_id = %[1]s; %[2]s AND t.id = %[1]s;", trigger.id, taskSearchInsertSQL)))
+	}
+
+	for _, statement := range statements {
+		if err := database.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	if existing == 0 {
+		// 首次创建索引时回填已有任务
+		return rebuildTaskSearch(database)
+	}
+	return nil
+}
+
+func rebuildTaskSearch(database *gorm.DB) error {
+	return database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM task_search").Error; err != nil {
+			return err
+		}
+		return tx.Exec(taskSearchInsertSQL).Error
+	})
+}
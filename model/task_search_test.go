@@ -0,0 +1,235 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"code-kanban/model/tables"
+)
+
+func TestParseTaskQuery(t *testing.T) {
+	query, err := ParseTaskQuery(`tag:backend,api status:in_progress branch:feat/* due:<2026-11-01 priority:2 "exact phrase" login url:x`)
+	if err != nil {
+		t.Fatalf("ParseTaskQuery returned error: %v", err)
+	}
+	if len(query.Tags) != 1 || len(query.Tags[0]) != 2 || query.Tags[0][1] != "api" {
+		t.Fatalf("unexpected tags %+v", query.Tags)
+	}
+	if len(query.Statuses) != 1 || query.Statuses[0] != "in_progress" {
+		t.Fatalf("unexpected statuses %+v", query.Statuses)
+	}
+	if len(query.Branches) != 1 || query.Branches[0] != "feat/*" {
+		t.Fatalf("unexpected branches %+v", query.Branches)
+	}
+	if len(query.Due) != 1 || query.Due[0].Op != "<" || query.Due[0].Date.Format("2006-01-02") != "2026-11-01" {
+		t.Fatalf("unexpected due filters %+v", query.Due)
+	}
+	if query.Priority == nil || *query.Priority != 2 {
+		t.Fatalf("unexpected priority %v", query.Priority)
+	}
+	if len(query.Phrases) != 1 || query.Phrases[0] != "exact phrase" {
+		t.Fatalf("unexpected phrases %+v", query.Phrases)
+	}
+	if len(query.Terms) != 2 || query.Terms[0] != "login" || query.Terms[1] != "url:x" {
+		t.Fatalf("unexpected terms %+v", query.Terms)
+	}
+
+	quoted, err := ParseTaskQuery(`tag:"needs review"`)
+	if err != nil {
+		t.Fatalf("ParseTaskQuery returned error: %v", err)
+	}
+	if len(quoted.Tags) != 1 || quoted.Tags[0][0] != "needs review" || quoted.HasText() {
+		t.Fatalf("expected quoted qualifier value, got %+v", quoted)
+	}
+
+	for _, raw := range []string{`"unterminated`, `due:tomorrow`, `priority:high`, `status:`} {
+		if _, err := ParseTaskQuery(raw); !errors.Is(err, ErrInvalidTaskQuery) {
+			t.Fatalf("expected ErrInvalidTaskQuery for %q, got %v", raw, err)
+		}
+	}
+}
+
+func TestTaskServiceSearchTasks(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	worktree := seedWorktree(t, project.ID, "feat/login")
+	tasks := &TaskService{}
+	comments := NewTaskCommentService()
+
+	due := time.Date(2026, 10, 20, 0, 0, 0, 0, time.Local)
+	login, err := tasks.CreateTask(ctx, &CreateTaskRequest{
+		ProjectID:   project.ID,
+		WorktreeID:  &worktree.ID,
+		Title:       "Fix login redirect",
+		Description: "Session cookie is dropped after OAuth callback",
+		Tags:        tables.StringArray{"backend", "auth"},
+		DueDate:     &due,
+	})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	styles, err := tasks.CreateTask(ctx, &CreateTaskRequest{
+		ProjectID:   project.ID,
+		Title:       "修复登录页面样式",
+		Description: "按钮在移动端错位",
+		Tags:        tables.StringArray{"frontend"},
+	})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+
+	search := func(q string) []TaskSearchResult {
+		t.Helper()
+		results, total, err := tasks.SearchTasks(ctx, &SearchTasksRequest{ProjectID: project.ID, Query: q})
+		if err != nil {
+			t.Fatalf("SearchTasks(%q) returned error: %v", q, err)
+		}
+		if int(total) != len(results) {
+			t.Fatalf("SearchTasks(%q) total %d does not match %d results", q, total, len(results))
+		}
+		return results
+	}
+
+	results := search("login")
+	if len(results) != 1 || results[0].Task.ID != login.ID {
+		t.Fatalf("expected login task, got %+v", results)
+	}
+	if !strings.Contains(results[0].TitleHighlight, "<mark>login</mark>") {
+		t.Fatalf("expected highlighted title, got %q", results[0].TitleHighlight)
+	}
+
+	if results := search("登录页"); len(results) != 1 || results[0].Task.ID != styles.ID {
+		t.Fatalf("expected chinese substring match, got %+v", results)
+	}
+	if results := search("tag:backend branch:feat/*"); len(results) != 1 || results[0].Task.ID != login.ID {
+		t.Fatalf("expected tag and branch filters to match, got %+v", results)
+	}
+	if results := search("tag:frontend branch:feat/*"); len(results) != 0 {
+		t.Fatalf("expected no results, got %+v", results)
+	}
+	if results := search("due:<2026-11-01"); len(results) != 1 || results[0].Task.ID != login.ID {
+		t.Fatalf("expected due filter to match, got %+v", results)
+	}
+	if results := search("due:none"); len(results) != 1 || results[0].Task.ID != styles.ID {
+		t.Fatalf("expected due:none to match, got %+v", results)
+	}
+	if results := search(`"cookie is dropped"`); len(results) != 1 {
+		t.Fatalf("expected phrase match, got %+v", results)
+	}
+	if results := search("移动"); len(results) != 1 || results[0].TitleHighlight != styles.Title {
+		t.Fatalf("expected short term fallback match, got %+v", results)
+	}
+
+	comment, err := comments.CreateComment(ctx, styles.ID, "Reproduced on Safari only")
+	if err != nil {
+		t.Fatalf("CreateComment returned error: %v", err)
+	}
+	results = search("safari")
+	if len(results) != 1 || results[0].Task.ID != styles.ID || !strings.Contains(results[0].Snippet, "<mark>Safari</mark>") {
+		t.Fatalf("expected comment match with snippet, got %+v", results)
+	}
+	if err := comments.DeleteComment(ctx, comment.ID); err != nil {
+		t.Fatalf("DeleteComment returned error: %v", err)
+	}
+	if results := search("safari"); len(results) != 0 {
+		t.Fatalf("expected deleted comment to leave index, got %+v", results)
+	}
+
+	if _, err := tasks.UpdateTask(ctx, login.ID, map[string]interface{}{"title": "Fix SSO redirect"}); err != nil {
+		t.Fatalf("UpdateTask returned error: %v", err)
+	}
+	if results := search("login redirect"); len(results) != 1 {
+		t.Fatalf("expected branch name to keep matching login, got %+v", results)
+	}
+	if results := search("SSO"); len(results) != 1 {
+		t.Fatalf("expected updated title to be indexed, got %+v", results)
+	}
+
+	listed, _, err := tasks.ListTasks(ctx, &ListTasksRequest{ProjectID: project.ID, Keyword: "tag:frontend"})
+	if err != nil {
+		t.Fatalf("ListTasks returned error: %v", err)
+	}
+	if len(listed) != 1 || listed[0].ID != styles.ID {
+		t.Fatalf("expected keyword query syntax in ListTasks, got %+v", listed)
+	}
+
+	if err := tasks.DeleteTask(ctx, login.ID); err != nil {
+		t.Fatalf("DeleteTask returned error: %v", err)
+	}
+	if results := search("redirect"); len(results) != 0 {
+		t.Fatalf("expected deleted task to leave index, got %+v", results)
+	}
+}
+
+func TestTaskServiceListTasksFallsBackToLiteralKeyword(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	tasks := &TaskService{}
+
+	task, err := tasks.CreateTask(ctx, &CreateTaskRequest{
+		ProjectID: project.ID,
+		Title:     "Reject empty tag: filters",
+	})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	if _, err := tasks.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "Unrelated"}); err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+
+	listed, total, err := tasks.ListTasks(ctx, &ListTasksRequest{ProjectID: project.ID, Keyword: "tag:"})
+	if err != nil {
+		t.Fatalf("ListTasks returned error: %v", err)
+	}
+	if total != 1 || len(listed) != 1 || listed[0].ID != task.ID {
+		t.Fatalf("expected literal keyword match, got total=%d tasks=%+v", total, listed)
+	}
+
+	if _, _, err := tasks.SearchTasks(ctx, &SearchTasksRequest{ProjectID: project.ID, Query: "tag:"}); !errors.Is(err, ErrInvalidTaskQuery) {
+		t.Fatalf("expected search to reject invalid query, got %v", err)
+	}
+}
+
+func TestTaskServiceSearchTasksEscapesHighlights(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	tasks := &TaskService{}
+
+	if _, err := tasks.CreateTask(ctx, &CreateTaskRequest{
+		ProjectID:   project.ID,
+		Title:       `<img src=x onerror=alert(1)> payload`,
+		Description: `<script>alert("payload")</script>`,
+	}); err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+
+	for _, q := range []string{"payload", "x"} {
+		results, _, err := tasks.SearchTasks(ctx, &SearchTasksRequest{ProjectID: project.ID, Query: q})
+		if err != nil {
+			t.Fatalf("SearchTasks(%q) returned error: %v", q, err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("SearchTasks(%q) expected 1 result, got %d", q, len(results))
+		}
+		for _, fragment := range []string{results[0].TitleHighlight, results[0].Snippet} {
+			if strings.Contains(fragment, "<img") || strings.Contains(fragment, "<script") {
+				t.Fatalf("SearchTasks(%q) returned unescaped markup %q", q, fragment)
+			}
+		}
+		if !strings.Contains(results[0].TitleHighlight, "&lt;img") || !strings.Contains(results[0].TitleHighlight, "<mark>") {
+			t.Fatalf("SearchTasks(%q) expected escaped title with marks, got %q", q, results[0].TitleHighlight)
+		}
+	}
+}
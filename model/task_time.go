@@ -0,0 +1,399 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"code-kanban/model/tables"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// TaskTimeSourceManual marks time logged after the fact.
+	TaskTimeSourceManual = "manual"
+	// TaskTimeSourceTimer marks time measured with the start/stop timer.
+	TaskTimeSourceTimer = "timer"
+	// TaskTimeSourceAssistant marks time inferred from an AI assistant running in the bound worktree.
+	TaskTimeSourceAssistant = "assistant"
+)
+
+var (
+	// ErrTaskTimeEntryNotFound indicates the requested time entry does not exist.
+	ErrTaskTimeEntryNotFound = errors.New("task time entry not found")
+	// ErrInvalidTaskTimeEntry indicates the time entry payload is invalid.
+	ErrInvalidTaskTimeEntry = errors.New("invalid task time entry")
+	// ErrTaskTimerRunning indicates the actor already has a running timer on the task.
+	ErrTaskTimerRunning = errors.New("task timer already running")
+	// ErrTaskTimerNotRunning indicates there is no running timer to stop.
+	ErrTaskTimerNotRunning = errors.New("task timer not running")
+)
+
+// LogTimeRequest describes a manually logged time entry. Either Minutes or both
+// StartedAt and EndedAt must be provided.
+type LogTimeRequest struct {
+	StartedAt *time.Time
+	EndedAt   *time.Time
+	Minutes   int
+	Note      string
+}
+
+// TaskTimeSummary aggregates the tracked time of a task against its estimate.
+type TaskTimeSummary struct {
+	TaskID           string   `json:"taskId"`
+	EstimateMinutes  *int     `json:"estimateMinutes"`
+	EstimatePoints   *float64 `json:"estimatePoints"`
+	TrackedSeconds   int64    `json:"trackedSeconds"`
+	ManualSeconds    int64    `json:"manualSeconds"` // 手动补录和计时器
+	AssistantSeconds int64    `json:"assistantSeconds"`
+	RemainingMinutes *int     `json:"remainingMinutes"` // 预估剩余工时，超出预估时为负数
+	Running          bool     `json:"running"`
+}
+
+// TaskTimeService records time entries for tasks.
+type TaskTimeService struct {
+	taskSvc *TaskService
+	now     func() time.Time
+}
+
+// NewTaskTimeService constructs a time tracking service with a task dependency.
+func NewTaskTimeService() *TaskTimeService {
+	return &TaskTimeService{taskSvc: &TaskService{}, now: time.Now}
+}
+
+// ListEntries returns the time entries of a task, newest first.
+func (s *TaskTimeService) ListEntries(ctx context.Context, taskID string) ([]tables.TaskTimeEntryTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.taskSvc.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	var entries []tables.TaskTimeEntryTable
+	if err := dbCtx.
+		Where("task_id = ?", taskID).
+		Order("started_at DESC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// LogTime records a finished manual entry.
+func (s *TaskTimeService) LogTime(ctx context.Context, taskID string, req *LogTimeRequest) (*tables.TaskTimeEntryTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.taskSvc.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, fmt.Errorf("%w: request is required", ErrInvalidTaskTimeEntry)
+	}
+
+	var startedAt, endedAt time.Time
+	switch {
+	case req.StartedAt != nil && req.EndedAt != nil:
+		startedAt, endedAt = *req.StartedAt, *req.EndedAt
+		if !endedAt.After(startedAt) {
+			return nil, fmt.Errorf("%w: endedAt must be after startedAt", ErrInvalidTaskTimeEntry)
+		}
+	case req.Minutes > 0:
+		endedAt = s.now()
+		if req.EndedAt != nil {
+			endedAt = *req.EndedAt
+		}
+		startedAt = endedAt.Add(-time.Duration(req.Minutes) * time.Minute)
+		if req.StartedAt != nil {
+			startedAt = *req.StartedAt
+			endedAt = startedAt.Add(time.Duration(req.Minutes) * time.Minute)
+		}
+	default:
+		return nil, fmt.Errorf("%w: minutes or startedAt/endedAt is required", ErrInvalidTaskTimeEntry)
+	}
+	if endedAt.After(s.now().Add(time.Minute)) {
+		return nil, fmt.Errorf("%w: entries cannot end in the future", ErrInvalidTaskTimeEntry)
+	}
+
+	entry := &tables.TaskTimeEntryTable{
+		TaskID:          task.ID,
+		ProjectID:       task.ProjectID,
+		Source:          TaskTimeSourceManual,
+		Actor:           ActorFromContext(ctx),
+		StartedAt:       startedAt,
+		EndedAt:         &endedAt,
+		DurationSeconds: int64(endedAt.Sub(startedAt).Seconds()),
+		Note:            strings.TrimSpace(req.Note),
+	}
+	if err := dbCtx.Create(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// StartTimer starts a running timer for the context actor.
+func (s *TaskTimeService) StartTimer(ctx context.Context, taskID, note string) (*tables.TaskTimeEntryTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.taskSvc.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	actor := ActorFromContext(ctx)
+
+	var running int64
+	if err := dbCtx.Model(&tables.TaskTimeEntryTable{}).
+		Where("task_id = ? AND source = ? AND actor = ? AND ended_at IS NULL", task.ID, TaskTimeSourceTimer, actor).
+		Count(&running).Error; err != nil {
+		return nil, err
+	}
+	if running > 0 {
+		return nil, ErrTaskTimerRunning
+	}
+
+	entry := &tables.TaskTimeEntryTable{
+		TaskID:    task.ID,
+		ProjectID: task.ProjectID,
+		Source:    TaskTimeSourceTimer,
+		Actor:     actor,
+		StartedAt: s.now(),
+		Note:      strings.TrimSpace(note),
+	}
+	if err := dbCtx.Create(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// StopTimer stops the running timer of the context actor.
+func (s *TaskTimeService) StopTimer(ctx context.Context, taskID string) (*tables.TaskTimeEntryTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var entry tables.TaskTimeEntryTable
+	if err := dbCtx.
+		Where("task_id = ? AND source = ? AND actor = ? AND ended_at IS NULL", taskID, TaskTimeSourceTimer, ActorFromContext(ctx)).
+		Order("started_at DESC").
+		First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskTimerNotRunning
+		}
+		return nil, err
+	}
+	if err := s.closeEntry(dbCtx, &entry, s.now()); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// DeleteEntry removes a time entry.
+func (s *TaskTimeService) DeleteEntry(ctx context.Context, id string) error {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbCtx.Delete(&tables.TaskTimeEntryTable{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTaskTimeEntryNotFound
+	}
+	return nil
+}
+
+// Summary totals the tracked time of a task, counting running entries up to now.
+func (s *TaskTimeService) Summary(ctx context.Context, taskID string) (*TaskTimeSummary, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.taskSvc.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	var entries []tables.TaskTimeEntryTable
+	if err := dbCtx.Where("task_id = ?", task.ID).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	summary := &TaskTimeSummary{
+		TaskID:          task.ID,
+		EstimateMinutes: task.EstimateMinutes,
+		EstimatePoints:  task.EstimatePoints,
+	}
+	for _, entry := range entries {
+		seconds := entry.DurationSeconds
+		if entry.EndedAt == nil {
+			summary.Running = true
+			seconds = int64(now.Sub(entry.StartedAt).Seconds())
+		}
+		summary.TrackedSeconds += seconds
+		if entry.Source == TaskTimeSourceAssistant {
+			summary.AssistantSeconds += seconds
+		} else {
+			summary.ManualSeconds += seconds
+		}
+	}
+	if task.EstimateMinutes != nil {
+		remaining := *task.EstimateMinutes - int(summary.TrackedSeconds/60)
+		summary.RemainingMinutes = &remaining
+	}
+	return summary, nil
+}
+
+// StartAssistantActivity opens an automatic entry on every open task bound to the
+// worktree while an AI assistant runs in the given terminal session.
+func (s *TaskTimeService) StartAssistantActivity(ctx context.Context, worktreeID, sessionID, assistant string, at time.Time) ([]tables.TaskTimeEntryTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	worktreeID = strings.TrimSpace(worktreeID)
+	if worktreeID == "" || sessionID == "" {
+		return nil, nil
+	}
+
+	var tasks []tables.TaskTable
+	if err := dbCtx.Where("worktree_id = ?", worktreeID).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	workflows := map[string]*taskWorkflow{}
+	var entries []tables.TaskTimeEntryTable
+	for _, task := range tasks {
+		workflow, ok := workflows[task.ProjectID]
+		if !ok {
+			if workflow, err = loadTaskWorkflow(dbCtx, task.ProjectID); err != nil {
+				return entries, err
+			}
+			workflows[task.ProjectID] = workflow
+		}
+		if category := workflow.category(task.Status); category == TaskCategoryDone || category == TaskCategoryArchived {
+			continue
+		}
+
+		var running int64
+		if err := dbCtx.Model(&tables.TaskTimeEntryTable{}).
+			Where("task_id = ? AND session_id = ? AND ended_at IS NULL", task.ID, sessionID).
+			Count(&running).Error; err != nil {
+			return entries, err
+		}
+		if running > 0 {
+			continue
+		}
+		entry := tables.TaskTimeEntryTable{
+			TaskID:     task.ID,
+			ProjectID:  task.ProjectID,
+			Source:     TaskTimeSourceAssistant,
+			Actor:      "agent:" + assistant,
+			SessionID:  sessionID,
+			Assistant:  assistant,
+			StartedAt:  at,
+			LastSeenAt: &at,
+		}
+		if err := dbCtx.Create(&entry).Error; err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// StopAssistantActivity closes the automatic entries opened for a terminal session.
+func (s *TaskTimeService) StopAssistantActivity(ctx context.Context, sessionID string, at time.Time) (int, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var entries []tables.TaskTimeEntryTable
+	if err := dbCtx.
+		Where("source = ? AND session_id = ? AND ended_at IS NULL", TaskTimeSourceAssistant, sessionID).
+		Find(&entries).Error; err != nil {
+		return 0, err
+	}
+	for i := range entries {
+		if err := s.closeEntry(dbCtx, &entries[i], at); err != nil {
+			return i, err
+		}
+	}
+	return len(entries), nil
+}
+
+// HeartbeatAssistantActivity records that the running automatic entries of the
+// given sessions are still active, and closes the ones whose session is gone at
+// their last heartbeat. Passing no sessions closes every running automatic entry,
+// which is what happens on startup because terminals do not survive a restart.
+func (s *TaskTimeService) HeartbeatAssistantActivity(ctx context.Context, liveSessionIDs []string, at time.Time) error {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	live := make(map[string]struct{}, len(liveSessionIDs))
+	for _, id := range liveSessionIDs {
+		live[id] = struct{}{}
+	}
+
+	var entries []tables.TaskTimeEntryTable
+	if err := dbCtx.
+		Where("source = ? AND ended_at IS NULL", TaskTimeSourceAssistant).
+		Find(&entries).Error; err != nil {
+		return err
+	}
+	var alive []string
+	for i := range entries {
+		entry := &entries[i]
+		if _, ok := live[entry.SessionID]; ok {
+			alive = append(alive, entry.ID)
+			continue
+		}
+		end := entry.StartedAt
+		if entry.LastSeenAt != nil {
+			end = *entry.LastSeenAt
+		}
+		if err := s.closeEntry(dbCtx, entry, end); err != nil {
+			return err
+		}
+	}
+	if len(alive) == 0 {
+		return nil
+	}
+	return dbCtx.Model(&tables.TaskTimeEntryTable{}).
+		Where("id IN ?", alive).
+		UpdateColumn("last_seen_at", at).Error
+}
+
+func (s *TaskTimeService) closeEntry(dbCtx *gorm.DB, entry *tables.TaskTimeEntryTable, at time.Time) error {
+	if at.Before(entry.StartedAt) {
+		at = entry.StartedAt
+	}
+	duration := int64(at.Sub(entry.StartedAt).Seconds())
+	if err := dbCtx.Model(&tables.TaskTimeEntryTable{}).
+		Where("id = ?", entry.ID).
+		Updates(map[string]interface{}{
+			"ended_at":         at,
+			"duration_seconds": duration,
+		}).Error; err != nil {
+		return err
+	}
+	entry.EndedAt = &at
+	entry.DurationSeconds = duration
+	return nil
+}
+
+func (s *TaskTimeService) dbWithContext(ctx context.Context) (*gorm.DB, error) {
+	if db == nil {
+		return nil, ErrDBNotInitialized
+	}
+	return db.WithContext(ensureContext(ctx)), nil
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTaskTimeEntries(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := WithActor(context.Background(), "user:alice")
+	project := seedProject(t)
+	estimate := 120
+	task, err := (&TaskService{}).CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "Estimate me", EstimateMinutes: &estimate})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+
+	now := time.Now()
+	svc := NewTaskTimeService()
+	svc.now = func() time.Time { return now }
+
+	if _, err := svc.LogTime(ctx, task.ID, &LogTimeRequest{Minutes: 30, Note: "review"}); err != nil {
+		t.Fatalf("LogTime returned error: %v", err)
+	}
+	if _, err := svc.LogTime(ctx, task.ID, &LogTimeRequest{}); !errors.Is(err, ErrInvalidTaskTimeEntry) {
+		t.Fatalf("expected ErrInvalidTaskTimeEntry, got %v", err)
+	}
+	start, end := now.Add(-time.Hour), now.Add(-2*time.Hour)
+	if _, err := svc.LogTime(ctx, task.ID, &LogTimeRequest{StartedAt: &start, EndedAt: &end}); !errors.Is(err, ErrInvalidTaskTimeEntry) {
+		t.Fatalf("expected reversed range to be rejected, got %v", err)
+	}
+
+	svc.now = func() time.Time { return now.Add(-20 * time.Minute) }
+	if _, err := svc.StartTimer(ctx, task.ID, ""); err != nil {
+		t.Fatalf("StartTimer returned error: %v", err)
+	}
+	if _, err := svc.StartTimer(ctx, task.ID, ""); !errors.Is(err, ErrTaskTimerRunning) {
+		t.Fatalf("expected ErrTaskTimerRunning, got %v", err)
+	}
+	svc.now = func() time.Time { return now }
+	timer, err := svc.StopTimer(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("StopTimer returned error: %v", err)
+	}
+	if timer.DurationSeconds != 20*60 || timer.Actor != "user:alice" {
+		t.Fatalf("unexpected timer entry: %+v", timer)
+	}
+	if _, err := svc.StopTimer(ctx, task.ID); !errors.Is(err, ErrTaskTimerNotRunning) {
+		t.Fatalf("expected ErrTaskTimerNotRunning, got %v", err)
+	}
+
+	summary, err := svc.Summary(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("Summary returned error: %v", err)
+	}
+	if summary.TrackedSeconds != 50*60 || summary.RemainingMinutes == nil || *summary.RemainingMinutes != 70 || summary.Running {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestTaskTimeAssistantActivity(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	project := seedProject(t)
+	worktree := seedWorktree(t, project.ID, "feature/time")
+	taskSvc := &TaskService{}
+	open, err := taskSvc.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "Open", WorktreeID: &worktree.ID})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	done, err := taskSvc.CreateTask(ctx, &CreateTaskRequest{ProjectID: project.ID, Title: "Done", Status: "done", WorktreeID: &worktree.ID})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+
+	svc := NewTaskTimeService()
+	start := time.Now().Add(-time.Hour)
+	entries, err := svc.StartAssistantActivity(ctx, worktree.ID, "session-1", "Claude Code", start)
+	if err != nil {
+		t.Fatalf("StartAssistantActivity returned error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].TaskID != open.ID || entries[0].Source != TaskTimeSourceAssistant {
+		t.Fatalf("expected one entry for the open task, got %+v", entries)
+	}
+	// A repeated start for the same session does not open a second entry.
+	if entries, _ := svc.StartAssistantActivity(ctx, worktree.ID, "session-1", "Claude Code", start); len(entries) != 0 {
+		t.Fatalf("expected duplicate start to be ignored, got %+v", entries)
+	}
+	if _, err := svc.StartAssistantActivity(ctx, worktree.ID, "session-2", "Codex", start); err != nil {
+		t.Fatalf("StartAssistantActivity returned error: %v", err)
+	}
+
+	closed, err := svc.StopAssistantActivity(ctx, "session-1", start.Add(15*time.Minute))
+	if err != nil || closed != 1 {
+		t.Fatalf("expected one closed entry, got %d (%v)", closed, err)
+	}
+
+	// session-2 went away without a stop event; it ends at its last heartbeat.
+	if err := svc.HeartbeatAssistantActivity(ctx, []string{"session-2"}, start.Add(10*time.Minute)); err != nil {
+		t.Fatalf("HeartbeatAssistantActivity returned error: %v", err)
+	}
+	if err := svc.HeartbeatAssistantActivity(ctx, nil, start.Add(30*time.Minute)); err != nil {
+		t.Fatalf("HeartbeatAssistantActivity returned error: %v", err)
+	}
+
+	summary, err := svc.Summary(ctx, open.ID)
+	if err != nil {
+		t.Fatalf("Summary returned error: %v", err)
+	}
+	if summary.Running || summary.AssistantSeconds != 25*60 || summary.ManualSeconds != 0 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if entries, _ := svc.ListEntries(ctx, done.ID); len(entries) != 0 {
+		t.Fatalf("expected no entries on the done task, got %+v", entries)
+	}
+}
@@ -0,0 +1,212 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"code-kanban/model/tables"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTaskViewNotFound indicates the saved view does not exist for the user.
+	ErrTaskViewNotFound = errors.New("task view not found")
+	// ErrTaskViewExists indicates the user already has a view with the same name.
+	ErrTaskViewExists = errors.New("task view name already exists")
+)
+
+// TaskViewService manages saved board views.
+type TaskViewService struct {
+	taskSvc *TaskService
+}
+
+// NewTaskViewService constructs a saved view service with a task dependency.
+func NewTaskViewService() *TaskViewService {
+	return &TaskViewService{taskSvc: &TaskService{}}
+}
+
+// SaveTaskViewRequest captures the definition of a saved view.
+type SaveTaskViewRequest struct {
+	UserID     string
+	Name       string
+	ProjectIDs tables.StringArray
+	Query      string
+	Sort       string
+	GroupBy    string
+}
+
+// ListViews returns the saved views of a user ordered by name.
+func (s *TaskViewService) ListViews(ctx context.Context, userID string) ([]tables.TaskViewTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var views []tables.TaskViewTable
+	if err := dbCtx.
+		Where("user_id = ?", strings.TrimSpace(userID)).
+		Order("name ASC").
+		Find(&views).Error; err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+// GetView loads a view owned by the user.
+func (s *TaskViewService) GetView(ctx context.Context, userID, id string) (*tables.TaskViewTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var view tables.TaskViewTable
+	if err := dbCtx.
+		Where("id = ? AND user_id = ?", id, strings.TrimSpace(userID)).
+		First(&view).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskViewNotFound
+		}
+		return nil, err
+	}
+	return &view, nil
+}
+
+// CreateView stores a new view for the user.
+func (s *TaskViewService) CreateView(ctx context.Context, req *SaveTaskViewRequest) (*tables.TaskViewTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	view, err := s.buildView(dbCtx, req, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := dbCtx.Create(view).Error; err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// UpdateView replaces the definition of an existing view.
+func (s *TaskViewService) UpdateView(ctx context.Context, id string, req *SaveTaskViewRequest) (*tables.TaskViewTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+
+	existing, err := s.GetView(ctx, req.UserID, id)
+	if err != nil {
+		return nil, err
+	}
+	view, err := s.buildView(dbCtx, req, existing.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dbCtx.
+		Model(&tables.TaskViewTable{}).
+		Where("id = ?", existing.ID).
+		Updates(map[string]interface{}{
+			"name":        view.Name,
+			"project_ids": view.ProjectIDs,
+			"query":       view.Query,
+			"sort":        view.Sort,
+			"group_by":    view.GroupBy,
+		}).Error; err != nil {
+		return nil, err
+	}
+	return s.GetView(ctx, req.UserID, id)
+}
+
+// DeleteView removes a view owned by the user.
+func (s *TaskViewService) DeleteView(ctx context.Context, userID, id string) error {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	result := dbCtx.
+		Where("id = ? AND user_id = ?", id, strings.TrimSpace(userID)).
+		Delete(&tables.TaskViewTable{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTaskViewNotFound
+	}
+	return nil
+}
+
+// OpenView loads a saved view and runs its board query in one call.
+func (s *TaskViewService) OpenView(ctx context.Context, userID, id string) (*tables.TaskViewTable, *TaskBoard, error) {
+	view, err := s.GetView(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	board, err := s.taskSvc.Board(ctx, &TaskBoardRequest{
+		ProjectIDs: view.ProjectIDs,
+		Keyword:    view.Query,
+		Sort:       view.Sort,
+		GroupBy:    view.GroupBy,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return view, board, nil
+}
+
+// buildView validates a view definition; excludeID skips the view itself in the name check.
+func (s *TaskViewService) buildView(dbCtx *gorm.DB, req *SaveTaskViewRequest, excludeID string) (*tables.TaskViewTable, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidTaskView)
+	}
+	query := strings.TrimSpace(req.Query)
+	if _, err := ParseTaskQuery(query); err != nil {
+		return nil, err
+	}
+	sortBy, groupBy, err := normalizeTaskViewLayout(req.Sort, req.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := strings.TrimSpace(req.UserID)
+	existing := dbCtx.Model(&tables.TaskViewTable{}).Where("user_id = ? AND name = ?", userID, name)
+	if excludeID != "" {
+		existing = existing.Where("id <> ?", excludeID)
+	}
+	var count int64
+	if err := existing.Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrTaskViewExists
+	}
+
+	return &tables.TaskViewTable{
+		UserID:     userID,
+		Name:       name,
+		ProjectIDs: sanitizeTags(req.ProjectIDs),
+		Query:      query,
+		Sort:       sortBy,
+		GroupBy:    groupBy,
+	}, nil
+}
+
+func (s *TaskViewService) dbWithContext(ctx context.Context) (*gorm.DB, error) {
+	if db == nil {
+		return nil, ErrDBNotInitialized
+	}
+	return db.WithContext(ensureContext(ctx)), nil
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"code-kanban/model/tables"
+)
+
+func TestTaskServiceBoardAcrossProjects(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	api := seedProject(t)
+	web := seedProject(t)
+	if err := db.Model(&tables.ProjectTable{}).Where("id = ?", web.ID).Update("name", "zz-web").Error; err != nil {
+		t.Fatalf("rename project: %v", err)
+	}
+	tasks := &TaskService{}
+
+	for _, req := range []CreateTaskRequest{
+		{ProjectID: api.ID, Title: "api backlog", Priority: 1, Tags: tables.StringArray{"backend"}},
+		{ProjectID: api.ID, Title: "api active", Status: "in_progress", Priority: 3, Tags: tables.StringArray{"backend"}},
+		{ProjectID: web.ID, Title: "web active", Status: "in_progress", Priority: 2},
+	} {
+		req := req
+		if _, err := tasks.CreateTask(ctx, &req); err != nil {
+			t.Fatalf("CreateTask(%s) returned error: %v", req.Title, err)
+		}
+	}
+
+	board, err := tasks.Board(ctx, &TaskBoardRequest{})
+	if err != nil {
+		t.Fatalf("Board returned error: %v", err)
+	}
+	if board.Total != 3 || len(board.Groups) != 2 || board.Groups[1].Key != web.ID || board.Groups[1].Label != "zz-web" {
+		t.Fatalf("unexpected project grouping %+v", board.Groups)
+	}
+
+	board, err = tasks.Board(ctx, &TaskBoardRequest{Status: "in_progress", Sort: TaskSortPriority, GroupBy: TaskGroupNone})
+	if err != nil {
+		t.Fatalf("Board returned error: %v", err)
+	}
+	if len(board.Groups) != 1 || len(board.Groups[0].Tasks) != 2 || board.Groups[0].Tasks[0].Title != "api active" {
+		t.Fatalf("unexpected status filter result %+v", board.Groups)
+	}
+
+	board, err = tasks.Board(ctx, &TaskBoardRequest{Keyword: "tag:backend", GroupBy: TaskGroupCategory})
+	if err != nil {
+		t.Fatalf("Board returned error: %v", err)
+	}
+	if len(board.Groups) != 2 || board.Groups[0].Key != TaskCategoryTodo || board.Groups[1].Key != TaskCategoryInProgress {
+		t.Fatalf("unexpected category grouping %+v", board.Groups)
+	}
+
+	if _, err := tasks.Board(ctx, &TaskBoardRequest{Sort: "random"}); !errors.Is(err, ErrInvalidTaskView) {
+		t.Fatalf("expected ErrInvalidTaskView, got %v", err)
+	}
+
+	views := NewTaskViewService()
+	view, err := views.CreateView(ctx, &SaveTaskViewRequest{
+		UserID:     "alice",
+		Name:       "Web in progress",
+		ProjectIDs: tables.StringArray{web.ID},
+		Query:      "status:in_progress",
+		GroupBy:    TaskGroupStatus,
+	})
+	if err != nil {
+		t.Fatalf("CreateView returned error: %v", err)
+	}
+	if view.Sort != TaskSortOrder {
+		t.Fatalf("expected default sort, got %s", view.Sort)
+	}
+	if _, err := views.CreateView(ctx, &SaveTaskViewRequest{UserID: "alice", Name: "Web in progress"}); !errors.Is(err, ErrTaskViewExists) {
+		t.Fatalf("expected ErrTaskViewExists, got %v", err)
+	}
+	if _, err := views.CreateView(ctx, &SaveTaskViewRequest{UserID: "bob", Name: "Web in progress"}); err != nil {
+		t.Fatalf("expected other user to reuse the name, got %v", err)
+	}
+
+	_, opened, err := views.OpenView(ctx, "alice", view.ID)
+	if err != nil {
+		t.Fatalf("OpenView returned error: %v", err)
+	}
+	if opened.Total != 1 || opened.Groups[0].Key != "in_progress" || opened.Groups[0].Label != "进行中" {
+		t.Fatalf("unexpected view board %+v", opened)
+	}
+	if _, _, err := views.OpenView(ctx, "bob", view.ID); !errors.Is(err, ErrTaskViewNotFound) {
+		t.Fatalf("expected views to be scoped per user, got %v", err)
+	}
+
+	listed, err := views.ListViews(ctx, "alice")
+	if err != nil {
+		t.Fatalf("ListViews returned error: %v", err)
+	}
+	if len(listed) != 1 {
+		t.Fatalf("expected 1 view for alice, got %d", len(listed))
+	}
+	if err := views.DeleteView(ctx, "alice", view.ID); err != nil {
+		t.Fatalf("DeleteView returned error: %v", err)
+	}
+}
@@ -20,6 +20,8 @@ INSERT INTO worktrees (
   path,
   is_main,
   is_bare,
+  is_detached,
+  source_ref,
   head_commit,
   head_commit_date,
   status_ahead,
@@ -46,8 +48,10 @@ INSERT INTO worktrees (
   ?14,
   ?15,
   ?16,
-  ?17
-) RETURNING id, created_at, updated_at, deleted_at, project_id, branch_name, path, is_main, is_bare, head_commit, head_commit_date, status_ahead, status_behind, status_modified, status_staged, status_untracked, status_conflicts, status_updated_at
+  ?17,
+  ?18,
+  ?19
+) RETURNING id, created_at, updated_at, deleted_at, project_id, branch_name, path, is_main, is_bare, is_detached, source_ref, head_commit, head_commit_date, status_ahead, status_behind, status_modified, status_staged, status_untracked, status_conflicts, status_updated_at
 `
 
 type WorktreeCreateParams struct {
@@ -59,6 +63,8 @@ type WorktreeCreateParams struct {
 	Path            string     `db:"path" json:"path"`
 	IsMain          bool       `db:"is_main" json:"isMain"`
 	IsBare          bool       `db:"is_bare" json:"isBare"`
+	IsDetached      bool       `db:"is_detached" json:"isDetached"`
+	SourceRef       *string    `db:"source_ref" json:"sourceRef"`
 	HeadCommit      *string    `db:"head_commit" json:"headCommit"`
 	HeadCommitDate  *time.Time `db:"head_commit_date" json:"headCommitDate"`
 	StatusAhead     *int64     `db:"status_ahead" json:"statusAhead"`
@@ -80,6 +86,8 @@ func (q *Queries) WorktreeCreate(ctx context.Context, arg *WorktreeCreateParams)
 		arg.Path,
 		arg.IsMain,
 		arg.IsBare,
+		arg.IsDetached,
+		arg.SourceRef,
 		arg.HeadCommit,
 		arg.HeadCommitDate,
 		arg.StatusAhead,
@@ -101,6 +109,8 @@ func (q *Queries) WorktreeCreate(ctx context.Context, arg *WorktreeCreateParams)
 		&i.Path,
 		&i.IsMain,
 		&i.IsBare,
+		&i.IsDetached,
+		&i.SourceRef,
 		&i.HeadCommit,
 		&i.HeadCommitDate,
 		&i.StatusAhead,
@@ -125,6 +135,8 @@ SELECT
   path,
   is_main,
   is_bare,
+  is_detached,
+  source_ref,
   head_commit,
   head_commit_date,
   status_ahead,
@@ -153,6 +165,8 @@ func (q *Queries) WorktreeGetByID(ctx context.Context, id string) (*Worktree, er
 		&i.Path,
 		&i.IsMain,
 		&i.IsBare,
+		&i.IsDetached,
+		&i.SourceRef,
 		&i.HeadCommit,
 		&i.HeadCommitDate,
 		&i.StatusAhead,
@@ -167,7 +181,7 @@ func (q *Queries) WorktreeGetByID(ctx context.Context, id string) (*Worktree, er
 }
 
 const worktreeListByProject = `-- name: WorktreeListByProject :many
-SELECT id, created_at, updated_at, deleted_at, project_id, branch_name, path, is_main, is_bare, head_commit, head_commit_date, status_ahead, status_behind, status_modified, status_staged, status_untracked, status_conflicts, status_updated_at FROM worktrees
+SELECT id, created_at, updated_at, deleted_at, project_id, branch_name, path, is_main, is_bare, is_detached, source_ref, head_commit, head_commit_date, status_ahead, status_behind, status_modified, status_staged, status_untracked, status_conflicts, status_updated_at FROM worktrees
 WHERE project_id = ?1
   AND deleted_at IS NULL
 ORDER BY is_main DESC, created_at ASC
@@ -192,6 +206,8 @@ func (q *Queries) WorktreeListByProject(ctx context.Context, projectID string) (
 			&i.Path,
 			&i.IsMain,
 			&i.IsBare,
+			&i.IsDetached,
+			&i.SourceRef,
 			&i.HeadCommit,
 			&i.HeadCommitDate,
 			&i.StatusAhead,
@@ -245,8 +261,9 @@ SET
   branch_name = ?2,
   head_commit = ?3,
   is_main = ?4,
-  is_bare = ?5
-WHERE id = ?6
+  is_bare = ?5,
+  is_detached = ?6
+WHERE id = ?7
   AND deleted_at IS NULL
 `
 
@@ -256,6 +273,7 @@ type WorktreeUpdateMetadataParams struct {
 	HeadCommit *string   `db:"head_commit" json:"headCommit"`
 	IsMain     bool      `db:"is_main" json:"isMain"`
 	IsBare     bool      `db:"is_bare" json:"isBare"`
+	IsDetached bool      `db:"is_detached" json:"isDetached"`
 	Id         string    `db:"id" json:"id"`
 }
 
@@ -266,6 +284,7 @@ func (q *Queries) WorktreeUpdateMetadata(ctx context.Context, arg *WorktreeUpdat
 		arg.HeadCommit,
 		arg.IsMain,
 		arg.IsBare,
+		arg.IsDetached,
 		arg.Id,
 	)
 	return err
@@ -296,6 +315,8 @@ RETURNING
   path,
   is_main,
   is_bare,
+  is_detached,
+  source_ref,
   head_commit,
   head_commit_date,
   status_ahead,
@@ -346,6 +367,8 @@ func (q *Queries) WorktreeUpdateStatus(ctx context.Context, arg *WorktreeUpdateS
 		&i.Path,
 		&i.IsMain,
 		&i.IsBare,
+		&i.IsDetached,
+		&i.SourceRef,
 		&i.HeadCommit,
 		&i.HeadCommitDate,
 		&i.StatusAhead,
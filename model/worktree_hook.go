@@ -0,0 +1,87 @@
+package model
+
+import (
+	"context"
+	"errors"
+
+	"code-kanban/model/tables"
+	"code-kanban/utils/worktreehook"
+
+	"gorm.io/gorm"
+)
+
+// WorktreeHookService stores per-project worktree lifecycle hooks.
+type WorktreeHookService struct{}
+
+// NewWorktreeHookService constructs a WorktreeHookService.
+func NewWorktreeHookService() *WorktreeHookService {
+	return &WorktreeHookService{}
+}
+
+// GetHooks returns the hooks stored for a project, or nil when the project has none
+// and relies on its repository hook file.
+func (s *WorktreeHookService) GetHooks(ctx context.Context, projectID string) (*tables.WorktreeHookTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureProjectExists(dbCtx, projectID); err != nil {
+		return nil, err
+	}
+	var hooks tables.WorktreeHookTable
+	err = dbCtx.Where("project_id = ?", projectID).First(&hooks).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &hooks, nil
+}
+
+// SaveHooks validates and stores the hooks of a project, replacing earlier ones.
+func (s *WorktreeHookService) SaveHooks(ctx context.Context, projectID string, cfg worktreehook.Config) (*tables.WorktreeHookTable, error) {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	hooks, err := s.GetHooks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if hooks == nil {
+		hooks = &tables.WorktreeHookTable{ProjectID: projectID}
+	}
+	hooks.PostCreate = tables.StringArray(cfg.PostCreate)
+	hooks.Copy = tables.StringArray(cfg.Copy)
+	hooks.Symlink = tables.StringArray(cfg.Symlink)
+	hooks.PreDelete = tables.StringArray(cfg.PreDelete)
+	hooks.Timeout = cfg.Timeout
+	if err := dbCtx.Save(hooks).Error; err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// DeleteHooks removes the stored hooks so the repository hook file applies again.
+func (s *WorktreeHookService) DeleteHooks(ctx context.Context, projectID string) error {
+	dbCtx, err := s.dbWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := ensureProjectExists(dbCtx, projectID); err != nil {
+		return err
+	}
+	// 硬删除，否则软删除的记录会占住 project_id 唯一索引
+	return dbCtx.Unscoped().Where("project_id = ?", projectID).Delete(&tables.WorktreeHookTable{}).Error
+}
+
+func (s *WorktreeHookService) dbWithContext(ctx context.Context) (*gorm.DB, error) {
+	if db == nil {
+		return nil, ErrDBNotInitialized
+	}
+	return db.WithContext(ensureContext(ctx)), nil
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"path/filepath"
+
+	"code-kanban/utils"
+	"code-kanban/utils/storage"
+)
+
+// NewAttachmentStorage builds the attachment backend selected by cfg: an S3-compatible
+// bucket when UseS3 is set, otherwise a directory below the data dir.
+func NewAttachmentStorage(cfg utils.AttachmentConfig) (storage.Storage, error) {
+	if cfg.UseS3 {
+		return storage.NewS3(storage.S3Config{
+			Endpoint:     cfg.Endpoint,
+			Bucket:       cfg.Bucket,
+			Region:       cfg.Region,
+			AccessKey:    cfg.AccessKey,
+			SecretKey:    cfg.SecretKey,
+			SessionToken: cfg.Token,
+		})
+	}
+	dir := cfg.LocalDir
+	if dir == "" {
+		dir = filepath.Join(utils.GetDataDir(), "attachments")
+	}
+	return storage.NewLocal(dir)
+}
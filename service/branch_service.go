@@ -20,13 +20,15 @@ import (
 
 // BranchService coordinates git branch operations with persistence.
 type BranchService struct {
-	cache *cache.Cache
+	cache      *cache.Cache
+	automation *model.TaskAutomationService
 }
 
 // NewBranchService constructs a BranchService with a default ttl cache.
 func NewBranchService() *BranchService {
 	return &BranchService{
-		cache: cache.NewCache(1 * time.Minute),
+		cache:      cache.NewCache(1 * time.Minute),
+		automation: model.NewTaskAutomationService(),
 	}
 }
 
@@ -346,6 +348,10 @@ func (s *BranchService) MergeBranch(ctx context.Context, worktreeID, sourceBranc
 		zap.String("strategy", string(strategy)),
 	)
 
+	if mergeCompletesSource(project, strategy, opts.Commit, source, targetBranch) {
+		s.applyMergeAutomation(ctx, project.Id, source, targetBranch)
+	}
+
 	resultMsg := "merged successfully"
 	if opts.Commit {
 		resultMsg = "merged and committed successfully"
@@ -356,6 +362,44 @@ func (s *BranchService) MergeBranch(ctx context.Context, worktreeID, sourceBranc
 	}, nil
 }
 
+// mergeCompletesSource reports whether the merge integrated a feature branch into the
+// project's default branch, the only case in which the source branch's tasks are done.
+// Refreshing a feature branch from the default branch or rebasing onto the source does
+// not finish the source's work.
+func mergeCompletesSource(project *model.Project, strategy git.MergeStrategy, committed bool, source, target string) bool {
+	if strategy == git.MergeStrategyRebase {
+		return false
+	}
+	// 未提交的 squash 合并仍停留在暂存区，此时不视为分支已合并
+	if strategy == git.MergeStrategySquash && !committed {
+		return false
+	}
+	integration := diffBase(project, "")
+	return target == integration && source != integration
+}
+
+func (s *BranchService) applyMergeAutomation(ctx context.Context, projectID, source, target string) {
+	if s.automation == nil {
+		return
+	}
+	moved, err := s.automation.HandleBranchMerged(ctx, projectID, source, target)
+	if err != nil {
+		s.logger(ctx).Warn("task automation after merge failed",
+			zap.Error(err),
+			zap.String("projectId", projectID),
+			zap.String("source", source),
+		)
+		return
+	}
+	if len(moved) > 0 {
+		s.logger(ctx).Info("tasks completed by merge",
+			zap.String("projectId", projectID),
+			zap.String("source", source),
+			zap.Int("count", len(moved)),
+		)
+	}
+}
+
 func (s *BranchService) refreshBranches(ctx context.Context, worktreeService *WorktreeService, projectID string, branches ...string) {
 	if worktreeService == nil {
 		return
@@ -301,6 +301,102 @@ func TestBranchServiceSquashMergeCommit(t *testing.T) {
 	}
 }
 
+func TestBranchServiceMergeAutomationOnlyForFeatureIntoDefault(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	repoPath := createProjectTestRepo(t)
+	ctx := context.Background()
+	project, err := (&model.ProjectService{}).CreateProject(ctx, model.CreateProjectParams{
+		Name: "Merge Automation Project",
+		Path: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("CreateProject returned error: %v", err)
+	}
+
+	worktreeService := NewWorktreeService()
+	featureWT, err := worktreeService.CreateWorktree(ctx, project.Id, "feature/automation", defaultBranch(project), true)
+	if err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+	worktrees, err := worktreeService.ListWorktrees(ctx, project.Id)
+	if err != nil {
+		t.Fatalf("ListWorktrees failed: %v", err)
+	}
+	var mainWT *model.Worktree
+	for _, wt := range worktrees {
+		if wt.BranchName == defaultBranch(project) {
+			mainWT = wt
+			break
+		}
+	}
+	if mainWT == nil {
+		t.Fatalf("failed to locate default branch worktree")
+	}
+
+	taskService := &model.TaskService{}
+	task, err := taskService.CreateTask(ctx, &model.CreateTaskRequest{
+		ProjectID:  project.Id,
+		WorktreeID: &featureWT.Id,
+		Title:      "feature work",
+	})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	initialStatus := task.Status
+
+	commitFile := func(dir, name string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+		runGitCommand(t, dir, "add", name)
+		runGitCommand(t, dir, "commit", "-m", "add "+name)
+	}
+	assertStatus := func(step, want string) {
+		t.Helper()
+		current, err := taskService.GetTask(ctx, task.ID)
+		if err != nil {
+			t.Fatalf("GetTask returned error: %v", err)
+		}
+		if current.Status != want {
+			t.Fatalf("%s: expected task status %q, got %q", step, want, current.Status)
+		}
+	}
+	merge := func(target *model.Worktree, source, strategy string) {
+		t.Helper()
+		result, err := NewBranchService().MergeBranch(ctx, target.Id, source, model.MergeBranchOptions{
+			TargetBranch: target.BranchName,
+			Strategy:     strategy,
+		})
+		if err != nil {
+			t.Fatalf("MergeBranch(%s into %s) returned error: %v", source, target.BranchName, err)
+		}
+		if !result.Success {
+			t.Fatalf("expected merge success, got result: %+v", result)
+		}
+	}
+
+	commitFile(featureWT.Path, "feature.txt")
+	commitFile(mainWT.Path, "main.txt")
+
+	merge(featureWT, mainWT.BranchName, "merge")
+	assertStatus("default merged into feature", initialStatus)
+
+	merge(mainWT, featureWT.BranchName, "rebase")
+	assertStatus("rebase", initialStatus)
+
+	merge(mainWT, featureWT.BranchName, "merge")
+	current, err := taskService.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTask returned error: %v", err)
+	}
+	if current.Status == initialStatus || current.CompletedAt == nil {
+		t.Fatalf("expected feature merged into default to complete the task, got status %q", current.Status)
+	}
+}
+
 func defaultBranch(project *model.Project) string {
 	if project.DefaultBranch == nil {
 		return ""
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"code-kanban/model"
+	"code-kanban/utils/git"
+)
+
+const (
+	defaultCommitPageSize = 50
+	maxCommitPageSize     = 200
+)
+
+// CommitQuery filters and paginates a commit listing.
+type CommitQuery struct {
+	AheadOnly bool   // 只列出不在基准分支上的提交
+	Base      string // AheadOnly 的基准分支，默认项目默认分支
+	Paths     []string
+	Author    string
+	Page      int
+	PageSize  int
+}
+
+// CommitDetail is a commit together with its diff against the first parent.
+type CommitDetail struct {
+	Commit *git.Commit `json:"commit"`
+	Diff   *git.Diff   `json:"diff"`
+}
+
+// ListWorktreeCommits lists the history of the worktree's HEAD.
+func (s *WorktreeService) ListWorktreeCommits(ctx context.Context, id string, query *CommitQuery) ([]git.Commit, int64, error) {
+	worktree, project, repo, err := s.openWorktreeRepo(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	return listCommits(repo, worktree.Path, "", project, query)
+}
+
+// GetWorktreeCommit returns one commit reachable from the worktree with its diff.
+func (s *WorktreeService) GetWorktreeCommit(ctx context.Context, id, sha string, opts git.DiffOptions) (*CommitDetail, error) {
+	worktree, _, repo, err := s.openWorktreeRepo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return commitDetail(repo, worktree.Path, sha, opts)
+}
+
+// ListBranchCommits lists the history of a local branch, or of a remote-tracking
+// branch such as origin/feature when no local branch has the name.
+func (s *BranchService) ListBranchCommits(ctx context.Context, projectID, branch string, query *CommitQuery) ([]git.Commit, int64, error) {
+	ctx = ensureContext(ctx)
+	project, repo, err := s.getProjectAndRepo(ctx, projectID)
+	if err != nil {
+		return nil, 0, err
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return nil, 0, model.ErrBranchNotFound
+	}
+	ref := "refs/heads/" + branch
+	if !repo.BranchExists(branch) {
+		ref = "refs/remotes/" + branch
+	}
+	commits, total, err := listCommits(repo, project.Path, ref, project, query)
+	if errors.Is(err, git.ErrCommitNotFound) {
+		return nil, 0, model.ErrBranchNotFound
+	}
+	return commits, total, err
+}
+
+// GetCommit returns any commit of the project repository with its diff.
+func (s *BranchService) GetCommit(ctx context.Context, projectID, sha string, opts git.DiffOptions) (*CommitDetail, error) {
+	project, repo, err := s.getProjectAndRepo(ensureContext(ctx), projectID)
+	if err != nil {
+		return nil, err
+	}
+	return commitDetail(repo, project.Path, sha, opts)
+}
+
+func listCommits(repo *git.GitRepo, path, ref string, project *model.Project, query *CommitQuery) ([]git.Commit, int64, error) {
+	if query == nil {
+		query = &CommitQuery{}
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = defaultCommitPageSize
+	}
+	if query.PageSize > maxCommitPageSize {
+		query.PageSize = maxCommitPageSize
+	}
+
+	opts := git.LogOptions{Ref: ref, Paths: query.Paths, Author: query.Author}
+	if query.AheadOnly {
+		opts.Base = diffBase(project, query.Base)
+	}
+	total, err := repo.CountCommits(path, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	opts.Skip = (query.Page - 1) * query.PageSize
+	opts.Limit = query.PageSize
+	commits, err := repo.Log(path, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return commits, int64(total), nil
+}
+
+func commitDetail(repo *git.GitRepo, path, sha string, opts git.DiffOptions) (*CommitDetail, error) {
+	commit, err := repo.GetCommit(path, sha)
+	if err != nil {
+		return nil, err
+	}
+	opts.Mode = git.DiffModeCommit
+	opts.Commit = commit.SHA
+	diff, err := repo.Diff(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &CommitDetail{Commit: commit, Diff: diff}, nil
+}
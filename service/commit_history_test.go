@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-kanban/model"
+	"code-kanban/utils/git"
+)
+
+func TestCommitHistory(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	repoPath := createProjectTestRepo(t)
+	project, err := (&model.ProjectService{}).CreateProject(context.Background(), model.CreateProjectParams{
+		Name: "History Project",
+		Path: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+
+	svc := NewWorktreeService()
+	svc.AsyncRefresh(false)
+	ctx := context.Background()
+	worktree, err := svc.CreateWorktree(ctx, project.Id, "feature/history", "main", true)
+	if err != nil {
+		t.Fatalf("CreateWorktree returned error: %v", err)
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(worktree.Path, name), []byte(name+"\n"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		runGitCommand(t, worktree.Path, "add", name)
+		runGitCommand(t, worktree.Path, "commit", "-m", "agent adds "+name)
+	}
+
+	ahead, total, err := svc.ListWorktreeCommits(ctx, worktree.Id, &CommitQuery{AheadOnly: true, PageSize: 2})
+	if err != nil {
+		t.Fatalf("ListWorktreeCommits returned error: %v", err)
+	}
+	if total != 3 || len(ahead) != 2 || ahead[0].Subject != "agent adds c.txt" {
+		t.Fatalf("unexpected ahead commits (total %d): %+v", total, ahead)
+	}
+
+	all, total, err := svc.ListWorktreeCommits(ctx, worktree.Id, nil)
+	if err != nil || total != 4 || len(all) != 4 {
+		t.Fatalf("expected full history of 4 commits, got %d/%d (%v)", len(all), total, err)
+	}
+
+	detail, err := svc.GetWorktreeCommit(ctx, worktree.Id, ahead[1].ShortSHA, git.DiffOptions{})
+	if err != nil {
+		t.Fatalf("GetWorktreeCommit returned error: %v", err)
+	}
+	if detail.Commit.SHA != ahead[1].SHA || len(detail.Diff.Files) != 1 || detail.Diff.Files[0].Path != "b.txt" {
+		t.Fatalf("unexpected commit detail: %+v", detail)
+	}
+
+	branchSvc := NewBranchService()
+	branchCommits, total, err := branchSvc.ListBranchCommits(ctx, project.Id, "feature/history", &CommitQuery{AheadOnly: true, Page: 2, PageSize: 2})
+	if err != nil {
+		t.Fatalf("ListBranchCommits returned error: %v", err)
+	}
+	if total != 3 || len(branchCommits) != 1 || branchCommits[0].Subject != "agent adds a.txt" {
+		t.Fatalf("unexpected branch commits (total %d): %+v", total, branchCommits)
+	}
+	if _, _, err := branchSvc.ListBranchCommits(ctx, project.Id, "missing", nil); !errors.Is(err, model.ErrBranchNotFound) {
+		t.Fatalf("expected ErrBranchNotFound, got %v", err)
+	}
+	if _, err := branchSvc.GetCommit(ctx, project.Id, "deadbeef", git.DiffOptions{}); !errors.Is(err, git.ErrCommitNotFound) {
+		t.Fatalf("expected ErrCommitNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"code-kanban/model"
+	"code-kanban/utils"
+	"code-kanban/utils/commitmsg"
+	"code-kanban/utils/git"
+)
+
+// CommitMessageDrafter writes commit messages from the staged diff when a commit is
+// requested without one.
+type CommitMessageDrafter struct {
+	Generator    commitmsg.Generator
+	Timeout      time.Duration
+	MaxDiffBytes int
+}
+
+// NewCommitMessageDrafter builds the drafter selected by cfg.Provider. It returns nil
+// without error when no provider is configured.
+func NewCommitMessageDrafter(cfg utils.CommitMessageConfig) (*CommitMessageDrafter, error) {
+	var generator commitmsg.Generator
+	switch strings.ToLower(strings.TrimSpace(cfg.Provider)) {
+	case "":
+		return nil, nil
+	case "command":
+		g, err := commitmsg.NewCommandGenerator(cfg.Command)
+		if err != nil {
+			return nil, err
+		}
+		generator = g
+	case "openai":
+		g, err := commitmsg.NewOpenAIGenerator(commitmsg.OpenAIConfig{
+			BaseURL: cfg.BaseURL,
+			APIKey:  cfg.APIKey,
+			Model:   cfg.Model,
+		})
+		if err != nil {
+			return nil, err
+		}
+		generator = g
+	default:
+		return nil, fmt.Errorf("unknown commit message provider %q", cfg.Provider)
+	}
+	return &CommitMessageDrafter{
+		Generator:    generator,
+		Timeout:      cfg.TimeoutDuration(),
+		MaxDiffBytes: cfg.MaxDiffBytes,
+	}, nil
+}
+
+// Draft asks the generator for a message describing what is staged in the worktree.
+func (d *CommitMessageDrafter) Draft(ctx context.Context, repo *git.GitRepo, worktree *model.Worktree) (string, error) {
+	return d.draft(ctx, repo, worktree, git.DiffOptions{Mode: git.DiffModeStaged})
+}
+
+// DraftAll describes every pending change, staged or not, including untracked files.
+// It reads the diff without touching the index, so a failed draft leaves nothing staged.
+func (d *CommitMessageDrafter) DraftAll(ctx context.Context, repo *git.GitRepo, worktree *model.Worktree) (string, error) {
+	return d.draft(ctx, repo, worktree,
+		git.DiffOptions{Mode: git.DiffModeStaged},
+		git.DiffOptions{Mode: git.DiffModeWorking, IncludeUntracked: true},
+	)
+}
+
+func (d *CommitMessageDrafter) draft(ctx context.Context, repo *git.GitRepo, worktree *model.Worktree, sources ...git.DiffOptions) (string, error) {
+	req := commitmsg.Request{Branch: worktree.BranchName}
+	// 同一文件可能同时有暂存和未暂存的改动，合并为一条记录
+	byPath := make(map[string]int)
+	omitted := 0
+	var patch []byte
+	for _, opts := range sources {
+		diff, err := repo.Diff(worktree.Path, opts)
+		if err != nil {
+			return "", err
+		}
+		omitted += diff.OmittedFiles
+		for _, file := range diff.Files {
+			if idx, ok := byPath[file.Path]; ok {
+				req.Files[idx].Additions += file.Additions
+				req.Files[idx].Deletions += file.Deletions
+				continue
+			}
+			byPath[file.Path] = len(req.Files)
+			req.Files = append(req.Files, commitmsg.FileChange{
+				Path:      file.Path,
+				Status:    file.Status,
+				Additions: file.Additions,
+				Deletions: file.Deletions,
+			})
+		}
+		if len(diff.Files) == 0 {
+			continue
+		}
+		part, err := repo.Patch(worktree.Path, opts)
+		if err != nil {
+			return "", err
+		}
+		patch = append(patch, part...)
+	}
+	if len(req.Files) == 0 && omitted == 0 {
+		return "", git.ErrNothingStaged
+	}
+	req.Patch, req.Truncated = truncatePatch(patch, d.MaxDiffBytes)
+
+	if d.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+	return commitmsg.Draft(ctx, d.Generator, req)
+}
+
+// truncatePatch keeps whole lines up to limit bytes; a non-positive limit keeps everything.
+func truncatePatch(patch []byte, limit int) (string, bool) {
+	if limit <= 0 || len(patch) <= limit {
+		return string(patch), false
+	}
+	cut := patch[:limit]
+	if idx := strings.LastIndexByte(string(cut), '\n'); idx > 0 {
+		cut = cut[:idx+1]
+	}
+	return string(cut), true
+}
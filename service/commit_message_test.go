@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code-kanban/model"
+	"code-kanban/utils/commitmsg"
+	"code-kanban/utils/commitmsg/commitmsgtest"
+	"code-kanban/utils/git"
+)
+
+func TestWorktreeServiceDraftsCommitMessage(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	repoPath := createProjectTestRepo(t)
+	project, err := (&model.ProjectService{}).CreateProject(context.Background(), model.CreateProjectParams{
+		Name: "Draft Project",
+		Path: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+
+	svc := NewWorktreeService()
+	svc.AsyncRefresh(false)
+	ctx := context.Background()
+	worktree, err := svc.CreateWorktree(ctx, project.Id, "feature/draft", "main", true)
+	if err != nil {
+		t.Fatalf("CreateWorktree returned error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree.Path, "parser.go"), []byte("package parser\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	if _, err := svc.CommitWorktree(ctx, worktree.Id, ""); err == nil {
+		t.Fatal("expected an empty message to be rejected without a drafter")
+	}
+
+	generator := &commitmsgtest.Generator{Message: "```\nFeat(parser): add parser package.\n```"}
+	svc.SetCommitMessageDrafter(&CommitMessageDrafter{Generator: generator, MaxDiffBytes: 1024})
+
+	if _, err := svc.CommitStagedWorktree(ctx, worktree.Id, ""); !errors.Is(err, git.ErrNothingStaged) {
+		t.Fatalf("expected ErrNothingStaged before staging, got %v", err)
+	}
+	if _, err := svc.CommitWorktree(ctx, worktree.Id, "  "); err != nil {
+		t.Fatalf("CommitWorktree returned error: %v", err)
+	}
+
+	prompts := generator.Prompts()
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "added parser.go") || !strings.Contains(prompts[0], "+package parser") {
+		t.Fatalf("unexpected prompts: %q", prompts)
+	}
+	subject, err := exec.Command("git", "-C", worktree.Path, "log", "-1", "--format=%s").Output()
+	if err != nil {
+		t.Fatalf("git log failed: %v", err)
+	}
+	if strings.TrimSpace(string(subject)) != "feat(parser): add parser package" {
+		t.Fatalf("unexpected commit subject %q", subject)
+	}
+
+	generator.Err = errors.New("model offline")
+	if err := os.WriteFile(filepath.Join(worktree.Path, "lexer.go"), []byte("package parser\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree.Path, "parser.go"), []byte("package parser\n\nfunc Parse() {}\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := svc.CommitWorktree(ctx, worktree.Id, ""); !errors.Is(err, commitmsg.ErrGeneratorFailed) {
+		t.Fatalf("expected ErrGeneratorFailed, got %v", err)
+	}
+	staged, err := exec.Command("git", "-C", worktree.Path, "diff", "--cached", "--name-only").Output()
+	if err != nil {
+		t.Fatalf("git diff failed: %v", err)
+	}
+	if strings.TrimSpace(string(staged)) != "" {
+		t.Fatalf("expected a failed draft to leave the index untouched, got %q", staged)
+	}
+	prompts = generator.Prompts()
+	if last := prompts[len(prompts)-1]; !strings.Contains(last, "added lexer.go") || !strings.Contains(last, "modified parser.go") {
+		t.Fatalf("expected the draft to cover unstaged and untracked changes, got %q", last)
+	}
+
+	generator.Err = nil
+	if _, err := svc.CommitWorktree(ctx, worktree.Id, ""); err != nil {
+		t.Fatalf("CommitWorktree returned error: %v", err)
+	}
+	if _, err := svc.CommitWorktree(ctx, worktree.Id, ""); !errors.Is(err, model.ErrWorktreeClean) {
+		t.Fatalf("expected ErrWorktreeClean on a clean worktree, got %v", err)
+	}
+}
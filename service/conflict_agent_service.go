@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"code-kanban/model"
+	"code-kanban/service/terminal"
+	"code-kanban/utils"
+	"code-kanban/utils/git"
+
+	"go.uber.org/zap"
+)
+
+// ConflictPromptEnv carries the conflict resolution prompt into the agent terminal.
+const ConflictPromptEnv = "CODEKANBAN_CONFLICT_PROMPT"
+
+// ConflictAgentService hands the conflicts of a worktree over to an AI assistant.
+type ConflictAgentService struct {
+	manager     *terminal.Manager
+	commands    utils.AIAgentCommandConfig
+	worktreeSvc *WorktreeService
+}
+
+// ConflictAgentParams describes how the assistant terminal should be opened.
+type ConflictAgentParams struct {
+	Assistant string
+	Rows      int
+	Cols      int
+}
+
+// NewConflictAgentService wires a ConflictAgentService around a terminal manager and configured CLI commands.
+func NewConflictAgentService(manager *terminal.Manager, commands utils.AIAgentCommandConfig) *ConflictAgentService {
+	return &ConflictAgentService{
+		manager:     manager,
+		commands:    commands,
+		worktreeSvc: NewWorktreeService(),
+	}
+}
+
+// HandToAgent opens a terminal in the worktree running the assistant with a prompt
+// listing the conflicted files. The assistant only resolves and stages; continuing
+// or aborting the operation is left to the user.
+func (s *ConflictAgentService) HandToAgent(ctx context.Context, worktreeID string, params ConflictAgentParams) (*terminal.Session, error) {
+	ctx = ensureContext(ctx)
+	if s.manager == nil {
+		return nil, errors.New("terminal manager is not available")
+	}
+
+	assistant := strings.TrimSpace(params.Assistant)
+	if assistant == "" {
+		assistant = "claude-code"
+	}
+	command := strings.TrimSpace(s.commands.Command(assistant))
+	if command == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAssistant, assistant)
+	}
+
+	worktree, err := s.worktreeSvc.GetWorktree(ctx, worktreeID)
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.worktreeSvc.ConflictState(ctx, worktreeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(state.Conflicts) == 0 {
+		return nil, git.ErrNoOperationInProgress
+	}
+
+	shell, err := s.manager.ShellCommand()
+	if err != nil {
+		return nil, err
+	}
+	promptRef, err := utils.ShellEnvReference(shell[0], ConflictPromptEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.manager.CreateSession(ctx, terminal.CreateSessionParams{
+		ProjectID:    worktree.ProjectId,
+		WorktreeID:   worktree.Id,
+		WorkingDir:   worktree.Path,
+		Title:        truncateRunes("Resolve conflicts: "+worktree.BranchName, 64),
+		Env:          []string{ConflictPromptEnv + "=" + buildConflictPrompt(worktree, state)},
+		Rows:         params.Rows,
+		Cols:         params.Cols,
+		InitialInput: command + " " + promptRef,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.LoggerFromContext(ctx).Named("conflict-agent-service").Info("conflict agent started",
+		zap.String("worktreeId", worktree.Id),
+		zap.String("sessionId", session.ID()),
+		zap.String("assistant", assistant),
+		zap.Int("conflicts", len(state.Conflicts)),
+	)
+	return session, nil
+}
+
+// buildConflictPrompt describes the interrupted operation and the files to resolve.
+func buildConflictPrompt(worktree *model.Worktree, state *git.ConflictState) string {
+	var b strings.Builder
+	operation := state.Operation
+	if operation == "" {
+		operation = "merge"
+	}
+	fmt.Fprintf(&b, "A git %s in branch %s stopped with conflicts.\n", operation, worktree.BranchName)
+	if state.Incoming != "" {
+		fmt.Fprintf(&b, "Incoming commit: %s\n", state.Incoming)
+	}
+	if state.Operation == git.OperationRebase {
+		b.WriteString("This is a rebase: \"ours\" is the branch being rebased onto, \"theirs\" is the commit being replayed.\n")
+	}
+
+	b.WriteString("\nConflicted files:\n")
+	for _, file := range state.Conflicts {
+		b.WriteString("- ")
+		b.WriteString(file)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\nResolve every conflict keeping the intent of both sides, remove all conflict markers " +
+		"and stage each resolved file with git add. Do not commit, and do not run --continue or --abort; " +
+		"the user will review and finish the operation.")
+	return b.String()
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"code-kanban/model"
+	"code-kanban/model/tables"
+	"code-kanban/utils"
+	"code-kanban/utils/issuetracker"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrIssueSyncRunning indicates a sync job for the project is already in progress.
+	ErrIssueSyncRunning = errors.New("issue sync is already running")
+)
+
+// IssueSyncStatus combines the persisted sync state with the runner's in-memory job info.
+type IssueSyncStatus struct {
+	tables.IssueSyncTable
+	Running    bool                   `json:"running"`
+	LastResult *model.IssueSyncResult `json:"lastResult,omitempty"`
+}
+
+// IssueSyncRunner runs issue tracker syncs as background jobs, both on demand and on a timer.
+type IssueSyncRunner struct {
+	cfg     utils.IssueSyncConfig
+	syncSvc *model.IssueSyncService
+
+	mu      sync.Mutex
+	running map[string]bool
+	results map[string]*model.IssueSyncResult
+	baseCtx context.Context
+}
+
+// NewIssueSyncRunner wires a runner whose trackers are resolved from the configured hosts.
+func NewIssueSyncRunner(cfg utils.IssueSyncConfig) *IssueSyncRunner {
+	return &IssueSyncRunner{
+		cfg:     cfg,
+		syncSvc: model.NewIssueSyncService(NewIssueTrackerResolver(cfg)),
+		running: map[string]bool{},
+		results: map[string]*model.IssueSyncResult{},
+		baseCtx: context.Background(),
+	}
+}
+
+// NewIssueTrackerResolver maps a project's RemoteURL to a GitHub/Gitea client using the
+// host settings (kind, API URL, token) from the configuration.
+func NewIssueTrackerResolver(cfg utils.IssueSyncConfig) model.IssueTrackerResolver {
+	return func(project *tables.ProjectTable) (issuetracker.Tracker, error) {
+		remote, err := issuetracker.ParseRemote(project.RemoteURL)
+		if err != nil {
+			return nil, err
+		}
+		clientCfg := issuetracker.Config{}
+		if host := cfg.Host(remote.Host); host != nil {
+			clientCfg.Kind = issuetracker.Kind(host.Kind)
+			clientCfg.APIURL = host.APIURL
+			clientCfg.Token = host.Token
+		}
+		return issuetracker.New(remote, clientCfg)
+	}
+}
+
+// StartBackground periodically syncs every project that enabled issue sync. The timer is
+// disabled when the configured interval is zero.
+func (r *IssueSyncRunner) StartBackground(ctx context.Context) {
+	ctx = ensureContext(ctx)
+	r.mu.Lock()
+	r.baseCtx = ctx
+	r.mu.Unlock()
+
+	interval := r.cfg.IntervalDuration()
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.syncEnabled(ctx)
+			}
+		}
+	}()
+}
+
+// Trigger starts a sync job for the project and returns immediately.
+func (r *IssueSyncRunner) Trigger(ctx context.Context, projectID, direction string) error {
+	// 先校验项目存在，避免为不存在的项目启动任务
+	if _, err := r.syncSvc.GetStatus(ctx, projectID); err != nil {
+		return err
+	}
+	if !r.begin(projectID) {
+		return ErrIssueSyncRunning
+	}
+	r.mu.Lock()
+	jobCtx := r.baseCtx
+	r.mu.Unlock()
+	go r.runJob(jobCtx, projectID, direction)
+	return nil
+}
+
+// Status reports the persisted sync state plus whether a job is currently running.
+func (r *IssueSyncRunner) Status(ctx context.Context, projectID string) (*IssueSyncStatus, error) {
+	state, err := r.syncSvc.GetStatus(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &IssueSyncStatus{
+		IssueSyncTable: *state,
+		Running:        r.running[projectID],
+		LastResult:     r.results[projectID],
+	}, nil
+}
+
+// Configure enables or disables background sync for a project.
+func (r *IssueSyncRunner) Configure(ctx context.Context, projectID string, enabled bool, direction string) (*IssueSyncStatus, error) {
+	if _, err := r.syncSvc.Configure(ctx, projectID, enabled, direction); err != nil {
+		return nil, err
+	}
+	return r.Status(ctx, projectID)
+}
+
+func (r *IssueSyncRunner) syncEnabled(ctx context.Context) {
+	projectIDs, err := r.syncSvc.ListEnabledProjects(ctx)
+	if err != nil {
+		r.logger(ctx).Warn("list issue sync projects failed", zap.Error(err))
+		return
+	}
+	for _, projectID := range projectIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		if !r.begin(projectID) {
+			continue
+		}
+		r.runJob(ctx, projectID, "")
+	}
+}
+
+func (r *IssueSyncRunner) begin(projectID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running[projectID] {
+		return false
+	}
+	r.running[projectID] = true
+	return true
+}
+
+func (r *IssueSyncRunner) runJob(ctx context.Context, projectID, direction string) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			r.logger(ctx).Error("issue sync panicked", zap.String("projectId", projectID), zap.Any("panic", rec))
+		}
+		r.mu.Lock()
+		delete(r.running, projectID)
+		r.mu.Unlock()
+	}()
+
+	result, err := r.syncSvc.Sync(ctx, projectID, direction)
+	if err != nil {
+		r.logger(ctx).Warn("issue sync failed", zap.String("projectId", projectID), zap.Error(err))
+	}
+	if result != nil {
+		r.mu.Lock()
+		r.results[projectID] = result
+		r.mu.Unlock()
+	}
+}
+
+func (r *IssueSyncRunner) logger(ctx context.Context) *zap.Logger {
+	return utils.LoggerFromContext(ctx).Named("issue-sync")
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"code-kanban/model"
+	"code-kanban/utils"
+	"code-kanban/utils/issuetracker/trackertest"
+)
+
+func TestIssueSyncRunnerTriggerAndStatus(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	server := trackertest.NewServer()
+	defer server.Close()
+	server.Token = "gh-token"
+	server.AddIssue("remote issue", "from github", "bug")
+
+	repoPath := createProjectTestRepo(t)
+	runGitCommand(t, repoPath, "remote", "add", "origin", "git@github.com:acme/kanban.git")
+	ctx := context.Background()
+	project, err := (&model.ProjectService{}).CreateProject(ctx, model.CreateProjectParams{
+		Name: "Synced Project",
+		Path: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+	if _, err := (&model.TaskService{}).CreateTask(ctx, &model.CreateTaskRequest{ProjectID: project.Id, Title: "local task"}); err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+
+	runner := NewIssueSyncRunner(utils.IssueSyncConfig{
+		Hosts: []utils.IssueTrackerHostConfig{{Host: "github.com", APIURL: server.URL, Token: "gh-token"}},
+	})
+	runner.StartBackground(ctx)
+
+	if err := runner.Trigger(ctx, "missing", ""); !errors.Is(err, model.ErrProjectNotFound) {
+		t.Fatalf("expected missing project error, got %v", err)
+	}
+	if err := runner.Trigger(ctx, project.Id, ""); err != nil {
+		t.Fatalf("Trigger returned error: %v", err)
+	}
+
+	var status *IssueSyncStatus
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, err = runner.Status(ctx, project.Id)
+		if err != nil {
+			t.Fatalf("Status returned error: %v", err)
+		}
+		if !status.Running && status.LastRunAt != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("sync job did not finish: %+v", status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if status.LastError != "" || status.Repo != "acme/kanban" || status.LastResult == nil {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if status.LastResult.TasksCreated != 1 || status.LastResult.IssuesCreated != 1 || len(server.Issues()) != 2 {
+		t.Fatalf("unexpected sync result %+v", status.LastResult)
+	}
+
+	configured, err := runner.Configure(ctx, project.Id, true, model.IssueSyncPush)
+	if err != nil {
+		t.Fatalf("Configure returned error: %v", err)
+	}
+	if !configured.Enabled || configured.Direction != model.IssueSyncPush {
+		t.Fatalf("unexpected configured status %+v", configured)
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"code-kanban/utils/git"
+)
+
+// MatrixBranch is one active worktree branch in a ConflictMatrix.
+type MatrixBranch struct {
+	WorktreeID    string   `json:"worktreeId"`
+	Branch        string   `json:"branch"`
+	Head          string   `json:"head"`
+	Additions     int      `json:"additions"`
+	Deletions     int      `json:"deletions"`
+	BaseConflicts []string `json:"baseConflicts"` // 直接合入基准分支时冲突的文件
+}
+
+// BranchConflict lists the files two worktree branches would conflict on.
+type BranchConflict struct {
+	A         string   `json:"a"` // worktree id
+	B         string   `json:"b"`
+	Conflicts []string `json:"conflicts"`
+}
+
+// ConflictMatrix predicts conflicts between the active branches of a project and
+// against the base branch, so merges can be sequenced.
+type ConflictMatrix struct {
+	Base     string           `json:"base"`
+	Branches []MatrixBranch   `json:"branches"`
+	Pairs    []BranchConflict `json:"pairs"` // 只列出会冲突的组合
+}
+
+// PreviewWorktreeMerge computes the result of merging the worktree's committed
+// HEAD into target (the project default branch when empty). Uncommitted changes
+// are not part of the preview.
+func (s *WorktreeService) PreviewWorktreeMerge(ctx context.Context, id, target string) (*git.MergePreview, error) {
+	worktree, project, repo, err := s.openWorktreeRepo(ensureContext(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	preview, err := repo.PreviewMerge(worktree.Path, diffBase(project, target), "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if worktree.BranchName != "" {
+		preview.Source = worktree.BranchName
+	}
+	return preview, nil
+}
+
+// ConflictMatrix previews every active worktree branch of the project against base
+// and against each other. Main and bare worktrees, and branches with nothing left to
+// merge into base, are skipped.
+func (s *WorktreeService) ConflictMatrix(ctx context.Context, projectID, base string) (*ConflictMatrix, error) {
+	ctx = ensureContext(ctx)
+	project, repo, err := openProjectRepo(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	worktrees, err := s.ListWorktrees(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := &ConflictMatrix{Base: diffBase(project, base), Branches: []MatrixBranch{}, Pairs: []BranchConflict{}}
+	for _, worktree := range worktrees {
+		if worktree.IsMain || worktree.IsBare || strings.TrimSpace(worktree.BranchName) == "" {
+			continue
+		}
+		preview, err := repo.PreviewMerge(project.Path, matrix.Base, worktree.BranchName)
+		if err != nil {
+			return nil, err
+		}
+		if preview.UpToDate {
+			continue
+		}
+		matrix.Branches = append(matrix.Branches, MatrixBranch{
+			WorktreeID:    worktree.Id,
+			Branch:        worktree.BranchName,
+			Head:          preview.SourceCommit,
+			Additions:     preview.Additions,
+			Deletions:     preview.Deletions,
+			BaseConflicts: preview.ConflictPaths(),
+		})
+	}
+
+	for i, a := range matrix.Branches {
+		for _, b := range matrix.Branches[i+1:] {
+			preview, err := repo.PreviewMerge(project.Path, a.Head, b.Head)
+			if err != nil {
+				return nil, err
+			}
+			if !preview.Clean {
+				matrix.Pairs = append(matrix.Pairs, BranchConflict{A: a.WorktreeID, B: b.WorktreeID, Conflicts: preview.ConflictPaths()})
+			}
+		}
+	}
+	return matrix, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-kanban/model"
+)
+
+func TestMergePreviewAndConflictMatrix(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	repoPath := createProjectTestRepo(t)
+	project, err := (&model.ProjectService{}).CreateProject(context.Background(), model.CreateProjectParams{
+		Name: "Matrix Project",
+		Path: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+
+	svc := NewWorktreeService()
+	svc.AsyncRefresh(false)
+	ctx := context.Background()
+	commitIn := func(branch, file, content string) *model.Worktree {
+		t.Helper()
+		worktree, err := svc.CreateWorktree(ctx, project.Id, branch, "main", true)
+		if err != nil {
+			t.Fatalf("CreateWorktree(%s) returned error: %v", branch, err)
+		}
+		if err := os.WriteFile(filepath.Join(worktree.Path, file), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", file, err)
+		}
+		runGitCommand(t, worktree.Path, "add", file)
+		runGitCommand(t, worktree.Path, "commit", "-m", branch+" edits "+file)
+		return worktree
+	}
+	agentA := commitIn("agent/a", "README.md", "agent a\n")
+	agentB := commitIn("agent/b", "README.md", "agent b\n")
+	agentC := commitIn("agent/c", "other.txt", "agent c\n")
+	if _, err := svc.CreateWorktree(ctx, project.Id, "agent/idle", "main", true); err != nil {
+		t.Fatalf("CreateWorktree(idle) returned error: %v", err)
+	}
+
+	preview, err := svc.PreviewWorktreeMerge(ctx, agentC.Id, "")
+	if err != nil {
+		t.Fatalf("PreviewWorktreeMerge returned error: %v", err)
+	}
+	if !preview.FastForward || !preview.Clean || preview.Source != "agent/c" || preview.Target != "main" {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+	if len(preview.Files) != 1 || preview.Files[0].Path != "other.txt" {
+		t.Fatalf("unexpected diffstat: %+v", preview.Files)
+	}
+
+	matrix, err := svc.ConflictMatrix(ctx, project.Id, "")
+	if err != nil {
+		t.Fatalf("ConflictMatrix returned error: %v", err)
+	}
+	if matrix.Base != "main" || len(matrix.Branches) != 3 {
+		t.Fatalf("expected three active branches, got %+v", matrix.Branches)
+	}
+	if len(matrix.Pairs) != 1 {
+		t.Fatalf("expected exactly one conflicting pair, got %+v", matrix.Pairs)
+	}
+	pair := matrix.Pairs[0]
+	ids := map[string]bool{pair.A: true, pair.B: true}
+	if !ids[agentA.Id] || !ids[agentB.Id] || len(pair.Conflicts) != 1 || pair.Conflicts[0] != "README.md" {
+		t.Fatalf("unexpected conflicting pair: %+v", pair)
+	}
+}
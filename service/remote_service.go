@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"code-kanban/model"
+	"code-kanban/utils"
+	"code-kanban/utils/git"
+
+	"go.uber.org/zap"
+)
+
+// remoteLocks serialises network operations per project so a fetch and a push on
+// the same repository never race for ref locks.
+var remoteLocks sync.Map // project id -> *sync.Mutex
+
+func lockRemote(projectID string) func() {
+	value, _ := remoteLocks.LoadOrStore(projectID, &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// RemoteService fetches, pulls and pushes project repositories.
+type RemoteService struct {
+	worktreeSvc *WorktreeService
+	projectSvc  *model.ProjectService
+}
+
+// NewRemoteService constructs a RemoteService.
+func NewRemoteService() *RemoteService {
+	return &RemoteService{
+		worktreeSvc: NewWorktreeService(),
+		projectSvc:  model.NewProjectService(),
+	}
+}
+
+// Fetch updates the remote-tracking branches of a project, records the sync time and
+// refreshes the ahead/behind counts of its worktrees. An empty remote fetches all.
+func (s *RemoteService) Fetch(ctx context.Context, projectID string, opts git.FetchOptions, progress git.ProgressFunc) (*model.Project, error) {
+	ctx = ensureContext(ctx)
+	project, repo, err := openProjectRepo(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := lockRemote(project.Id)
+	err = repo.Fetch(ctx, project.Path, opts, progress)
+	unlock()
+	if err != nil {
+		s.logger(ctx).Warn("fetch failed", zap.String("projectId", project.Id), zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.projectSvc.MarkSynced(ctx, project.Id, time.Now()); err != nil {
+		return nil, err
+	}
+	if _, failed, err := s.worktreeSvc.RefreshAllWorktrees(ctx, project.Id); err != nil || failed > 0 {
+		s.logger(ctx).Warn("refresh after fetch incomplete",
+			zap.String("projectId", project.Id),
+			zap.Int("failed", failed),
+			zap.Error(err),
+		)
+	}
+	return s.projectSvc.GetProject(ctx, project.Id)
+}
+
+// Pull brings the worktree's branch up to date with its remote branch. When a
+// rebasing pull conflicts the worktree is refreshed and git.ErrPullConflicts returned.
+func (s *RemoteService) Pull(ctx context.Context, worktreeID string, opts git.PullOptions, progress git.ProgressFunc) (*model.Worktree, error) {
+	return s.runInWorktree(ctx, worktreeID, func(repo *git.GitRepo, worktree *model.Worktree) error {
+		return repo.Pull(ctx, worktree.Path, opts, progress)
+	})
+}
+
+// Push uploads the worktree's branch to its remote.
+func (s *RemoteService) Push(ctx context.Context, worktreeID string, opts git.PushOptions, progress git.ProgressFunc) (*model.Worktree, error) {
+	return s.runInWorktree(ctx, worktreeID, func(repo *git.GitRepo, worktree *model.Worktree) error {
+		return repo.Push(ctx, worktree.Path, opts, progress)
+	})
+}
+
+// Upstream returns the remote branch the worktree's branch tracks.
+func (s *RemoteService) Upstream(ctx context.Context, worktreeID string) (*git.Upstream, error) {
+	worktree, _, repo, err := s.worktreeSvc.openWorktreeRepo(ensureContext(ctx), worktreeID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Upstream(worktree.Path)
+}
+
+func (s *RemoteService) runInWorktree(ctx context.Context, worktreeID string, run func(repo *git.GitRepo, worktree *model.Worktree) error) (*model.Worktree, error) {
+	ctx = ensureContext(ctx)
+	worktree, _, repo, err := s.worktreeSvc.openWorktreeRepo(ctx, worktreeID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := lockRemote(worktree.ProjectId)
+	err = run(repo, worktree)
+	unlock()
+
+	if err != nil && !errors.Is(err, git.ErrPullConflicts) {
+		s.logger(ctx).Warn("remote operation failed", zap.String("worktreeId", worktree.Id), zap.Error(err))
+		return nil, err
+	}
+	refreshed, refreshErr := s.worktreeSvc.RefreshWorktreeStatus(ctx, worktree.Id)
+	if err != nil {
+		return refreshed, err
+	}
+	return refreshed, refreshErr
+}
+
+func (s *RemoteService) logger(ctx context.Context) *zap.Logger {
+	return utils.LoggerFromContext(ctx).Named("remote-service")
+}
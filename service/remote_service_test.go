@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-kanban/model"
+	"code-kanban/utils/git"
+)
+
+func TestRemoteServiceFetchPullPush(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	repoPath := createProjectTestRepo(t)
+	remotePath := filepath.Join(t.TempDir(), "remote.git")
+	runGitCommand(t, repoPath, "clone", "--bare", "-q", repoPath, remotePath)
+	runGitCommand(t, repoPath, "remote", "add", "origin", remotePath)
+
+	ctx := context.Background()
+	project, err := (&model.ProjectService{}).CreateProject(ctx, model.CreateProjectParams{
+		Name: "Remote Project",
+		Path: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+
+	svc := NewRemoteService()
+	svc.worktreeSvc.AsyncRefresh(false)
+	worktree, err := svc.worktreeSvc.CreateWorktree(ctx, project.Id, "feature/remote", "main", true)
+	if err != nil {
+		t.Fatalf("CreateWorktree returned error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree.Path, "agent.txt"), []byte("agent\n"), 0o644); err != nil {
+		t.Fatalf("write agent file: %v", err)
+	}
+	runGitCommand(t, worktree.Path, "add", "agent.txt")
+	runGitCommand(t, worktree.Path, "commit", "-m", "agent work")
+
+	var progress []git.RemoteProgress
+	pushed, err := svc.Push(ctx, worktree.Id, git.PushOptions{SetUpstream: true}, func(p git.RemoteProgress) {
+		progress = append(progress, p)
+	})
+	if err != nil {
+		t.Fatalf("Push returned error: %v", err)
+	}
+	if len(progress) == 0 {
+		t.Fatal("expected push progress")
+	}
+	if pushed.StatusAhead == nil || *pushed.StatusAhead != 0 {
+		t.Fatalf("expected worktree to be in sync after push, got ahead %v", pushed.StatusAhead)
+	}
+
+	// 另一个协作者向同一分支推送
+	other := filepath.Join(t.TempDir(), "other")
+	runGitCommand(t, filepath.Dir(other), "clone", "-q", "-b", "feature/remote", remotePath, other)
+	runGitCommand(t, other, "config", "user.email", "other@example.com")
+	runGitCommand(t, other, "config", "user.name", "Other")
+	if err := os.WriteFile(filepath.Join(other, "review.txt"), []byte("review\n"), 0o644); err != nil {
+		t.Fatalf("write review file: %v", err)
+	}
+	runGitCommand(t, other, "add", "review.txt")
+	runGitCommand(t, other, "commit", "-m", "review notes")
+	runGitCommand(t, other, "push", "-q", "origin", "feature/remote")
+
+	fetched, err := svc.Fetch(ctx, project.Id, git.FetchOptions{Prune: true}, nil)
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if fetched.LastSyncAt == nil {
+		t.Fatal("expected LastSyncAt to be recorded")
+	}
+	behind, err := svc.worktreeSvc.GetWorktree(ctx, worktree.Id)
+	if err != nil {
+		t.Fatalf("GetWorktree returned error: %v", err)
+	}
+	if behind.StatusBehind == nil || *behind.StatusBehind != 1 {
+		t.Fatalf("expected worktree to be 1 behind after fetch, got %v", behind.StatusBehind)
+	}
+
+	pulled, err := svc.Pull(ctx, worktree.Id, git.PullOptions{}, nil)
+	if err != nil {
+		t.Fatalf("Pull returned error: %v", err)
+	}
+	if pulled.StatusBehind == nil || *pulled.StatusBehind != 0 {
+		t.Fatalf("expected worktree to catch up, got behind %v", pulled.StatusBehind)
+	}
+	if _, err := os.Stat(filepath.Join(worktree.Path, "review.txt")); err != nil {
+		t.Fatalf("expected pulled file: %v", err)
+	}
+}
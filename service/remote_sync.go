@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"code-kanban/model"
+	"code-kanban/utils"
+	"code-kanban/utils/git"
+
+	"go.uber.org/zap"
+)
+
+// RemoteEventDefaultBranchAdvanced is emitted when a fetch moves the remote-tracking
+// branch of a project's default branch.
+const RemoteEventDefaultBranchAdvanced = "default-branch-advanced"
+
+// StaleWorktree is a worktree whose branch no longer contains the tip of the default branch.
+type StaleWorktree struct {
+	WorktreeID string `json:"worktreeId"`
+	Branch     string `json:"branch"`
+	Behind     int    `json:"behind"` // 落后默认分支远程跟踪分支的提交数
+}
+
+// RemoteEvent describes a change observed while syncing a project's remotes.
+type RemoteEvent struct {
+	Type      string          `json:"type"`
+	ProjectID string          `json:"projectId"`
+	Branch    string          `json:"branch"` // 默认分支
+	Ref       string          `json:"ref"`    // 对应的远程跟踪分支，例如 origin/main
+	From      string          `json:"from"`
+	To        string          `json:"to"`
+	Commits   int             `json:"commits"` // 本次新增的提交数
+	Stale     []StaleWorktree `json:"stale"`
+	At        time.Time       `json:"at"`
+}
+
+// RemoteListener receives remote events. It is called synchronously and must not block.
+type RemoteListener func(RemoteEvent)
+
+// RemoteSyncScheduler fetches every project's remotes on a timer so worktree behind
+// counts stay current, and reports when the default branch moves on the remote.
+type RemoteSyncScheduler struct {
+	cfg        utils.RemoteSyncConfig
+	remoteSvc  *RemoteService
+	projectSvc *model.ProjectService
+
+	syncLocks sync.Map // project id -> *sync.Mutex
+
+	listenerMu     sync.RWMutex
+	nextListenerID int
+	listeners      map[int]RemoteListener
+}
+
+// NewRemoteSyncScheduler constructs a scheduler using the configured interval.
+func NewRemoteSyncScheduler(cfg utils.RemoteSyncConfig) *RemoteSyncScheduler {
+	return &RemoteSyncScheduler{
+		cfg:        cfg,
+		remoteSvc:  NewRemoteService(),
+		projectSvc: model.NewProjectService(),
+		listeners:  map[int]RemoteListener{},
+	}
+}
+
+// OnRemoteEvent registers a listener and returns a function that removes it.
+func (s *RemoteSyncScheduler) OnRemoteEvent(listener RemoteListener) func() {
+	if listener == nil {
+		return func() {}
+	}
+	s.listenerMu.Lock()
+	id := s.nextListenerID
+	s.nextListenerID++
+	s.listeners[id] = listener
+	s.listenerMu.Unlock()
+
+	return func() {
+		s.listenerMu.Lock()
+		delete(s.listeners, id)
+		s.listenerMu.Unlock()
+	}
+}
+
+// StartBackground syncs all projects immediately and then on every interval until
+// ctx is done. Nothing runs when the configured interval is zero.
+func (s *RemoteSyncScheduler) StartBackground(ctx context.Context) {
+	ctx = ensureContext(ctx)
+	interval := s.cfg.IntervalDuration()
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.Tick(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Tick fetches every git project that has a remote and returns how many were synced
+// and how many failed. Projects that are not repositories or have no remote are skipped.
+func (s *RemoteSyncScheduler) Tick(ctx context.Context) (synced, failed int) {
+	ctx = ensureContext(ctx)
+	logger := s.logger(ctx)
+
+	projects, err := s.projectSvc.ListProjects(ctx)
+	if err != nil {
+		logger.Warn("list projects failed", zap.Error(err))
+		return 0, 0
+	}
+	for _, project := range projects {
+		if ctx.Err() != nil {
+			break
+		}
+		if _, err := git.DetectRepository(project.Path); err != nil {
+			continue
+		}
+		_, err := s.SyncProject(ctx, project.Id, git.FetchOptions{Prune: s.cfg.Prune}, nil)
+		switch {
+		case err == nil:
+			synced++
+		case errors.Is(err, git.ErrNoRemote):
+		default:
+			failed++
+			logger.Warn("background fetch failed", zap.String("projectId", project.Id), zap.Error(err))
+		}
+	}
+	return synced, failed
+}
+
+// SyncProject fetches the project's remotes through RemoteService and emits
+// RemoteEventDefaultBranchAdvanced when the default branch moved. Nothing is emitted
+// the first time the remote-tracking branch appears, as there is nothing to compare.
+func (s *RemoteSyncScheduler) SyncProject(ctx context.Context, projectID string, opts git.FetchOptions, progress git.ProgressFunc) (*model.Project, error) {
+	ctx = ensureContext(ctx)
+	project, repo, err := openProjectRepo(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一项目的抓取前后比较需要串行，否则并发的手动抓取会让事件重复或遗漏
+	value, _ := s.syncLocks.LoadOrStore(project.Id, &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	mu.Lock()
+	defer mu.Unlock()
+
+	branch := diffBase(project, "")
+	trackingRef := repo.RemoteTrackingRef(branch)
+	before, _ := repo.ResolveCommit(project.Path, trackingRef)
+
+	synced, err := s.remoteSvc.Fetch(ctx, project.Id, opts, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := repo.ResolveCommit(project.Path, trackingRef)
+	if err != nil || before == "" || after == before {
+		return synced, nil
+	}
+	event := s.defaultBranchAdvanced(ctx, project.Id, repo, branch, trackingRef, before, after)
+	s.logger(ctx).Info("default branch advanced",
+		zap.String("projectId", project.Id),
+		zap.String("ref", event.Ref),
+		zap.Int("commits", event.Commits),
+		zap.Int("staleWorktrees", len(event.Stale)),
+	)
+	s.emit(event)
+	return synced, nil
+}
+
+func (s *RemoteSyncScheduler) defaultBranchAdvanced(ctx context.Context, projectID string, repo *git.GitRepo, branch, trackingRef, before, after string) RemoteEvent {
+	event := RemoteEvent{
+		Type:      RemoteEventDefaultBranchAdvanced,
+		ProjectID: projectID,
+		Branch:    branch,
+		Ref:       strings.TrimPrefix(trackingRef, "refs/remotes/"),
+		From:      before,
+		To:        after,
+		Stale:     []StaleWorktree{},
+		At:        time.Now(),
+	}
+	event.Commits, _ = repo.CountCommits("", git.LogOptions{Ref: after, Base: before})
+
+	worktrees, err := s.remoteSvc.worktreeSvc.ListWorktrees(ctx, projectID)
+	if err != nil {
+		s.logger(ctx).Warn("list worktrees failed", zap.String("projectId", projectID), zap.Error(err))
+		return event
+	}
+	for _, worktree := range worktrees {
+		if worktree.IsBare || worktree.IsDetached || worktree.BranchName == branch {
+			continue
+		}
+		behind, err := repo.CountCommits(worktree.Path, git.LogOptions{Ref: after, Base: "HEAD"})
+		if err != nil || behind == 0 {
+			continue
+		}
+		event.Stale = append(event.Stale, StaleWorktree{
+			WorktreeID: worktree.Id,
+			Branch:     worktree.BranchName,
+			Behind:     behind,
+		})
+	}
+	return event
+}
+
+func (s *RemoteSyncScheduler) emit(event RemoteEvent) {
+	s.listenerMu.RLock()
+	listeners := make([]RemoteListener, 0, len(s.listeners))
+	for _, listener := range s.listeners {
+		listeners = append(listeners, listener)
+	}
+	s.listenerMu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+func (s *RemoteSyncScheduler) logger(ctx context.Context) *zap.Logger {
+	return utils.LoggerFromContext(ctx).Named("remote-sync")
+}
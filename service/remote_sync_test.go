@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-kanban/model"
+	"code-kanban/utils"
+	"code-kanban/utils/git"
+)
+
+func TestRemoteSyncSchedulerReportsDefaultBranchAdvance(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	repoPath := createProjectTestRepo(t)
+	remotePath := filepath.Join(t.TempDir(), "remote.git")
+	runGitCommand(t, repoPath, "clone", "--bare", "-q", repoPath, remotePath)
+	runGitCommand(t, repoPath, "remote", "add", "origin", remotePath)
+
+	ctx := context.Background()
+	project, err := (&model.ProjectService{}).CreateProject(ctx, model.CreateProjectParams{
+		Name: "Sync Project",
+		Path: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+	// 没有远程仓库的项目应被跳过而不是计为失败
+	if _, err := (&model.ProjectService{}).CreateProject(ctx, model.CreateProjectParams{
+		Name: "Local Project",
+		Path: createProjectTestRepo(t),
+	}); err != nil {
+		t.Fatalf("create local project failed: %v", err)
+	}
+
+	scheduler := NewRemoteSyncScheduler(utils.RemoteSyncConfig{Interval: "0", Prune: true})
+	scheduler.remoteSvc.worktreeSvc.AsyncRefresh(false)
+	worktree, err := scheduler.remoteSvc.worktreeSvc.CreateWorktree(ctx, project.Id, "feature/stale", "main", true)
+	if err != nil {
+		t.Fatalf("CreateWorktree returned error: %v", err)
+	}
+
+	var events []RemoteEvent
+	remove := scheduler.OnRemoteEvent(func(event RemoteEvent) {
+		events = append(events, event)
+	})
+	defer remove()
+
+	if synced, failed := scheduler.Tick(ctx); synced != 1 || failed != 0 {
+		t.Fatalf("expected 1 synced project, got synced=%d failed=%d", synced, failed)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no event on first fetch, got %+v", events)
+	}
+
+	// 其他协作者向远端 main 推送两个提交
+	other := filepath.Join(t.TempDir(), "other")
+	runGitCommand(t, filepath.Dir(other), "clone", "-q", remotePath, other)
+	runGitCommand(t, other, "config", "user.email", "other@example.com")
+	runGitCommand(t, other, "config", "user.name", "Other")
+	for _, name := range []string{"one.txt", "two.txt"} {
+		if err := os.WriteFile(filepath.Join(other, name), []byte(name+"\n"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		runGitCommand(t, other, "add", name)
+		runGitCommand(t, other, "commit", "-m", "add "+name)
+	}
+	runGitCommand(t, other, "push", "-q", "origin", "main")
+
+	synced, err := scheduler.SyncProject(ctx, project.Id, git.FetchOptions{}, nil)
+	if err != nil {
+		t.Fatalf("SyncProject returned error: %v", err)
+	}
+	if synced.LastSyncAt == nil {
+		t.Fatal("expected LastSyncAt to be recorded")
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected one event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Type != RemoteEventDefaultBranchAdvanced || event.Ref != "origin/main" || event.Commits != 2 {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	if len(event.Stale) != 1 || event.Stale[0].WorktreeID != worktree.Id || event.Stale[0].Behind != 2 {
+		t.Fatalf("expected feature/stale to be 2 behind, got %+v", event.Stale)
+	}
+
+	// 远端没有变化时不再发出事件
+	if _, err := scheduler.SyncProject(ctx, project.Id, git.FetchOptions{}, nil); err != nil {
+		t.Fatalf("second SyncProject returned error: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected no new event, got %d", len(events))
+	}
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"code-kanban/model"
+	"code-kanban/model/tables"
+	"code-kanban/service/terminal"
+	"code-kanban/utils"
+	"code-kanban/utils/git"
+
+	"go.uber.org/zap"
+)
+
+// TaskPromptEnv carries the generated task prompt into the agent terminal.
+const TaskPromptEnv = "CODEKANBAN_TASK_PROMPT"
+
+const maxTaskBranchSlugLength = 40
+
+var (
+	// ErrUnsupportedAssistant indicates no CLI command is configured for the requested assistant.
+	ErrUnsupportedAssistant = errors.New("assistant is not supported")
+)
+
+// TaskAgentService launches AI assistants for tasks in dedicated worktrees.
+type TaskAgentService struct {
+	manager     *terminal.Manager
+	commands    utils.AIAgentCommandConfig
+	worktreeSvc *WorktreeService
+	taskSvc     *model.TaskService
+	commentSvc  *model.TaskCommentService
+}
+
+// StartAgentParams describes how the agent should be launched.
+type StartAgentParams struct {
+	Assistant  string
+	BranchName string
+	BaseBranch string
+	Rows       int
+	Cols       int
+}
+
+// StartAgentResult bundles the entities touched when launching an agent.
+type StartAgentResult struct {
+	Task     *tables.TaskTable
+	Worktree *model.Worktree
+	Session  *terminal.Session
+}
+
+// NewTaskAgentService wires a TaskAgentService around a terminal manager and configured CLI commands.
+func NewTaskAgentService(manager *terminal.Manager, commands utils.AIAgentCommandConfig) *TaskAgentService {
+	return &TaskAgentService{
+		manager:     manager,
+		commands:    commands,
+		worktreeSvc: NewWorktreeService(),
+		taskSvc:     &model.TaskService{},
+		commentSvc:  model.NewTaskCommentService(),
+	}
+}
+
+// StartAgent prepares a worktree for the task, binds it and opens a terminal running the assistant.
+func (s *TaskAgentService) StartAgent(ctx context.Context, taskID string, params StartAgentParams) (*StartAgentResult, error) {
+	ctx = ensureContext(ctx)
+	logger := s.logger(ctx)
+	if s.manager == nil {
+		return nil, errors.New("terminal manager is not available")
+	}
+
+	assistant := strings.TrimSpace(params.Assistant)
+	if assistant == "" {
+		assistant = "claude-code"
+	}
+	command := strings.TrimSpace(s.commands.Command(assistant))
+	if command == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAssistant, assistant)
+	}
+	ctx = model.WithActor(ctx, "agent:"+assistant)
+
+	task, err := s.taskSvc.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	worktree, err := s.ensureWorktree(ctx, task, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.WorktreeID == nil || *task.WorktreeID != worktree.Id {
+		task, err = s.taskSvc.BindWorktree(ctx, task.ID, &worktree.Id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	comments, err := s.commentSvc.ListComments(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	shell, err := s.manager.ShellCommand()
+	if err != nil {
+		return nil, err
+	}
+	promptRef, err := utils.ShellEnvReference(shell[0], TaskPromptEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.manager.CreateSession(ctx, terminal.CreateSessionParams{
+		ProjectID:    task.ProjectID,
+		WorktreeID:   worktree.Id,
+		WorkingDir:   worktree.Path,
+		Title:        truncateRunes(task.Title, 64),
+		Env:          []string{TaskPromptEnv + "=" + buildTaskPrompt(task, comments)},
+		Rows:         params.Rows,
+		Cols:         params.Cols,
+		InitialInput: command + " " + promptRef,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("task agent started",
+		zap.String("taskId", task.ID),
+		zap.String("worktreeId", worktree.Id),
+		zap.String("sessionId", session.ID()),
+		zap.String("assistant", assistant),
+	)
+
+	return &StartAgentResult{
+		Task:     task,
+		Worktree: worktree,
+		Session:  session,
+	}, nil
+}
+
+// ensureWorktree returns the worktree the agent should run in, creating branch and worktree when needed.
+func (s *TaskAgentService) ensureWorktree(ctx context.Context, task *tables.TaskTable, params StartAgentParams) (*model.Worktree, error) {
+	if task.WorktreeID != nil && *task.WorktreeID != "" {
+		worktree, err := s.worktreeSvc.GetWorktree(ctx, *task.WorktreeID)
+		if err == nil {
+			return worktree, nil
+		}
+		if !errors.Is(err, model.ErrWorktreeNotFound) {
+			return nil, err
+		}
+	}
+
+	branchName := strings.TrimSpace(params.BranchName)
+	if branchName == "" {
+		branchName = taskBranchName(task)
+	}
+
+	worktrees, err := s.worktreeSvc.ListWorktrees(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, wt := range worktrees {
+		if wt.BranchName == branchName {
+			return wt, nil
+		}
+	}
+
+	q, err := model.ResolveQueries(nil)
+	if err != nil {
+		return nil, err
+	}
+	project, err := q.ProjectGetByID(ctx, task.ProjectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrProjectNotFound
+		}
+		return nil, err
+	}
+	repo, err := git.DetectRepository(project.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.ValidateBranchName(branchName); err != nil {
+		return nil, err
+	}
+
+	return s.worktreeSvc.CreateWorktree(ctx, task.ProjectID, branchName, params.BaseBranch, !repo.BranchExists(branchName))
+}
+
+func (s *TaskAgentService) logger(ctx context.Context) *zap.Logger {
+	return utils.LoggerFromContext(ctx).Named("task-agent-service")
+}
+
+// taskBranchName derives a branch such as task/fix-login-timeout-a1b2c3 from the task title and id.
+// The title goes through sanitizeBranchName so the worktree directory matches the branch.
+func taskBranchName(task *tables.TaskTable) string {
+	slug := truncateRunes(sanitizeBranchName(strings.ToLower(task.Title)), maxTaskBranchSlugLength)
+	slug = strings.Trim(slug, "-_.")
+
+	suffix := strings.ToLower(task.ID)
+	if len(suffix) > 6 {
+		suffix = suffix[len(suffix)-6:]
+	}
+	if slug == "" {
+		return "task/" + suffix
+	}
+	return "task/" + slug + "-" + suffix
+}
+
+// buildTaskPrompt renders the task details into the initial prompt handed to the assistant.
+func buildTaskPrompt(task *tables.TaskTable, comments []tables.TaskCommentTable) string {
+	var b strings.Builder
+	b.WriteString("Task: ")
+	b.WriteString(strings.TrimSpace(task.Title))
+	b.WriteString("\n")
+
+	if description := strings.TrimSpace(task.Description); description != "" {
+		b.WriteString("\nDescription:\n")
+		b.WriteString(description)
+		b.WriteString("\n")
+	}
+
+	if len(comments) > 0 {
+		b.WriteString("\nComments:\n")
+		for _, comment := range comments {
+			b.WriteString("- ")
+			b.WriteString(strings.TrimSpace(comment.Content))
+			b.WriteString("\n")
+		}
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+func truncateRunes(value string, limit int) string {
+	runes := []rune(strings.TrimSpace(value))
+	if len(runes) <= limit {
+		return string(runes)
+	}
+	return string(runes[:limit])
+}
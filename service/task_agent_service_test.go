@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"code-kanban/model"
+	"code-kanban/model/tables"
+	"code-kanban/service/terminal"
+	"code-kanban/utils"
+)
+
+func TestTaskAgentServiceStartAgent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("agent terminal test relies on a POSIX shell")
+	}
+
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	repoPath := createProjectTestRepo(t)
+	ctx := context.Background()
+	project, err := (&model.ProjectService{}).CreateProject(ctx, model.CreateProjectParams{
+		Name: "Agent Project",
+		Path: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+
+	taskService := &model.TaskService{}
+	task, err := taskService.CreateTask(ctx, &model.CreateTaskRequest{
+		ProjectID:   project.Id,
+		Title:       "Fix login timeout",
+		Description: "Sessions expire too early",
+	})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	if _, err := model.NewTaskCommentService().CreateComment(ctx, task.ID, "check the refresh token"); err != nil {
+		t.Fatalf("CreateComment returned error: %v", err)
+	}
+
+	manager := terminal.NewManager(terminal.Config{
+		Shell: utils.TerminalShellConfig{Linux: "/bin/sh", Darwin: "/bin/sh"},
+	}, nil)
+	svc := NewTaskAgentService(manager, utils.AIAgentCommandConfig{ClaudeCode: "echo"})
+	svc.worktreeSvc.AsyncRefresh(false)
+
+	result, err := svc.StartAgent(ctx, task.ID, StartAgentParams{Assistant: "claude-code"})
+	if err != nil {
+		t.Fatalf("StartAgent returned error: %v", err)
+	}
+	defer result.Session.Close()
+
+	if !strings.HasPrefix(result.Worktree.BranchName, "task/fix-login-timeout-") {
+		t.Fatalf("unexpected branch name %s", result.Worktree.BranchName)
+	}
+	if result.Task.WorktreeID == nil || *result.Task.WorktreeID != result.Worktree.Id {
+		t.Fatalf("expected task to be bound to the new worktree")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var output strings.Builder
+		for _, chunk := range result.Session.Scrollback() {
+			output.Write(chunk)
+		}
+		if strings.Contains(output.String(), "- check the refresh token") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected prompt to be echoed, got %q", output.String())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	again, err := svc.StartAgent(ctx, task.ID, StartAgentParams{Assistant: "claude-code"})
+	if err != nil {
+		t.Fatalf("second StartAgent returned error: %v", err)
+	}
+	defer again.Session.Close()
+	if again.Worktree.Id != result.Worktree.Id {
+		t.Fatalf("expected bound worktree to be reused")
+	}
+
+	if _, err := svc.StartAgent(ctx, task.ID, StartAgentParams{Assistant: "copilot"}); !errors.Is(err, ErrUnsupportedAssistant) {
+		t.Fatalf("expected ErrUnsupportedAssistant, got %v", err)
+	}
+}
+
+func TestTaskBranchName(t *testing.T) {
+	cases := []struct {
+		title string
+		want  string
+	}{
+		{title: "Fix login timeout", want: "task/fix-login-timeout-abc123"},
+		{title: "  修复登录超时  ", want: "task/修复登录超时-abc123"},
+		{title: "API: add /tasks endpoint", want: "task/api_-add-__tasks-endpoint-abc123"},
+		{title: "Release v1..2 ~ draft", want: "task/release-v1_2-_-draft-abc123"},
+		{title: "  ???  ", want: "task/abc123"},
+	}
+	for _, tc := range cases {
+		task := &tables.TaskTable{Title: tc.title}
+		task.ID = "xyzABC123"
+		got := taskBranchName(task)
+		if got != tc.want {
+			t.Fatalf("taskBranchName(%q) = %q, want %q", tc.title, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"code-kanban/model"
+	"code-kanban/utils"
+
+	"go.uber.org/zap"
+)
+
+const defaultSchedulerInterval = time.Minute
+
+// TaskScheduler spawns recurring task instances and flags overdue tasks on a timer.
+type TaskScheduler struct {
+	interval      time.Duration
+	taskSvc       *model.TaskService
+	recurrenceSvc *model.TaskRecurrenceService
+}
+
+// NewTaskScheduler constructs a scheduler that ticks once a minute.
+func NewTaskScheduler() *TaskScheduler {
+	return &TaskScheduler{
+		interval:      defaultSchedulerInterval,
+		taskSvc:       &model.TaskService{},
+		recurrenceSvc: model.NewTaskRecurrenceService(),
+	}
+}
+
+// StartBackground runs a tick immediately and then on every interval until ctx is done.
+func (s *TaskScheduler) StartBackground(ctx context.Context) {
+	ctx = ensureContext(ctx)
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.Tick(ctx, time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Tick performs one scheduling pass and returns how many tasks were spawned and flagged.
+func (s *TaskScheduler) Tick(ctx context.Context, now time.Time) (spawned, flagged int) {
+	logger := utils.LoggerFromContext(ctx).Named("task-scheduler")
+
+	tasks, err := s.recurrenceSvc.SpawnDue(ctx, now)
+	if err != nil {
+		logger.Warn("spawn recurring tasks failed", zap.Error(err))
+	}
+	for _, task := range tasks {
+		logger.Info("recurring task spawned", zap.String("taskId", task.ID), zap.String("title", task.Title))
+	}
+
+	overdue, err := s.taskSvc.FlagOverdueTasks(ctx, now)
+	if err != nil {
+		logger.Warn("flag overdue tasks failed", zap.Error(err))
+	}
+	for _, task := range overdue {
+		logger.Info("task overdue", zap.String("taskId", task.ID), zap.String("title", task.Title))
+	}
+	return len(tasks), len(overdue)
+}
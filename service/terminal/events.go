@@ -0,0 +1,28 @@
+package terminal
+
+import (
+	"time"
+
+	"code-kanban/utils/ai_assistant"
+)
+
+// AssistantEventType describes lifecycle changes of an AI assistant inside a session.
+type AssistantEventType string
+
+const (
+	AssistantEventStarted AssistantEventType = "started"
+	AssistantEventStopped AssistantEventType = "stopped"
+)
+
+// AssistantEvent is emitted when an AI assistant appears in or leaves a terminal session.
+type AssistantEvent struct {
+	Type       AssistantEventType
+	SessionID  string
+	ProjectID  string
+	WorktreeID string
+	Assistant  ai_assistant.AIAssistantInfo
+	At         time.Time
+}
+
+// AssistantListener receives assistant lifecycle events. Listeners run on their own goroutine.
+type AssistantListener func(event AssistantEvent)
@@ -35,6 +35,8 @@ type CreateSessionParams struct {
 	Rows       int
 	Cols       int
 	Encoding   string
+	// InitialInput is typed into the shell once the session starts, e.g. a command line to launch.
+	InitialInput string
 }
 
 // Manager orchestrates PTY sessions.
@@ -46,6 +48,9 @@ type Manager struct {
 	encoding  string
 	baseCtx   context.Context
 	baseCtxMu sync.RWMutex
+
+	listenerMu         sync.RWMutex
+	assistantListeners []AssistantListener
 }
 
 // NewManager builds a manager instance.
@@ -110,6 +115,7 @@ func (m *Manager) CreateSession(ctx context.Context, params CreateSessionParams)
 		Encoding:          m.cfg.Encoding,
 		ScrollbackLimit:   m.cfg.ScrollbackBytes,
 		AIAssistantStatus: &m.cfg.AIAssistantStatus,
+		OnAssistantEvent:  m.emitAssistantEvent,
 	})
 	if err != nil {
 		return nil, err
@@ -134,9 +140,28 @@ func (m *Manager) CreateSession(ctx context.Context, params CreateSessionParams)
 
 	go m.watchSession(session)
 
+	if input := strings.TrimSpace(params.InitialInput); input != "" {
+		if _, err := session.Write([]byte(input + "\r")); err != nil {
+			m.logger.Warn("failed to write initial input",
+				zap.Error(err),
+				zap.String("sessionId", session.ID()),
+			)
+		}
+	}
+
 	return session, nil
 }
 
+// OnAssistantEvent registers a listener invoked whenever an AI assistant starts or stops in any session.
+func (m *Manager) OnAssistantEvent(listener AssistantListener) {
+	if listener == nil {
+		return
+	}
+	m.listenerMu.Lock()
+	m.assistantListeners = append(m.assistantListeners, listener)
+	m.listenerMu.Unlock()
+}
+
 // GetSession returns a session by identifier.
 func (m *Manager) GetSession(id string) (*Session, error) {
 	session, ok := m.sessions.Load(id)
@@ -190,6 +215,21 @@ func (m *Manager) ListSessions(projectID string) []SessionSnapshot {
 	return results
 }
 
+func (m *Manager) emitAssistantEvent(event AssistantEvent) {
+	m.listenerMu.RLock()
+	listeners := append([]AssistantListener(nil), m.assistantListeners...)
+	m.listenerMu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+// ShellCommand reports the shell command new sessions are started with.
+func (m *Manager) ShellCommand() ([]string, error) {
+	return m.shellCommand()
+}
+
 func (m *Manager) shellCommand() ([]string, error) {
 	return utils.ResolveShellCommand("", m.cfg.Shell)
 }
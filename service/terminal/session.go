@@ -152,6 +152,8 @@ type Session struct {
 
 	metaMu       sync.RWMutex
 	lastMetadata *SessionMetadata
+
+	onAssistantEvent AssistantListener
 }
 
 // SessionParams collects the data required to bootstrap a session.
@@ -169,6 +171,7 @@ type SessionParams struct {
 	Encoding          string
 	ScrollbackLimit   int
 	AIAssistantStatus *utils.AIAssistantStatusConfig
+	OnAssistantEvent  AssistantListener
 }
 
 // sessionError provides a non-nil wrapper so atomic.Value never stores nil.
@@ -214,6 +217,7 @@ func NewSession(params SessionParams) (*Session, error) {
 		scrollbackLimit:  scrollbackLimit,
 		subscribers:      make(map[string]*sessionSubscriber),
 		assistantTracker: ai_assistant.NewStatusTracker(),
+		onAssistantEvent: params.OnAssistantEvent,
 	}
 
 	// Set AI assistant status tracking checker if config is provided
@@ -380,6 +384,8 @@ func (s *Session) checkAndBroadcastMetadata() {
 		s.lastMetadata = metadata
 		s.metaMu.Unlock()
 
+		s.emitAssistantTransition(lastMeta, metadata)
+
 		// Broadcast metadata change
 		s.broadcast(StreamEvent{
 			Type:     StreamEventMetadata,
@@ -388,6 +394,45 @@ func (s *Session) checkAndBroadcastMetadata() {
 	}
 }
 
+// emitAssistantTransition notifies the listener when an assistant starts, stops or is replaced.
+func (s *Session) emitAssistantTransition(old, new *SessionMetadata) {
+	if s.onAssistantEvent == nil {
+		return
+	}
+
+	var before, after *ai_assistant.AIAssistantInfo
+	if old != nil {
+		before = old.AIAssistant
+	}
+	if new != nil {
+		after = new.AIAssistant
+	}
+	if before != nil && after != nil && before.Type == after.Type {
+		return
+	}
+
+	now := time.Now()
+	if before != nil {
+		s.dispatchAssistantEvent(AssistantEventStopped, *before, now)
+	}
+	if after != nil {
+		s.dispatchAssistantEvent(AssistantEventStarted, *after, now)
+	}
+}
+
+func (s *Session) dispatchAssistantEvent(eventType AssistantEventType, info ai_assistant.AIAssistantInfo, at time.Time) {
+	event := AssistantEvent{
+		Type:       eventType,
+		SessionID:  s.id,
+		ProjectID:  s.projectID,
+		WorktreeID: s.worktreeID,
+		Assistant:  info,
+		At:         at,
+	}
+	listener := s.onAssistantEvent
+	go listener(event)
+}
+
 func (s *Session) metadataChanged(old, new *SessionMetadata) bool {
 	if old == nil {
 		return true
@@ -535,6 +580,14 @@ func (s *Session) Close() error {
 		s.mu.Unlock()
 		close(s.closed)
 		s.notifyExit(s.Err())
+
+		// 关闭终端时助手随之退出，补发停止事件以便自动计时等监听方收尾
+		s.metaMu.RLock()
+		lastMeta := s.lastMetadata
+		s.metaMu.RUnlock()
+		if s.onAssistantEvent != nil && lastMeta != nil && lastMeta.AIAssistant != nil {
+			s.dispatchAssistantEvent(AssistantEventStopped, *lastMeta.AIAssistant, time.Now())
+		}
 	})
 	return closeErr
 }
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"code-kanban/model"
+	"code-kanban/service/terminal"
+	"code-kanban/utils"
+
+	"go.uber.org/zap"
+)
+
+const defaultTimeTrackerHeartbeat = time.Minute
+
+// TimeTracker turns AI assistant activity in terminals into automatic time
+// entries on the tasks bound to the terminal's worktree.
+type TimeTracker struct {
+	manager   *terminal.Manager
+	heartbeat time.Duration
+	timeSvc   *model.TaskTimeService
+}
+
+// NewTimeTracker constructs a tracker listening to the given terminal manager.
+func NewTimeTracker(manager *terminal.Manager) *TimeTracker {
+	return &TimeTracker{
+		manager:   manager,
+		heartbeat: defaultTimeTrackerHeartbeat,
+		timeSvc:   model.NewTaskTimeService(),
+	}
+}
+
+// StartBackground closes entries left open by a previous run, subscribes to
+// assistant events and refreshes the heartbeat of running entries until ctx is done.
+func (t *TimeTracker) StartBackground(ctx context.Context) {
+	ctx = ensureContext(ctx)
+	logger := utils.LoggerFromContext(ctx).Named("time-tracker")
+
+	// 终端不会在重启后保留，上次运行遗留的自动计时按最后一次心跳结束
+	if err := t.timeSvc.HeartbeatAssistantActivity(ctx, nil, time.Now()); err != nil {
+		logger.Warn("close orphaned assistant time entries failed", zap.Error(err))
+	}
+
+	t.manager.OnAssistantEvent(func(event terminal.AssistantEvent) {
+		t.HandleAssistantEvent(ctx, event)
+	})
+
+	go func() {
+		ticker := time.NewTicker(t.heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				t.Heartbeat(ctx, now)
+			}
+		}
+	}()
+}
+
+// HandleAssistantEvent opens or closes automatic entries for one assistant event.
+func (t *TimeTracker) HandleAssistantEvent(ctx context.Context, event terminal.AssistantEvent) {
+	logger := utils.LoggerFromContext(ctx).Named("time-tracker")
+	at := event.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	switch event.Type {
+	case terminal.AssistantEventStarted:
+		entries, err := t.timeSvc.StartAssistantActivity(ctx, event.WorktreeID, event.SessionID, event.Assistant.DisplayName, at)
+		if err != nil {
+			logger.Warn("start assistant time entries failed", zap.String("sessionId", event.SessionID), zap.Error(err))
+		}
+		for _, entry := range entries {
+			logger.Debug("assistant time entry started", zap.String("taskId", entry.TaskID), zap.String("sessionId", event.SessionID))
+		}
+	case terminal.AssistantEventStopped:
+		if _, err := t.timeSvc.StopAssistantActivity(ctx, event.SessionID, at); err != nil {
+			logger.Warn("stop assistant time entries failed", zap.String("sessionId", event.SessionID), zap.Error(err))
+		}
+	}
+}
+
+// Heartbeat marks entries of live sessions as still running and closes the rest.
+func (t *TimeTracker) Heartbeat(ctx context.Context, now time.Time) {
+	sessions := t.manager.ListSessions("")
+	live := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		if session.Status == terminal.SessionStatusClosed {
+			continue
+		}
+		live = append(live, session.ID)
+	}
+	if err := t.timeSvc.HeartbeatAssistantActivity(ctx, live, now); err != nil {
+		utils.LoggerFromContext(ctx).Named("time-tracker").Warn("refresh assistant time entries failed", zap.Error(err))
+	}
+}
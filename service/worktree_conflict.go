@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+
+	"code-kanban/model"
+	"code-kanban/utils/git"
+)
+
+// ConflictState reports the merge, rebase, cherry-pick or revert the worktree is
+// stopped in, if any, and which files still conflict.
+func (s *WorktreeService) ConflictState(ctx context.Context, id string) (*git.ConflictState, error) {
+	worktree, _, repo, err := s.openWorktreeRepo(ensureContext(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ConflictState(worktree.Path)
+}
+
+// ConflictFile returns the base, ours and theirs versions of a conflicted file.
+func (s *WorktreeService) ConflictFile(ctx context.Context, id, file string) (*git.ConflictFile, error) {
+	worktree, _, repo, err := s.openWorktreeRepo(ensureContext(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ConflictFile(worktree.Path, file)
+}
+
+// ResolveConflict resolves one file by picking a side or writing merged content
+// and returns the remaining conflict state.
+func (s *WorktreeService) ResolveConflict(ctx context.Context, id, file, resolution string, content []byte) (*git.ConflictState, error) {
+	return s.applyConflictAction(ctx, id, func(repo *git.GitRepo, worktree *model.Worktree) error {
+		return repo.ResolveConflict(worktree.Path, file, resolution, content)
+	})
+}
+
+// AbortOperation cancels the interrupted operation of the worktree.
+func (s *WorktreeService) AbortOperation(ctx context.Context, id string) (*git.ConflictState, error) {
+	return s.applyConflictAction(ctx, id, func(repo *git.GitRepo, worktree *model.Worktree) error {
+		return repo.AbortOperation(worktree.Path)
+	})
+}
+
+// ContinueOperation concludes the current step of the interrupted operation. The
+// returned state is non-empty when a rebase stopped again on a later commit.
+func (s *WorktreeService) ContinueOperation(ctx context.Context, id, message string) (*git.ConflictState, error) {
+	return s.applyConflictAction(ctx, id, func(repo *git.GitRepo, worktree *model.Worktree) error {
+		return repo.ContinueOperation(worktree.Path, message)
+	})
+}
+
+func (s *WorktreeService) applyConflictAction(ctx context.Context, id string, apply func(repo *git.GitRepo, worktree *model.Worktree) error) (*git.ConflictState, error) {
+	ctx = ensureContext(ctx)
+	worktree, _, repo, err := s.openWorktreeRepo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := apply(repo, worktree); err != nil {
+		return nil, err
+	}
+	// 冲突数、ahead/behind 等都可能变化
+	if _, err := s.RefreshWorktreeStatus(ctx, id); err != nil {
+		return nil, err
+	}
+	return repo.ConflictState(worktree.Path)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code-kanban/model"
+	"code-kanban/utils/git"
+)
+
+func TestWorktreeConflictResolution(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	repoPath := createProjectTestRepo(t)
+	project, err := (&model.ProjectService{}).CreateProject(context.Background(), model.CreateProjectParams{
+		Name: "Conflict Project",
+		Path: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+
+	svc := NewWorktreeService()
+	svc.AsyncRefresh(false)
+	ctx := context.Background()
+	worktree, err := svc.CreateWorktree(ctx, project.Id, "feature/conflict", "main", true)
+	if err != nil {
+		t.Fatalf("CreateWorktree returned error: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("main readme\n"), 0o644); err != nil {
+		t.Fatalf("write main readme: %v", err)
+	}
+	runGitCommand(t, repoPath, "commit", "-am", "main readme")
+	if err := os.WriteFile(filepath.Join(worktree.Path, "README.md"), []byte("feature readme\n"), 0o644); err != nil {
+		t.Fatalf("write feature readme: %v", err)
+	}
+	runGitCommand(t, worktree.Path, "commit", "-am", "feature readme")
+
+	if _, err := svc.AbortOperation(ctx, worktree.Id); !errors.Is(err, git.ErrNoOperationInProgress) {
+		t.Fatalf("expected ErrNoOperationInProgress, got %v", err)
+	}
+
+	result, err := NewBranchService().MergeBranch(ctx, worktree.Id, "main", model.MergeBranchOptions{Strategy: "merge"})
+	if err != nil || result.Success {
+		t.Fatalf("expected merge to stop on conflicts, got %+v (%v)", result, err)
+	}
+
+	state, err := svc.ConflictState(ctx, worktree.Id)
+	if err != nil {
+		t.Fatalf("ConflictState returned error: %v", err)
+	}
+	if state.Operation != git.OperationMerge || len(state.Conflicts) != 1 || state.Conflicts[0] != "README.md" {
+		t.Fatalf("unexpected conflict state: %+v", state)
+	}
+
+	file, err := svc.ConflictFile(ctx, worktree.Id, "README.md")
+	if err != nil {
+		t.Fatalf("ConflictFile returned error: %v", err)
+	}
+	if strings.TrimSpace(file.Theirs.Content) != "main readme" || len(file.Regions) != 1 {
+		t.Fatalf("unexpected conflict file: %+v", file)
+	}
+
+	prompt := buildConflictPrompt(worktree, state)
+	if !strings.Contains(prompt, "- README.md") || !strings.Contains(prompt, "Do not commit") {
+		t.Fatalf("unexpected prompt: %s", prompt)
+	}
+
+	if _, err := svc.ContinueOperation(ctx, worktree.Id, ""); !errors.Is(err, git.ErrUnresolvedConflicts) {
+		t.Fatalf("expected ErrUnresolvedConflicts, got %v", err)
+	}
+	state, err = svc.ResolveConflict(ctx, worktree.Id, "README.md", git.ResolveContent, []byte("merged readme\n"))
+	if err != nil {
+		t.Fatalf("ResolveConflict returned error: %v", err)
+	}
+	if !state.CanContinue {
+		t.Fatalf("expected merge to be ready to continue: %+v", state)
+	}
+	state, err = svc.ContinueOperation(ctx, worktree.Id, "")
+	if err != nil {
+		t.Fatalf("ContinueOperation returned error: %v", err)
+	}
+	if state.Operation != "" || len(state.Conflicts) != 0 {
+		t.Fatalf("expected merge to be concluded: %+v", state)
+	}
+	content, _ := os.ReadFile(filepath.Join(worktree.Path, "README.md"))
+	if string(content) != "merged readme\n" {
+		t.Fatalf("unexpected merged content %q", content)
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"code-kanban/model"
+	"code-kanban/utils/git"
+)
+
+// DiffWorktree returns the parsed diff of a worktree. Branch diffs default to the
+// project's default branch as base.
+func (s *WorktreeService) DiffWorktree(ctx context.Context, id string, opts git.DiffOptions) (*git.Diff, error) {
+	worktree, project, repo, err := s.openWorktreeRepo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	opts.Base = diffBase(project, opts.Base)
+	return repo.Diff(worktree.Path, opts)
+}
+
+// PatchWorktree returns the raw patch for the same selection as DiffWorktree.
+func (s *WorktreeService) PatchWorktree(ctx context.Context, id string, opts git.DiffOptions) ([]byte, error) {
+	worktree, project, repo, err := s.openWorktreeRepo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	opts.Base = diffBase(project, opts.Base)
+	return repo.Patch(worktree.Path, opts)
+}
+
+// openWorktreeRepo loads a worktree with its project and opens the project repository.
+func (s *WorktreeService) openWorktreeRepo(ctx context.Context, id string) (*model.Worktree, *model.Project, *git.GitRepo, error) {
+	ctx = ensureContext(ctx)
+	worktree, err := s.GetWorktree(ctx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	project, repo, err := openProjectRepo(ctx, worktree.ProjectId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return worktree, project, repo, nil
+}
+
+// openProjectRepo loads a project and opens its repository.
+func openProjectRepo(ctx context.Context, projectID string) (*model.Project, *git.GitRepo, error) {
+	q, err := model.ResolveQueries(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	project, err := q.ProjectGetByID(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, model.ErrProjectNotFound
+		}
+		return nil, nil, err
+	}
+	repo, err := git.DetectRepository(project.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return project, repo, nil
+}
+
+func diffBase(project *model.Project, base string) string {
+	if base = strings.TrimSpace(base); base != "" {
+		return base
+	}
+	if project.DefaultBranch != nil && *project.DefaultBranch != "" {
+		return *project.DefaultBranch
+	}
+	return "main"
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code-kanban/model"
+	"code-kanban/utils/git"
+)
+
+func TestWorktreeServiceDiff(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	repoPath := createProjectTestRepo(t)
+	project, err := (&model.ProjectService{}).CreateProject(context.Background(), model.CreateProjectParams{
+		Name: "Diff Project",
+		Path: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+
+	svc := NewWorktreeService()
+	svc.AsyncRefresh(false)
+	ctx := context.Background()
+	worktree, err := svc.CreateWorktree(ctx, project.Id, "feature/diff", "main", true)
+	if err != nil {
+		t.Fatalf("CreateWorktree returned error: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(worktree.Path, "agent.txt"), []byte("generated\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	runGitCommand(t, worktree.Path, "add", "agent.txt")
+	runGitCommand(t, worktree.Path, "commit", "-m", "agent output")
+	if err := os.WriteFile(filepath.Join(worktree.Path, "README.md"), []byte("demo\nmore\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	// 未指定 base 时使用项目默认分支
+	branch, err := svc.DiffWorktree(ctx, worktree.Id, git.DiffOptions{Mode: git.DiffModeBranch})
+	if err != nil {
+		t.Fatalf("DiffWorktree returned error: %v", err)
+	}
+	if branch.Base != defaultBranch(project) || len(branch.Files) != 1 || branch.Files[0].Path != "agent.txt" {
+		t.Fatalf("unexpected branch diff: %+v", branch)
+	}
+
+	working, err := svc.DiffWorktree(ctx, worktree.Id, git.DiffOptions{Mode: git.DiffModeWorking})
+	if err != nil {
+		t.Fatalf("DiffWorktree returned error: %v", err)
+	}
+	if len(working.Files) != 1 || working.Files[0].Path != "README.md" || working.Additions != 2 {
+		t.Fatalf("unexpected working diff: %+v", working)
+	}
+
+	patch, err := svc.PatchWorktree(ctx, worktree.Id, git.DiffOptions{Mode: git.DiffModeBranch})
+	if err != nil {
+		t.Fatalf("PatchWorktree returned error: %v", err)
+	}
+	if !strings.Contains(string(patch), "+generated") {
+		t.Fatalf("unexpected patch:\n%s", patch)
+	}
+}
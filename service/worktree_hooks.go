@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"os"
+
+	"code-kanban/model"
+	"code-kanban/utils"
+	"code-kanban/utils/git"
+	"code-kanban/utils/worktreehook"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// WorktreeHookSourceDatabase marks hooks configured through the API.
+	WorktreeHookSourceDatabase = "database"
+	// WorktreeHookSourceFile marks hooks read from the repository hook file.
+	WorktreeHookSourceFile = "file"
+)
+
+// WorktreeHooks are the hooks that apply to a project and where they came from.
+// Source is empty when the project has no hooks.
+type WorktreeHooks struct {
+	Source string `json:"source"`
+	worktreehook.Config
+}
+
+// WorktreeHooks returns the hooks in effect for a project. Stored hooks take
+// precedence over the repository hook file.
+func (s *WorktreeService) WorktreeHooks(ctx context.Context, projectID string) (*WorktreeHooks, error) {
+	ctx = ensureContext(ctx)
+	project, _, err := openProjectRepo(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	cfg, source, err := resolveWorktreeHooks(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	hooks := &WorktreeHooks{Source: source}
+	if cfg != nil {
+		hooks.Config = *cfg
+	}
+	return hooks, nil
+}
+
+func resolveWorktreeHooks(ctx context.Context, project *model.Project) (*worktreehook.Config, string, error) {
+	stored, err := model.NewWorktreeHookService().GetHooks(ctx, project.Id)
+	if err != nil {
+		return nil, "", err
+	}
+	if stored != nil {
+		return &worktreehook.Config{
+			PostCreate: stored.PostCreate,
+			Copy:       stored.Copy,
+			Symlink:    stored.Symlink,
+			PreDelete:  stored.PreDelete,
+			Timeout:    stored.Timeout,
+		}, WorktreeHookSourceDatabase, nil
+	}
+	cfg, err := worktreehook.LoadFile(project.Path)
+	if err != nil || cfg == nil {
+		return nil, "", err
+	}
+	return cfg, WorktreeHookSourceFile, nil
+}
+
+// runPreDeleteHooks runs the pre-delete commands inside the worktree, if it still exists.
+func (s *WorktreeService) runPreDeleteHooks(ctx context.Context, project *model.Project, worktree *model.Worktree, output worktreehook.OutputFunc) error {
+	if _, err := os.Stat(worktree.Path); err != nil {
+		return nil
+	}
+	hooks, _, err := resolveWorktreeHooks(ctx, project)
+	if err != nil {
+		return err
+	}
+	env := worktreehook.Env{
+		ProjectID:    project.Id,
+		MainPath:     project.Path,
+		WorktreePath: worktree.Path,
+		Branch:       worktree.BranchName,
+	}
+	return worktreehook.RunPreDelete(ctx, hooks, env, hookOutput(ctx, env, output))
+}
+
+// hookOutput logs every hook line and forwards it to output, when given.
+func hookOutput(ctx context.Context, env worktreehook.Env, output worktreehook.OutputFunc) worktreehook.OutputFunc {
+	logger := utils.LoggerFromContext(ctx).Named("worktree-hook").With(
+		zap.String("projectId", env.ProjectID),
+		zap.String("branch", env.Branch),
+	)
+	return func(event worktreehook.Event) {
+		logger.Info(event.Line,
+			zap.String("stage", event.Stage),
+			zap.String("step", event.Step),
+			zap.String("stream", event.Stream),
+		)
+		if output != nil {
+			output(event)
+		}
+	}
+}
+
+// rollbackWorktree undoes a partially created worktree, including the branch when it
+// was created for it.
+func rollbackWorktree(ctx context.Context, repo *git.GitRepo, path, branch string, branchCreated bool) {
+	logger := utils.LoggerFromContext(ctx)
+	if err := repo.RemoveWorktree(path, true); err != nil {
+		logger.Warn("failed to remove worktree during rollback", zap.Error(err), zap.String("path", path))
+	}
+	if !branchCreated {
+		return
+	}
+	if err := repo.DeleteBranch(branch, true); err != nil {
+		logger.Warn("failed to delete branch during rollback", zap.Error(err), zap.String("branch", branch))
+	}
+}
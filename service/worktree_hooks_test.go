@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"code-kanban/model"
+	"code-kanban/utils/git"
+	"code-kanban/utils/worktreehook"
+)
+
+func TestWorktreeHooksRunAndRollBack(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in this test need a POSIX shell")
+	}
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	repoPath := createProjectTestRepo(t)
+	if err := os.WriteFile(filepath.Join(repoPath, ".env"), []byte("SECRET=1\n"), 0o644); err != nil {
+		t.Fatalf("write .env: %v", err)
+	}
+	hookFile := "worktree:\n  copy: [.env]\n  postCreate:\n    - echo ready > hook.txt\n    - echo done\n"
+	if err := os.WriteFile(filepath.Join(repoPath, ".codekanban.yml"), []byte(hookFile), 0o644); err != nil {
+		t.Fatalf("write hook file: %v", err)
+	}
+
+	ctx := context.Background()
+	project, err := (&model.ProjectService{}).CreateProject(ctx, model.CreateProjectParams{
+		Name: "Hook Project",
+		Path: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+
+	svc := NewWorktreeService()
+	svc.AsyncRefresh(false)
+	hooks, err := svc.WorktreeHooks(ctx, project.Id)
+	if err != nil || hooks.Source != WorktreeHookSourceFile || len(hooks.PostCreate) != 2 {
+		t.Fatalf("expected hooks from the repository file, got %+v (%v)", hooks, err)
+	}
+
+	var events []worktreehook.Event
+	worktree, err := svc.CreateWorktreeWithOptions(ctx, project.Id, "feature/hooks", CreateWorktreeOptions{
+		BaseBranch:   "main",
+		CreateBranch: true,
+		HookOutput:   func(e worktreehook.Event) { events = append(events, e) },
+	})
+	if err != nil {
+		t.Fatalf("CreateWorktreeWithOptions returned error: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(worktree.Path, ".env")); string(content) != "SECRET=1\n" {
+		t.Fatalf("expected .env to be copied, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(worktree.Path, "hook.txt")); err != nil {
+		t.Fatalf("expected post-create command to run in the worktree: %v", err)
+	}
+	var sawDone bool
+	for _, event := range events {
+		if event.Stream == worktreehook.StreamStdout && event.Line == "done" {
+			sawDone = true
+		}
+	}
+	if !sawDone {
+		t.Fatalf("expected command output to be streamed, got %+v", events)
+	}
+
+	// 数据库中的配置优先于仓库文件
+	hookSvc := model.NewWorktreeHookService()
+	if _, err := hookSvc.SaveHooks(ctx, project.Id, worktreehook.Config{
+		PostCreate: []string{"exit 1"},
+		PreDelete:  []string{"exit 2"},
+	}); err != nil {
+		t.Fatalf("SaveHooks returned error: %v", err)
+	}
+	_, err = svc.CreateWorktree(ctx, project.Id, "feature/broken", "main", true)
+	if !errors.Is(err, worktreehook.ErrHookFailed) {
+		t.Fatalf("expected ErrHookFailed, got %v", err)
+	}
+	repo, err := git.DetectRepository(repoPath)
+	if err != nil {
+		t.Fatalf("DetectRepository failed: %v", err)
+	}
+	if repo.BranchExists("feature/broken") {
+		t.Fatal("expected created branch to be rolled back")
+	}
+	worktrees, err := svc.ListWorktrees(ctx, project.Id)
+	if err != nil {
+		t.Fatalf("ListWorktrees returned error: %v", err)
+	}
+	for _, wt := range worktrees {
+		if wt.BranchName == "feature/broken" {
+			t.Fatal("expected no worktree record after rollback")
+		}
+	}
+
+	if err := svc.DeleteWorktree(ctx, worktree.Id, false, true); !errors.Is(err, worktreehook.ErrHookFailed) {
+		t.Fatalf("expected failing pre-delete hook to stop deletion, got %v", err)
+	}
+	if err := svc.DeleteWorktree(ctx, worktree.Id, true, true); err != nil {
+		t.Fatalf("forced DeleteWorktree returned error: %v", err)
+	}
+
+	if err := hookSvc.DeleteHooks(ctx, project.Id); err != nil {
+		t.Fatalf("DeleteHooks returned error: %v", err)
+	}
+	if hooks, err := svc.WorktreeHooks(ctx, project.Id); err != nil || hooks.Source != WorktreeHookSourceFile {
+		t.Fatalf("expected repository file to apply again, got %+v (%v)", hooks, err)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"code-kanban/model"
 	"code-kanban/utils"
 	"code-kanban/utils/git"
+	"code-kanban/utils/worktreehook"
 
 	"go.uber.org/zap"
 )
@@ -20,6 +21,7 @@ import (
 // WorktreeService coordinates CRUD operations between git worktrees and the database.
 type WorktreeService struct {
 	asyncStatusRefresh bool
+	messageDrafter     *CommitMessageDrafter
 }
 
 // NewWorktreeService builds a WorktreeService with async status refresh enabled.
@@ -37,6 +39,40 @@ func (s *WorktreeService) AsyncRefresh(enabled bool) {
 	s.asyncStatusRefresh = enabled
 }
 
+// SetCommitMessageDrafter enables drafting messages for commits requested without one.
+func (s *WorktreeService) SetCommitMessageDrafter(drafter *CommitMessageDrafter) {
+	if s == nil {
+		return
+	}
+	s.messageDrafter = drafter
+}
+
+// CreateWorktreeOptions controls CreateWorktreeWithOptions.
+type CreateWorktreeOptions struct {
+	BaseBranch   string
+	CreateBranch bool
+	// Source is one of the WorktreeSource constants; empty means WorktreeSourceBranch.
+	// For the other sources the branch name is optional and BaseBranch/CreateBranch are ignored.
+	Source string
+	// Ref is the remote-tracking branch (origin/feature), tag or commit to start from.
+	Ref string
+	// PullRequest and Remote select refs/pull/<n>/head; Remote defaults to origin.
+	PullRequest int
+	Remote      string
+	// Progress receives fetch progress for pull requests. It may be nil.
+	Progress git.ProgressFunc
+	// HookOutput receives the output of the project's post-create hooks. It may be nil.
+	HookOutput worktreehook.OutputFunc
+}
+
+// DeleteWorktreeOptions controls DeleteWorktreeWithOptions.
+type DeleteWorktreeOptions struct {
+	Force        bool
+	DeleteBranch bool
+	// HookOutput receives the output of the project's pre-delete hooks. It may be nil.
+	HookOutput worktreehook.OutputFunc
+}
+
 // CreateWorktree provisions a new git worktree and persists its metadata.
 func (s *WorktreeService) CreateWorktree(
 	ctx context.Context,
@@ -44,6 +80,21 @@ func (s *WorktreeService) CreateWorktree(
 	branchName string,
 	baseBranch string,
 	createBranch bool,
+) (*model.Worktree, error) {
+	return s.CreateWorktreeWithOptions(ctx, projectID, branchName, CreateWorktreeOptions{
+		BaseBranch:   baseBranch,
+		CreateBranch: createBranch,
+	})
+}
+
+// CreateWorktreeWithOptions provisions a new git worktree, runs the project's
+// post-create hooks in it and persists its metadata. When a hook fails the worktree,
+// and the branch if it was created here, are removed again.
+func (s *WorktreeService) CreateWorktreeWithOptions(
+	ctx context.Context,
+	projectID string,
+	branchName string,
+	opts CreateWorktreeOptions,
 ) (*model.Worktree, error) {
 	if ctx == nil {
 		ctx = context.Background()
@@ -56,9 +107,6 @@ func (s *WorktreeService) CreateWorktree(
 	if strings.TrimSpace(projectID) == "" {
 		return nil, fmt.Errorf("project id is required")
 	}
-	if strings.TrimSpace(branchName) == "" {
-		return nil, fmt.Errorf("branch name is required")
-	}
 
 	project, err := q.ProjectGetByID(ctx, projectID)
 	if err != nil {
@@ -72,31 +120,48 @@ func (s *WorktreeService) CreateWorktree(
 	if err != nil {
 		return nil, err
 	}
+	// 先解析钩子，配置有误时不留下任何分支或目录
+	hooks, _, err := resolveWorktreeHooks(ctx, project)
+	if err != nil {
+		return nil, err
+	}
 
-	targetBranch := strings.TrimSpace(branchName)
-	if createBranch {
-		refBranch := strings.TrimSpace(baseBranch)
-		if refBranch == "" {
-			if project.DefaultBranch != nil && *project.DefaultBranch != "" {
-				refBranch = *project.DefaultBranch
-			} else {
-				refBranch = "main"
-			}
-		}
-		if err := gitRepo.CreateBranch(targetBranch, refBranch); err != nil {
-			return nil, err
-		}
+	target, err := resolveWorktreeTarget(ctx, gitRepo, project, branchName, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	worktreePath, err := s.resolveWorktreePath(project, targetBranch)
+	worktreePath, err := s.resolveWorktreePath(project, target.dirName)
+	if err == nil {
+		if target.branch != "" {
+			err = gitRepo.AddWorktree(worktreePath, target.branch, false)
+		} else {
+			err = gitRepo.AddDetachedWorktree(worktreePath, target.commit)
+		}
+	}
 	if err != nil {
+		// 分支是为这个 worktree 创建的，不留下来阻碍重试
+		if target.branchCreated {
+			_ = gitRepo.DeleteBranch(target.branch, true)
+		}
 		return nil, err
 	}
 
-	if err := gitRepo.AddWorktree(worktreePath, targetBranch, false); err != nil {
+	env := worktreehook.Env{
+		ProjectID:    projectID,
+		MainPath:     project.Path,
+		WorktreePath: worktreePath,
+		Branch:       target.branch,
+	}
+	if err := worktreehook.RunPostCreate(ctx, hooks, env, hookOutput(ctx, env, opts.HookOutput)); err != nil {
+		rollbackWorktree(ctx, gitRepo, worktreePath, target.branch, target.branchCreated)
 		return nil, err
 	}
 
+	var sourceRef *string
+	if target.sourceRef != "" {
+		sourceRef = &target.sourceRef
+	}
 	now := time.Now()
 	idVal := utils.NewID()
 	zeroVal := int64(0)
@@ -105,10 +170,12 @@ func (s *WorktreeService) CreateWorktree(
 		CreatedAt:       now,
 		UpdatedAt:       now,
 		ProjectId:       projectID,
-		BranchName:      targetBranch,
+		BranchName:      target.branch,
 		Path:            worktreePath,
 		IsMain:          false,
 		IsBare:          false,
+		IsDetached:      target.branch == "",
+		SourceRef:       sourceRef,
 		HeadCommit:      nil,
 		StatusAhead:     &zeroVal,
 		StatusBehind:    &zeroVal,
@@ -119,7 +186,7 @@ func (s *WorktreeService) CreateWorktree(
 		StatusUpdatedAt: nil,
 	})
 	if err != nil {
-		_ = gitRepo.RemoveWorktree(worktreePath, true)
+		rollbackWorktree(ctx, gitRepo, worktreePath, target.branch, target.branchCreated)
 		return nil, err
 	}
 
@@ -174,6 +241,13 @@ func (s *WorktreeService) GetWorktree(ctx context.Context, id string) (*model.Wo
 
 // DeleteWorktree removes a worktree from git and the database.
 func (s *WorktreeService) DeleteWorktree(ctx context.Context, id string, force, deleteBranch bool) error {
+	return s.DeleteWorktreeWithOptions(ctx, id, DeleteWorktreeOptions{Force: force, DeleteBranch: deleteBranch})
+}
+
+// DeleteWorktreeWithOptions runs the project's pre-delete hooks and then removes the
+// worktree from git and the database. A failing hook stops the deletion unless Force is set.
+func (s *WorktreeService) DeleteWorktreeWithOptions(ctx context.Context, id string, opts DeleteWorktreeOptions) error {
+	force, deleteBranch := opts.Force, opts.DeleteBranch
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -226,6 +300,15 @@ func (s *WorktreeService) DeleteWorktree(ctx context.Context, id string, force,
 				zap.String("worktreeId", id),
 			)
 		} else {
+			if err := s.runPreDeleteHooks(ctx, project, worktree, opts.HookOutput); err != nil {
+				if !force {
+					return err
+				}
+				utils.Logger().Warn("pre-delete hook failed, removing worktree anyway",
+					zap.Error(err),
+					zap.String("worktreeId", id),
+				)
+			}
 			// Try to remove the worktree from git
 			if err := gitRepo.RemoveWorktree(worktree.Path, force); err != nil {
 				// If the worktree path doesn't exist anymore, we can still proceed
@@ -243,7 +326,7 @@ func (s *WorktreeService) DeleteWorktree(ctx context.Context, id string, force,
 		}
 	}
 
-	if deleteBranch && gitRepo != nil {
+	if deleteBranch && gitRepo != nil && !worktree.IsDetached {
 		if err := gitRepo.DeleteBranch(worktree.BranchName, force); err != nil {
 			utils.Logger().Warn("failed to delete branch",
 				zap.Error(err),
@@ -412,6 +495,7 @@ func (s *WorktreeService) SyncWorktrees(ctx context.Context, projectID string) e
 				HeadCommit: headPtr,
 				IsMain:     gitWT.IsMain,
 				IsBare:     gitWT.IsBare,
+				IsDetached: gitWT.IsDetached,
 				Id:         existing.Id,
 			}); err != nil {
 				return err
@@ -434,6 +518,7 @@ func (s *WorktreeService) SyncWorktrees(ctx context.Context, projectID string) e
 			Path:            filepath.Clean(gitWT.Path),
 			IsMain:          gitWT.IsMain,
 			IsBare:          gitWT.IsBare,
+			IsDetached:      gitWT.IsDetached,
 			HeadCommit:      headPtr,
 			StatusAhead:     &zeroVal,
 			StatusBehind:    &zeroVal,
@@ -464,13 +549,14 @@ func (s *WorktreeService) SyncWorktrees(ctx context.Context, projectID string) e
 }
 
 // CommitWorktree stages all changes within the worktree and creates a commit with the provided message.
+// An empty message is drafted from the staged diff when a CommitMessageDrafter is set.
 func (s *WorktreeService) CommitWorktree(ctx context.Context, id, message string) (*model.Worktree, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
 	trimmedMessage := strings.TrimSpace(message)
-	if trimmedMessage == "" {
+	if trimmedMessage == "" && s.messageDrafter == nil {
 		return nil, fmt.Errorf("commit message is required")
 	}
 
@@ -505,6 +591,15 @@ func (s *WorktreeService) CommitWorktree(ctx context.Context, id, message string
 		return nil, model.ErrWorktreeClean
 	}
 
+	// 先起草再暂存，起草失败或超时不会留下被暂存的改动
+	if trimmedMessage == "" {
+		if trimmedMessage, err = s.messageDrafter.DraftAll(ctx, repo, worktree); err != nil {
+			if errors.Is(err, git.ErrNothingStaged) {
+				return nil, model.ErrWorktreeClean
+			}
+			return nil, err
+		}
+	}
 	if err := repo.AddAll(worktree.Path); err != nil {
 		return nil, err
 	}
@@ -540,6 +635,9 @@ func (s *WorktreeService) resolveWorktreePath(project *model.Project, branchName
 	return filepath.Join(basePath, dirName), nil
 }
 
+// sanitizeBranchName maps text to a single path segment that is also a valid branch
+// name component. Characters git rejects in branch names are replaced too, so the
+// mapping is unchanged for existing branches and reusable for names derived from text.
 func sanitizeBranchName(branch string) string {
 	replacer := strings.NewReplacer(
 		"/", "__",
@@ -550,6 +648,12 @@ func sanitizeBranchName(branch string) string {
 		"<", "_",
 		">", "_",
 		"|", "_",
+		"~", "_",
+		"^", "_",
+		"[", "_",
+		"\"", "_",
+		"..", "_",
+		"@{", "_",
 	)
-	return replacer.Replace(strings.TrimSpace(branch))
+	return strings.Join(strings.Fields(replacer.Replace(strings.TrimSpace(branch))), "-")
 }
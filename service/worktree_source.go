@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code-kanban/model"
+	"code-kanban/utils/git"
+)
+
+const (
+	// WorktreeSourceBranch checks out a local branch, optionally creating it from BaseBranch.
+	WorktreeSourceBranch = "branch"
+	// WorktreeSourceRemoteBranch creates a local branch tracking a remote-tracking branch.
+	WorktreeSourceRemoteBranch = "remote-branch"
+	// WorktreeSourceTag detaches HEAD at a tag.
+	WorktreeSourceTag = "tag"
+	// WorktreeSourceCommit detaches HEAD at a commit.
+	WorktreeSourceCommit = "commit"
+	// WorktreeSourcePullRequest fetches refs/pull/<n>/head and creates a local branch from it.
+	WorktreeSourcePullRequest = "pull-request"
+)
+
+// worktreeTarget is what a new worktree checks out.
+type worktreeTarget struct {
+	branch        string // 为空时以分离 HEAD 检出 commit
+	commit        string
+	dirName       string
+	sourceRef     string
+	branchCreated bool
+}
+
+// resolveWorktreeTarget prepares the branch or commit a new worktree starts from,
+// creating the local branch when the source calls for one.
+func resolveWorktreeTarget(
+	ctx context.Context,
+	repo *git.GitRepo,
+	project *model.Project,
+	branchName string,
+	opts CreateWorktreeOptions,
+) (*worktreeTarget, error) {
+	branchName = strings.TrimSpace(branchName)
+	ref := strings.TrimSpace(opts.Ref)
+
+	switch opts.Source {
+	case "", WorktreeSourceBranch:
+		if branchName == "" {
+			return nil, fmt.Errorf("branch name is required")
+		}
+		if opts.CreateBranch {
+			base := strings.TrimSpace(opts.BaseBranch)
+			if base == "" {
+				base = diffBase(project, "")
+			}
+			if err := repo.CreateBranch(branchName, base); err != nil {
+				return nil, err
+			}
+		}
+		return &worktreeTarget{branch: branchName, dirName: branchName, branchCreated: opts.CreateBranch}, nil
+
+	case WorktreeSourceRemoteBranch:
+		if ref == "" {
+			return nil, fmt.Errorf("remote branch is required")
+		}
+		upstream, err := repo.ResolveRemoteBranch(ref)
+		if err != nil {
+			return nil, err
+		}
+		if branchName == "" {
+			branchName = upstream.Branch
+		}
+		if err := checkNewBranch(repo, branchName); err != nil {
+			return nil, err
+		}
+		if err := repo.CreateTrackingBranch(branchName, *upstream); err != nil {
+			return nil, err
+		}
+		return &worktreeTarget{
+			branch:        branchName,
+			dirName:       branchName,
+			sourceRef:     upstream.Remote + "/" + upstream.Branch,
+			branchCreated: true,
+		}, nil
+
+	case WorktreeSourceTag:
+		if ref == "" {
+			return nil, fmt.Errorf("tag is required")
+		}
+		tag := strings.TrimPrefix(ref, "refs/tags/")
+		commit, err := repo.ResolveCommit("", "refs/tags/"+tag)
+		if err != nil {
+			return nil, err
+		}
+		return &worktreeTarget{commit: commit, dirName: tag, sourceRef: tag}, nil
+
+	case WorktreeSourceCommit:
+		if ref == "" {
+			return nil, fmt.Errorf("commit is required")
+		}
+		commit, err := repo.ResolveCommit("", ref)
+		if err != nil {
+			return nil, err
+		}
+		return &worktreeTarget{commit: commit, dirName: "detached-" + commit[:7], sourceRef: commit}, nil
+
+	case WorktreeSourcePullRequest:
+		if opts.PullRequest <= 0 {
+			return nil, fmt.Errorf("pull request number is required")
+		}
+		if branchName == "" {
+			branchName = fmt.Sprintf("pr/%d", opts.PullRequest)
+		}
+		// 先校验分支名，避免分支已存在时白白下载
+		if err := checkNewBranch(repo, branchName); err != nil {
+			return nil, err
+		}
+		fetched, err := repo.FetchPullRequest(ctx, opts.Remote, opts.PullRequest, opts.Progress)
+		if err != nil {
+			return nil, err
+		}
+		if err := repo.CreateBranch(branchName, fetched); err != nil {
+			return nil, err
+		}
+		return &worktreeTarget{
+			branch:        branchName,
+			dirName:       branchName,
+			sourceRef:     fmt.Sprintf("refs/pull/%d/head", opts.PullRequest),
+			branchCreated: true,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown worktree source %q", opts.Source)
+	}
+}
+
+// checkNewBranch reports whether name can be created as a new local branch.
+func checkNewBranch(repo *git.GitRepo, name string) error {
+	if err := repo.ValidateBranchName(name); err != nil {
+		return fmt.Errorf("%w: %v", model.ErrInvalidBranchName, err)
+	}
+	if repo.BranchExists(name) {
+		return fmt.Errorf("%w: %s", model.ErrBranchExists, name)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-kanban/model"
+	"code-kanban/utils/git"
+)
+
+func TestCreateWorktreeFromSources(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	// 同事把分支推到远端并开了 PR #12
+	remoteDir := filepath.Join(t.TempDir(), "remote.git")
+	runGitCommand(t, filepath.Dir(remoteDir), "init", "-q", "--bare", "-b", "main", remoteDir)
+	colleague := createProjectTestRepo(t)
+	runGitCommand(t, colleague, "remote", "add", "origin", remoteDir)
+	runGitCommand(t, colleague, "push", "-q", "origin", "main")
+	runGitCommand(t, colleague, "checkout", "-q", "-b", "feature/login")
+	if err := os.WriteFile(filepath.Join(colleague, "login.txt"), []byte("login\n"), 0o644); err != nil {
+		t.Fatalf("write login.txt: %v", err)
+	}
+	runGitCommand(t, colleague, "add", "login.txt")
+	runGitCommand(t, colleague, "commit", "-q", "-m", "add login")
+	runGitCommand(t, colleague, "push", "-q", "origin", "feature/login", "feature/login:refs/pull/12/head")
+
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	runGitCommand(t, filepath.Dir(repoPath), "clone", "-q", remoteDir, repoPath)
+	runGitCommand(t, repoPath, "tag", "v1.0.0")
+
+	ctx := context.Background()
+	project, err := (&model.ProjectService{}).CreateProject(ctx, model.CreateProjectParams{
+		Name: "Source Project",
+		Path: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+	svc := NewWorktreeService()
+	svc.AsyncRefresh(false)
+	repo, err := git.DetectRepository(repoPath)
+	if err != nil {
+		t.Fatalf("DetectRepository failed: %v", err)
+	}
+
+	tracking, err := svc.CreateWorktreeWithOptions(ctx, project.Id, "", CreateWorktreeOptions{
+		Source: WorktreeSourceRemoteBranch,
+		Ref:    "origin/feature/login",
+	})
+	if err != nil {
+		t.Fatalf("create from remote branch failed: %v", err)
+	}
+	if tracking.BranchName != "feature/login" || tracking.SourceRef == nil || *tracking.SourceRef != "origin/feature/login" {
+		t.Fatalf("unexpected worktree %+v", tracking)
+	}
+	if upstream, err := repo.Upstream(tracking.Path); err != nil || upstream.Branch != "feature/login" {
+		t.Fatalf("expected local branch to track origin/feature/login, got %+v (%v)", upstream, err)
+	}
+	_, err = svc.CreateWorktreeWithOptions(ctx, project.Id, "", CreateWorktreeOptions{
+		Source: WorktreeSourceRemoteBranch,
+		Ref:    "origin/feature/login",
+	})
+	if !errors.Is(err, model.ErrBranchExists) {
+		t.Fatalf("expected ErrBranchExists, got %v", err)
+	}
+
+	tagged, err := svc.CreateWorktreeWithOptions(ctx, project.Id, "", CreateWorktreeOptions{
+		Source: WorktreeSourceTag,
+		Ref:    "v1.0.0",
+	})
+	if err != nil {
+		t.Fatalf("create from tag failed: %v", err)
+	}
+	if !tagged.IsDetached || tagged.BranchName != "" || filepath.Base(tagged.Path) != "v1.0.0" {
+		t.Fatalf("expected detached worktree at the tag, got %+v", tagged)
+	}
+	if _, err := svc.CreateWorktreeWithOptions(ctx, project.Id, "", CreateWorktreeOptions{
+		Source: WorktreeSourceCommit,
+		Ref:    "0000000",
+	}); !errors.Is(err, git.ErrCommitNotFound) {
+		t.Fatalf("expected ErrCommitNotFound, got %v", err)
+	}
+
+	review, err := svc.CreateWorktreeWithOptions(ctx, project.Id, "", CreateWorktreeOptions{
+		Source:      WorktreeSourcePullRequest,
+		PullRequest: 12,
+	})
+	if err != nil {
+		t.Fatalf("create from pull request failed: %v", err)
+	}
+	if review.BranchName != "pr/12" {
+		t.Fatalf("expected pr/12 branch, got %q", review.BranchName)
+	}
+	if content, _ := os.ReadFile(filepath.Join(review.Path, "login.txt")); string(content) != "login\n" {
+		t.Fatalf("expected pull request contents, got %q", content)
+	}
+	if _, err := svc.CreateWorktreeWithOptions(ctx, project.Id, "pr/13", CreateWorktreeOptions{
+		Source:      WorktreeSourcePullRequest,
+		PullRequest: 13,
+	}); !errors.Is(err, git.ErrPullRequestNotFound) {
+		t.Fatalf("expected ErrPullRequestNotFound, got %v", err)
+	}
+
+	// 同步后仍保留分离 HEAD 标记；删除时不会尝试删除分支
+	if err := svc.SyncWorktrees(ctx, project.Id); err != nil {
+		t.Fatalf("SyncWorktrees returned error: %v", err)
+	}
+	synced, err := svc.GetWorktree(ctx, tagged.Id)
+	if err != nil || !synced.IsDetached || synced.SourceRef == nil || *synced.SourceRef != "v1.0.0" {
+		t.Fatalf("expected detached metadata to survive sync, got %+v (%v)", synced, err)
+	}
+	if err := svc.DeleteWorktree(ctx, tagged.Id, false, true); err != nil {
+		t.Fatalf("DeleteWorktree returned error: %v", err)
+	}
+}
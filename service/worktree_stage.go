@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code-kanban/model"
+	"code-kanban/utils/git"
+)
+
+// ChangeSelection picks the changes a staging operation acts on: whole paths, or
+// hunks of a single file as numbered by the worktree diff with default options.
+type ChangeSelection struct {
+	Paths []string
+	File  string
+	Hunks []git.HunkRef
+}
+
+func (sel ChangeSelection) byHunk() bool {
+	return len(sel.Hunks) > 0
+}
+
+// StageChanges adds the selected paths or hunks to the index.
+func (s *WorktreeService) StageChanges(ctx context.Context, id string, sel ChangeSelection) (*model.Worktree, error) {
+	return s.applyChangeSelection(ctx, id, func(repo *git.GitRepo, worktree *model.Worktree) error {
+		if sel.byHunk() {
+			return repo.StageHunks(worktree.Path, sel.File, sel.Hunks)
+		}
+		return repo.StagePaths(worktree.Path, sel.Paths)
+	})
+}
+
+// UnstageChanges moves the selected paths or staged hunks back out of the index.
+func (s *WorktreeService) UnstageChanges(ctx context.Context, id string, sel ChangeSelection) (*model.Worktree, error) {
+	return s.applyChangeSelection(ctx, id, func(repo *git.GitRepo, worktree *model.Worktree) error {
+		if sel.byHunk() {
+			return repo.UnstageHunks(worktree.Path, sel.File, sel.Hunks)
+		}
+		return repo.UnstagePaths(worktree.Path, sel.Paths)
+	})
+}
+
+// DiscardChanges throws away unstaged changes to the selected paths or hunks.
+// includeStaged only applies to paths and also resets their index entries to HEAD.
+func (s *WorktreeService) DiscardChanges(ctx context.Context, id string, sel ChangeSelection, includeStaged bool) (*model.Worktree, error) {
+	return s.applyChangeSelection(ctx, id, func(repo *git.GitRepo, worktree *model.Worktree) error {
+		if sel.byHunk() {
+			return repo.DiscardHunks(worktree.Path, sel.File, sel.Hunks)
+		}
+		return repo.DiscardPaths(worktree.Path, sel.Paths, includeStaged)
+	})
+}
+
+// CommitStagedWorktree commits only what is already staged; unstaged and untracked
+// files stay in the working tree.
+func (s *WorktreeService) CommitStagedWorktree(ctx context.Context, id, message string) (*model.Worktree, error) {
+	trimmedMessage := strings.TrimSpace(message)
+	if trimmedMessage == "" && s.messageDrafter == nil {
+		return nil, fmt.Errorf("commit message is required")
+	}
+	return s.applyChangeSelection(ctx, id, func(repo *git.GitRepo, worktree *model.Worktree) error {
+		if trimmedMessage == "" {
+			drafted, err := s.messageDrafter.Draft(ctx, repo, worktree)
+			if err != nil {
+				return err
+			}
+			trimmedMessage = drafted
+		}
+		return repo.CommitStaged(worktree.Path, trimmedMessage)
+	})
+}
+
+func (s *WorktreeService) applyChangeSelection(ctx context.Context, id string, apply func(repo *git.GitRepo, worktree *model.Worktree) error) (*model.Worktree, error) {
+	ctx = ensureContext(ctx)
+	worktree, _, repo, err := s.openWorktreeRepo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := apply(repo, worktree); err != nil {
+		return nil, err
+	}
+	return s.RefreshWorktreeStatus(ctx, id)
+}
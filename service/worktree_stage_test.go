@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code-kanban/model"
+	"code-kanban/utils/git"
+)
+
+func TestWorktreeServiceCommitStaged(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	repoPath := createProjectTestRepo(t)
+	project, err := (&model.ProjectService{}).CreateProject(context.Background(), model.CreateProjectParams{
+		Name: "Stage Project",
+		Path: repoPath,
+	})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+
+	svc := NewWorktreeService()
+	svc.AsyncRefresh(false)
+	ctx := context.Background()
+	worktree, err := svc.CreateWorktree(ctx, project.Id, "feature/stage", "main", true)
+	if err != nil {
+		t.Fatalf("CreateWorktree returned error: %v", err)
+	}
+
+	for name, content := range map[string]string{"feature.txt": "wanted\n", "debug.log": "noise\n"} {
+		if err := os.WriteFile(filepath.Join(worktree.Path, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	if _, err := svc.CommitStagedWorktree(ctx, worktree.Id, "feat: nothing"); !errors.Is(err, git.ErrNothingStaged) {
+		t.Fatalf("expected ErrNothingStaged, got %v", err)
+	}
+	if _, err := svc.StageChanges(ctx, worktree.Id, ChangeSelection{Paths: []string{"feature.txt"}}); err != nil {
+		t.Fatalf("StageChanges returned error: %v", err)
+	}
+	updated, err := svc.CommitStagedWorktree(ctx, worktree.Id, "feat: add feature")
+	if err != nil {
+		t.Fatalf("CommitStagedWorktree returned error: %v", err)
+	}
+	if updated.StatusUntracked == nil || *updated.StatusUntracked != 1 {
+		t.Fatalf("expected debug.log to remain untracked, got %+v", updated)
+	}
+
+	output, err := exec.Command("git", "-C", worktree.Path, "show", "--name-only", "--format=", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("git show failed: %v", err)
+	}
+	if strings.TrimSpace(string(output)) != "feature.txt" {
+		t.Fatalf("expected only feature.txt to be committed, got %q", output)
+	}
+
+	if _, err := svc.DiscardChanges(ctx, worktree.Id, ChangeSelection{Paths: []string{"debug.log"}}, false); err != nil {
+		t.Fatalf("DiscardChanges returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktree.Path, "debug.log")); !os.IsNotExist(err) {
+		t.Fatalf("expected debug.log to be removed, got %v", err)
+	}
+}
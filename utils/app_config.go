@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/knadh/koanf/parsers/yaml"
@@ -14,11 +15,13 @@ import (
 
 type AttachmentConfig struct {
 	UseS3     bool   `json:"useS3" yaml:"useS3"`
-	Endpoint  string `json:"endpoint" yaml:"endpoint"`
+	Endpoint  string `json:"endpoint" yaml:"endpoint"` // 如 https://s3.amazonaws.com 或 http://127.0.0.1:9000（MinIO）
 	Bucket    string `json:"bucket" yaml:"bucket"`
+	Region    string `json:"region" yaml:"region"` // 留空时为 us-east-1
 	AccessKey string `json:"accessKey" yaml:"accessKey"`
 	SecretKey string `json:"secretKey" yaml:"secretKey"`
 	Token     string `json:"token" yaml:"token"`
+	LocalDir  string `json:"localDir" yaml:"localDir"` // 本地存储目录，留空时使用数据目录下的 attachments
 }
 
 type TerminalShellConfig struct {
@@ -36,14 +39,98 @@ type AIAssistantStatusConfig struct {
 	Copilot    bool `json:"copilot" yaml:"copilot"`       // 未充分测试，默认禁用
 }
 
+type AIAgentCommandConfig struct {
+	ClaudeCode string `json:"claudeCode" yaml:"claudeCode"`
+	Codex      string `json:"codex" yaml:"codex"`
+	QwenCode   string `json:"qwenCode" yaml:"qwenCode"`
+	Gemini     string `json:"gemini" yaml:"gemini"`
+	Cursor     string `json:"cursor" yaml:"cursor"`
+	Copilot    string `json:"copilot" yaml:"copilot"`
+}
+
+// IssueTrackerHostConfig 描述一个 issue 托管平台的访问方式，按远程仓库的主机名匹配
+type IssueTrackerHostConfig struct {
+	Host   string `json:"host" yaml:"host"`
+	Kind   string `json:"kind" yaml:"kind"`     // github 或 gitea，留空时 github.com 视为 github，其余视为 gitea
+	APIURL string `json:"apiUrl" yaml:"apiUrl"` // 留空时根据主机名推断
+	Token  string `json:"token" yaml:"token"`
+}
+
+type IssueSyncConfig struct {
+	Interval string                   `json:"interval" yaml:"interval"`
+	Hosts    []IssueTrackerHostConfig `json:"hosts" yaml:"hosts"`
+}
+
+// IntervalDuration parses the background sync interval; zero or invalid values disable the timer.
+func (c *IssueSyncConfig) IntervalDuration() time.Duration {
+	if c == nil {
+		return 0
+	}
+	return parseInterval(c.Interval)
+}
+
+// Host 返回与主机名匹配的配置，未配置时返回 nil
+func (c *IssueSyncConfig) Host(host string) *IssueTrackerHostConfig {
+	for i := range c.Hosts {
+		if strings.EqualFold(c.Hosts[i].Host, host) {
+			return &c.Hosts[i]
+		}
+	}
+	return nil
+}
+
+// RemoteSyncConfig 配置后台定时抓取各项目远程仓库
+type RemoteSyncConfig struct {
+	Interval string `json:"interval" yaml:"interval"` // 为空或 0 时关闭后台抓取
+	Prune    bool   `json:"prune" yaml:"prune"`       // 抓取时清理远端已删除的分支
+}
+
+// IntervalDuration parses the fetch interval; zero or invalid values disable the scheduler.
+func (c *RemoteSyncConfig) IntervalDuration() time.Duration {
+	if c == nil {
+		return 0
+	}
+	return parseInterval(c.Interval)
+}
+
+// parseInterval parses a background timer interval; empty, negative or invalid values yield 0.
+func parseInterval(raw string) time.Duration {
+	dur, err := time.ParseDuration(strings.TrimSpace(raw))
+	if err != nil || dur < 0 {
+		return 0
+	}
+	return dur
+}
+
+// CommitMessageConfig 配置提交信息为空时自动生成所用的模型
+type CommitMessageConfig struct {
+	Provider     string `json:"provider" yaml:"provider"` // command 或 openai，留空时不自动生成
+	Command      string `json:"command" yaml:"command"`   // provider 为 command 时执行的命令，提示词通过标准输入传入
+	BaseURL      string `json:"baseUrl" yaml:"baseUrl"`   // OpenAI 兼容接口地址，留空时为 https://api.openai.com/v1
+	APIKey       string `json:"apiKey" yaml:"apiKey"`
+	Model        string `json:"model" yaml:"model"`
+	Timeout      string `json:"timeout" yaml:"timeout"`
+	MaxDiffBytes int    `json:"maxDiffBytes" yaml:"maxDiffBytes"` // 发送给模型的 diff 上限，超出部分截断
+}
+
+// TimeoutDuration parses Timeout and falls back to one minute.
+func (c *CommitMessageConfig) TimeoutDuration() time.Duration {
+	dur, err := time.ParseDuration(c.Timeout)
+	if err != nil || dur <= 0 {
+		return time.Minute
+	}
+	return dur
+}
+
 type TerminalConfig struct {
-	Shell                 TerminalShellConfig      `json:"shell" yaml:"shell"`
-	IdleTimeout           string                   `json:"idleTimeout" yaml:"idleTimeout"`
-	MaxSessionsPerProject int                      `json:"maxSessionsPerProject" yaml:"maxSessionsPerProject"`
-	AllowedRoots          []string                 `json:"allowedRoots" yaml:"allowedRoots"`
-	Encoding              string                   `json:"encoding" yaml:"encoding"`
-	ScrollbackBytes       int                      `json:"scrollbackBytes" yaml:"scrollbackBytes"`
-	AIAssistantStatus     AIAssistantStatusConfig  `json:"aiAssistantStatus" yaml:"aiAssistantStatus"`
+	Shell                 TerminalShellConfig     `json:"shell" yaml:"shell"`
+	IdleTimeout           string                  `json:"idleTimeout" yaml:"idleTimeout"`
+	MaxSessionsPerProject int                     `json:"maxSessionsPerProject" yaml:"maxSessionsPerProject"`
+	AllowedRoots          []string                `json:"allowedRoots" yaml:"allowedRoots"`
+	Encoding              string                  `json:"encoding" yaml:"encoding"`
+	ScrollbackBytes       int                     `json:"scrollbackBytes" yaml:"scrollbackBytes"`
+	AIAssistantStatus     AIAssistantStatusConfig `json:"aiAssistantStatus" yaml:"aiAssistantStatus"`
+	AgentCommands         AIAgentCommandConfig    `json:"agentCommands" yaml:"agentCommands"`
 
 	idleDuration time.Duration
 }
@@ -89,27 +176,50 @@ func (c *AIAssistantStatusConfig) IsEnabled(assistantType string) bool {
 	}
 }
 
+// Command 返回启动指定 AI 助手所用的命令行，未配置时返回空字符串
+func (c *AIAgentCommandConfig) Command(assistantType string) string {
+	switch assistantType {
+	case "claude-code":
+		return c.ClaudeCode
+	case "codex":
+		return c.Codex
+	case "qwen-code":
+		return c.QwenCode
+	case "gemini":
+		return c.Gemini
+	case "cursor":
+		return c.Cursor
+	case "copilot":
+		return c.Copilot
+	default:
+		return ""
+	}
+}
+
 type AppConfig struct {
-	ServeAt             string           `json:"serveAt" yaml:"serveAt"`
-	Domain              string           `json:"domain" yaml:"domain"`
-	RegisterOpen        bool             `json:"registerOpen" yaml:"registerOpen"`
-	WebUrl              string           `json:"webUrl" yaml:"webUrl"`
-	AttachmentSizeLimit int64            `json:"attachmentSizeLimit" yaml:"attachmentSizeLimit"`
-	ImageCompress       bool             `json:"imageCompress" yaml:"imageCompress"`
-	LogFile             string           `json:"logFile" yaml:"logFile"`
-	LogLevel            string           `json:"logLevel" yaml:"logLevel"`
-	DBLogLevel          int              `json:"dbLogLevel" yaml:"dbLogLevel"`
-	CorsAllowOrigins    string           `json:"corsAllowOrigins" yaml:"corsAllowOrigins"`
-	UIOverwrite         string           `json:"uiOverwrite" yaml:"uiOverwrite"`
-	AutoMigrate         bool             `json:"autoMigrate" yaml:"autoMigrate"`
-	OpenAPIEnabled      bool             `json:"openapiEnabled" yaml:"openapiEnabled"`
-	DocsPath            string           `json:"docsPath" yaml:"docsPath"`
-	APITitle            string           `json:"apiTitle" yaml:"apiTitle"`
-	APIVersion          string           `json:"apiVersion" yaml:"apiVersion"`
-	AttachmentConfig    AttachmentConfig `json:"attachmentConfig" yaml:"attachmentConfig"`
-	DSN                 string           `json:"dbUrl" yaml:"dbUrl"`
-	PrintConfig         bool             `json:"printConfig" yaml:"printConfig"`
-	Terminal            TerminalConfig   `json:"terminal" yaml:"terminal"`
+	ServeAt             string              `json:"serveAt" yaml:"serveAt"`
+	Domain              string              `json:"domain" yaml:"domain"`
+	RegisterOpen        bool                `json:"registerOpen" yaml:"registerOpen"`
+	WebUrl              string              `json:"webUrl" yaml:"webUrl"`
+	AttachmentSizeLimit int64               `json:"attachmentSizeLimit" yaml:"attachmentSizeLimit"`
+	ImageCompress       bool                `json:"imageCompress" yaml:"imageCompress"`
+	LogFile             string              `json:"logFile" yaml:"logFile"`
+	LogLevel            string              `json:"logLevel" yaml:"logLevel"`
+	DBLogLevel          int                 `json:"dbLogLevel" yaml:"dbLogLevel"`
+	CorsAllowOrigins    string              `json:"corsAllowOrigins" yaml:"corsAllowOrigins"`
+	UIOverwrite         string              `json:"uiOverwrite" yaml:"uiOverwrite"`
+	AutoMigrate         bool                `json:"autoMigrate" yaml:"autoMigrate"`
+	OpenAPIEnabled      bool                `json:"openapiEnabled" yaml:"openapiEnabled"`
+	DocsPath            string              `json:"docsPath" yaml:"docsPath"`
+	APITitle            string              `json:"apiTitle" yaml:"apiTitle"`
+	APIVersion          string              `json:"apiVersion" yaml:"apiVersion"`
+	AttachmentConfig    AttachmentConfig    `json:"attachmentConfig" yaml:"attachmentConfig"`
+	DSN                 string              `json:"dbUrl" yaml:"dbUrl"`
+	PrintConfig         bool                `json:"printConfig" yaml:"printConfig"`
+	Terminal            TerminalConfig      `json:"terminal" yaml:"terminal"`
+	IssueSync           IssueSyncConfig     `json:"issueSync" yaml:"issueSync"`
+	CommitMessage       CommitMessageConfig `json:"commitMessage" yaml:"commitMessage"`
+	RemoteSync          RemoteSyncConfig    `json:"remoteSync" yaml:"remoteSync"`
 }
 
 var configStore = koanf.New(".")
@@ -170,6 +280,29 @@ func ReadConfig() *AppConfig {
 				Cursor:     false, // 未充分测试
 				Copilot:    false, // 未充分测试
 			},
+			AgentCommands: AIAgentCommandConfig{
+				ClaudeCode: "claude",
+				Codex:      "codex",
+				QwenCode:   "qwen",
+				Gemini:     "gemini",
+				Cursor:     "cursor-agent",
+				Copilot:    "copilot",
+			},
+		},
+		IssueSync: IssueSyncConfig{
+			Interval: "15m",
+			Hosts:    []IssueTrackerHostConfig{},
+		},
+		CommitMessage: CommitMessageConfig{
+			Provider:     "",
+			Command:      "claude -p",
+			Model:        "gpt-4o-mini",
+			Timeout:      "60s",
+			MaxDiffBytes: 60000,
+		},
+		RemoteSync: RemoteSyncConfig{
+			Interval: "",
+			Prune:    false,
 		},
 	}
 
@@ -0,0 +1,46 @@
+package commitmsg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/google/shlex"
+)
+
+// CommandGenerator runs a local CLI such as `claude -p` or `llm` with the prompt on
+// stdin and reads the message from stdout.
+type CommandGenerator struct {
+	args []string
+}
+
+// NewCommandGenerator parses command with shell quoting rules.
+func NewCommandGenerator(command string) (*CommandGenerator, error) {
+	args, err := shlex.Split(strings.TrimSpace(command))
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit message command %q: %w", command, err)
+	}
+	if len(args) == 0 {
+		return nil, errors.New("commit message command is empty")
+	}
+	return &CommandGenerator{args: args}, nil
+}
+
+// Generate runs the command until it exits or ctx is done.
+func (g *CommandGenerator) Generate(ctx context.Context, prompt string) (string, error) {
+	cmd := exec.CommandContext(ctx, g.args[0], g.args[1:]...)
+	cmd.Stdin = strings.NewReader(prompt)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return "", fmt.Errorf("%s: %w: %s", g.args[0], err, detail)
+		}
+		return "", fmt.Errorf("%s: %w", g.args[0], err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,158 @@
+// Package commitmsg drafts conventional-commit messages from a staged diff with an
+// external language model.
+package commitmsg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxSubjectLength is the subject line limit enforced by Format.
+const MaxSubjectLength = 72
+
+var (
+	// ErrEmptyMessage indicates the generator produced nothing usable.
+	ErrEmptyMessage = errors.New("generated commit message is empty")
+	// ErrGeneratorFailed wraps errors returned by the configured generator.
+	ErrGeneratorFailed = errors.New("commit message generation failed")
+)
+
+// FileChange summarises one staged file for the prompt.
+type FileChange struct {
+	Path      string
+	Status    string
+	Additions int
+	Deletions int
+}
+
+// Request is the context handed to a generator.
+type Request struct {
+	Branch    string
+	Files     []FileChange
+	Patch     string
+	Truncated bool // Patch 超出长度限制被截断
+}
+
+// Generator turns a prompt into a raw commit message.
+type Generator interface {
+	Generate(ctx context.Context, prompt string) (string, error)
+}
+
+// Draft builds the prompt for req, asks g for a message and normalises the result.
+func Draft(ctx context.Context, g Generator, req Request) (string, error) {
+	raw, err := g.Generate(ctx, BuildPrompt(req))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrGeneratorFailed, err)
+	}
+	return Format(raw)
+}
+
+// BuildPrompt renders the instructions and the staged changes.
+func BuildPrompt(req Request) string {
+	var b strings.Builder
+	b.WriteString("Write a git commit message for the staged changes below.\n")
+	b.WriteString("Use the Conventional Commits format: `type(optional scope): summary`, where type is one of ")
+	b.WriteString(strings.Join(conventionalTypes, ", "))
+	b.WriteString(".\n")
+	fmt.Fprintf(&b, "Keep the summary in the imperative mood, lower case, without a trailing period and under %d characters.\n", MaxSubjectLength)
+	b.WriteString("Add a short body after a blank line only when the change needs explaining.\n")
+	b.WriteString("Reply with the commit message only, without quotes, code fences or commentary.\n\n")
+
+	if req.Branch != "" {
+		fmt.Fprintf(&b, "Branch: %s\n\n", req.Branch)
+	}
+	if len(req.Files) > 0 {
+		b.WriteString("Files:\n")
+		for _, file := range req.Files {
+			fmt.Fprintf(&b, "- %s %s (+%d -%d)\n", file.Status, file.Path, file.Additions, file.Deletions)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("Diff:\n")
+	b.WriteString(req.Patch)
+	if !strings.HasSuffix(req.Patch, "\n") {
+		b.WriteString("\n")
+	}
+	if req.Truncated {
+		b.WriteString("[diff truncated]\n")
+	}
+	return b.String()
+}
+
+var conventionalTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+var subjectPattern = regexp.MustCompile(`^([A-Za-z]+)(\([^)]*\))?(!)?:\s*(.+)$`)
+
+// Format cleans up model output into `type(scope): subject` plus an optional body.
+// Fences, quotes and labels such as "Commit message:" are stripped, the type is lower
+// cased and subjects without a recognised type fall back to chore.
+func Format(raw string) (string, error) {
+	text := strings.ReplaceAll(strings.TrimSpace(raw), "\r\n", "\n")
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return "", ErrEmptyMessage
+	}
+
+	subject := strings.TrimSpace(lines[0])
+	if lower := strings.ToLower(subject); strings.HasPrefix(lower, "commit message:") {
+		subject = strings.TrimSpace(subject[len("commit message:"):])
+	}
+	subject = strings.Trim(subject, "\"'`")
+	if subject == "" {
+		return "", ErrEmptyMessage
+	}
+
+	if match := subjectPattern.FindStringSubmatch(subject); match != nil && isConventionalType(match[1]) {
+		subject = strings.ToLower(match[1]) + match[2] + match[3] + ": " + match[4]
+	} else {
+		subject = "chore: " + subject
+	}
+	subject = truncateSubject(strings.TrimRight(subject, "."))
+
+	body := strings.TrimSpace(strings.Join(lines[1:], "\n"))
+	body = strings.Trim(body, "\"'")
+	for strings.Contains(body, "\n\n\n") {
+		body = strings.ReplaceAll(body, "\n\n\n", "\n\n")
+	}
+	if body == "" {
+		return subject, nil
+	}
+	return subject + "\n\n" + body, nil
+}
+
+func isConventionalType(value string) bool {
+	value = strings.ToLower(value)
+	for _, t := range conventionalTypes {
+		if t == value {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateSubject cuts at the last word boundary that fits MaxSubjectLength.
+func truncateSubject(subject string) string {
+	if utf8.RuneCountInString(subject) <= MaxSubjectLength {
+		return subject
+	}
+	runes := []rune(subject)[:MaxSubjectLength]
+	cut := string(runes)
+	if idx := strings.LastIndex(cut, " "); idx > len(cut)/2 {
+		cut = cut[:idx]
+	}
+	return strings.TrimRight(cut, " ,;:.")
+}
@@ -0,0 +1,114 @@
+package commitmsg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "plain", raw: "feat(api): add staged commits\n", want: "feat(api): add staged commits"},
+		{name: "fenced with label", raw: "```\nCommit message: Fix: handle empty diff.\n```", want: "fix: handle empty diff"},
+		{name: "breaking with body", raw: "refactor!: drop legacy flag\n\n\n\nRemoves --old.", want: "refactor!: drop legacy flag\n\nRemoves --old."},
+		{name: "no type", raw: "\"Update readme\"", want: "chore: Update readme"},
+		{name: "unknown type", raw: "wip: something", want: "chore: wip: something"},
+		{
+			name: "long subject",
+			raw:  "feat: " + strings.Repeat("word ", 20),
+			want: "feat: " + strings.TrimSpace(strings.Repeat("word ", 13)),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Format(tc.raw)
+			if err != nil {
+				t.Fatalf("Format returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("Format(%q) = %q, want %q", tc.raw, got, tc.want)
+			}
+		})
+	}
+
+	if _, err := Format("```\n\n```"); !errors.Is(err, ErrEmptyMessage) {
+		t.Fatalf("expected ErrEmptyMessage, got %v", err)
+	}
+}
+
+func TestOpenAIGenerator(t *testing.T) {
+	var received chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, `{"error":{"message":"bad request"}}`, http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"docs: describe setup"}}]}`))
+	}))
+	defer server.Close()
+
+	generator, err := NewOpenAIGenerator(OpenAIConfig{BaseURL: server.URL + "/v1/", APIKey: "secret", Model: "tiny"})
+	if err != nil {
+		t.Fatalf("NewOpenAIGenerator returned error: %v", err)
+	}
+	message, err := Draft(context.Background(), generator, Request{
+		Branch: "feature/docs",
+		Files:  []FileChange{{Path: "README.md", Status: "modified", Additions: 3}},
+		Patch:  "+setup steps",
+	})
+	if err != nil {
+		t.Fatalf("Draft returned error: %v", err)
+	}
+	if message != "docs: describe setup" {
+		t.Fatalf("unexpected message %q", message)
+	}
+	if received.Model != "tiny" || len(received.Messages) != 2 || !strings.Contains(received.Messages[1].Content, "modified README.md (+3 -0)") {
+		t.Fatalf("unexpected request: %+v", received)
+	}
+
+	unauthorized, err := NewOpenAIGenerator(OpenAIConfig{BaseURL: server.URL + "/v1", Model: "tiny"})
+	if err != nil {
+		t.Fatalf("NewOpenAIGenerator returned error: %v", err)
+	}
+	if _, err := Draft(context.Background(), unauthorized, Request{}); !errors.Is(err, ErrGeneratorFailed) || !strings.Contains(err.Error(), "bad request") {
+		t.Fatalf("expected wrapped endpoint error, got %v", err)
+	}
+}
+
+func TestCommandGenerator(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	generator, err := NewCommandGenerator(`sh -c 'grep -q "Branch: main" && echo "test: cover generator"'`)
+	if err != nil {
+		t.Fatalf("NewCommandGenerator returned error: %v", err)
+	}
+	message, err := Draft(context.Background(), generator, Request{Branch: "main"})
+	if err != nil {
+		t.Fatalf("Draft returned error: %v", err)
+	}
+	if message != "test: cover generator" {
+		t.Fatalf("unexpected message %q", message)
+	}
+
+	failing, err := NewCommandGenerator(`sh -c 'echo boom >&2; exit 3'`)
+	if err != nil {
+		t.Fatalf("NewCommandGenerator returned error: %v", err)
+	}
+	if _, err := Draft(context.Background(), failing, Request{}); !errors.Is(err, ErrGeneratorFailed) || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected command failure, got %v", err)
+	}
+}
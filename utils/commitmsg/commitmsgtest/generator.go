@@ -0,0 +1,34 @@
+// Package commitmsgtest provides a scripted commit message generator for tests.
+package commitmsgtest
+
+import (
+	"context"
+	"sync"
+)
+
+// Generator returns Message (or Err) and records every prompt it receives.
+type Generator struct {
+	Message string
+	Err     error
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+// Generate implements commitmsg.Generator.
+func (g *Generator) Generate(_ context.Context, prompt string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.prompts = append(g.prompts, prompt)
+	if g.Err != nil {
+		return "", g.Err
+	}
+	return g.Message, nil
+}
+
+// Prompts returns the prompts received so far.
+func (g *Generator) Prompts() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.prompts...)
+}
@@ -0,0 +1,125 @@
+package commitmsg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultOpenAIBaseURL is used when OpenAIConfig.BaseURL is empty.
+const DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+const systemPrompt = "You write concise, accurate git commit messages."
+
+// OpenAIConfig configures an OpenAI-compatible chat completions endpoint, which also
+// covers Ollama, vLLM, LM Studio and similar servers.
+type OpenAIConfig struct {
+	BaseURL    string
+	APIKey     string
+	Model      string
+	HTTPClient *http.Client
+}
+
+// OpenAIGenerator asks a chat completions endpoint for the message.
+type OpenAIGenerator struct {
+	endpoint string
+	apiKey   string
+	model    string
+	http     *http.Client
+}
+
+// NewOpenAIGenerator validates cfg and fills in defaults.
+func NewOpenAIGenerator(cfg OpenAIConfig) (*OpenAIGenerator, error) {
+	if strings.TrimSpace(cfg.Model) == "" {
+		return nil, fmt.Errorf("commit message model is required")
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &OpenAIGenerator{
+		endpoint: baseURL + "/chat/completions",
+		apiKey:   cfg.APIKey,
+		model:    cfg.Model,
+		http:     httpClient,
+	}, nil
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Generate sends one chat completion request; the deadline comes from ctx.
+func (g *OpenAIGenerator) Generate(ctx context.Context, prompt string) (string, error) {
+	payload, err := json.Marshal(chatRequest{
+		Model: g.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: prompt},
+		},
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+g.apiKey)
+	}
+
+	resp, err := g.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+
+	var decoded chatResponse
+	decodeErr := json.Unmarshal(body, &decoded)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message := strings.TrimSpace(string(body))
+		if decodeErr == nil && decoded.Error != nil && decoded.Error.Message != "" {
+			message = decoded.Error.Message
+		}
+		return "", fmt.Errorf("endpoint returned %d: %s", resp.StatusCode, message)
+	}
+	if decodeErr != nil {
+		return "", fmt.Errorf("decode response: %w", decodeErr)
+	}
+	if len(decoded.Choices) == 0 {
+		return "", ErrEmptyMessage
+	}
+	return decoded.Choices[0].Message.Content, nil
+}
@@ -92,6 +92,49 @@ func (r *GitRepo) CreateBranch(name, base string) error {
 	return nil
 }
 
+// CreateTrackingBranch creates a local branch starting at a remote-tracking branch
+// and sets it as the branch's upstream. The upstream is written to the config
+// directly because --track refuses refs that several remotes' refspecs map to.
+func (r *GitRepo) CreateTrackingBranch(name string, upstream Upstream) error {
+	if r == nil {
+		return errors.New("git repository is not initialized")
+	}
+	branch := strings.TrimSpace(name)
+	if branch == "" {
+		return errors.New("branch name is required")
+	}
+
+	commands := [][]string{
+		{"branch", "--no-track", branch, "refs/remotes/" + upstream.Remote + "/" + upstream.Branch},
+		{"config", "branch." + branch + ".remote", upstream.Remote},
+		{"config", "branch." + branch + ".merge", "refs/heads/" + upstream.Branch},
+	}
+	for i, args := range commands {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = r.Path
+		if output, err := cmd.CombinedOutput(); err != nil {
+			if i > 0 {
+				_ = r.DeleteBranch(branch, true)
+			}
+			return fmt.Errorf("create branch failed: %s", strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// BranchExists reports whether a local branch with the given name exists.
+func (r *GitRepo) BranchExists(name string) bool {
+	if r == nil || r.Repository == nil {
+		return false
+	}
+	branch := strings.TrimSpace(name)
+	if branch == "" {
+		return false
+	}
+	_, err := r.Repository.Reference(plumbing.NewBranchReferenceName(branch), true)
+	return err == nil
+}
+
 // DeleteBranch removes a local branch. Force controls the -D flag.
 func (r *GitRepo) DeleteBranch(name string, force bool) error {
 	if r == nil {
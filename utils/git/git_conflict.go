@@ -0,0 +1,434 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Operations that can leave a worktree waiting for conflict resolution.
+const (
+	OperationMerge      = "merge"
+	OperationRebase     = "rebase"
+	OperationCherryPick = "cherry-pick"
+	OperationRevert     = "revert"
+)
+
+// Conflict resolutions accepted by ResolveConflict.
+const (
+	ResolveOurs    = "ours"
+	ResolveTheirs  = "theirs"
+	ResolveContent = "content"
+)
+
+// maxConflictBlobBytes bounds each stage returned by ConflictFile.
+const maxConflictBlobBytes = 1 << 20
+
+var (
+	// ErrNoOperationInProgress indicates there is no merge, rebase, cherry-pick or revert to act on.
+	ErrNoOperationInProgress = errors.New("no merge or rebase in progress")
+	// ErrUnresolvedConflicts indicates conflicts must be resolved before continuing.
+	ErrUnresolvedConflicts = errors.New("unresolved conflicts remain")
+	// ErrNotConflicted indicates the file has no conflict to resolve.
+	ErrNotConflicted = errors.New("file is not in conflict")
+	// ErrInvalidResolution indicates an unknown resolution or unusable merged content.
+	ErrInvalidResolution = errors.New("invalid conflict resolution")
+)
+
+// ConflictState describes an interrupted operation in a worktree. Operation is empty
+// when nothing is in progress; conflicts can still exist after a squash merge.
+type ConflictState struct {
+	Operation   string   `json:"operation,omitempty"`
+	Head        string   `json:"head,omitempty"`       // 当前 HEAD
+	Incoming    string   `json:"incoming,omitempty"`   // MERGE_HEAD、REBASE_HEAD 等正在合入的提交
+	Onto        string   `json:"onto,omitempty"`       // rebase 的目标提交
+	Branch      string   `json:"branch,omitempty"`     // rebase 中的原分支
+	Step        int      `json:"step,omitempty"`       // rebase 当前步骤
+	TotalSteps  int      `json:"totalSteps,omitempty"` // rebase 总步骤数
+	Message     string   `json:"message,omitempty"`    // 准备好的提交信息
+	Conflicts   []string `json:"conflicts"`
+	CanContinue bool     `json:"canContinue"`
+}
+
+// ConflictStage is one side of a conflicted file. Missing means the side deleted the file.
+type ConflictStage struct {
+	Missing bool   `json:"missing,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// ConflictRegion is one marked conflict block of the working tree file.
+type ConflictRegion struct {
+	StartLine   int     `json:"startLine"`
+	EndLine     int     `json:"endLine"`
+	OursLabel   string  `json:"oursLabel,omitempty"`
+	TheirsLabel string  `json:"theirsLabel,omitempty"`
+	Ours        string  `json:"ours"`
+	Base        *string `json:"base,omitempty"` // 仅 diff3/zdiff3 风格的标记包含
+	Theirs      string  `json:"theirs"`
+}
+
+// ConflictFile holds the three index stages and the marked-up working copy of a file.
+// During a rebase "ours" is the branch being rebased onto and "theirs" the replayed commit.
+type ConflictFile struct {
+	Path     string           `json:"path"`
+	Binary   bool             `json:"binary,omitempty"`
+	TooLarge bool             `json:"tooLarge,omitempty"`
+	Base     ConflictStage    `json:"base"`
+	Ours     ConflictStage    `json:"ours"`
+	Theirs   ConflictStage    `json:"theirs"`
+	Working  string           `json:"working,omitempty"`
+	Regions  []ConflictRegion `json:"regions"`
+}
+
+// ConflictState inspects the git directory of the worktree for an interrupted operation.
+func (r *GitRepo) ConflictState(worktreePath string) (*ConflictState, error) {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	state := &ConflictState{Conflicts: []string{}}
+	if output, err := runGitOutput(path, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		state.Head = strings.TrimSpace(string(output))
+	}
+
+	switch {
+	case gitPathExists(path, "rebase-merge"):
+		state.Operation = OperationRebase
+		readRebaseState(path, "rebase-merge", state)
+	case gitPathExists(path, "rebase-apply") && !gitPathExists(path, "rebase-apply/applying"):
+		state.Operation = OperationRebase
+		readRebaseState(path, "rebase-apply", state)
+	case gitPathExists(path, "MERGE_HEAD"):
+		state.Operation = OperationMerge
+		state.Incoming = firstLine(readGitPath(path, "MERGE_HEAD"))
+	case gitPathExists(path, "CHERRY_PICK_HEAD"):
+		state.Operation = OperationCherryPick
+		state.Incoming = firstLine(readGitPath(path, "CHERRY_PICK_HEAD"))
+	case gitPathExists(path, "REVERT_HEAD"):
+		state.Operation = OperationRevert
+		state.Incoming = firstLine(readGitPath(path, "REVERT_HEAD"))
+	}
+	if state.Operation != "" && state.Operation != OperationRebase {
+		state.Message = strings.TrimSpace(readGitPath(path, "MERGE_MSG"))
+	}
+
+	conflicts, err := listConflicts(path)
+	if err != nil {
+		return nil, err
+	}
+	state.Conflicts = conflicts
+	state.CanContinue = state.Operation != "" && len(conflicts) == 0
+	return state, nil
+}
+
+// AbortOperation cancels the interrupted operation and restores the pre-operation state.
+// Conflicts left without an operation (squash merges) are reset to HEAD.
+func (r *GitRepo) AbortOperation(worktreePath string) error {
+	state, err := r.ConflictState(worktreePath)
+	if err != nil {
+		return err
+	}
+	path, _ := r.resolveWorktreePath(worktreePath)
+	switch state.Operation {
+	case "":
+		if len(state.Conflicts) == 0 {
+			return ErrNoOperationInProgress
+		}
+		return runGitNonInteractive(path, "reset", "--merge")
+	default:
+		return runGitNonInteractive(path, state.Operation, "--abort")
+	}
+}
+
+// ContinueOperation concludes the current step once every conflict is staged. A
+// non-empty message replaces the prepared merge message. A rebase may stop again
+// on the next commit; callers should re-read ConflictState afterwards.
+func (r *GitRepo) ContinueOperation(worktreePath, message string) error {
+	state, err := r.ConflictState(worktreePath)
+	if err != nil {
+		return err
+	}
+	if state.Operation == "" {
+		return ErrNoOperationInProgress
+	}
+	if len(state.Conflicts) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnresolvedConflicts, strings.Join(state.Conflicts, ", "))
+	}
+	path, _ := r.resolveWorktreePath(worktreePath)
+
+	message = strings.TrimSpace(message)
+	if state.Operation == OperationMerge && message != "" {
+		return runGitNonInteractive(path, "commit", "-m", message)
+	}
+	err = runGitNonInteractive(path, state.Operation, "--continue")
+	if err != nil && state.Operation == OperationRebase {
+		// 下一个提交再次冲突时 git 以非零退出，此时状态仍然有效
+		if next, stateErr := r.ConflictState(worktreePath); stateErr == nil && len(next.Conflicts) > 0 {
+			return nil
+		}
+	}
+	return err
+}
+
+// ConflictFile returns base, ours and theirs for a conflicted file along with the
+// conflict regions found in the working tree copy.
+func (r *GitRepo) ConflictFile(worktreePath, file string) (*ConflictFile, error) {
+	path, cleaned, err := r.pathsArgs(worktreePath, []string{file})
+	if err != nil {
+		return nil, err
+	}
+	stages, err := conflictStages(path, cleaned[0])
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ConflictFile{Path: cleaned[0], Regions: []ConflictRegion{}}
+	sides := map[string]*ConflictStage{"1": &result.Base, "2": &result.Ours, "3": &result.Theirs}
+	for stage, side := range sides {
+		blob, ok := stages[stage]
+		if !ok {
+			side.Missing = true
+			continue
+		}
+		size, err := runGitOutput(path, "cat-file", "-s", blob)
+		if err != nil {
+			return nil, err
+		}
+		if n, _ := strconv.Atoi(strings.TrimSpace(string(size))); n > maxConflictBlobBytes {
+			result.TooLarge = true
+			continue
+		}
+		content, err := runGitOutput(path, "cat-file", "blob", blob)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.IndexByte(content, 0) >= 0 {
+			result.Binary = true
+		}
+		side.Content = string(content)
+	}
+	if result.Binary || result.TooLarge {
+		result.Base.Content, result.Ours.Content, result.Theirs.Content = "", "", ""
+		return result, nil
+	}
+
+	working, err := os.ReadFile(filepath.Join(path, filepath.FromSlash(cleaned[0])))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(working) > maxConflictBlobBytes {
+		result.TooLarge = true
+		return result, nil
+	}
+	result.Working = string(working)
+	result.Regions = ParseConflictRegions(result.Working)
+	return result, nil
+}
+
+// ResolveConflict resolves a file by taking one side or by writing merged content,
+// and stages the result. A side that deleted the file resolves to a deletion.
+func (r *GitRepo) ResolveConflict(worktreePath, file, resolution string, content []byte) error {
+	path, cleaned, err := r.pathsArgs(worktreePath, []string{file})
+	if err != nil {
+		return err
+	}
+	target := cleaned[0]
+	stages, err := conflictStages(path, target)
+	if err != nil {
+		return err
+	}
+
+	switch resolution {
+	case ResolveOurs, ResolveTheirs:
+		stage := "2"
+		if resolution == ResolveTheirs {
+			stage = "3"
+		}
+		if _, ok := stages[stage]; !ok {
+			return r.runInWorktree(path, "rm", "--quiet", "--", target)
+		}
+		if err := r.runInWorktree(path, "checkout", "--"+resolution, "--", target); err != nil {
+			return err
+		}
+	case ResolveContent:
+		if len(ParseConflictRegions(string(content))) > 0 {
+			return fmt.Errorf("%w: content still contains conflict markers", ErrInvalidResolution)
+		}
+		fullPath := filepath.Join(path, filepath.FromSlash(target))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(fullPath, content, 0o644); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidResolution, resolution)
+	}
+	return r.runInWorktree(path, "add", "--", target)
+}
+
+// ParseConflictRegions finds <<<<<<< / ||||||| / ======= / >>>>>>> blocks. Unterminated
+// blocks are ignored.
+func ParseConflictRegions(content string) []ConflictRegion {
+	regions := []ConflictRegion{}
+	lines := strings.Split(content, "\n")
+
+	const (
+		outside = iota
+		inOurs
+		inBase
+		inTheirs
+	)
+	state := outside
+	var current ConflictRegion
+	var ours, base, theirs []string
+	hasBase := false
+
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case isConflictMarker(line, "<<<<<<<"):
+			state = inOurs
+			current = ConflictRegion{StartLine: i + 1, OursLabel: markerLabel(line)}
+			ours, base, theirs, hasBase = nil, nil, nil, false
+		case state == inOurs && isConflictMarker(line, "|||||||"):
+			state = inBase
+			hasBase = true
+		case (state == inOurs || state == inBase) && isConflictMarker(line, "======="):
+			state = inTheirs
+		case state == inTheirs && isConflictMarker(line, ">>>>>>>"):
+			current.EndLine = i + 1
+			current.TheirsLabel = markerLabel(line)
+			current.Ours = strings.Join(ours, "\n")
+			current.Theirs = strings.Join(theirs, "\n")
+			if hasBase {
+				joined := strings.Join(base, "\n")
+				current.Base = &joined
+			}
+			regions = append(regions, current)
+			state = outside
+		case state == inOurs:
+			ours = append(ours, line)
+		case state == inBase:
+			base = append(base, line)
+		case state == inTheirs:
+			theirs = append(theirs, line)
+		}
+	}
+	return regions
+}
+
+func isConflictMarker(line, marker string) bool {
+	return line == marker || strings.HasPrefix(line, marker+" ")
+}
+
+func markerLabel(line string) string {
+	return strings.TrimSpace(line[7:])
+}
+
+// conflictStages maps stage numbers ("1" base, "2" ours, "3" theirs) to blob ids.
+func conflictStages(path, file string) (map[string]string, error) {
+	output, err := runGitOutput(path, "ls-files", "--unmerged", "-z", "--", file)
+	if err != nil {
+		return nil, err
+	}
+	stages := map[string]string{}
+	for _, entry := range strings.Split(string(output), "\x00") {
+		meta, name, ok := strings.Cut(entry, "\t")
+		if !ok || name != file {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) == 3 {
+			stages[fields[2]] = fields[1]
+		}
+	}
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotConflicted, file)
+	}
+	return stages, nil
+}
+
+func listConflicts(path string) ([]string, error) {
+	output, err := runGitOutput(path, "ls-files", "--unmerged", "-z")
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]struct{}{}
+	conflicts := []string{}
+	for _, entry := range strings.Split(string(output), "\x00") {
+		_, name, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		if _, dup := seen[name]; dup {
+			continue
+		}
+		seen[name] = struct{}{}
+		conflicts = append(conflicts, name)
+	}
+	return conflicts, nil
+}
+
+func readRebaseState(path, dir string, state *ConflictState) {
+	state.Incoming = firstLine(readGitPath(path, "REBASE_HEAD"))
+	state.Onto = firstLine(readGitPath(path, dir+"/onto"))
+	state.Branch = strings.TrimPrefix(firstLine(readGitPath(path, dir+"/head-name")), "refs/heads/")
+	stepFile, totalFile := "msgnum", "end"
+	if dir == "rebase-apply" {
+		stepFile, totalFile = "next", "last"
+	}
+	state.Step, _ = strconv.Atoi(firstLine(readGitPath(path, dir+"/"+stepFile)))
+	state.TotalSteps, _ = strconv.Atoi(firstLine(readGitPath(path, dir+"/"+totalFile)))
+}
+
+// gitPath resolves a path inside the worktree's git directory; linked worktrees keep
+// their MERGE_HEAD and rebase state apart from the main repository.
+func gitPath(path, name string) string {
+	output, err := runGitOutput(path, "rev-parse", "--git-path", name)
+	if err != nil {
+		return ""
+	}
+	resolved := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(path, resolved)
+	}
+	return resolved
+}
+
+func gitPathExists(path, name string) bool {
+	resolved := gitPath(path, name)
+	if resolved == "" {
+		return false
+	}
+	_, err := os.Stat(resolved)
+	return err == nil
+}
+
+func readGitPath(path, name string) string {
+	resolved := gitPath(path, name)
+	if resolved == "" {
+		return ""
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// runGitNonInteractive accepts prepared commit messages instead of opening an editor.
+func runGitNonInteractive(path string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = path
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true", "GIT_TERMINAL_PROMPT=0")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConflictRegions(t *testing.T) {
+	content := "keep\n<<<<<<< HEAD\nours line\n||||||| base\nbase line\n=======\ntheirs line\n>>>>>>> feature\nmiddle\n<<<<<<< HEAD\na\n=======\nb\n>>>>>>> other\n<<<<<<< unterminated\n"
+	regions := ParseConflictRegions(content)
+	if len(regions) != 2 {
+		t.Fatalf("expected 2 regions, got %+v", regions)
+	}
+	first := regions[0]
+	if first.StartLine != 2 || first.EndLine != 8 || first.Ours != "ours line" || first.Theirs != "theirs line" || first.Base == nil || *first.Base != "base line" {
+		t.Fatalf("unexpected first region: %+v", first)
+	}
+	if first.OursLabel != "HEAD" || first.TheirsLabel != "feature" {
+		t.Fatalf("unexpected labels: %+v", first)
+	}
+	if regions[1].Base != nil || regions[1].Ours != "a" || regions[1].Theirs != "b" {
+		t.Fatalf("unexpected second region: %+v", regions[1])
+	}
+}
+
+func TestGitRepoConflictWorkflow(t *testing.T) {
+	repoDir := initTestRepo(t)
+	repo, err := DetectRepository(repoDir)
+	if err != nil {
+		t.Fatalf("DetectRepository failed: %v", err)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	write("app.txt", "version 1\n")
+	write("notes.txt", "notes\n")
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-q", "-m", "base")
+	runGit(t, repoDir, "checkout", "-q", "-b", "feature")
+	write("app.txt", "version feature\n")
+	write("notes.txt", "feature notes\n")
+	runGit(t, repoDir, "commit", "-q", "-am", "feature change")
+	runGit(t, repoDir, "checkout", "-q", "main")
+	write("app.txt", "version main\n")
+	write("notes.txt", "main notes\n")
+	runGit(t, repoDir, "commit", "-q", "-am", "main change")
+
+	state, err := repo.ConflictState(repoDir)
+	if err != nil || state.Operation != "" || len(state.Conflicts) != 0 {
+		t.Fatalf("expected clean state, got %+v (%v)", state, err)
+	}
+	if err := repo.AbortOperation(repoDir); !errors.Is(err, ErrNoOperationInProgress) {
+		t.Fatalf("expected ErrNoOperationInProgress, got %v", err)
+	}
+
+	if err := repo.MergeBranch(repoDir, "feature", MergeStrategyMerge); !IsConflictError(err) {
+		t.Fatalf("expected merge conflict, got %v", err)
+	}
+	state, err = repo.ConflictState(repoDir)
+	if err != nil {
+		t.Fatalf("ConflictState failed: %v", err)
+	}
+	if state.Operation != OperationMerge || len(state.Conflicts) != 2 || state.CanContinue || state.Incoming == "" {
+		t.Fatalf("unexpected merge state: %+v", state)
+	}
+
+	file, err := repo.ConflictFile(repoDir, "app.txt")
+	if err != nil {
+		t.Fatalf("ConflictFile failed: %v", err)
+	}
+	if strings.TrimSpace(file.Base.Content) != "version 1" || strings.TrimSpace(file.Ours.Content) != "version main" || strings.TrimSpace(file.Theirs.Content) != "version feature" {
+		t.Fatalf("unexpected stages: %+v", file)
+	}
+	if len(file.Regions) != 1 || strings.TrimSpace(file.Regions[0].Theirs) != "version feature" {
+		t.Fatalf("unexpected regions: %+v", file.Regions)
+	}
+	if _, err := repo.ConflictFile(repoDir, "README.md"); !errors.Is(err, ErrNotConflicted) {
+		t.Fatalf("expected ErrNotConflicted, got %v", err)
+	}
+
+	if err := repo.ContinueOperation(repoDir, ""); !errors.Is(err, ErrUnresolvedConflicts) {
+		t.Fatalf("expected ErrUnresolvedConflicts, got %v", err)
+	}
+	if err := repo.ResolveConflict(repoDir, "app.txt", ResolveTheirs, nil); err != nil {
+		t.Fatalf("resolve theirs failed: %v", err)
+	}
+	if err := repo.ResolveConflict(repoDir, "notes.txt", ResolveContent, []byte("<<<<<<< HEAD\nx\n=======\ny\n>>>>>>> feature\n")); !errors.Is(err, ErrInvalidResolution) {
+		t.Fatalf("expected markers to be rejected, got %v", err)
+	}
+	if err := repo.ResolveConflict(repoDir, "notes.txt", ResolveContent, []byte("main and feature notes\n")); err != nil {
+		t.Fatalf("resolve content failed: %v", err)
+	}
+
+	state, err = repo.ConflictState(repoDir)
+	if err != nil || !state.CanContinue {
+		t.Fatalf("expected state to allow continue, got %+v (%v)", state, err)
+	}
+	if err := repo.ContinueOperation(repoDir, "merge feature with notes"); err != nil {
+		t.Fatalf("ContinueOperation failed: %v", err)
+	}
+	state, err = repo.ConflictState(repoDir)
+	if err != nil || state.Operation != "" {
+		t.Fatalf("expected merge to be concluded, got %+v (%v)", state, err)
+	}
+	subject, err := runGitOutput(repoDir, "log", "-1", "--format=%s")
+	if err != nil || strings.TrimSpace(string(subject)) != "merge feature with notes" {
+		t.Fatalf("unexpected merge commit %q (%v)", subject, err)
+	}
+	app, _ := os.ReadFile(filepath.Join(repoDir, "app.txt"))
+	if strings.TrimSpace(string(app)) != "version feature" {
+		t.Fatalf("expected theirs to win, got %q", app)
+	}
+
+	// rebase 冲突后放弃，应回到原分支
+	runGit(t, repoDir, "checkout", "-q", "-b", "topic", "HEAD~1")
+	write("app.txt", "version topic\n")
+	runGit(t, repoDir, "commit", "-q", "-am", "topic change")
+	if err := repo.MergeBranch(repoDir, "feature", MergeStrategyRebase); err == nil {
+		t.Fatal("expected rebase to stop on conflicts")
+	}
+	state, err = repo.ConflictState(repoDir)
+	if err != nil || state.Operation != OperationRebase || state.Branch != "topic" || state.Step != 1 || state.TotalSteps != 2 || len(state.Conflicts) == 0 {
+		t.Fatalf("unexpected rebase state: %+v (%v)", state, err)
+	}
+	if err := repo.AbortOperation(repoDir); err != nil {
+		t.Fatalf("AbortOperation failed: %v", err)
+	}
+	state, err = repo.ConflictState(repoDir)
+	if err != nil || state.Operation != "" || len(state.Conflicts) != 0 {
+		t.Fatalf("expected rebase to be aborted, got %+v (%v)", state, err)
+	}
+}
@@ -0,0 +1,657 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// DiffMode selects which two trees a diff compares.
+type DiffMode string
+
+const (
+	// DiffModeWorking compares the working tree with the index (unstaged changes).
+	DiffModeWorking DiffMode = "working"
+	// DiffModeStaged compares the index with HEAD (staged changes).
+	DiffModeStaged DiffMode = "staged"
+	// DiffModeBranch compares HEAD with its merge-base against a base branch.
+	DiffModeBranch DiffMode = "branch"
+	// DiffModeCommit compares a commit with its first parent.
+	DiffModeCommit DiffMode = "commit"
+)
+
+// File statuses reported in DiffFile.Status.
+const (
+	DiffStatusAdded       = "added"
+	DiffStatusDeleted     = "deleted"
+	DiffStatusModified    = "modified"
+	DiffStatusRenamed     = "renamed"
+	DiffStatusCopied      = "copied"
+	DiffStatusModeChanged = "mode_changed"
+)
+
+// Line kinds reported in DiffLine.Kind.
+const (
+	DiffLineContext = "context"
+	DiffLineAdd     = "add"
+	DiffLineDelete  = "delete"
+)
+
+const (
+	defaultDiffMaxFiles      = 300
+	defaultDiffMaxFileLines  = 3000
+	defaultDiffMaxTotalLines = 30000
+	defaultDiffMaxPatchBytes = 32 << 20
+	devNull                  = "/dev/null"
+	emptyTreeHash            = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+)
+
+var (
+	// ErrInvalidDiffMode indicates an unsupported diff mode.
+	ErrInvalidDiffMode = errors.New("invalid diff mode")
+	// ErrBaseBranchNotFound indicates the base branch of a branch diff cannot be resolved.
+	ErrBaseBranchNotFound = errors.New("base branch not found")
+)
+
+// DiffOptions configures Diff and Patch.
+type DiffOptions struct {
+	Mode             DiffMode
+	Base             string   // DiffModeBranch 的基准分支
+	Commit           string   // DiffModeCommit 要查看的提交
+	Paths            []string // 仅比较这些路径
+	ContextLines     int      // 默认 3
+	IgnoreWhitespace bool
+	IncludeUntracked bool // DiffModeWorking 时把未跟踪文件作为新增文件列出
+	WordDiff         bool // 为成对的删除/新增行计算行内差异
+	Limits           DiffLimits
+}
+
+// DiffLimits bounds how much of a diff is returned. Zero values use the defaults.
+type DiffLimits struct {
+	MaxFiles      int   // 超出的文件不再列出
+	MaxFileLines  int   // 单个文件超出时只返回统计，不返回 hunk
+	MaxTotalLines int   // 所有文件累计超出后，后续文件只返回统计
+	MaxPatchBytes int64 // git 输出超出时截断
+}
+
+func (l DiffLimits) withDefaults() DiffLimits {
+	if l.MaxFiles <= 0 {
+		l.MaxFiles = defaultDiffMaxFiles
+	}
+	if l.MaxFileLines <= 0 {
+		l.MaxFileLines = defaultDiffMaxFileLines
+	}
+	if l.MaxTotalLines <= 0 {
+		l.MaxTotalLines = defaultDiffMaxTotalLines
+	}
+	if l.MaxPatchBytes <= 0 {
+		l.MaxPatchBytes = defaultDiffMaxPatchBytes
+	}
+	return l
+}
+
+// Diff is a parsed diff between two trees.
+type Diff struct {
+	Mode         DiffMode   `json:"mode"`
+	Base         string     `json:"base,omitempty"`
+	MergeBase    string     `json:"mergeBase,omitempty"`
+	Files        []DiffFile `json:"files"`
+	Additions    int        `json:"additions"`
+	Deletions    int        `json:"deletions"`
+	Truncated    bool       `json:"truncated"`              // 输出或文件数量超出限制
+	OmittedFiles int        `json:"omittedFiles,omitempty"` // 因数量限制未列出的文件数
+}
+
+// DiffFile describes the changes made to one file.
+type DiffFile struct {
+	Path       string     `json:"path"`
+	OldPath    string     `json:"oldPath,omitempty"`
+	Status     string     `json:"status"`
+	Similarity int        `json:"similarity,omitempty"`
+	OldMode    string     `json:"oldMode,omitempty"`
+	NewMode    string     `json:"newMode,omitempty"`
+	Binary     bool       `json:"binary"`
+	Untracked  bool       `json:"untracked,omitempty"`
+	Additions  int        `json:"additions"`
+	Deletions  int        `json:"deletions"`
+	Truncated  bool       `json:"truncated"` // 超出大小限制，未返回 hunk
+	Hunks      []DiffHunk `json:"hunks"`
+}
+
+// DiffHunk is one @@ section of a file diff.
+type DiffHunk struct {
+	Header   string     `json:"header"`
+	OldStart int        `json:"oldStart"`
+	OldLines int        `json:"oldLines"`
+	NewStart int        `json:"newStart"`
+	NewLines int        `json:"newLines"`
+	Section  string     `json:"section,omitempty"` // @@ 之后的函数上下文
+	Lines    []DiffLine `json:"lines"`
+}
+
+// DiffLine is one line of a hunk with its line numbers on each side.
+type DiffLine struct {
+	Kind      string        `json:"kind"`
+	Content   string        `json:"content"`
+	OldLine   int           `json:"oldLine,omitempty"`
+	NewLine   int           `json:"newLine,omitempty"`
+	NoNewline bool          `json:"noNewline,omitempty"` // 文件末尾没有换行
+	Segments  []DiffSegment `json:"segments,omitempty"`  // 行内差异，仅在 WordDiff 时返回
+}
+
+// Diff runs git diff in the worktree and parses the result.
+func (r *GitRepo) Diff(worktreePath string, opts DiffOptions) (*Diff, error) {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	limits := opts.Limits.withDefaults()
+	args, mergeBase, err := diffArgs(path, opts, false)
+	if err != nil {
+		return nil, err
+	}
+
+	output, truncated, err := runGitLimited(path, limits.MaxPatchBytes, args...)
+	if err != nil {
+		return nil, err
+	}
+	result := &Diff{Mode: opts.Mode, MergeBase: mergeBase, Files: []DiffFile{}}
+	if opts.Mode == DiffModeBranch {
+		result.Base = strings.TrimSpace(opts.Base)
+	}
+	files := ParseUnifiedDiff(output)
+	if truncated && len(files) > 0 {
+		// 最后一个文件可能只读到一半
+		files[len(files)-1].Hunks = nil
+		files[len(files)-1].Truncated = true
+	}
+
+	if opts.Mode == DiffModeWorking && opts.IncludeUntracked && !truncated {
+		untracked, err := r.untrackedDiffs(path, opts, limits.MaxFiles-len(files)+1)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, untracked...)
+	}
+
+	result.Truncated = truncated
+	applyDiffLimits(result, files, limits)
+	if opts.WordDiff {
+		for i := range result.Files {
+			for j := range result.Files[i].Hunks {
+				annotateWordDiff(&result.Files[i].Hunks[j])
+			}
+		}
+	}
+	return result, nil
+}
+
+// Patch returns the raw patch for the same selection as Diff, including binary
+// data so it can be applied with git apply.
+func (r *GitRepo) Patch(worktreePath string, opts DiffOptions) ([]byte, error) {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	args, _, err := diffArgs(path, opts, true)
+	if err != nil {
+		return nil, err
+	}
+	output, err := runGitOutput(path, args...)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Mode != DiffModeWorking || !opts.IncludeUntracked {
+		return output, nil
+	}
+
+	files, err := listUntracked(path, opts.Paths)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(output)
+	for _, file := range files {
+		patch, err := untrackedPatch(path, file, opts, true)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(patch)
+	}
+	return buf.Bytes(), nil
+}
+
+// MergeBase returns the best common ancestor of two revisions.
+func (r *GitRepo) MergeBase(worktreePath, a, b string) (string, error) {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return "", err
+	}
+	output, err := runGitOutput(path, "merge-base", a, b)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (r *GitRepo) resolveWorktreePath(worktreePath string) (string, error) {
+	if r == nil {
+		return "", errors.New("git repository is not initialized")
+	}
+	path := strings.TrimSpace(worktreePath)
+	if path == "" {
+		path = r.Path
+	}
+	return path, nil
+}
+
+// resolveBaseRef returns the first of base and origin/base that exists.
+func resolveBaseRef(path, base string) (string, error) {
+	base = strings.TrimSpace(base)
+	if base == "" {
+		return "", fmt.Errorf("%w: base branch is required", ErrBaseBranchNotFound)
+	}
+	for _, candidate := range []string{base, "origin/" + base} {
+		if _, err := runGitOutput(path, "rev-parse", "--verify", "--quiet", candidate+"^{commit}"); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrBaseBranchNotFound, base)
+}
+
+func diffArgs(path string, opts DiffOptions, patch bool) ([]string, string, error) {
+	args := []string{"-c", "core.quotepath=false", "diff", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/", "-M"}
+	if patch {
+		args = append(args, "--binary", "--no-textconv")
+	}
+	if opts.ContextLines > 0 {
+		args = append(args, fmt.Sprintf("-U%d", opts.ContextLines))
+	}
+	if opts.IgnoreWhitespace {
+		args = append(args, "-w")
+	}
+
+	var mergeBase string
+	switch opts.Mode {
+	case DiffModeWorking:
+	case DiffModeStaged:
+		args = append(args, "--cached")
+	case DiffModeBranch:
+		base, err := resolveBaseRef(path, opts.Base)
+		if err != nil {
+			return nil, "", err
+		}
+		output, err := runGitOutput(path, "merge-base", base, "HEAD")
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %s has no common history with HEAD", ErrBaseBranchNotFound, base)
+		}
+		mergeBase = strings.TrimSpace(string(output))
+		args = append(args, mergeBase, "HEAD")
+	case DiffModeCommit:
+		commit, err := resolveCommit(path, opts.Commit)
+		if err != nil {
+			return nil, "", err
+		}
+		// 根提交没有父提交，与空树比较
+		parent := emptyTreeHash
+		if output, err := runGitOutput(path, "rev-parse", "--verify", "--quiet", commit+"^1"); err == nil {
+			parent = strings.TrimSpace(string(output))
+		}
+		args = append(args, parent, commit)
+	default:
+		return nil, "", fmt.Errorf("%w: %q", ErrInvalidDiffMode, opts.Mode)
+	}
+	args = append(args, "--")
+	args = append(args, opts.Paths...)
+	return args, mergeBase, nil
+}
+
+func (r *GitRepo) untrackedDiffs(path string, opts DiffOptions, max int) ([]DiffFile, error) {
+	files, err := listUntracked(path, opts.Paths)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]DiffFile, 0, len(files))
+	for i, file := range files {
+		if i >= max {
+			// 超出数量限制的文件只需计数，不再逐个生成 diff
+			result = append(result, DiffFile{Path: file, Status: DiffStatusAdded, Untracked: true, Truncated: true})
+			continue
+		}
+		patch, err := untrackedPatch(path, file, opts, false)
+		if err != nil {
+			return nil, err
+		}
+		parsed := ParseUnifiedDiff(patch)
+		if len(parsed) == 0 {
+			// 空文件没有 hunk，git 也不输出任何内容
+			parsed = []DiffFile{{Path: file, Status: DiffStatusAdded, Hunks: []DiffHunk{}}}
+		}
+		for _, item := range parsed {
+			item.Untracked = true
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+func listUntracked(path string, paths []string) ([]string, error) {
+	args := append([]string{"ls-files", "--others", "--exclude-standard", "-z", "--"}, paths...)
+	output, err := runGitOutput(path, args...)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func untrackedPatch(path, file string, opts DiffOptions, patch bool) ([]byte, error) {
+	args := []string{"-c", "core.quotepath=false", "diff", "--no-index", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/"}
+	if patch {
+		args = append(args, "--binary", "--no-textconv")
+	}
+	if opts.ContextLines > 0 {
+		args = append(args, fmt.Sprintf("-U%d", opts.ContextLines))
+	}
+	args = append(args, "--", devNull, file)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	var exitErr *exec.ExitError
+	// --no-index 在存在差异时以 1 退出
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return nil, gitCommandError(args, err)
+	}
+	return output, nil
+}
+
+func applyDiffLimits(result *Diff, files []DiffFile, limits DiffLimits) {
+	totalLines := 0
+	for i := range files {
+		file := &files[i]
+		if i >= limits.MaxFiles {
+			result.Truncated = true
+			result.OmittedFiles = len(files) - limits.MaxFiles
+			break
+		}
+		lines := 0
+		for _, hunk := range file.Hunks {
+			lines += len(hunk.Lines)
+		}
+		if lines > limits.MaxFileLines || totalLines+lines > limits.MaxTotalLines {
+			file.Hunks = nil
+			file.Truncated = true
+		} else {
+			totalLines += lines
+		}
+		if file.Hunks == nil {
+			file.Hunks = []DiffHunk{}
+		}
+		result.Additions += file.Additions
+		result.Deletions += file.Deletions
+		result.Files = append(result.Files, *file)
+	}
+}
+
+// ParseUnifiedDiff parses `git diff` output into files, hunks and numbered lines.
+// Additions and deletions are counted for every file even when hunks are dropped later.
+func ParseUnifiedDiff(patch []byte) []DiffFile {
+	files := []DiffFile{}
+	var file *DiffFile
+	var hunk *DiffHunk
+	oldLine, newLine := 0, 0
+
+	flush := func() {
+		if file == nil {
+			return
+		}
+		if file.Status == "" {
+			file.Status = DiffStatusModified
+			if file.OldMode != "" && len(file.Hunks) == 0 && !file.Binary {
+				file.Status = DiffStatusModeChanged
+			}
+		}
+		if file.Hunks == nil {
+			file.Hunks = []DiffHunk{}
+		}
+		files = append(files, *file)
+		file, hunk = nil, nil
+	}
+
+	for _, raw := range bytes.Split(patch, []byte("\n")) {
+		line := strings.TrimSuffix(string(raw), "\r")
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+			oldPath, newPath := parseDiffGitLine(strings.TrimPrefix(line, "diff --git "))
+			file = &DiffFile{Path: newPath, OldPath: oldPath}
+			continue
+		}
+		if file == nil {
+			continue
+		}
+
+		if hunk != nil {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffLineAdd, Content: line[1:], NewLine: newLine})
+				newLine++
+				file.Additions++
+				continue
+			case strings.HasPrefix(line, "-"):
+				hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffLineDelete, Content: line[1:], OldLine: oldLine})
+				oldLine++
+				file.Deletions++
+				continue
+			case strings.HasPrefix(line, " "):
+				hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffLineContext, Content: line[1:], OldLine: oldLine, NewLine: newLine})
+				oldLine++
+				newLine++
+				continue
+			case strings.HasPrefix(line, `\`):
+				if n := len(hunk.Lines); n > 0 {
+					hunk.Lines[n-1].NoNewline = true
+				}
+				continue
+			case line == "":
+				// 部分工具会去掉空上下文行前的空格
+				continue
+			}
+		}
+
+		switch {
+		case strings.HasPrefix(line, "@@ "):
+			parsed, ok := parseHunkHeader(line)
+			if !ok {
+				continue
+			}
+			file.Hunks = append(file.Hunks, parsed)
+			hunk = &file.Hunks[len(file.Hunks)-1]
+			oldLine, newLine = hunk.OldStart, hunk.NewStart
+		case strings.HasPrefix(line, "new file mode "):
+			file.Status = DiffStatusAdded
+			file.NewMode = strings.TrimPrefix(line, "new file mode ")
+		case strings.HasPrefix(line, "deleted file mode "):
+			file.Status = DiffStatusDeleted
+			file.OldMode = strings.TrimPrefix(line, "deleted file mode ")
+		case strings.HasPrefix(line, "old mode "):
+			file.OldMode = strings.TrimPrefix(line, "old mode ")
+		case strings.HasPrefix(line, "new mode "):
+			file.NewMode = strings.TrimPrefix(line, "new mode ")
+		case strings.HasPrefix(line, "similarity index "):
+			file.Similarity, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(line, "similarity index "), "%"))
+		case strings.HasPrefix(line, "rename from "):
+			file.Status = DiffStatusRenamed
+			file.OldPath = unquoteGitPath(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "rename to "):
+			file.Path = unquoteGitPath(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "copy from "):
+			file.Status = DiffStatusCopied
+			file.OldPath = unquoteGitPath(strings.TrimPrefix(line, "copy from "))
+		case strings.HasPrefix(line, "copy to "):
+			file.Path = unquoteGitPath(strings.TrimPrefix(line, "copy to "))
+		case strings.HasPrefix(line, "--- "):
+			if name := strings.TrimPrefix(line, "--- "); name != devNull {
+				file.OldPath = trimDiffPrefix(unquoteGitPath(name), "a/")
+			}
+		case strings.HasPrefix(line, "+++ "):
+			if name := strings.TrimPrefix(line, "+++ "); name != devNull {
+				file.Path = trimDiffPrefix(unquoteGitPath(name), "b/")
+			}
+		case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+			file.Binary = true
+		}
+	}
+	flush()
+
+	for i := range files {
+		switch files[i].Status {
+		case DiffStatusAdded:
+			files[i].OldPath = ""
+		case DiffStatusModified, DiffStatusModeChanged:
+			if files[i].OldPath == files[i].Path {
+				files[i].OldPath = ""
+			}
+		}
+		if files[i].Status == DiffStatusDeleted && files[i].Path == "" {
+			files[i].Path = files[i].OldPath
+		}
+	}
+	return files
+}
+
+// parseDiffGitLine splits the "a/old b/new" part of a diff --git header.
+func parseDiffGitLine(rest string) (string, string) {
+	if strings.HasPrefix(rest, `"`) {
+		if end := closingQuote(rest); end > 0 {
+			oldPath := unquoteGitPath(rest[:end+1])
+			newPath := unquoteGitPath(strings.TrimSpace(rest[end+1:]))
+			return trimDiffPrefix(oldPath, "a/"), trimDiffPrefix(newPath, "b/")
+		}
+	}
+	// 两侧路径相同时长度为 2*len(path)+5，可以准确地从中间切分
+	if (len(rest)-1)%2 == 0 {
+		half := (len(rest) - 1) / 2
+		oldPath, newPath := rest[:half], rest[half+1:]
+		if strings.HasPrefix(oldPath, "a/") && strings.HasPrefix(newPath, "b/") && oldPath[2:] == newPath[2:] {
+			return oldPath[2:], newPath[2:]
+		}
+	}
+	if idx := strings.Index(rest, " b/"); idx >= 0 {
+		return trimDiffPrefix(rest[:idx], "a/"), unquoteGitPath(rest[idx+3:])
+	}
+	return rest, rest
+}
+
+func closingQuote(value string) int {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func unquoteGitPath(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+	}
+	return value
+}
+
+func trimDiffPrefix(value, prefix string) string {
+	return strings.TrimPrefix(value, prefix)
+}
+
+func parseHunkHeader(line string) (DiffHunk, bool) {
+	end := strings.Index(line[3:], " @@")
+	if end < 0 {
+		return DiffHunk{}, false
+	}
+	ranges := strings.Fields(line[3 : 3+end])
+	if len(ranges) != 2 {
+		return DiffHunk{}, false
+	}
+	hunk := DiffHunk{Header: line, Lines: []DiffLine{}}
+	var ok1, ok2 bool
+	hunk.OldStart, hunk.OldLines, ok1 = parseHunkRange(strings.TrimPrefix(ranges[0], "-"))
+	hunk.NewStart, hunk.NewLines, ok2 = parseHunkRange(strings.TrimPrefix(ranges[1], "+"))
+	hunk.Section = strings.TrimSpace(line[3+end+3:])
+	return hunk, ok1 && ok2
+}
+
+func parseHunkRange(value string) (start, count int, ok bool) {
+	count = 1
+	if idx := strings.IndexByte(value, ','); idx >= 0 {
+		var err error
+		if count, err = strconv.Atoi(value[idx+1:]); err != nil {
+			return 0, 0, false
+		}
+		value = value[:idx]
+	}
+	start, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, count, true
+}
+
+func runGitOutput(path string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, gitCommandError(args, err)
+	}
+	return output, nil
+}
+
+// runGitLimited reads at most limit bytes of stdout and reports whether the output was cut.
+func runGitLimited(path string, limit int64, args ...string) ([]byte, bool, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = path
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, false, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, false, err
+	}
+	output, readErr := io.ReadAll(io.LimitReader(stdout, limit+1))
+	truncated := int64(len(output)) > limit
+	if truncated {
+		output = output[:limit]
+		_ = cmd.Process.Kill()
+	}
+	waitErr := cmd.Wait()
+	if readErr != nil {
+		return nil, false, readErr
+	}
+	if waitErr != nil && !truncated {
+		return nil, false, fmt.Errorf("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return output, truncated, nil
+}
+
+func gitCommandError(args []string, err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return fmt.Errorf("git %s failed: %w", strings.Join(args, " "), err)
+}
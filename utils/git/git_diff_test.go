@@ -0,0 +1,174 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseUnifiedDiff(t *testing.T) {
+	patch := `diff --git a/main.go b/main.go
+index 83db48f..bf269f4 100644
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,4 @@ package main
+ package main
+-func old() {}
++func renamed() {}
+ 
+ // end
+\ No newline at end of file
+diff --git a/docs/old name.md b/docs/new name.md
+similarity index 90%
+rename from docs/old name.md
+rename to docs/new name.md
+index 1111111..2222222 100644
+--- a/docs/old name.md
++++ b/docs/new name.md
+@@ -3,0 +4,2 @@
++added one
++added two
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..3333333
+Binary files /dev/null and b/logo.png differ
+diff --git a/run.sh b/run.sh
+old mode 100644
+new mode 100755
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+index 4444444..0000000
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`
+	files := ParseUnifiedDiff([]byte(patch))
+	if len(files) != 5 {
+		t.Fatalf("expected 5 files, got %d: %+v", len(files), files)
+	}
+
+	main := files[0]
+	if main.Path != "main.go" || main.Status != DiffStatusModified || main.Additions != 1 || main.Deletions != 1 {
+		t.Fatalf("unexpected main.go: %+v", main)
+	}
+	hunk := main.Hunks[0]
+	if hunk.Section != "package main" || len(hunk.Lines) != 5 {
+		t.Fatalf("unexpected hunk: %+v", hunk)
+	}
+	if line := hunk.Lines[1]; line.Kind != DiffLineDelete || line.OldLine != 2 || line.NewLine != 0 {
+		t.Fatalf("unexpected deleted line: %+v", line)
+	}
+	if line := hunk.Lines[2]; line.Kind != DiffLineAdd || line.NewLine != 2 {
+		t.Fatalf("unexpected added line: %+v", line)
+	}
+	if line := hunk.Lines[4]; line.OldLine != 4 || line.NewLine != 4 || !line.NoNewline {
+		t.Fatalf("unexpected last line: %+v", line)
+	}
+
+	renamed := files[1]
+	if renamed.Status != DiffStatusRenamed || renamed.OldPath != "docs/old name.md" || renamed.Path != "docs/new name.md" || renamed.Similarity != 90 {
+		t.Fatalf("unexpected rename: %+v", renamed)
+	}
+	if renamed.Hunks[0].NewStart != 4 || renamed.Hunks[0].OldLines != 0 || renamed.Hunks[0].Lines[1].NewLine != 5 {
+		t.Fatalf("unexpected rename hunk: %+v", renamed.Hunks[0])
+	}
+
+	if logo := files[2]; !logo.Binary || logo.Status != DiffStatusAdded || logo.Path != "logo.png" {
+		t.Fatalf("unexpected binary file: %+v", logo)
+	}
+	if script := files[3]; script.Status != DiffStatusModeChanged || script.NewMode != "100755" {
+		t.Fatalf("unexpected mode change: %+v", script)
+	}
+	if gone := files[4]; gone.Status != DiffStatusDeleted || gone.Path != "gone.txt" || gone.Deletions != 1 {
+		t.Fatalf("unexpected deletion: %+v", gone)
+	}
+}
+
+func TestWordDiff(t *testing.T) {
+	oldSegs, newSegs := wordDiff("return a + b", "return a - b")
+	if len(oldSegs) != 3 || oldSegs[1].Text != "+" || !oldSegs[1].Changed {
+		t.Fatalf("unexpected old segments: %+v", oldSegs)
+	}
+	if len(newSegs) != 3 || newSegs[1].Text != "-" || newSegs[0].Text != "return a " {
+		t.Fatalf("unexpected new segments: %+v", newSegs)
+	}
+}
+
+func TestGitRepoDiffModes(t *testing.T) {
+	repoDir := initTestRepo(t)
+	repo, err := DetectRepository(repoDir)
+	if err != nil {
+		t.Fatalf("DetectRepository returned error: %v", err)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	runGit(t, repoDir, "checkout", "-b", "feature/diff")
+	write("feature.txt", "one\ntwo\n")
+	runGit(t, repoDir, "add", "feature.txt")
+	runGit(t, repoDir, "commit", "-m", "add feature")
+	runGit(t, repoDir, "mv", "README.md", "GUIDE.md")
+	write("feature.txt", "one\nTWO\n")
+	write("notes.txt", "untracked\n")
+
+	branch, err := repo.Diff(repoDir, DiffOptions{Mode: DiffModeBranch, Base: "main"})
+	if err != nil {
+		t.Fatalf("branch diff returned error: %v", err)
+	}
+	if branch.MergeBase == "" || len(branch.Files) != 1 || branch.Files[0].Path != "feature.txt" || branch.Files[0].Status != DiffStatusAdded {
+		t.Fatalf("unexpected branch diff: %+v", branch)
+	}
+
+	staged, err := repo.Diff(repoDir, DiffOptions{Mode: DiffModeStaged})
+	if err != nil {
+		t.Fatalf("staged diff returned error: %v", err)
+	}
+	if len(staged.Files) != 1 || staged.Files[0].Status != DiffStatusRenamed || staged.Files[0].OldPath != "README.md" {
+		t.Fatalf("unexpected staged diff: %+v", staged)
+	}
+
+	working, err := repo.Diff(repoDir, DiffOptions{Mode: DiffModeWorking, IncludeUntracked: true, WordDiff: true})
+	if err != nil {
+		t.Fatalf("working diff returned error: %v", err)
+	}
+	if len(working.Files) != 2 || working.Additions != 2 || working.Deletions != 1 {
+		t.Fatalf("unexpected working diff: %+v", working)
+	}
+	if notes := working.Files[1]; notes.Path != "notes.txt" || !notes.Untracked || notes.Status != DiffStatusAdded {
+		t.Fatalf("unexpected untracked file: %+v", notes)
+	}
+	if line := working.Files[0].Hunks[0].Lines[2]; line.Kind != DiffLineAdd || len(line.Segments) == 0 {
+		t.Fatalf("expected word segments on the changed line: %+v", line)
+	}
+
+	limited, err := repo.Diff(repoDir, DiffOptions{Mode: DiffModeWorking, IncludeUntracked: true, Limits: DiffLimits{MaxFiles: 1}})
+	if err != nil {
+		t.Fatalf("limited diff returned error: %v", err)
+	}
+	if !limited.Truncated || len(limited.Files) != 1 || limited.OmittedFiles != 1 {
+		t.Fatalf("expected file limit to apply: %+v", limited)
+	}
+
+	patch, err := repo.Patch(repoDir, DiffOptions{Mode: DiffModeWorking, IncludeUntracked: true})
+	if err != nil {
+		t.Fatalf("Patch returned error: %v", err)
+	}
+	if !bytes.Contains(patch, []byte("+TWO")) || !strings.Contains(string(patch), "b/notes.txt") {
+		t.Fatalf("unexpected patch:\n%s", patch)
+	}
+
+	if _, err := repo.Diff(repoDir, DiffOptions{Mode: DiffModeBranch, Base: "missing"}); !errors.Is(err, ErrBaseBranchNotFound) {
+		t.Fatalf("expected ErrBaseBranchNotFound, got %v", err)
+	}
+	if _, err := repo.Diff(repoDir, DiffOptions{Mode: "bogus"}); !errors.Is(err, ErrInvalidDiffMode) {
+		t.Fatalf("expected ErrInvalidDiffMode, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCommitNotFound indicates a revision that does not resolve to a commit.
+var ErrCommitNotFound = errors.New("commit not found")
+
+// Commit is one entry of a commit log with its change statistics.
+type Commit struct {
+	SHA          string    `json:"sha"`
+	ShortSHA     string    `json:"shortSha"`
+	Parents      []string  `json:"parents"`
+	AuthorName   string    `json:"authorName"`
+	AuthorEmail  string    `json:"authorEmail"`
+	AuthoredAt   time.Time `json:"authoredAt"`
+	Committer    string    `json:"committer"`
+	CommittedAt  time.Time `json:"committedAt"`
+	Subject      string    `json:"subject"`
+	Body         string    `json:"body,omitempty"`
+	FilesChanged int       `json:"filesChanged"`
+	Additions    int       `json:"additions"`
+	Deletions    int       `json:"deletions"`
+}
+
+// LogOptions selects the commits returned by Log and CountCommits.
+type LogOptions struct {
+	Ref    string   // 起点，默认 HEAD
+	Base   string   // 排除基准可达的提交，只保留领先于基准的提交；找不到时尝试 origin/<base>
+	Paths  []string // 仅包含修改了这些路径的提交
+	Author string   // 作者名或邮箱的模式
+	Skip   int
+	Limit  int
+}
+
+// 每条记录以 \x1e 开头，字段之间用 \x00 分隔，之后跟随 --numstat 输出
+const logFormat = "--format=%x1e%H%x00%P%x00%an%x00%ae%x00%aI%x00%cn%x00%cI%x00%s%x00%b%x00"
+
+const logFieldCount = 10
+
+// Log lists commits newest first.
+func (r *GitRepo) Log(worktreePath string, opts LogOptions) ([]Commit, error) {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	revs, empty, err := logRevisions(path, opts)
+	if err != nil || empty {
+		return []Commit{}, err
+	}
+
+	args := []string{"-c", "core.quotepath=false", "log", logFormat, "--numstat", "--no-color"}
+	if opts.Skip > 0 {
+		args = append(args, "--skip="+strconv.Itoa(opts.Skip))
+	}
+	if opts.Limit > 0 {
+		args = append(args, "--max-count="+strconv.Itoa(opts.Limit))
+	}
+	args = append(args, logFilterArgs(opts)...)
+	args = append(args, revs...)
+	args = append(args, "--")
+	args = append(args, opts.Paths...)
+
+	output, err := runGitOutput(path, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(string(output)), nil
+}
+
+// CountCommits returns how many commits Log would list without Skip and Limit.
+func (r *GitRepo) CountCommits(worktreePath string, opts LogOptions) (int, error) {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return 0, err
+	}
+	revs, empty, err := logRevisions(path, opts)
+	if err != nil || empty {
+		return 0, err
+	}
+	args := append([]string{"rev-list", "--count"}, logFilterArgs(opts)...)
+	args = append(append(args, revs...), "--")
+	output, err := runGitOutput(path, append(args, opts.Paths...)...)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// GetCommit returns a single commit with its statistics against the first parent.
+func (r *GitRepo) GetCommit(worktreePath, rev string) (*Commit, error) {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	sha, err := resolveCommit(path, rev)
+	if err != nil {
+		return nil, err
+	}
+	output, err := runGitOutput(path, "-c", "core.quotepath=false", "show", logFormat, "--numstat", "--first-parent", "--no-color", sha, "--")
+	if err != nil {
+		return nil, err
+	}
+	commits := parseLog(string(output))
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrCommitNotFound, rev)
+	}
+	return &commits[0], nil
+}
+
+// ResolveCommit returns the full hash of the commit rev points to.
+func (r *GitRepo) ResolveCommit(worktreePath, rev string) (string, error) {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return "", err
+	}
+	return resolveCommit(path, rev)
+}
+
+// resolveCommit turns a revision into a full commit hash. Revisions that look like
+// options are rejected before reaching git.
+func resolveCommit(path, rev string) (string, error) {
+	rev = strings.TrimSpace(rev)
+	if rev == "" || strings.HasPrefix(rev, "-") {
+		return "", fmt.Errorf("%w: %q", ErrCommitNotFound, rev)
+	}
+	output, err := runGitOutput(path, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrCommitNotFound, rev)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// logRevisions resolves the start and the excluded base. empty reports an unborn HEAD,
+// which has no history yet.
+func logRevisions(path string, opts LogOptions) ([]string, bool, error) {
+	ref := strings.TrimSpace(opts.Ref)
+	if ref == "" {
+		if !hasHead(path) {
+			return nil, true, nil
+		}
+		ref = "HEAD"
+	}
+	start, err := resolveCommit(path, ref)
+	if err != nil {
+		return nil, false, err
+	}
+	revs := []string{start}
+	if strings.TrimSpace(opts.Base) != "" {
+		base, err := resolveBaseRef(path, opts.Base)
+		if err != nil {
+			return nil, false, err
+		}
+		baseSHA, err := resolveCommit(path, base)
+		if err != nil {
+			return nil, false, err
+		}
+		revs = append(revs, "^"+baseSHA)
+	}
+	return revs, false, nil
+}
+
+func logFilterArgs(opts LogOptions) []string {
+	if author := strings.TrimSpace(opts.Author); author != "" {
+		return []string{"--regexp-ignore-case", "--author=" + author}
+	}
+	return nil
+}
+
+func parseLog(output string) []Commit {
+	commits := []Commit{}
+	for _, record := range strings.Split(output, "\x1e") {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+		fields := strings.SplitN(record, "\x00", logFieldCount)
+		if len(fields) < logFieldCount {
+			continue
+		}
+		commit := Commit{
+			SHA:         fields[0],
+			ShortSHA:    shortCommit(fields[0]),
+			Parents:     strings.Fields(fields[1]),
+			AuthorName:  fields[2],
+			AuthorEmail: fields[3],
+			Committer:   fields[5],
+			Subject:     fields[7],
+			Body:        strings.TrimSpace(fields[8]),
+		}
+		commit.AuthoredAt, _ = time.Parse(time.RFC3339, fields[4])
+		commit.CommittedAt, _ = time.Parse(time.RFC3339, fields[6])
+
+		for _, line := range strings.Split(fields[9], "\n") {
+			parts := strings.SplitN(line, "\t", 3)
+			if len(parts) < 3 {
+				continue
+			}
+			commit.FilesChanged++
+			// 二进制文件的增删行数为 "-"
+			if added, err := strconv.Atoi(parts[0]); err == nil {
+				commit.Additions += added
+			}
+			if deleted, err := strconv.Atoi(parts[1]); err == nil {
+				commit.Deletions += deleted
+			}
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
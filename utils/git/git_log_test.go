@@ -0,0 +1,84 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitRepoLog(t *testing.T) {
+	repoDir := initTestRepo(t)
+	repo, err := DetectRepository(repoDir)
+	if err != nil {
+		t.Fatalf("DetectRepository failed: %v", err)
+	}
+
+	runGit(t, repoDir, "checkout", "-q", "-b", "feature/log")
+	for i, name := range []string{"one.txt", "two.txt", "three.txt"} {
+		content := strings.Repeat("line\n", i+1)
+		if err := os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		runGit(t, repoDir, "add", name)
+		runGit(t, repoDir, "commit", "-q", "-m", "add "+name, "-m", "details for "+name)
+	}
+
+	all, err := repo.Log(repoDir, LogOptions{})
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	if len(all) != 4 || all[0].Subject != "add three.txt" || all[3].Subject != "initial commit" {
+		t.Fatalf("unexpected log: %+v", all)
+	}
+	head := all[0]
+	if head.Body != "details for three.txt" || head.FilesChanged != 1 || head.Additions != 3 || head.AuthorEmail != "test@example.com" || len(head.Parents) != 1 || head.AuthoredAt.IsZero() {
+		t.Fatalf("unexpected head commit: %+v", head)
+	}
+
+	page, err := repo.Log(repoDir, LogOptions{Base: "main", Skip: 1, Limit: 1})
+	if err != nil {
+		t.Fatalf("Log with base failed: %v", err)
+	}
+	if len(page) != 1 || page[0].Subject != "add two.txt" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	ahead, err := repo.CountCommits(repoDir, LogOptions{Base: "main"})
+	if err != nil || ahead != 3 {
+		t.Fatalf("expected 3 commits ahead of main, got %d (%v)", ahead, err)
+	}
+	filtered, err := repo.CountCommits(repoDir, LogOptions{Ref: "feature/log", Paths: []string{"two.txt"}})
+	if err != nil || filtered != 1 {
+		t.Fatalf("expected 1 commit touching two.txt, got %d (%v)", filtered, err)
+	}
+
+	commit, err := repo.GetCommit(repoDir, head.ShortSHA)
+	if err != nil {
+		t.Fatalf("GetCommit failed: %v", err)
+	}
+	if commit.SHA != head.SHA || commit.Additions != 3 {
+		t.Fatalf("unexpected commit: %+v", commit)
+	}
+	diff, err := repo.Diff(repoDir, DiffOptions{Mode: DiffModeCommit, Commit: head.SHA})
+	if err != nil {
+		t.Fatalf("commit diff failed: %v", err)
+	}
+	if len(diff.Files) != 1 || diff.Files[0].Path != "three.txt" || diff.Additions != 3 {
+		t.Fatalf("unexpected commit diff: %+v", diff)
+	}
+	root, err := repo.Diff(repoDir, DiffOptions{Mode: DiffModeCommit, Commit: all[3].SHA})
+	if err != nil {
+		t.Fatalf("root commit diff failed: %v", err)
+	}
+	if len(root.Files) != 1 || root.Files[0].Status != DiffStatusAdded {
+		t.Fatalf("unexpected root commit diff: %+v", root)
+	}
+
+	if _, err := repo.GetCommit(repoDir, "--all"); !errors.Is(err, ErrCommitNotFound) {
+		t.Fatalf("expected ErrCommitNotFound, got %v", err)
+	}
+	if _, err := repo.Log(repoDir, LogOptions{Base: "missing"}); !errors.Is(err, ErrBaseBranchNotFound) {
+		t.Fatalf("expected ErrBaseBranchNotFound, got %v", err)
+	}
+}
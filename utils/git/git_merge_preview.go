@@ -0,0 +1,184 @@
+package git
+
+import (
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ErrMergePreviewUnsupported indicates the installed git lacks merge-tree --write-tree (git >= 2.38).
+var ErrMergePreviewUnsupported = errors.New("merge preview requires git 2.38 or newer")
+
+// MergeConflict is a path that would not merge cleanly.
+type MergeConflict struct {
+	Path     string   `json:"path"`
+	Types    []string `json:"types"`              // 例如 contents、modify/delete、rename/delete
+	Messages []string `json:"messages,omitempty"` // git 给出的冲突说明
+}
+
+// DiffStatFile is the line count summary of one changed file.
+type DiffStatFile struct {
+	Path      string `json:"path"`
+	OldPath   string `json:"oldPath,omitempty"`
+	Binary    bool   `json:"binary,omitempty"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// MergePreview is the outcome of merging source into target computed in memory.
+type MergePreview struct {
+	Target       string          `json:"target"`
+	Source       string          `json:"source"`
+	TargetCommit string          `json:"targetCommit"`
+	SourceCommit string          `json:"sourceCommit"`
+	MergeBase    string          `json:"mergeBase,omitempty"`
+	Tree         string          `json:"tree"` // 合并结果的 tree，有冲突时其中的文件带冲突标记
+	Clean        bool            `json:"clean"`
+	UpToDate     bool            `json:"upToDate"`    // source 已经包含在 target 中
+	FastForward  bool            `json:"fastForward"` // target 可以直接快进到 source
+	Conflicts    []MergeConflict `json:"conflicts"`
+	Files        []DiffStatFile  `json:"files"` // 合并会给 target 带来的改动
+	Additions    int             `json:"additions"`
+	Deletions    int             `json:"deletions"`
+}
+
+// PreviewMerge computes what merging source into target would produce with
+// git merge-tree. Neither the index nor any working tree is touched; the result
+// tree is written to the object database only.
+func (r *GitRepo) PreviewMerge(worktreePath, target, source string) (*MergePreview, error) {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	preview := &MergePreview{Target: target, Source: source, Conflicts: []MergeConflict{}, Files: []DiffStatFile{}}
+	if preview.TargetCommit, err = resolveCommit(path, target); err != nil {
+		return nil, err
+	}
+	if preview.SourceCommit, err = resolveCommit(path, source); err != nil {
+		return nil, err
+	}
+	// 没有共同祖先时 merge-base 失败，交给 merge-tree 报告
+	if output, err := runGitOutput(path, "merge-base", preview.TargetCommit, preview.SourceCommit); err == nil {
+		preview.MergeBase = strings.TrimSpace(string(output))
+	}
+	preview.UpToDate = preview.MergeBase == preview.SourceCommit
+	preview.FastForward = !preview.UpToDate && preview.MergeBase == preview.TargetCommit
+
+	args := []string{"-c", "core.quotepath=false", "merge-tree", "--write-tree", "--name-only", "-z", preview.TargetCommit, preview.SourceCommit}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		preview.Clean = true
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		// 退出码 1 表示存在冲突，输出仍然完整
+	case errors.As(err, &exitErr) && strings.Contains(string(exitErr.Stderr), "write-tree"):
+		return nil, ErrMergePreviewUnsupported
+	default:
+		return nil, gitCommandError(args, err)
+	}
+	preview.Tree, preview.Conflicts = parseMergeTree(string(output))
+
+	files, additions, deletions, err := diffStat(path, preview.TargetCommit, preview.Tree)
+	if err != nil {
+		return nil, err
+	}
+	preview.Files, preview.Additions, preview.Deletions = files, additions, deletions
+	return preview, nil
+}
+
+// ConflictPaths returns only the paths of the conflicts.
+func (p *MergePreview) ConflictPaths() []string {
+	paths := make([]string, 0, len(p.Conflicts))
+	for _, conflict := range p.Conflicts {
+		paths = append(paths, conflict.Path)
+	}
+	return paths
+}
+
+// parseMergeTree reads the -z --name-only output of merge-tree: the tree id, the
+// conflicted paths, an empty field, then messages as
+// <path count> <paths...> <type> <message>.
+func parseMergeTree(output string) (string, []MergeConflict) {
+	fields := strings.Split(output, "\x00")
+	tree := strings.TrimSpace(fields[0])
+	conflicts := []MergeConflict{}
+	index := map[string]int{}
+
+	i := 1
+	for ; i < len(fields) && fields[i] != ""; i++ {
+		if _, ok := index[fields[i]]; !ok {
+			index[fields[i]] = len(conflicts)
+			conflicts = append(conflicts, MergeConflict{Path: fields[i], Types: []string{}})
+		}
+	}
+	for i++; i < len(fields); {
+		count, err := strconv.Atoi(fields[i])
+		if err != nil || i+count+2 >= len(fields) {
+			break
+		}
+		paths := fields[i+1 : i+1+count]
+		kind := fields[i+1+count]
+		message := strings.TrimSpace(fields[i+2+count])
+		i += count + 3
+
+		if !strings.HasPrefix(kind, "CONFLICT") {
+			continue
+		}
+		kind = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(kind, "CONFLICT"), " ("), ")")
+		for _, p := range paths {
+			idx, ok := index[p]
+			if !ok {
+				continue
+			}
+			conflicts[idx].Types = appendUnique(conflicts[idx].Types, kind)
+			conflicts[idx].Messages = appendUnique(conflicts[idx].Messages, message)
+		}
+	}
+	return tree, conflicts
+}
+
+// diffStat summarises the changes between two tree-ish objects.
+func diffStat(path, from, to string) ([]DiffStatFile, int, int, error) {
+	output, err := runGitOutput(path, "-c", "core.quotepath=false", "diff", "--numstat", "-z", "-M", "--no-color", from, to, "--")
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	files := []DiffStatFile{}
+	additions, deletions := 0, 0
+	fields := strings.Split(string(output), "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		file := DiffStatFile{Path: parts[2]}
+		// 重命名时路径字段为空，旧路径和新路径分别跟在后面
+		if file.Path == "" && i+2 < len(fields) {
+			file.OldPath, file.Path = fields[i+1], fields[i+2]
+			i += 2
+		}
+		if parts[0] == "-" {
+			file.Binary = true
+		} else {
+			file.Additions, _ = strconv.Atoi(parts[0])
+			file.Deletions, _ = strconv.Atoi(parts[1])
+		}
+		additions += file.Additions
+		deletions += file.Deletions
+		files = append(files, file)
+	}
+	return files, additions, deletions, nil
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,87 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitRepoPreviewMerge(t *testing.T) {
+	repoDir := initTestRepo(t)
+	repo, err := DetectRepository(repoDir)
+	if err != nil {
+		t.Fatalf("DetectRepository failed: %v", err)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	write("app.txt", "one\ntwo\nthree\n")
+	write("notes.txt", "notes\n")
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-q", "-m", "base")
+
+	runGit(t, repoDir, "checkout", "-q", "-b", "clean")
+	write("extra.txt", "extra\nlines\n")
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-q", "-m", "add extra")
+
+	runGit(t, repoDir, "checkout", "-q", "-b", "conflict", "main")
+	write("app.txt", "one\nTWO\nthree\n")
+	runGit(t, repoDir, "rm", "-q", "notes.txt")
+	runGit(t, repoDir, "commit", "-q", "-am", "conflicting change")
+
+	runGit(t, repoDir, "checkout", "-q", "main")
+	write("app.txt", "one\n2\nthree\n")
+	write("notes.txt", "more notes\n")
+	runGit(t, repoDir, "commit", "-q", "-am", "main change")
+	head, _ := runGitOutput(repoDir, "rev-parse", "HEAD")
+
+	preview, err := repo.PreviewMerge(repoDir, "main", "clean")
+	if err != nil {
+		t.Fatalf("PreviewMerge(clean) failed: %v", err)
+	}
+	if !preview.Clean || preview.FastForward || preview.UpToDate || len(preview.Conflicts) != 0 {
+		t.Fatalf("unexpected clean preview: %+v", preview)
+	}
+	if len(preview.Files) != 1 || preview.Files[0].Path != "extra.txt" || preview.Additions != 2 {
+		t.Fatalf("unexpected diffstat: %+v", preview.Files)
+	}
+
+	preview, err = repo.PreviewMerge(repoDir, "main", "conflict")
+	if err != nil {
+		t.Fatalf("PreviewMerge(conflict) failed: %v", err)
+	}
+	if preview.Clean || len(preview.Conflicts) != 2 {
+		t.Fatalf("expected two conflicts, got %+v", preview.Conflicts)
+	}
+	kinds := map[string]string{}
+	for _, conflict := range preview.Conflicts {
+		kinds[conflict.Path] = strings.Join(conflict.Types, ",")
+	}
+	if kinds["app.txt"] != "contents" || kinds["notes.txt"] != "modify/delete" {
+		t.Fatalf("unexpected conflict types: %v", kinds)
+	}
+
+	if preview, err = repo.PreviewMerge(repoDir, "clean", "main~1"); err != nil || !preview.UpToDate {
+		t.Fatalf("expected up-to-date preview, got %+v (%v)", preview, err)
+	}
+	if preview, err = repo.PreviewMerge(repoDir, "main~1", "clean"); err != nil || !preview.FastForward {
+		t.Fatalf("expected fast-forward preview, got %+v (%v)", preview, err)
+	}
+	if _, err := repo.PreviewMerge(repoDir, "main", "missing"); !errors.Is(err, ErrCommitNotFound) {
+		t.Fatalf("expected ErrCommitNotFound, got %v", err)
+	}
+
+	// 预览不能改动工作区或 HEAD
+	after, _ := runGitOutput(repoDir, "rev-parse", "HEAD")
+	status, _ := runGitOutput(repoDir, "status", "--porcelain")
+	if string(after) != string(head) || len(strings.TrimSpace(string(status))) != 0 {
+		t.Fatalf("preview touched the worktree: head %s -> %s, status %q", head, after, status)
+	}
+}
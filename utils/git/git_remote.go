@@ -0,0 +1,439 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// remoteCommandTimeout bounds a single network git command, so a stalled connection
+// or an ssh prompt nobody can answer does not block forever.
+const remoteCommandTimeout = 10 * time.Minute
+
+var (
+	// ErrNoRemote indicates the repository has no remote to talk to.
+	ErrNoRemote = errors.New("no remote configured")
+	// ErrNoUpstream indicates the branch does not track a remote branch.
+	ErrNoUpstream = errors.New("branch has no upstream")
+	// ErrDetachedHead indicates the operation needs a checked out branch.
+	ErrDetachedHead = errors.New("HEAD is detached")
+	// ErrNotFastForward indicates the histories diverged and a fast-forward is impossible.
+	ErrNotFastForward = errors.New("not a fast-forward")
+	// ErrStaleLease indicates the remote branch moved since it was last fetched.
+	ErrStaleLease = errors.New("remote branch changed since last fetch")
+	// ErrPullConflicts indicates a rebasing pull stopped on conflicts.
+	ErrPullConflicts = errors.New("pull stopped on conflicts")
+	// ErrRemoteBranchNotFound indicates a name that is not a fetched remote-tracking branch.
+	ErrRemoteBranchNotFound = errors.New("remote branch not found")
+	// ErrPullRequestNotFound indicates the remote has no refs/pull/<n>/head.
+	ErrPullRequestNotFound = errors.New("pull request not found")
+)
+
+// RemoteProgress is one progress line reported by git while talking to a remote.
+type RemoteProgress struct {
+	Phase   string `json:"phase,omitempty"` // 例如 Receiving objects、Writing objects
+	Percent int    `json:"percent"`         // -1 表示该行没有百分比
+	Line    string `json:"line"`
+}
+
+// ProgressFunc receives progress lines as they are produced. It may be nil.
+type ProgressFunc func(RemoteProgress)
+
+// FetchOptions controls Fetch. An empty Remote fetches every remote.
+type FetchOptions struct {
+	Remote string
+	Prune  bool
+}
+
+// PullOptions controls Pull. Without Rebase the pull is fast-forward only.
+// Remote and Branch default to the upstream of the current branch.
+type PullOptions struct {
+	Remote string
+	Branch string
+	Rebase bool
+}
+
+// PushOptions controls Push. Remote defaults to the upstream remote, then origin;
+// Branch defaults to the upstream branch name, then the local branch name.
+type PushOptions struct {
+	Remote         string
+	Branch         string
+	SetUpstream    bool
+	ForceWithLease bool
+}
+
+// Upstream is the remote branch a local branch tracks.
+type Upstream struct {
+	Remote string `json:"remote"`
+	Branch string `json:"branch"`
+}
+
+var progressPattern = regexp.MustCompile(`^(?:remote:\s*)?([A-Za-z][A-Za-z ]*?):\s+(\d+)%`)
+
+// Fetch downloads objects and refs, optionally pruning deleted remote branches.
+func (r *GitRepo) Fetch(ctx context.Context, worktreePath string, opts FetchOptions, progress ProgressFunc) error {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return err
+	}
+	args := []string{"fetch", "--progress"}
+	if opts.Prune {
+		args = append(args, "--prune")
+	}
+	if remote := strings.TrimSpace(opts.Remote); remote != "" {
+		if strings.HasPrefix(remote, "-") {
+			return fmt.Errorf("%w: %s", ErrNoRemote, remote)
+		}
+		args = append(args, remote)
+	} else {
+		remotes, err := runGitOutput(path, "remote")
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(string(remotes)) == "" {
+			return ErrNoRemote
+		}
+		args = append(args, "--all")
+	}
+	_, err = runRemoteCommand(ctx, path, progress, args...)
+	return err
+}
+
+// Pull integrates the remote branch into the current branch, by fast-forward or by
+// rebasing local commits. A rebase that conflicts is left in progress and reported
+// as ErrPullConflicts so it can be finished through the conflict workflow.
+func (r *GitRepo) Pull(ctx context.Context, worktreePath string, opts PullOptions, progress ProgressFunc) error {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return err
+	}
+	branch, err := currentBranch(path)
+	if err != nil {
+		return err
+	}
+	remote, remoteBranch := strings.TrimSpace(opts.Remote), strings.TrimSpace(opts.Branch)
+	if remote == "" || remoteBranch == "" {
+		upstream, err := branchUpstream(path, branch)
+		if err != nil {
+			return err
+		}
+		if remote == "" {
+			remote = upstream.Remote
+		}
+		if remoteBranch == "" {
+			remoteBranch = upstream.Branch
+		}
+	}
+	if strings.HasPrefix(remote, "-") || strings.HasPrefix(remoteBranch, "-") {
+		return fmt.Errorf("%w: %s %s", ErrNoUpstream, remote, remoteBranch)
+	}
+
+	args := []string{"pull", "--progress", "--ff-only"}
+	if opts.Rebase {
+		args = []string{"pull", "--progress", "--rebase"}
+	}
+	args = append(args, remote, remoteBranch)
+	output, err := runRemoteCommand(ctx, path, progress, args...)
+	if err == nil {
+		return nil
+	}
+	if opts.Rebase {
+		if conflicts, listErr := listConflicts(path); listErr == nil && len(conflicts) > 0 {
+			return fmt.Errorf("%w: %s", ErrPullConflicts, strings.Join(conflicts, ", "))
+		}
+	}
+	if strings.Contains(output, "Not possible to fast-forward") || strings.Contains(output, "diverging branches") {
+		return fmt.Errorf("%w: %s", ErrNotFastForward, lastLine(output))
+	}
+	return err
+}
+
+// Push uploads the current branch. ForceWithLease only overwrites the remote branch
+// when it still points where the last fetch saw it.
+func (r *GitRepo) Push(ctx context.Context, worktreePath string, opts PushOptions, progress ProgressFunc) error {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return err
+	}
+	branch, err := currentBranch(path)
+	if err != nil {
+		return err
+	}
+	remote, remoteBranch := strings.TrimSpace(opts.Remote), strings.TrimSpace(opts.Branch)
+	if upstream, err := branchUpstream(path, branch); err == nil {
+		if remote == "" {
+			remote = upstream.Remote
+		}
+		if remoteBranch == "" && remote == upstream.Remote {
+			remoteBranch = upstream.Branch
+		}
+	}
+	if remote == "" {
+		if remote, err = defaultRemote(path); err != nil {
+			return err
+		}
+	}
+	if remoteBranch == "" {
+		remoteBranch = branch
+	}
+	if strings.HasPrefix(remote, "-") {
+		return fmt.Errorf("%w: %s", ErrNoRemote, remote)
+	}
+
+	args := []string{"push", "--progress", "--porcelain"}
+	if opts.SetUpstream {
+		args = append(args, "--set-upstream")
+	}
+	if opts.ForceWithLease {
+		args = append(args, "--force-with-lease")
+	}
+	args = append(args, remote, "refs/heads/"+branch+":refs/heads/"+remoteBranch)
+	output, err := runRemoteCommand(ctx, path, progress, args...)
+	if err == nil {
+		return nil
+	}
+	switch {
+	case strings.Contains(output, "stale info"):
+		return fmt.Errorf("%w: %s/%s", ErrStaleLease, remote, remoteBranch)
+	case strings.Contains(output, "non-fast-forward"), strings.Contains(output, "fetch first"):
+		return fmt.Errorf("%w: %s/%s has commits that are not present locally", ErrNotFastForward, remote, remoteBranch)
+	}
+	return err
+}
+
+// Upstream returns the remote branch the worktree's current branch tracks.
+func (r *GitRepo) Upstream(worktreePath string) (*Upstream, error) {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	branch, err := currentBranch(path)
+	if err != nil {
+		return nil, err
+	}
+	return branchUpstream(path, branch)
+}
+
+// RemoteTrackingRef names the remote-tracking branch of a local branch: its
+// upstream when one is configured, otherwise origin/<branch>.
+func (r *GitRepo) RemoteTrackingRef(branch string) string {
+	path, err := r.resolveWorktreePath("")
+	if err == nil {
+		if upstream, err := branchUpstream(path, branch); err == nil {
+			return "refs/remotes/" + upstream.Remote + "/" + upstream.Branch
+		}
+	}
+	return "refs/remotes/origin/" + branch
+}
+
+// ResolveRemoteBranch splits a remote-tracking branch such as origin/feature/x or
+// refs/remotes/origin/feature/x into remote and branch. The longest matching remote
+// name wins, so remotes whose name contains a slash are handled.
+func (r *GitRepo) ResolveRemoteBranch(ref string) (*Upstream, error) {
+	path, err := r.resolveWorktreePath("")
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimPrefix(strings.TrimSpace(ref), "refs/remotes/")
+	output, err := runGitOutput(path, "remote")
+	if err != nil {
+		return nil, err
+	}
+	var upstream *Upstream
+	for _, remote := range strings.Fields(string(output)) {
+		branch, ok := strings.CutPrefix(name, remote+"/")
+		if !ok || branch == "" {
+			continue
+		}
+		if upstream == nil || len(remote) > len(upstream.Remote) {
+			upstream = &Upstream{Remote: remote, Branch: branch}
+		}
+	}
+	if upstream == nil {
+		return nil, fmt.Errorf("%w: %s", ErrRemoteBranchNotFound, ref)
+	}
+	if _, err := runGitOutput(path, "rev-parse", "--verify", "--quiet", "refs/remotes/"+name+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrRemoteBranchNotFound, ref)
+	}
+	return upstream, nil
+}
+
+// FetchPullRequest fetches refs/pull/<number>/head from remote (the default remote
+// when empty) and returns the local ref it was stored under. The ref lives outside
+// refs/remotes so that fetch --prune leaves it alone.
+func (r *GitRepo) FetchPullRequest(ctx context.Context, remote string, number int, progress ProgressFunc) (string, error) {
+	path, err := r.resolveWorktreePath("")
+	if err != nil {
+		return "", err
+	}
+	if number <= 0 {
+		return "", fmt.Errorf("%w: #%d", ErrPullRequestNotFound, number)
+	}
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		if remote, err = defaultRemote(path); err != nil {
+			return "", err
+		}
+	} else if strings.HasPrefix(remote, "-") {
+		return "", fmt.Errorf("%w: %s", ErrNoRemote, remote)
+	}
+
+	local := fmt.Sprintf("refs/pull/%s/%d", remote, number)
+	refspec := fmt.Sprintf("+refs/pull/%d/head:%s", number, local)
+	output, err := runRemoteCommand(ctx, path, progress, "fetch", "--progress", remote, refspec)
+	if err != nil {
+		if strings.Contains(output, "couldn't find remote ref") {
+			return "", fmt.Errorf("%w: %s #%d", ErrPullRequestNotFound, remote, number)
+		}
+		return "", err
+	}
+	return local, nil
+}
+
+func currentBranch(path string) (string, error) {
+	output, err := runGitOutput(path, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return "", ErrDetachedHead
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// branchUpstream reads branch.<name>.remote/merge rather than parsing
+// <branch>@{upstream}, which is ambiguous for remotes whose name contains a slash.
+func branchUpstream(path, branch string) (*Upstream, error) {
+	remote, _ := runGitOutput(path, "config", "--get", "branch."+branch+".remote")
+	merge, _ := runGitOutput(path, "config", "--get", "branch."+branch+".merge")
+	upstream := &Upstream{
+		Remote: strings.TrimSpace(string(remote)),
+		Branch: strings.TrimPrefix(strings.TrimSpace(string(merge)), "refs/heads/"),
+	}
+	if upstream.Remote == "" || upstream.Branch == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoUpstream, branch)
+	}
+	return upstream, nil
+}
+
+// defaultRemote prefers origin, then the only configured remote.
+func defaultRemote(path string) (string, error) {
+	output, err := runGitOutput(path, "remote")
+	if err != nil {
+		return "", err
+	}
+	remotes := strings.Fields(string(output))
+	for _, remote := range remotes {
+		if remote == "origin" {
+			return remote, nil
+		}
+	}
+	if len(remotes) == 1 {
+		return remotes[0], nil
+	}
+	return "", ErrNoRemote
+}
+
+// runRemoteCommand runs a network git command, reporting progress lines from stderr
+// as they arrive. Prompts are disabled so a missing credential fails instead of
+// hanging; credential helpers from the user's git config still apply. The command is
+// killed when ctx is done or after remoteCommandTimeout. The returned text is stdout
+// and stderr combined, for classifying failures.
+func runRemoteCommand(ctx context.Context, path string, progress ProgressFunc, args ...string) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, remoteCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = path
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+	// git 被终止后，ssh 等子进程可能仍持有输出管道，限定等待时间
+	cmd.WaitDelay = 5 * time.Second
+	var stdout bytes.Buffer
+	stderr := &progressWriter{progress: progress}
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+
+	runErr := cmd.Run()
+	stderr.flush()
+
+	collected := stderr.collected.String()
+	output := stdout.String() + collected
+	if runErr != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return output, fmt.Errorf("git %s aborted: %w", args[0], ctxErr)
+		}
+		return output, fmt.Errorf("git %s failed: %s", args[0], lastLine(collected))
+	}
+	return output, nil
+}
+
+// progressWriter collects stderr and reports each complete line as progress.
+type progressWriter struct {
+	progress  ProgressFunc
+	pending   []byte
+	collected strings.Builder
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		advance, line, _ := scanProgressLines(w.pending, false)
+		if advance == 0 {
+			break
+		}
+		w.pending = w.pending[advance:]
+		w.emit(string(line))
+	}
+	return len(p), nil
+}
+
+func (w *progressWriter) flush() {
+	if len(w.pending) > 0 {
+		w.emit(string(w.pending))
+		w.pending = nil
+	}
+}
+
+func (w *progressWriter) emit(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	w.collected.WriteString(line)
+	w.collected.WriteByte('\n')
+	if w.progress != nil {
+		w.progress(parseProgressLine(line))
+	}
+}
+
+// scanProgressLines splits on \n and on the \r git uses to redraw progress meters.
+func scanProgressLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+func parseProgressLine(line string) RemoteProgress {
+	progress := RemoteProgress{Percent: -1, Line: line}
+	if match := progressPattern.FindStringSubmatch(line); match != nil {
+		progress.Phase = match[1]
+		progress.Percent, _ = strconv.Atoi(match[2])
+	}
+	return progress
+}
+
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,220 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseProgressLine(t *testing.T) {
+	progress := parseProgressLine("remote: Counting objects:  45% (9/20)")
+	if progress.Phase != "Counting objects" || progress.Percent != 45 {
+		t.Fatalf("unexpected progress: %+v", progress)
+	}
+	if progress := parseProgressLine("To /tmp/remote.git"); progress.Percent != -1 || progress.Phase != "" {
+		t.Fatalf("expected plain line, got %+v", progress)
+	}
+}
+
+func TestGitRepoRemoteOperations(t *testing.T) {
+	ctx := context.Background()
+	remoteDir := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, filepath.Dir(remoteDir), "init", "-q", "--bare", "-b", "main", remoteDir)
+
+	clone := func(name string) (string, *GitRepo) {
+		t.Helper()
+		dir := filepath.Join(t.TempDir(), name)
+		runGit(t, filepath.Dir(dir), "clone", "-q", remoteDir, dir)
+		runGit(t, dir, "config", "user.email", name+"@example.com")
+		runGit(t, dir, "config", "user.name", name)
+		runGit(t, dir, "checkout", "-q", "-B", "main")
+		repo, err := DetectRepository(dir)
+		if err != nil {
+			t.Fatalf("DetectRepository failed: %v", err)
+		}
+		return dir, repo
+	}
+	commit := func(dir, file, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", file, err)
+		}
+		runGit(t, dir, "add", file)
+		runGit(t, dir, "commit", "-q", "-m", "edit "+file)
+	}
+
+	alice, aliceRepo := clone("alice")
+	// 克隆空仓库时 git 已经写入了跟踪配置，去掉以模拟全新的本地分支
+	runGit(t, alice, "config", "--remove-section", "branch.main")
+	commit(alice, "README.md", "hello\n")
+	if _, err := aliceRepo.Upstream(alice); !errors.Is(err, ErrNoUpstream) {
+		t.Fatalf("expected ErrNoUpstream before first push, got %v", err)
+	}
+	var lines []RemoteProgress
+	if err := aliceRepo.Push(ctx, alice, PushOptions{SetUpstream: true}, func(p RemoteProgress) { lines = append(lines, p) }); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if len(lines) == 0 {
+		t.Fatal("expected push progress to be reported")
+	}
+	upstream, err := aliceRepo.Upstream(alice)
+	if err != nil || upstream.Remote != "origin" || upstream.Branch != "main" {
+		t.Fatalf("unexpected upstream %+v (%v)", upstream, err)
+	}
+
+	bob, bobRepo := clone("bob")
+	runGit(t, bob, "branch", "-q", "--set-upstream-to=origin/main")
+
+	// alice 推送一个临时分支，随后在远端删除，bob 的 fetch --prune 应清理对应的远程跟踪分支
+	runGit(t, alice, "push", "-q", "origin", "main:refs/heads/scratch")
+	commit(alice, "app.txt", "alice app\n")
+	if err := aliceRepo.Push(ctx, alice, PushOptions{}, nil); err != nil {
+		t.Fatalf("second Push failed: %v", err)
+	}
+	if err := bobRepo.Fetch(ctx, bob, FetchOptions{Prune: true}, nil); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if !refExists(bob, "refs/remotes/origin/scratch") {
+		t.Fatal("expected origin/scratch after fetch")
+	}
+	runGit(t, alice, "push", "-q", "origin", "--delete", "scratch")
+	if err := bobRepo.Fetch(ctx, bob, FetchOptions{Remote: "origin", Prune: true}, nil); err != nil {
+		t.Fatalf("Fetch with prune failed: %v", err)
+	}
+	if refExists(bob, "refs/remotes/origin/scratch") {
+		t.Fatal("expected origin/scratch to be pruned")
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := bobRepo.Fetch(cancelled, bob, FetchOptions{}, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancelled Fetch to fail with context.Canceled, got %v", err)
+	}
+
+	if err := bobRepo.Pull(ctx, bob, PullOptions{}, nil); err != nil {
+		t.Fatalf("fast-forward Pull failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(bob, "app.txt")); string(content) != "alice app\n" {
+		t.Fatalf("expected pulled file, got %q", content)
+	}
+
+	// 双方各自提交后历史分叉
+	commit(alice, "alice.txt", "a\n")
+	if err := aliceRepo.Push(ctx, alice, PushOptions{}, nil); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	commit(bob, "bob.txt", "b\n")
+	if err := bobRepo.Push(ctx, bob, PushOptions{}, nil); !errors.Is(err, ErrNotFastForward) {
+		t.Fatalf("expected rejected push, got %v", err)
+	}
+	if err := bobRepo.Pull(ctx, bob, PullOptions{}, nil); !errors.Is(err, ErrNotFastForward) {
+		t.Fatalf("expected ff-only pull to fail, got %v", err)
+	}
+	if err := bobRepo.Pull(ctx, bob, PullOptions{Rebase: true}, nil); err != nil {
+		t.Fatalf("rebase Pull failed: %v", err)
+	}
+	if err := bobRepo.Push(ctx, bob, PushOptions{}, nil); err != nil {
+		t.Fatalf("Push after rebase failed: %v", err)
+	}
+
+	// alice 改写历史：远端已前进但 alice 未 fetch，租约应当失效
+	runGit(t, alice, "commit", "-q", "--amend", "-m", "rewritten")
+	if err := aliceRepo.Push(ctx, alice, PushOptions{ForceWithLease: true}, nil); !errors.Is(err, ErrStaleLease) {
+		t.Fatalf("expected stale lease, got %v", err)
+	}
+
+	// 变基冲突保持进行中状态，交给冲突处理流程
+	runGit(t, alice, "reset", "-q", "--hard", "origin/main")
+	if err := aliceRepo.Pull(ctx, alice, PullOptions{}, nil); err != nil {
+		t.Fatalf("Pull failed: %v", err)
+	}
+	commit(alice, "README.md", "alice readme\n")
+	commit(bob, "README.md", "bob readme\n")
+	if err := bobRepo.Push(ctx, bob, PushOptions{}, nil); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if err := aliceRepo.Pull(ctx, alice, PullOptions{Rebase: true}, nil); !errors.Is(err, ErrPullConflicts) {
+		t.Fatalf("expected ErrPullConflicts, got %v", err)
+	}
+	state, err := aliceRepo.ConflictState(alice)
+	if err != nil || state.Operation != OperationRebase || len(state.Conflicts) != 1 {
+		t.Fatalf("expected rebase in progress, got %+v (%v)", state, err)
+	}
+
+	runGit(t, alice, "rebase", "--abort")
+	runGit(t, alice, "checkout", "-q", "--detach")
+	if err := aliceRepo.Push(ctx, alice, PushOptions{}, nil); !errors.Is(err, ErrDetachedHead) {
+		t.Fatalf("expected ErrDetachedHead, got %v", err)
+	}
+}
+
+func refExists(dir, ref string) bool {
+	_, err := runGitOutput(dir, "rev-parse", "--verify", "--quiet", ref)
+	return err == nil
+}
+
+func TestGitRepoFetchWithoutRemote(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	repo, err := DetectRepository(dir)
+	if err != nil {
+		t.Fatalf("DetectRepository failed: %v", err)
+	}
+	if err := repo.Fetch(ctx, dir, FetchOptions{}, nil); !errors.Is(err, ErrNoRemote) {
+		t.Fatalf("expected ErrNoRemote, got %v", err)
+	}
+	if err := repo.Push(ctx, dir, PushOptions{}, nil); !errors.Is(err, ErrNoRemote) {
+		t.Fatalf("expected push without remote to fail, got %v", err)
+	}
+}
+
+func TestGitRepoRemoteBranchAndPullRequest(t *testing.T) {
+	ctx := context.Background()
+	remoteDir := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, filepath.Dir(remoteDir), "init", "-q", "--bare", "-b", "main", remoteDir)
+	seed := filepath.Join(t.TempDir(), "seed")
+	runGit(t, filepath.Dir(seed), "clone", "-q", remoteDir, seed)
+	runGit(t, seed, "config", "user.email", "seed@example.com")
+	runGit(t, seed, "config", "user.name", "seed")
+	runGit(t, seed, "commit", "-q", "--allow-empty", "-m", "init")
+	runGit(t, seed, "push", "-q", "origin", "HEAD:refs/heads/main", "HEAD:refs/heads/feature/x", "HEAD:refs/pull/7/head")
+
+	dir := filepath.Join(t.TempDir(), "work")
+	runGit(t, filepath.Dir(dir), "clone", "-q", remoteDir, dir)
+	// 名称带斜杠的远端与 origin 下的分支同名前缀，应按最长远端名匹配
+	runGit(t, dir, "remote", "add", "origin/fork", remoteDir)
+	runGit(t, dir, "fetch", "-q", "origin/fork")
+	repo, err := DetectRepository(dir)
+	if err != nil {
+		t.Fatalf("DetectRepository failed: %v", err)
+	}
+
+	upstream, err := repo.ResolveRemoteBranch("origin/feature/x")
+	if err != nil || upstream.Remote != "origin" || upstream.Branch != "feature/x" {
+		t.Fatalf("unexpected remote branch %+v (%v)", upstream, err)
+	}
+	upstream, err = repo.ResolveRemoteBranch("refs/remotes/origin/fork/main")
+	if err != nil || upstream.Remote != "origin/fork" || upstream.Branch != "main" {
+		t.Fatalf("unexpected remote branch %+v (%v)", upstream, err)
+	}
+	if _, err := repo.ResolveRemoteBranch("origin/missing"); !errors.Is(err, ErrRemoteBranchNotFound) {
+		t.Fatalf("expected ErrRemoteBranchNotFound, got %v", err)
+	}
+
+	if err := repo.CreateTrackingBranch("feature/x", *upstream); err != nil {
+		t.Fatalf("CreateTrackingBranch failed: %v", err)
+	}
+	if got, err := branchUpstream(dir, "feature/x"); err != nil || got.Remote != "origin/fork" || got.Branch != "main" {
+		t.Fatalf("expected tracking configuration, got %+v (%v)", got, err)
+	}
+
+	ref, err := repo.FetchPullRequest(ctx, "", 7, nil)
+	if err != nil || ref != "refs/pull/origin/7" || !refExists(dir, ref) {
+		t.Fatalf("unexpected pull request ref %q (%v)", ref, err)
+	}
+	if _, err := repo.FetchPullRequest(ctx, "origin", 8, nil); !errors.Is(err, ErrPullRequestNotFound) {
+		t.Fatalf("expected ErrPullRequestNotFound, got %v", err)
+	}
+}
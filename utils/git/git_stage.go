@@ -0,0 +1,308 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrInvalidPath indicates a path outside the worktree or otherwise unusable.
+	ErrInvalidPath = errors.New("invalid path")
+	// ErrHunkNotFound indicates the selected hunk no longer matches the current diff.
+	ErrHunkNotFound = errors.New("hunk not found")
+	// ErrNothingStaged indicates a staged-only commit was requested without staged changes.
+	ErrNothingStaged = errors.New("no staged changes to commit")
+)
+
+// HunkRef selects one hunk of a file diff by its position. When Header is set it
+// must equal the hunk's @@ line, which guards against acting on a stale diff.
+type HunkRef struct {
+	Index  int    `json:"index"`
+	Header string `json:"header,omitempty"`
+}
+
+// StagePaths adds the current content of the paths, including deletions, to the index.
+func (r *GitRepo) StagePaths(worktreePath string, paths []string) error {
+	path, cleaned, err := r.pathsArgs(worktreePath, paths)
+	if err != nil {
+		return err
+	}
+	return r.runInWorktree(path, append([]string{"add", "--all", "--"}, cleaned...)...)
+}
+
+// UnstagePaths resets the index entries of the paths to HEAD, keeping working tree changes.
+func (r *GitRepo) UnstagePaths(worktreePath string, paths []string) error {
+	path, cleaned, err := r.pathsArgs(worktreePath, paths)
+	if err != nil {
+		return err
+	}
+	if !hasHead(path) {
+		// 尚无提交时没有可恢复的 HEAD，直接从索引中移除
+		return r.runInWorktree(path, append([]string{"rm", "--cached", "-r", "-q", "--ignore-unmatch", "--"}, cleaned...)...)
+	}
+	return r.runInWorktree(path, append([]string{"restore", "--staged", "--"}, cleaned...)...)
+}
+
+// DiscardPaths drops working tree changes to the paths and deletes untracked ones.
+// With includeStaged the staged changes are discarded as well, restoring HEAD.
+func (r *GitRepo) DiscardPaths(worktreePath string, paths []string, includeStaged bool) error {
+	path, cleaned, err := r.pathsArgs(worktreePath, paths)
+	if err != nil {
+		return err
+	}
+
+	untracked, err := listUntracked(path, cleaned)
+	if err != nil {
+		return err
+	}
+	if len(untracked) > 0 {
+		if err := r.runInWorktree(path, append([]string{"clean", "-f", "-q", "--"}, untracked...)...); err != nil {
+			return err
+		}
+	}
+
+	output, err := runGitOutput(path, append([]string{"ls-files", "--cached", "-z", "--"}, cleaned...)...)
+	if err != nil {
+		return err
+	}
+	var tracked []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			tracked = append(tracked, file)
+		}
+	}
+	if len(tracked) == 0 {
+		return nil
+	}
+	args := []string{"restore", "--worktree"}
+	if includeStaged && hasHead(path) {
+		args = append(args, "--staged", "--source=HEAD")
+	}
+	return r.runInWorktree(path, append(append(args, "--"), tracked...)...)
+}
+
+// StageHunks stages the selected hunks of the file's unstaged diff.
+func (r *GitRepo) StageHunks(worktreePath, file string, hunks []HunkRef) error {
+	path, cleaned, err := r.pathsArgs(worktreePath, []string{file})
+	if err != nil {
+		return err
+	}
+	untracked, err := listUntracked(path, cleaned)
+	if err != nil {
+		return err
+	}
+	if len(untracked) > 0 {
+		// 未跟踪文件先登记为 intent-to-add，才能出现在 git diff 中按 hunk 暂存
+		if err := r.runInWorktree(path, "add", "--intent-to-add", "--", cleaned[0]); err != nil {
+			return err
+		}
+	}
+	return applySelectedHunks(path, cleaned[0], hunks, false, "--cached")
+}
+
+// UnstageHunks removes the selected hunks of the file's staged diff from the index.
+func (r *GitRepo) UnstageHunks(worktreePath, file string, hunks []HunkRef) error {
+	path, cleaned, err := r.pathsArgs(worktreePath, []string{file})
+	if err != nil {
+		return err
+	}
+	return applySelectedHunks(path, cleaned[0], hunks, true, "--cached", "--reverse")
+}
+
+// DiscardHunks reverts the selected hunks of the file's unstaged diff in the working tree.
+func (r *GitRepo) DiscardHunks(worktreePath, file string, hunks []HunkRef) error {
+	path, cleaned, err := r.pathsArgs(worktreePath, []string{file})
+	if err != nil {
+		return err
+	}
+	return applySelectedHunks(path, cleaned[0], hunks, false, "--reverse")
+}
+
+// CommitStaged commits the index as is, leaving unstaged changes in place.
+func (r *GitRepo) CommitStaged(worktreePath, message string) error {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return err
+	}
+	trimmed := strings.TrimSpace(message)
+	if trimmed == "" {
+		return errors.New("commit message is required")
+	}
+	if err := exec.Command("git", "-C", path, "diff", "--cached", "--quiet").Run(); err == nil {
+		return ErrNothingStaged
+	}
+	return r.runInWorktree(path, "commit", "-m", trimmed)
+}
+
+func (r *GitRepo) pathsArgs(worktreePath string, paths []string) (string, []string, error) {
+	path, err := r.resolveWorktreePath(worktreePath)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(paths) == 0 {
+		return "", nil, fmt.Errorf("%w: at least one path is required", ErrInvalidPath)
+	}
+	cleaned := make([]string, 0, len(paths))
+	for _, p := range paths {
+		value, err := cleanRelativePath(p)
+		if err != nil {
+			return "", nil, err
+		}
+		cleaned = append(cleaned, value)
+	}
+	return path, cleaned, nil
+}
+
+// cleanRelativePath accepts slash separated paths relative to the worktree root and
+// rejects anything that could escape it or be read as a pathspec magic.
+func cleanRelativePath(value string) (string, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" || strings.HasPrefix(trimmed, ":") || filepath.IsAbs(trimmed) || strings.HasPrefix(trimmed, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPath, value)
+	}
+	cleaned := filepath.ToSlash(filepath.Clean(filepath.FromSlash(trimmed)))
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPath, value)
+	}
+	return cleaned, nil
+}
+
+func hasHead(path string) bool {
+	return exec.Command("git", "-C", path, "rev-parse", "--verify", "--quiet", "HEAD").Run() == nil
+}
+
+// applySelectedHunks builds a patch holding only the chosen hunks of the file's
+// current diff (staged when cached is true) and feeds it to git apply.
+func applySelectedHunks(path, file string, refs []HunkRef, cached bool, applyArgs ...string) error {
+	if len(refs) == 0 {
+		return fmt.Errorf("%w: no hunk selected", ErrHunkNotFound)
+	}
+	diffArgs := []string{"-c", "core.quotepath=false", "diff", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/"}
+	if cached {
+		diffArgs = append(diffArgs, "--cached")
+	}
+	output, err := runGitOutput(path, append(diffArgs, "--", file)...)
+	if err != nil {
+		return err
+	}
+
+	header, hunks := splitFilePatch(output)
+	if len(hunks) == 0 {
+		return fmt.Errorf("%w: %s has no changes", ErrHunkNotFound, file)
+	}
+	for _, line := range header {
+		if strings.HasPrefix(line, "Binary files ") {
+			return fmt.Errorf("%w: binary files cannot be split into hunks", ErrInvalidPath)
+		}
+	}
+
+	selected := make(map[int]struct{}, len(refs))
+	for _, ref := range refs {
+		if ref.Index < 0 || ref.Index >= len(hunks) {
+			return fmt.Errorf("%w: index %d", ErrHunkNotFound, ref.Index)
+		}
+		if ref.Header != "" && strings.TrimSpace(ref.Header) != strings.TrimSpace(hunks[ref.Index][0]) {
+			return fmt.Errorf("%w: hunk %d changed since it was displayed", ErrHunkNotFound, ref.Index)
+		}
+		selected[ref.Index] = struct{}{}
+	}
+
+	reverse := false
+	for _, arg := range applyArgs {
+		if arg == "--reverse" {
+			reverse = true
+		}
+	}
+
+	var patch bytes.Buffer
+	for _, line := range header {
+		patch.WriteString(line)
+		patch.WriteByte('\n')
+	}
+	delta := 0
+	for i, hunk := range hunks {
+		if _, ok := selected[i]; !ok {
+			continue
+		}
+		parsed, ok := parseHunkHeader(hunk[0])
+		if !ok {
+			return fmt.Errorf("%w: malformed hunk header %q", ErrHunkNotFound, hunk[0])
+		}
+		patch.WriteString(rebaseHunkHeader(parsed, delta, reverse))
+		patch.WriteByte('\n')
+		for _, line := range hunk[1:] {
+			patch.WriteString(line)
+			patch.WriteByte('\n')
+		}
+		delta += parsed.NewLines - parsed.OldLines
+	}
+
+	return runGitInput(path, &patch, append(append([]string{"apply", "--whitespace=nowarn"}, applyArgs...), "-")...)
+}
+
+// rebaseHunkHeader recomputes the side of the header that moves when earlier hunks
+// are left out of the patch. Forward patches keep the old side and shift the new one;
+// reverse patches keep the new side and shift the old one.
+func rebaseHunkHeader(hunk DiffHunk, delta int, reverse bool) string {
+	oldStart, newStart := hunk.OldStart, hunk.NewStart
+	if reverse {
+		oldStart = shiftedStart(hunk.NewStart, hunk.NewLines, hunk.OldLines, -delta)
+	} else {
+		newStart = shiftedStart(hunk.OldStart, hunk.OldLines, hunk.NewLines, delta)
+	}
+	header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, hunk.OldLines, newStart, hunk.NewLines)
+	if hunk.Section != "" {
+		header += " " + hunk.Section
+	}
+	return header
+}
+
+// shiftedStart maps a hunk start on one side to the other. An empty range starts at
+// the line before the change, so moving between empty and non-empty ranges is off by one.
+func shiftedStart(start, lines, otherLines, delta int) int {
+	result := start + delta
+	switch {
+	case lines == 0 && otherLines > 0:
+		result++
+	case lines > 0 && otherLines == 0:
+		result--
+	}
+	return result
+}
+
+// splitFilePatch splits a single-file diff into its header lines and hunks, each hunk
+// starting with its @@ line.
+func splitFilePatch(output []byte) ([]string, [][]string) {
+	text := strings.TrimSuffix(string(output), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	var header []string
+	var hunks [][]string
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@ "):
+			hunks = append(hunks, []string{line})
+		case len(hunks) > 0:
+			hunks[len(hunks)-1] = append(hunks[len(hunks)-1], line)
+		default:
+			header = append(header, line)
+		}
+	}
+	return header, hunks
+}
+
+// runGitInput runs git with stdin attached, used to feed patches to git apply.
+func runGitInput(path string, stdin *bytes.Buffer, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = path
+	cmd.Stdin = stdin
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(string(output)))
+	}
+	return nil
+}
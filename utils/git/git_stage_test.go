@@ -0,0 +1,153 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitRepoStageHunks(t *testing.T) {
+	repoDir := initTestRepo(t)
+	repo, err := DetectRepository(repoDir)
+	if err != nil {
+		t.Fatalf("DetectRepository failed: %v", err)
+	}
+
+	lines := make([]string, 12)
+	for i := range lines {
+		lines[i] = "line " + string(rune('a'+i))
+	}
+	file := filepath.Join(repoDir, "list.txt")
+	writeLines := func(values []string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(strings.Join(values, "\n")+"\n"), 0o644); err != nil {
+			t.Fatalf("write list: %v", err)
+		}
+	}
+	writeLines(lines)
+	runGit(t, repoDir, "add", "list.txt")
+	runGit(t, repoDir, "commit", "-m", "add list")
+
+	changed := append([]string(nil), lines...)
+	changed[1] = "line B"
+	changed = append(changed[:10], append([]string{"inserted"}, changed[10:]...)...)
+	writeLines(changed)
+
+	working, err := repo.Diff(repoDir, DiffOptions{Mode: DiffModeWorking})
+	if err != nil {
+		t.Fatalf("working diff failed: %v", err)
+	}
+	if len(working.Files) != 1 || len(working.Files[0].Hunks) != 2 {
+		t.Fatalf("expected two hunks, got %+v", working.Files)
+	}
+	second := working.Files[0].Hunks[1]
+
+	if err := repo.StageHunks(repoDir, "list.txt", []HunkRef{{Index: 1, Header: "@@ -1,1 +1,1 @@"}}); !errors.Is(err, ErrHunkNotFound) {
+		t.Fatalf("expected ErrHunkNotFound for stale header, got %v", err)
+	}
+	if err := repo.StageHunks(repoDir, "list.txt", []HunkRef{{Index: 1, Header: second.Header}}); err != nil {
+		t.Fatalf("StageHunks failed: %v", err)
+	}
+
+	staged, err := repo.Diff(repoDir, DiffOptions{Mode: DiffModeStaged})
+	if err != nil {
+		t.Fatalf("staged diff failed: %v", err)
+	}
+	if len(staged.Files) != 1 || staged.Additions != 1 || staged.Deletions != 0 {
+		t.Fatalf("expected only the insertion to be staged, got %+v", staged)
+	}
+	working, err = repo.Diff(repoDir, DiffOptions{Mode: DiffModeWorking})
+	if err != nil {
+		t.Fatalf("working diff failed: %v", err)
+	}
+	if working.Additions != 1 || working.Deletions != 1 {
+		t.Fatalf("expected the rename to remain unstaged, got %+v", working)
+	}
+
+	if err := repo.DiscardHunks(repoDir, "list.txt", []HunkRef{{Index: 0}}); err != nil {
+		t.Fatalf("DiscardHunks failed: %v", err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("read list: %v", err)
+	}
+	if strings.Contains(string(content), "line B") || !strings.Contains(string(content), "inserted") {
+		t.Fatalf("unexpected content after discard: %q", content)
+	}
+
+	if err := repo.UnstageHunks(repoDir, "list.txt", []HunkRef{{Index: 0}}); err != nil {
+		t.Fatalf("UnstageHunks failed: %v", err)
+	}
+	staged, err = repo.Diff(repoDir, DiffOptions{Mode: DiffModeStaged})
+	if err != nil {
+		t.Fatalf("staged diff failed: %v", err)
+	}
+	if len(staged.Files) != 0 {
+		t.Fatalf("expected nothing staged, got %+v", staged.Files)
+	}
+}
+
+func TestGitRepoStagePathsAndCommitStaged(t *testing.T) {
+	repoDir := initTestRepo(t)
+	repo, err := DetectRepository(repoDir)
+	if err != nil {
+		t.Fatalf("DetectRepository failed: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("# Changed\n"), 0o644); err != nil {
+		t.Fatalf("write README: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "wanted.txt"), []byte("keep\n"), 0o644); err != nil {
+		t.Fatalf("write wanted: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "scratch.txt"), []byte("junk\n"), 0o644); err != nil {
+		t.Fatalf("write scratch: %v", err)
+	}
+
+	if err := repo.CommitStaged(repoDir, "nothing yet"); !errors.Is(err, ErrNothingStaged) {
+		t.Fatalf("expected ErrNothingStaged, got %v", err)
+	}
+	if err := repo.StagePaths(repoDir, []string{"../outside.txt"}); !errors.Is(err, ErrInvalidPath) {
+		t.Fatalf("expected ErrInvalidPath, got %v", err)
+	}
+
+	if err := repo.StagePaths(repoDir, []string{"wanted.txt", "README.md"}); err != nil {
+		t.Fatalf("StagePaths failed: %v", err)
+	}
+	if err := repo.UnstagePaths(repoDir, []string{"README.md"}); err != nil {
+		t.Fatalf("UnstagePaths failed: %v", err)
+	}
+	if err := repo.DiscardPaths(repoDir, []string{"scratch.txt", "README.md"}, false); err != nil {
+		t.Fatalf("DiscardPaths failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "scratch.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected untracked file to be removed, got %v", err)
+	}
+	readme, err := os.ReadFile(filepath.Join(repoDir, "README.md"))
+	if err != nil {
+		t.Fatalf("read README: %v", err)
+	}
+	if strings.TrimSpace(string(readme)) != "# Test Repo" {
+		t.Fatalf("expected README to be restored, got %q", readme)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoDir, "later.txt"), []byte("later\n"), 0o644); err != nil {
+		t.Fatalf("write later: %v", err)
+	}
+	if err := repo.CommitStaged(repoDir, "add wanted"); err != nil {
+		t.Fatalf("CommitStaged failed: %v", err)
+	}
+
+	files, err := runGitOutput(repoDir, "show", "--name-only", "--format=", "HEAD")
+	if err != nil {
+		t.Fatalf("git show failed: %v", err)
+	}
+	if strings.TrimSpace(string(files)) != "wanted.txt" {
+		t.Fatalf("expected only wanted.txt in the commit, got %q", files)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "later.txt")); err != nil {
+		t.Fatalf("expected unstaged file to stay in place: %v", err)
+	}
+}
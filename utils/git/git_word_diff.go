@@ -0,0 +1,119 @@
+package git
+
+import "unicode"
+
+// maxWordDiffTokens bounds the LCS table; longer lines are marked as changed as a whole.
+const maxWordDiffTokens = 400
+
+// DiffSegment is a run of text within a line, marked when it differs from the paired line.
+type DiffSegment struct {
+	Text    string `json:"text"`
+	Changed bool   `json:"changed"`
+}
+
+// annotateWordDiff pairs each run of deleted lines with the added lines that follow
+// it and fills in word-level segments for both sides.
+func annotateWordDiff(hunk *DiffHunk) {
+	lines := hunk.Lines
+	for i := 0; i < len(lines); {
+		if lines[i].Kind != DiffLineDelete {
+			i++
+			continue
+		}
+		delStart := i
+		for i < len(lines) && lines[i].Kind == DiffLineDelete {
+			i++
+		}
+		addStart := i
+		for i < len(lines) && lines[i].Kind == DiffLineAdd {
+			i++
+		}
+		pairs := addStart - delStart
+		if added := i - addStart; added < pairs {
+			pairs = added
+		}
+		for k := 0; k < pairs; k++ {
+			oldLine, newLine := &lines[delStart+k], &lines[addStart+k]
+			oldLine.Segments, newLine.Segments = wordDiff(oldLine.Content, newLine.Content)
+		}
+	}
+}
+
+// wordDiff splits both lines into words, whitespace and punctuation and marks the
+// tokens outside their longest common subsequence.
+func wordDiff(oldText, newText string) ([]DiffSegment, []DiffSegment) {
+	a, b := tokenizeWords(oldText), tokenizeWords(newText)
+	if len(a) > maxWordDiffTokens || len(b) > maxWordDiffTokens {
+		return []DiffSegment{{Text: oldText, Changed: true}}, []DiffSegment{{Text: newText, Changed: true}}
+	}
+
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var oldSegs, newSegs []DiffSegment
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			oldSegs = appendSegment(oldSegs, a[i], false)
+			newSegs = appendSegment(newSegs, b[j], false)
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			newSegs = appendSegment(newSegs, b[j], true)
+			j++
+		default:
+			oldSegs = appendSegment(oldSegs, a[i], true)
+			i++
+		}
+	}
+	return oldSegs, newSegs
+}
+
+func appendSegment(segments []DiffSegment, text string, changed bool) []DiffSegment {
+	if n := len(segments); n > 0 && segments[n-1].Changed == changed {
+		segments[n-1].Text += text
+		return segments
+	}
+	return append(segments, DiffSegment{Text: text, Changed: changed})
+}
+
+func tokenizeWords(text string) []string {
+	var tokens []string
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		start := i
+		switch {
+		case isWordRune(runes[i]):
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+		case unicode.IsSpace(runes[i]):
+			for i < len(runes) && unicode.IsSpace(runes[i]) {
+				i++
+			}
+		default:
+			i++
+		}
+		tokens = append(tokens, string(runes[start:i]))
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
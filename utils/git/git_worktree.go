@@ -16,6 +16,7 @@ type WorktreeInfo struct {
 	HeadCommit string
 	IsMain     bool
 	IsBare     bool
+	IsDetached bool
 }
 
 // ListWorktrees enumerates worktrees attached to the repository.
@@ -79,6 +80,32 @@ func (r *GitRepo) AddWorktree(path, branch string, createBranch bool) error {
 	return nil
 }
 
+// AddDetachedWorktree adds a worktree at path with HEAD detached at rev, which may
+// be a tag, a commit or any other revision that resolves to a commit.
+func (r *GitRepo) AddDetachedWorktree(path, rev string) error {
+	if r == nil {
+		return errors.New("git repository is not initialized")
+	}
+	if strings.TrimSpace(path) == "" {
+		return errors.New("worktree path is required")
+	}
+	targetPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	commit, err := resolveCommit(r.Path, rev)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "worktree", "add", "--detach", targetPath, commit)
+	cmd.Dir = r.Path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("add worktree failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // RemoveWorktree removes an existing worktree.
 func (r *GitRepo) RemoveWorktree(path string, force bool) error {
 	if r == nil {
@@ -143,11 +170,12 @@ func parseWorktreeList(output string) []WorktreeInfo {
 			continue
 		}
 
+		// bare、detached 等属性行没有取值
 		parts := strings.SplitN(line, " ", 2)
-		if len(parts) != 2 {
-			continue
+		key, val := parts[0], ""
+		if len(parts) == 2 {
+			val = strings.TrimSpace(parts[1])
 		}
-		key, val := parts[0], strings.TrimSpace(parts[1])
 
 		switch key {
 		case "worktree":
@@ -163,7 +191,7 @@ func parseWorktreeList(output string) []WorktreeInfo {
 		case "bare":
 			current.IsBare = true
 		case "detached":
-			current.Branch = val
+			current.IsDetached = true
 		}
 	}
 	resetCurrent()
@@ -13,6 +13,10 @@ branch refs/heads/main
 worktree /repo/feature
 HEAD 5da41358595c294c5b4af4a3e163192f7ca2ce50
 branch refs/heads/feature/demo
+
+worktree /repo/v1.0.0
+HEAD 9b2f1c4d2e7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c
+detached
 `
 
 	got := parseWorktreeList(input)
@@ -27,6 +31,11 @@ branch refs/heads/feature/demo
 			Branch:     "feature/demo",
 			HeadCommit: "5da4135",
 		},
+		{
+			Path:       "/repo/v1.0.0",
+			HeadCommit: "9b2f1c4",
+			IsDetached: true,
+		},
 	}
 
 	if !reflect.DeepEqual(got, want) {
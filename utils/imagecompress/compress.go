@@ -0,0 +1,114 @@
+// Package imagecompress shrinks uploaded screenshots and photos before they are stored.
+package imagecompress
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+)
+
+const (
+	// DefaultMaxDimension caps the longest side of a compressed image.
+	DefaultMaxDimension = 2560
+	// DefaultJPEGQuality is used when re-encoding JPEG images.
+	DefaultJPEGQuality = 82
+)
+
+// Options tunes Compress. Zero values fall back to the defaults.
+type Options struct {
+	MaxDimension int
+	JPEGQuality  int
+}
+
+// Result describes the outcome of Compress.
+type Result struct {
+	Data        []byte
+	ContentType string
+	// Changed is false when the original bytes were kept because re-encoding did not help.
+	Changed bool
+}
+
+// Compress downsizes JPEG and PNG images larger than MaxDimension and re-encodes
+// them. Other formats, undecodable data and re-encodes that would not shrink the
+// file are returned unchanged.
+func Compress(data []byte, contentType string, opts Options) Result {
+	original := Result{Data: data, ContentType: contentType}
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return original
+	}
+	if opts.MaxDimension <= 0 {
+		opts.MaxDimension = DefaultMaxDimension
+	}
+	if opts.JPEGQuality <= 0 || opts.JPEGQuality > 100 {
+		opts.JPEGQuality = DefaultJPEGQuality
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return original
+	}
+	resized := false
+	if bounds := img.Bounds(); bounds.Dx() > opts.MaxDimension || bounds.Dy() > opts.MaxDimension {
+		img = downscale(img, opts.MaxDimension)
+		resized = true
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: opts.JPEGQuality})
+	case "png":
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		err = encoder.Encode(&buf, img)
+	default:
+		return original
+	}
+	if err != nil {
+		return original
+	}
+	if !resized && buf.Len() >= len(data) {
+		return original
+	}
+	return Result{Data: buf.Bytes(), ContentType: "image/" + format, Changed: true}
+}
+
+// downscale shrinks img so its longest side equals maxDimension, averaging the
+// source pixels covered by each destination pixel.
+func downscale(img image.Image, maxDimension int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dstW, dstH := maxDimension, maxDimension
+	if srcW >= srcH {
+		dstH = max(1, srcH*maxDimension/srcW)
+	} else {
+		dstW = max(1, srcW*maxDimension/srcH)
+	}
+
+	src := image.NewNRGBA(image.Rect(0, 0, srcW, srcH))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*srcH/dstH, max((y+1)*srcH/dstH, y*srcH/dstH+1)
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*srcW/dstW, max((x+1)*srcW/dstW, x*srcW/dstW+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				offset := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					pix := src.Pix[offset : offset+4]
+					r += uint64(pix[0])
+					g += uint64(pix[1])
+					b += uint64(pix[2])
+					a += uint64(pix[3])
+					n++
+					offset += 4
+				}
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
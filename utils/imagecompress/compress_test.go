@@ -0,0 +1,44 @@
+package imagecompress
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestCompressDownscalesLargeImages(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 400; x++ {
+			src.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	result := Compress(buf.Bytes(), "image/png", Options{MaxDimension: 200})
+	if !result.Changed || result.ContentType != "image/png" {
+		t.Fatalf("expected a re-encoded png, got %+v", result.ContentType)
+	}
+	decoded, err := png.Decode(bytes.NewReader(result.Data))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if bounds := decoded.Bounds(); bounds.Dx() != 200 || bounds.Dy() != 50 {
+		t.Fatalf("unexpected size %v", bounds)
+	}
+}
+
+func TestCompressLeavesOtherDataAlone(t *testing.T) {
+	data := []byte("%PDF-1.4")
+	if result := Compress(data, "application/pdf", Options{}); result.Changed || !bytes.Equal(result.Data, data) {
+		t.Fatalf("expected non-images to be untouched")
+	}
+	if result := Compress([]byte("not really a png"), "image/png", Options{}); result.Changed {
+		t.Fatalf("expected undecodable data to be untouched")
+	}
+}
@@ -0,0 +1,300 @@
+package issuetracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	pageSize       = 50
+	maxPages       = 200
+)
+
+// Config configures a REST client for a GitHub-compatible issue API.
+type Config struct {
+	Kind       Kind
+	APIURL     string // 为空时根据远程地址推断
+	Token      string
+	HTTPClient *http.Client
+}
+
+// APIError is returned for non-2xx responses.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("issue tracker returned %d: %s", e.StatusCode, e.Message)
+}
+
+// Client talks to the GitHub or Gitea REST API. Both expose the same issue, label and
+// comment endpoints under /repos/{owner}/{repo}.
+type Client struct {
+	kind    Kind
+	baseURL string
+	token   string
+	remote  *Remote
+	http    *http.Client
+}
+
+// New builds a client for the repository behind remote.
+func New(remote *Remote, cfg Config) (*Client, error) {
+	if remote == nil {
+		return nil, ErrUnsupportedRemote
+	}
+	kind := cfg.Kind
+	if kind == "" {
+		kind = remote.DefaultKind()
+	}
+	if kind != KindGitHub && kind != KindGitea {
+		return nil, fmt.Errorf("unknown issue tracker kind %q", kind)
+	}
+	apiURL := strings.TrimRight(strings.TrimSpace(cfg.APIURL), "/")
+	if apiURL == "" {
+		apiURL = remote.DefaultAPIURL(kind)
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: requestTimeout}
+	}
+	return &Client{
+		kind:    kind,
+		baseURL: apiURL + "/repos/" + url.PathEscape(remote.Owner) + "/" + url.PathEscape(remote.Name),
+		token:   cfg.Token,
+		remote:  remote,
+		http:    httpClient,
+	}, nil
+}
+
+// Repo returns owner/name.
+func (c *Client) Repo() string {
+	return c.remote.Repo()
+}
+
+type apiLabel struct {
+	Name string `json:"name"`
+}
+
+type apiUser struct {
+	Login string `json:"login"`
+}
+
+type apiIssue struct {
+	Number      int             `json:"number"`
+	Title       string          `json:"title"`
+	Body        string          `json:"body"`
+	State       string          `json:"state"`
+	Labels      []apiLabel      `json:"labels"`
+	HTMLURL     string          `json:"html_url"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	PullRequest json.RawMessage `json:"pull_request,omitempty"`
+}
+
+type apiComment struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	User      apiUser   `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (i *apiIssue) toIssue() Issue {
+	labels := make([]string, 0, len(i.Labels))
+	for _, label := range i.Labels {
+		labels = append(labels, label.Name)
+	}
+	return Issue{
+		Number:    i.Number,
+		Title:     i.Title,
+		Body:      i.Body,
+		State:     i.State,
+		Labels:    labels,
+		URL:       i.HTMLURL,
+		UpdatedAt: i.UpdatedAt,
+	}
+}
+
+func (c *apiComment) toComment() Comment {
+	return Comment{
+		ID:        c.ID,
+		Body:      c.Body,
+		Author:    c.User.Login,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// ListIssues pages through all issues updated since the given time. Pull requests,
+// which GitHub returns from the same endpoint, are skipped.
+func (c *Client) ListIssues(ctx context.Context, since time.Time) ([]Issue, error) {
+	var issues []Issue
+	for page := 1; page <= maxPages; page++ {
+		query := url.Values{}
+		query.Set("state", "all")
+		query.Set("type", "issues") // Gitea
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", strconv.Itoa(pageSize)) // GitHub
+		query.Set("limit", strconv.Itoa(pageSize))    // Gitea
+		if !since.IsZero() {
+			query.Set("since", since.UTC().Format(time.RFC3339))
+		}
+
+		var batch []apiIssue
+		if err := c.do(ctx, http.MethodGet, "/issues?"+query.Encode(), nil, &batch); err != nil {
+			return nil, err
+		}
+		for i := range batch {
+			if len(batch[i].PullRequest) > 0 && string(batch[i].PullRequest) != "null" {
+				continue
+			}
+			issues = append(issues, batch[i].toIssue())
+		}
+		if len(batch) < pageSize {
+			break
+		}
+	}
+	return issues, nil
+}
+
+// CreateIssue opens an issue, then applies labels and state.
+func (c *Client) CreateIssue(ctx context.Context, input IssueInput) (*Issue, error) {
+	var created apiIssue
+	if err := c.do(ctx, http.MethodPost, "/issues", map[string]interface{}{
+		"title": input.Title,
+		"body":  input.Body,
+	}, &created); err != nil {
+		return nil, err
+	}
+	// Gitea 创建时只接受标签 ID，统一在创建后按名称设置标签和状态
+	if len(input.Labels) > 0 || input.State == StateClosed {
+		return c.UpdateIssue(ctx, created.Number, IssueInput{
+			Title:  input.Title,
+			Body:   input.Body,
+			State:  input.State,
+			Labels: input.Labels,
+		})
+	}
+	issue := created.toIssue()
+	return &issue, nil
+}
+
+// UpdateIssue edits title, body and state, replaces labels when given and returns the
+// issue as stored afterwards.
+func (c *Client) UpdateIssue(ctx context.Context, number int, input IssueInput) (*Issue, error) {
+	path := "/issues/" + strconv.Itoa(number)
+	payload := map[string]interface{}{
+		"title": input.Title,
+		"body":  input.Body,
+	}
+	if input.State != "" {
+		payload["state"] = input.State
+	}
+	if err := c.do(ctx, http.MethodPatch, path, payload, nil); err != nil {
+		return nil, err
+	}
+	if input.Labels != nil {
+		if err := c.do(ctx, http.MethodPut, path+"/labels", map[string]interface{}{"labels": input.Labels}, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	var updated apiIssue
+	if err := c.do(ctx, http.MethodGet, path, nil, &updated); err != nil {
+		return nil, err
+	}
+	issue := updated.toIssue()
+	return &issue, nil
+}
+
+// ListComments returns all comments of an issue in creation order.
+func (c *Client) ListComments(ctx context.Context, number int) ([]Comment, error) {
+	var comments []Comment
+	for page := 1; page <= maxPages; page++ {
+		query := url.Values{}
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", strconv.Itoa(pageSize))
+		query.Set("limit", strconv.Itoa(pageSize))
+
+		var batch []apiComment
+		if err := c.do(ctx, http.MethodGet, "/issues/"+strconv.Itoa(number)+"/comments?"+query.Encode(), nil, &batch); err != nil {
+			return nil, err
+		}
+		for i := range batch {
+			comments = append(comments, batch[i].toComment())
+		}
+		if len(batch) < pageSize {
+			break
+		}
+	}
+	return comments, nil
+}
+
+// CreateComment posts a comment on an issue.
+func (c *Client) CreateComment(ctx context.Context, number int, body string) (*Comment, error) {
+	var created apiComment
+	if err := c.do(ctx, http.MethodPost, "/issues/"+strconv.Itoa(number)+"/comments", map[string]interface{}{"body": body}, &created); err != nil {
+		return nil, err
+	}
+	comment := created.toComment()
+	return &comment, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, payload interface{}, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		if c.kind == KindGitHub {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		} else {
+			req.Header.Set("Authorization", "token "+c.token)
+		}
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		message := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			message = apiErr.Message
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: message}
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
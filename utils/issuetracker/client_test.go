@@ -0,0 +1,116 @@
+package issuetracker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"code-kanban/utils/issuetracker/trackertest"
+)
+
+func TestParseRemote(t *testing.T) {
+	cases := []struct {
+		raw      string
+		host     string
+		basePath string
+		repo     string
+	}{
+		{"https://github.com/acme/kanban.git", "github.com", "", "acme/kanban"},
+		{"git@github.com:acme/kanban.git", "github.com", "", "acme/kanban"},
+		{"ssh://git@gitea.example.com:2222/team/app", "gitea.example.com", "", "team/app"},
+		{"https://code.example.com/git/team/app/", "code.example.com", "/git", "team/app"},
+	}
+	for _, tc := range cases {
+		remote, err := ParseRemote(tc.raw)
+		if err != nil {
+			t.Fatalf("ParseRemote(%q) returned error: %v", tc.raw, err)
+		}
+		if remote.Host != tc.host || remote.BasePath != tc.basePath || remote.Repo() != tc.repo {
+			t.Fatalf("ParseRemote(%q) = %+v", tc.raw, remote)
+		}
+	}
+
+	if _, err := ParseRemote("/local/path/repo"); !errors.Is(err, ErrUnsupportedRemote) {
+		t.Fatalf("expected local path to be rejected, got %v", err)
+	}
+
+	remote, _ := ParseRemote("https://github.com/acme/kanban")
+	if remote.DefaultKind() != KindGitHub || remote.DefaultAPIURL(KindGitHub) != "https://api.github.com" {
+		t.Fatalf("unexpected github defaults for %+v", remote)
+	}
+	remote, _ = ParseRemote("https://code.example.com/git/team/app")
+	if remote.DefaultKind() != KindGitea || remote.DefaultAPIURL(KindGitea) != "https://code.example.com/git/api/v1" {
+		t.Fatalf("unexpected gitea defaults for %+v", remote)
+	}
+}
+
+func TestClientAgainstFakeServer(t *testing.T) {
+	server := trackertest.NewServer()
+	defer server.Close()
+	server.Token = "secret"
+
+	remote, _ := ParseRemote("https://github.com/acme/kanban.git")
+	ctx := context.Background()
+
+	unauthorized, err := New(remote, Config{APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	var apiErr *APIError
+	if _, err := unauthorized.ListIssues(ctx, time.Time{}); !errors.As(err, &apiErr) || apiErr.StatusCode != 401 {
+		t.Fatalf("expected 401 without token, got %v", err)
+	}
+
+	client, err := New(remote, Config{Kind: KindGitea, APIURL: server.URL, Token: "secret"})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if client.Repo() != "acme/kanban" {
+		t.Fatalf("unexpected repo %s", client.Repo())
+	}
+
+	existing := server.AddIssue("remote bug", "details", "bug")
+	pr := server.AddIssue("a pull request", "")
+	server.EditIssue(pr.Number, func(issue *trackertest.Issue) { issue.PullRequest = true })
+
+	created, err := client.CreateIssue(ctx, IssueInput{Title: "from kanban", Body: "body", State: StateClosed, Labels: []string{"ui", "p1"}})
+	if err != nil {
+		t.Fatalf("CreateIssue returned error: %v", err)
+	}
+	if created.State != StateClosed || len(created.Labels) != 2 || created.URL == "" {
+		t.Fatalf("unexpected created issue %+v", created)
+	}
+
+	issues, err := client.ListIssues(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("ListIssues returned error: %v", err)
+	}
+	if len(issues) != 2 || issues[0].Number != existing.Number || issues[0].Labels[0] != "bug" {
+		t.Fatalf("expected pull requests to be skipped, got %+v", issues)
+	}
+
+	updated, err := client.UpdateIssue(ctx, existing.Number, IssueInput{Title: "renamed", Body: "details"})
+	if err != nil {
+		t.Fatalf("UpdateIssue returned error: %v", err)
+	}
+	if updated.Title != "renamed" || len(updated.Labels) != 1 || !updated.UpdatedAt.After(existing.UpdatedAt) {
+		t.Fatalf("expected labels to be kept when nil, got %+v", updated)
+	}
+
+	if _, err := client.CreateComment(ctx, existing.Number, "hello"); err != nil {
+		t.Fatalf("CreateComment returned error: %v", err)
+	}
+	server.AddComment(existing.Number, "octocat", "hi back")
+	comments, err := client.ListComments(ctx, existing.Number)
+	if err != nil {
+		t.Fatalf("ListComments returned error: %v", err)
+	}
+	if len(comments) != 2 || comments[1].Author != "octocat" || comments[1].Body != "hi back" {
+		t.Fatalf("unexpected comments %+v", comments)
+	}
+
+	if _, err := client.UpdateIssue(ctx, 999, IssueInput{Title: "missing"}); !errors.As(err, &apiErr) || apiErr.StatusCode != 404 {
+		t.Fatalf("expected 404 for missing issue, got %v", err)
+	}
+}
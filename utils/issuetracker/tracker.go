@@ -0,0 +1,149 @@
+package issuetracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Kind identifies the flavour of the remote issue tracker API.
+type Kind string
+
+const (
+	// KindGitHub is the GitHub REST API (api.github.com).
+	KindGitHub Kind = "github"
+	// KindGitea is the Gitea/Forgejo REST API (<host>/api/v1), which mirrors GitHub's issue endpoints.
+	KindGitea Kind = "gitea"
+
+	// StateOpen is the state of an open issue.
+	StateOpen = "open"
+	// StateClosed is the state of a closed issue.
+	StateClosed = "closed"
+)
+
+var (
+	// ErrUnsupportedRemote indicates the remote URL does not point to a repository we can map to an API.
+	ErrUnsupportedRemote = errors.New("unsupported remote url")
+)
+
+// Issue is the subset of an issue the task sync works with.
+type Issue struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	State     string    `json:"state"`
+	Labels    []string  `json:"labels"`
+	URL       string    `json:"url"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Comment is an issue comment.
+type Comment struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// IssueInput carries the fields written to the tracker. A nil Labels slice leaves labels untouched.
+type IssueInput struct {
+	Title  string
+	Body   string
+	State  string
+	Labels []string
+}
+
+// Tracker is the issue tracker API used by the task sync.
+type Tracker interface {
+	// Repo returns the owner/name of the repository.
+	Repo() string
+	// ListIssues returns issues (not pull requests) in any state updated at or after since.
+	ListIssues(ctx context.Context, since time.Time) ([]Issue, error)
+	CreateIssue(ctx context.Context, input IssueInput) (*Issue, error)
+	UpdateIssue(ctx context.Context, number int, input IssueInput) (*Issue, error)
+	ListComments(ctx context.Context, number int) ([]Comment, error)
+	CreateComment(ctx context.Context, number int, body string) (*Comment, error)
+}
+
+// Remote is a repository location parsed from a git remote URL.
+type Remote struct {
+	Host     string
+	BasePath string // Gitea 部署在子路径时的前缀，如 /git
+	Owner    string
+	Name     string
+}
+
+// Repo returns owner/name.
+func (r *Remote) Repo() string {
+	return r.Owner + "/" + r.Name
+}
+
+// DefaultKind guesses the API flavour from the host.
+func (r *Remote) DefaultKind() Kind {
+	if strings.EqualFold(r.Host, "github.com") {
+		return KindGitHub
+	}
+	return KindGitea
+}
+
+// DefaultAPIURL returns the conventional API root for the remote.
+func (r *Remote) DefaultAPIURL(kind Kind) string {
+	if kind == KindGitHub {
+		if strings.EqualFold(r.Host, "github.com") {
+			return "https://api.github.com"
+		}
+		// GitHub Enterprise
+		return "https://" + r.Host + "/api/v3"
+	}
+	return "https://" + r.Host + r.BasePath + "/api/v1"
+}
+
+// ParseRemote understands https://host/owner/repo(.git), ssh://git@host[:port]/owner/repo(.git)
+// and the scp-like git@host:owner/repo(.git) form.
+func ParseRemote(raw string) (*Remote, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("%w: remote url is empty", ErrUnsupportedRemote)
+	}
+
+	var host, path string
+	if !strings.Contains(raw, "://") {
+		// scp 形式：git@host:owner/repo.git
+		at := strings.LastIndex(raw, "@")
+		rest := raw[at+1:]
+		colon := strings.Index(rest, ":")
+		if colon <= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedRemote, raw)
+		}
+		host, path = rest[:colon], rest[colon+1:]
+	} else {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedRemote, err)
+		}
+		switch parsed.Scheme {
+		case "http", "https", "ssh", "git":
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedRemote, raw)
+		}
+		host, path = parsed.Hostname(), parsed.Path
+	}
+
+	segments := strings.FieldsFunc(strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git"), func(r rune) bool { return r == '/' })
+	if host == "" || len(segments) < 2 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedRemote, raw)
+	}
+	remote := &Remote{
+		Host:  strings.ToLower(host),
+		Owner: segments[len(segments)-2],
+		Name:  segments[len(segments)-1],
+	}
+	if len(segments) > 2 {
+		remote.BasePath = "/" + strings.Join(segments[:len(segments)-2], "/")
+	}
+	return remote, nil
+}
@@ -0,0 +1,376 @@
+// Package trackertest provides an in-process fake of the GitHub/Gitea issue API for tests.
+package trackertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Issue is the server-side state of an issue.
+type Issue struct {
+	Number      int
+	Title       string
+	Body        string
+	State       string
+	Labels      []string
+	UpdatedAt   time.Time
+	PullRequest bool
+}
+
+// Comment is the server-side state of an issue comment.
+type Comment struct {
+	ID        int64
+	Author    string
+	Body      string
+	CreatedAt time.Time
+}
+
+// Server serves /repos/{owner}/{repo}/issues... for a single repository.
+type Server struct {
+	*httptest.Server
+
+	// Token, when set, must be presented in the Authorization header.
+	Token string
+
+	mu            sync.Mutex
+	issues        map[int]*Issue
+	comments      map[int][]*Comment
+	nextNumber    int
+	nextCommentID int64
+	requests      int
+}
+
+// NewServer starts a fake tracker. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		issues:        map[int]*Issue{},
+		comments:      map[int][]*Comment{},
+		nextNumber:    1,
+		nextCommentID: 1000,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/{owner}/{repo}/issues", s.listIssues)
+	mux.HandleFunc("POST /repos/{owner}/{repo}/issues", s.createIssue)
+	mux.HandleFunc("GET /repos/{owner}/{repo}/issues/{number}", s.getIssue)
+	mux.HandleFunc("PATCH /repos/{owner}/{repo}/issues/{number}", s.patchIssue)
+	mux.HandleFunc("PUT /repos/{owner}/{repo}/issues/{number}/labels", s.putLabels)
+	mux.HandleFunc("GET /repos/{owner}/{repo}/issues/{number}/comments", s.listComments)
+	mux.HandleFunc("POST /repos/{owner}/{repo}/issues/{number}/comments", s.createComment)
+	s.Server = httptest.NewServer(s.authorize(mux))
+	return s
+}
+
+// AddIssue seeds an issue as if created on the remote.
+func (s *Server) AddIssue(title, body string, labels ...string) *Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := &Issue{
+		Number:    s.nextNumber,
+		Title:     title,
+		Body:      body,
+		State:     "open",
+		Labels:    append([]string{}, labels...),
+		UpdatedAt: time.Now(),
+	}
+	s.nextNumber++
+	s.issues[issue.Number] = issue
+	return s.copyIssue(issue)
+}
+
+// EditIssue mutates an issue and bumps its updated_at.
+func (s *Server) EditIssue(number int, edit func(*Issue)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if issue, ok := s.issues[number]; ok {
+		edit(issue)
+		issue.UpdatedAt = time.Now()
+	}
+}
+
+// AddComment seeds a remote comment.
+func (s *Server) AddComment(number int, author, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addComment(number, author, body)
+}
+
+// Issue returns a copy of an issue, or nil.
+func (s *Server) Issue(number int) *Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue, ok := s.issues[number]
+	if !ok {
+		return nil
+	}
+	return s.copyIssue(issue)
+}
+
+// Issues returns copies of all issues ordered by number.
+func (s *Server) Issues() []Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]Issue, 0, len(s.issues))
+	for _, issue := range s.issues {
+		result = append(result, *s.copyIssue(issue))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Number < result[j].Number })
+	return result
+}
+
+// Comments returns copies of the comments of an issue.
+func (s *Server) Comments(number int) []Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]Comment, 0, len(s.comments[number]))
+	for _, comment := range s.comments[number] {
+		result = append(result, *comment)
+	}
+	return result
+}
+
+// Requests returns the number of API requests served.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		s.mu.Unlock()
+		if s.Token != "" {
+			header := r.Header.Get("Authorization")
+			if header != "Bearer "+s.Token && header != "token "+s.Token {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Bad credentials"})
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) listIssues(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var since time.Time
+	if value := query.Get("since"); value != "" {
+		since, _ = time.Parse(time.RFC3339, value)
+	}
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(query.Get("per_page"))
+	if limit < 1 {
+		limit = 30
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	numbers := make([]int, 0, len(s.issues))
+	for number, issue := range s.issues {
+		if !since.IsZero() && issue.UpdatedAt.Before(since) {
+			continue
+		}
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	result := []map[string]interface{}{}
+	for i := (page - 1) * limit; i < len(numbers) && i < page*limit; i++ {
+		result = append(result, s.issueJSON(r, s.issues[numbers[i]]))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) createIssue(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || strings.TrimSpace(payload.Title) == "" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "title is required"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := &Issue{
+		Number:    s.nextNumber,
+		Title:     payload.Title,
+		Body:      payload.Body,
+		State:     "open",
+		Labels:    []string{},
+		UpdatedAt: time.Now(),
+	}
+	s.nextNumber++
+	s.issues[issue.Number] = issue
+	writeJSON(w, http.StatusCreated, s.issueJSON(r, issue))
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := s.lookup(w, r)
+	if issue == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, s.issueJSON(r, issue))
+}
+
+func (s *Server) patchIssue(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Title *string `json:"title"`
+		Body  *string `json:"body"`
+		State *string `json:"state"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := s.lookup(w, r)
+	if issue == nil {
+		return
+	}
+	if payload.Title != nil {
+		issue.Title = *payload.Title
+	}
+	if payload.Body != nil {
+		issue.Body = *payload.Body
+	}
+	if payload.State != nil {
+		issue.State = *payload.State
+	}
+	issue.UpdatedAt = time.Now()
+	writeJSON(w, http.StatusOK, s.issueJSON(r, issue))
+}
+
+func (s *Server) putLabels(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Labels []string `json:"labels"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := s.lookup(w, r)
+	if issue == nil {
+		return
+	}
+	issue.Labels = append([]string{}, payload.Labels...)
+	issue.UpdatedAt = time.Now()
+	writeJSON(w, http.StatusOK, labelsJSON(issue.Labels))
+}
+
+func (s *Server) listComments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := s.lookup(w, r)
+	if issue == nil {
+		return
+	}
+	result := []map[string]interface{}{}
+	for _, comment := range s.comments[issue.Number] {
+		result = append(result, commentJSON(comment))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) createComment(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || strings.TrimSpace(payload.Body) == "" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "body is required"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := s.lookup(w, r)
+	if issue == nil {
+		return
+	}
+	comment := s.addComment(issue.Number, "sync-bot", payload.Body)
+	writeJSON(w, http.StatusCreated, commentJSON(comment))
+}
+
+func (s *Server) addComment(number int, author, body string) *Comment {
+	comment := &Comment{ID: s.nextCommentID, Author: author, Body: body, CreatedAt: time.Now()}
+	s.nextCommentID++
+	s.comments[number] = append(s.comments[number], comment)
+	if issue, ok := s.issues[number]; ok {
+		// 与 GitHub 一致，新评论会刷新 issue 的 updated_at
+		issue.UpdatedAt = comment.CreatedAt
+	}
+	return comment
+}
+
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) *Issue {
+	number, _ := strconv.Atoi(r.PathValue("number"))
+	issue, ok := s.issues[number]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return nil
+	}
+	return issue
+}
+
+func (s *Server) copyIssue(issue *Issue) *Issue {
+	copied := *issue
+	copied.Labels = append([]string{}, issue.Labels...)
+	return &copied
+}
+
+func (s *Server) issueJSON(r *http.Request, issue *Issue) map[string]interface{} {
+	result := map[string]interface{}{
+		"number":     issue.Number,
+		"title":      issue.Title,
+		"body":       issue.Body,
+		"state":      issue.State,
+		"labels":     labelsJSON(issue.Labels),
+		"html_url":   s.URL + "/" + r.PathValue("owner") + "/" + r.PathValue("repo") + "/issues/" + strconv.Itoa(issue.Number),
+		"updated_at": issue.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	}
+	if issue.PullRequest {
+		result["pull_request"] = map[string]string{"url": ""}
+	}
+	return result
+}
+
+func labelsJSON(labels []string) []map[string]string {
+	result := make([]map[string]string, 0, len(labels))
+	for _, label := range labels {
+		result = append(result, map[string]string{"name": label})
+	}
+	return result
+}
+
+func commentJSON(comment *Comment) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         comment.ID,
+		"body":       comment.Body,
+		"user":       map[string]string{"login": comment.Author},
+		"created_at": comment.CreatedAt.UTC().Format(time.RFC3339Nano),
+		"updated_at": comment.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,19 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	out, err := Render("**done**\n- [x] tests\n\n<script>alert(1)</script>\n\n[x](javascript:alert(1))")
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if !strings.Contains(out, "<strong>done</strong>") || !strings.Contains(out, `type="checkbox"`) {
+		t.Fatalf("unexpected output %s", out)
+	}
+	if strings.Contains(out, "<script>") || strings.Contains(out, "javascript:") {
+		t.Fatalf("expected unsafe content to be dropped, got %s", out)
+	}
+}
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 
@@ -98,3 +100,27 @@ func ensureExecutable(name string) error {
 	_, err := exec.LookPath(name)
 	return err
 }
+
+// ErrShellEnvUnsupported indicates the shell cannot reference an environment variable
+// without re-parsing its value as part of the command line.
+var ErrShellEnvUnsupported = errors.New("shell cannot pass environment variables as a single literal argument")
+
+// ShellEnvReference renders how the given shell expands an environment variable on
+// an interactive command line as one argument. The shells handled here expand the
+// reference after parsing, so the value is never interpreted as syntax. cmd.exe
+// substitutes %NAME% before parsing, letting &, |, > and quotes in the value run as
+// commands, and is therefore rejected.
+func ShellEnvReference(shell, name string) (string, error) {
+	base := strings.ToLower(filepath.Base(strings.TrimSpace(shell)))
+	base = strings.TrimSuffix(base, ".exe")
+	switch base {
+	case "pwsh", "powershell":
+		return "$env:" + name, nil
+	case "cmd":
+		return "", fmt.Errorf("%w: %s, configure pwsh or powershell as the terminal shell", ErrShellEnvUnsupported, base)
+	case "fish":
+		return "$" + name, nil
+	default:
+		return "\"$" + name + "\"", nil
+	}
+}
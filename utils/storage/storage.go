@@ -0,0 +1,56 @@
+// Package storage abstracts where attachment blobs are kept so the same code can
+// write to the local data directory or to an S3-compatible bucket.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+const (
+	// KindLocal identifies the local-disk backend.
+	KindLocal = "local"
+	// KindS3 identifies the S3-compatible backend.
+	KindS3 = "s3"
+)
+
+var (
+	// ErrNotFound indicates the requested object does not exist in the backend.
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidKey indicates an object key that is empty or escapes the storage root.
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Storage stores opaque blobs addressed by slash-separated keys.
+type Storage interface {
+	// Kind reports the backend identifier persisted alongside each object.
+	Kind() string
+	// Put writes data under key, replacing any existing object.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Open returns a reader for the object; callers must close it.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// cleanKey normalizes key and rejects keys that are empty or contain parent references.
+func cleanKey(key string) (string, error) {
+	key = strings.TrimSpace(strings.ReplaceAll(key, "\\", "/"))
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	cleaned := path.Clean(key)
+	if cleaned == "." {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return cleaned, nil
+}
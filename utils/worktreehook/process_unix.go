@@ -0,0 +1,17 @@
+//go:build !windows
+
+package worktreehook
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// isolateProcess starts the shell in its own process group so a timeout also kills
+// the programs it spawned, such as the node processes behind `npm install`.
+func isolateProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
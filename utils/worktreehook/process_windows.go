@@ -0,0 +1,9 @@
+//go:build windows
+
+package worktreehook
+
+import "os/exec"
+
+// isolateProcess keeps the default behaviour of killing only cmd.exe on timeout;
+// WaitDelay stops the runner from waiting on children that keep the pipes open.
+func isolateProcess(cmd *exec.Cmd) {}
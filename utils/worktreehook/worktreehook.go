@@ -0,0 +1,392 @@
+// Package worktreehook prepares freshly created worktrees and cleans up worktrees
+// before removal: copying untracked files such as .env from the main worktree,
+// symlinking shared directories and running setup or teardown commands.
+package worktreehook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames are the repository files hooks are read from, in order of preference.
+var FileNames = []string{".codekanban.yml", ".codekanban.yaml"}
+
+const (
+	// StagePostCreate runs after `git worktree add`.
+	StagePostCreate = "post-create"
+	// StagePreDelete runs before `git worktree remove`.
+	StagePreDelete = "pre-delete"
+
+	// StreamStdout, StreamStderr and StreamInfo tell where an output line came from;
+	// info lines are produced by the runner itself.
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+	StreamInfo   = "info"
+
+	defaultTimeout = 10 * time.Minute
+)
+
+var (
+	// ErrInvalidConfig indicates a malformed hook file or an unsafe path.
+	ErrInvalidConfig = errors.New("invalid worktree hook configuration")
+	// ErrHookFailed indicates a hook step failed.
+	ErrHookFailed = errors.New("worktree hook failed")
+)
+
+// Config lists the hook steps of a project. Paths are relative to the worktree root.
+type Config struct {
+	PostCreate []string `json:"postCreate" yaml:"postCreate"` // 创建后在新 worktree 中依次执行的命令
+	Copy       []string `json:"copy" yaml:"copy"`             // 从主 worktree 复制的文件或 glob，例如 .env*
+	Symlink    []string `json:"symlink" yaml:"symlink"`       // 链接到主 worktree 对应目录，例如 node_modules
+	PreDelete  []string `json:"preDelete" yaml:"preDelete"`   // 删除前在 worktree 中执行的命令
+	Timeout    string   `json:"timeout" yaml:"timeout"`       // 单条命令的超时，默认 10m
+}
+
+// Empty reports whether the config has no steps at all.
+func (c *Config) Empty() bool {
+	return c == nil || len(c.PostCreate)+len(c.Copy)+len(c.Symlink)+len(c.PreDelete) == 0
+}
+
+// Validate rejects paths that escape the worktree and unparsable timeouts.
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	for _, pattern := range append(append([]string{}, c.Copy...), c.Symlink...) {
+		if err := checkRelative(pattern); err != nil {
+			return err
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%w: bad pattern %q", ErrInvalidConfig, pattern)
+		}
+	}
+	if strings.TrimSpace(c.Timeout) != "" {
+		if dur, err := time.ParseDuration(c.Timeout); err != nil || dur <= 0 {
+			return fmt.Errorf("%w: bad timeout %q", ErrInvalidConfig, c.Timeout)
+		}
+	}
+	return nil
+}
+
+// TimeoutDuration returns the per-command timeout.
+func (c *Config) TimeoutDuration() time.Duration {
+	if c != nil {
+		if dur, err := time.ParseDuration(strings.TrimSpace(c.Timeout)); err == nil && dur > 0 {
+			return dur
+		}
+	}
+	return defaultTimeout
+}
+
+type fileConfig struct {
+	Worktree Config `yaml:"worktree"`
+}
+
+// LoadFile reads the `worktree` section of the hook file in dir. It returns nil
+// without error when the repository has no hook file.
+func LoadFile(dir string) (*Config, error) {
+	for _, name := range FileNames {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var file fileConfig
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, name, err)
+		}
+		if err := file.Worktree.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return &file.Worktree, nil
+	}
+	return nil, nil
+}
+
+// Event is one line of hook output.
+type Event struct {
+	Stage  string `json:"stage"`
+	Step   string `json:"step"`   // 命令原文，或 copy/symlink 的路径
+	Stream string `json:"stream"` // stdout、stderr 或 info
+	Line   string `json:"line"`
+}
+
+// OutputFunc receives hook output as it is produced. It may be nil.
+type OutputFunc func(Event)
+
+// Env describes the worktree the hooks run for. Commands see it as CODEKANBAN_*
+// environment variables.
+type Env struct {
+	ProjectID    string
+	MainPath     string
+	WorktreePath string
+	Branch       string
+}
+
+func (e Env) variables() []string {
+	return []string{
+		"CODEKANBAN_PROJECT_ID=" + e.ProjectID,
+		"CODEKANBAN_MAIN_WORKTREE=" + e.MainPath,
+		"CODEKANBAN_WORKTREE=" + e.WorktreePath,
+		"CODEKANBAN_BRANCH=" + e.Branch,
+	}
+}
+
+// RunPostCreate copies files, creates symlinks and then runs the post-create
+// commands, stopping at the first failure.
+func RunPostCreate(ctx context.Context, cfg *Config, env Env, output OutputFunc) error {
+	if cfg == nil {
+		return nil
+	}
+	out := newEmitter(StagePostCreate, output)
+	for _, pattern := range cfg.Copy {
+		if err := copyPattern(env, pattern, out); err != nil {
+			return fmt.Errorf("%w: copy %s: %v", ErrHookFailed, pattern, err)
+		}
+	}
+	for _, path := range cfg.Symlink {
+		if err := linkPath(env, path, out); err != nil {
+			return fmt.Errorf("%w: symlink %s: %v", ErrHookFailed, path, err)
+		}
+	}
+	return runCommands(ctx, cfg, cfg.PostCreate, env, out)
+}
+
+// RunPreDelete runs the pre-delete commands, stopping at the first failure.
+func RunPreDelete(ctx context.Context, cfg *Config, env Env, output OutputFunc) error {
+	if cfg == nil {
+		return nil
+	}
+	return runCommands(ctx, cfg, cfg.PreDelete, env, newEmitter(StagePreDelete, output))
+}
+
+// emitter serialises output from the stdout and stderr readers of a command.
+type emitter struct {
+	mu     sync.Mutex
+	stage  string
+	output OutputFunc
+}
+
+func newEmitter(stage string, output OutputFunc) *emitter {
+	return &emitter{stage: stage, output: output}
+}
+
+func (e *emitter) emit(step, stream, line string) {
+	if e.output == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.output(Event{Stage: e.stage, Step: step, Stream: stream, Line: line})
+}
+
+func runCommands(ctx context.Context, cfg *Config, commands []string, env Env, out *emitter) error {
+	for _, command := range commands {
+		command = strings.TrimSpace(command)
+		if command == "" {
+			continue
+		}
+		out.emit(command, StreamInfo, "$ "+command)
+		if err := runCommand(ctx, cfg.TimeoutDuration(), command, env, out); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrHookFailed, command, err)
+		}
+	}
+	return nil
+}
+
+func runCommand(ctx context.Context, timeout time.Duration, command string, env Env, out *emitter) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Dir = env.WorktreePath
+	cmd.Env = append(os.Environ(), env.variables()...)
+	isolateProcess(cmd)
+	// 命令被取消后，残留的子进程可能仍占用管道，避免 Wait 无限等待
+	cmd.WaitDelay = 5 * time.Second
+	stdout := &lineWriter{emit: func(line string) { out.emit(command, StreamStdout, line) }}
+	stderr := &lineWriter{emit: func(line string) { out.emit(command, StreamStderr, line) }}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	stdout.Flush()
+	stderr.Flush()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd.exe", "/C", command)
+	}
+	return exec.CommandContext(ctx, "/bin/sh", "-c", command)
+}
+
+// lineWriter hands complete lines to emit. Letting exec copy the output, rather than
+// reading pipes ourselves, keeps WaitDelay effective.
+type lineWriter struct {
+	buf  []byte
+	emit func(string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(strings.TrimRight(string(w.buf[:i]), "\r"))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush emits a trailing line that did not end with a newline.
+func (w *lineWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.emit(strings.TrimRight(string(w.buf), "\r"))
+		w.buf = nil
+	}
+}
+
+func checkRelative(path string) error {
+	clean := filepath.Clean(strings.TrimSpace(path))
+	if clean == "." || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%w: %q must be relative to the worktree", ErrInvalidConfig, path)
+	}
+	return nil
+}
+
+// copyPattern copies every match of pattern in the main worktree. Files that already
+// exist in the new worktree, typically tracked ones, are left alone.
+func copyPattern(env Env, pattern string, out *emitter) error {
+	if err := checkRelative(pattern); err != nil {
+		return err
+	}
+	matches, err := filepath.Glob(filepath.Join(env.MainPath, pattern))
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		out.emit(pattern, StreamInfo, "no match in main worktree, skipped")
+		return nil
+	}
+	for _, match := range matches {
+		rel, err := filepath.Rel(env.MainPath, match)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(env.WorktreePath, rel)
+		if _, err := os.Lstat(target); err == nil {
+			out.emit(pattern, StreamInfo, rel+" already exists, skipped")
+			continue
+		}
+		if err := copyTree(match, target); err != nil {
+			return err
+		}
+		out.emit(pattern, StreamInfo, "copied "+rel)
+	}
+	return nil
+}
+
+func copyTree(source, target string) error {
+	return filepath.WalkDir(source, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(target, rel)
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(dest, info.Mode().Perm())
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+				return err
+			}
+			return os.Symlink(link, dest)
+		case info.Mode().IsRegular():
+			return copyFile(path, dest, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+func copyFile(source, target string, mode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// linkPath points path in the new worktree at the same path in the main worktree,
+// so large directories such as dependency caches are shared instead of rebuilt.
+func linkPath(env Env, path string, out *emitter) error {
+	if err := checkRelative(path); err != nil {
+		return err
+	}
+	rel := filepath.Clean(path)
+	source := filepath.Join(env.MainPath, rel)
+	if _, err := os.Stat(source); errors.Is(err, fs.ErrNotExist) {
+		out.emit(path, StreamInfo, rel+" does not exist in main worktree, skipped")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	target := filepath.Join(env.WorktreePath, rel)
+	if _, err := os.Lstat(target); err == nil {
+		out.emit(path, StreamInfo, rel+" already exists, skipped")
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if err := os.Symlink(source, target); err != nil {
+		return err
+	}
+	out.emit(path, StreamInfo, "linked "+rel)
+	return nil
+}
@@ -0,0 +1,107 @@
+package worktreehook
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := LoadFile(dir)
+	if err != nil || cfg != nil {
+		t.Fatalf("expected no config without a hook file, got %+v (%v)", cfg, err)
+	}
+
+	content := "worktree:\n  copy: [\".env*\"]\n  symlink: [node_modules]\n  postCreate:\n    - npm install\n  timeout: 2m\n"
+	if err := os.WriteFile(filepath.Join(dir, ".codekanban.yml"), []byte(content), 0o644); err != nil {
+		t.Fatalf("write hook file: %v", err)
+	}
+	cfg, err = LoadFile(dir)
+	if err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+	if len(cfg.Copy) != 1 || cfg.Symlink[0] != "node_modules" || cfg.PostCreate[0] != "npm install" || cfg.TimeoutDuration().Minutes() != 2 {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".codekanban.yml"), []byte("worktree:\n  copy: [../secrets]\n"), 0o644); err != nil {
+		t.Fatalf("write hook file: %v", err)
+	}
+	if _, err := LoadFile(dir); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig for a path outside the worktree, got %v", err)
+	}
+}
+
+func TestRunPostCreate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands use /bin/sh in this test")
+	}
+	mainPath := t.TempDir()
+	worktreePath := t.TempDir()
+	writeFile(t, filepath.Join(mainPath, ".env"), "TOKEN=1\n")
+	writeFile(t, filepath.Join(mainPath, ".env.local"), "LOCAL=1\n")
+	writeFile(t, filepath.Join(mainPath, "config", "app.json"), "{}\n")
+	writeFile(t, filepath.Join(worktreePath, "config", "app.json"), "tracked\n")
+	writeFile(t, filepath.Join(mainPath, "node_modules", "pkg", "index.js"), "module.exports = 1\n")
+
+	cfg := &Config{
+		Copy:       []string{".env*", "config/app.json", "missing.txt"},
+		Symlink:    []string{"node_modules"},
+		PostCreate: []string{"echo setup $CODEKANBAN_BRANCH", "echo warn >&2"},
+	}
+	var events []Event
+	env := Env{ProjectID: "p1", MainPath: mainPath, WorktreePath: worktreePath, Branch: "feature/x"}
+	if err := RunPostCreate(context.Background(), cfg, env, func(e Event) { events = append(events, e) }); err != nil {
+		t.Fatalf("RunPostCreate returned error: %v", err)
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(worktreePath, ".env.local")); string(content) != "LOCAL=1\n" {
+		t.Fatalf("expected .env.local to be copied, got %q", content)
+	}
+	if content, _ := os.ReadFile(filepath.Join(worktreePath, "config", "app.json")); string(content) != "tracked\n" {
+		t.Fatalf("existing file must not be overwritten, got %q", content)
+	}
+	if link, err := os.Readlink(filepath.Join(worktreePath, "node_modules")); err != nil || link != filepath.Join(mainPath, "node_modules") {
+		t.Fatalf("expected node_modules symlink, got %q (%v)", link, err)
+	}
+
+	var sawStdout, sawStderr bool
+	for _, event := range events {
+		if event.Stage != StagePostCreate {
+			t.Fatalf("unexpected stage in %+v", event)
+		}
+		if event.Stream == StreamStdout && event.Line == "setup feature/x" {
+			sawStdout = true
+		}
+		if event.Stream == StreamStderr && event.Line == "warn" {
+			sawStderr = true
+		}
+	}
+	if !sawStdout || !sawStderr {
+		t.Fatalf("expected command output to be streamed, got %+v", events)
+	}
+
+	err := RunPostCreate(context.Background(), &Config{PostCreate: []string{"exit 3"}}, env, nil)
+	if !errors.Is(err, ErrHookFailed) || !strings.Contains(err.Error(), "exit 3") {
+		t.Fatalf("expected ErrHookFailed naming the command, got %v", err)
+	}
+	err = RunPreDelete(context.Background(), &Config{PreDelete: []string{"sleep 5"}, Timeout: "100ms"}, env, nil)
+	if !errors.Is(err, ErrHookFailed) || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout failure, got %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}